@@ -1,26 +1,200 @@
 package cache
 
 import (
-	"compress/gzip"
-	"encoding/gob"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/logging"
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
+// tempSuffix marks a snapshot that is still being written
+const tempSuffix = ".tmp"
+
+// maxSnapshots is how many snapshots are kept per key unless the retention
+// policy says otherwise
+const maxSnapshots = 3
+
+// timestampFormat is the snapshot time embedded in filenames
+const timestampFormat = "2006-01-02_150405"
+
+// maxKeyName is how much of a path Key keeps readable; the hash after it
+// tells longer paths apart
+const maxKeyName = 80
+
+// Header summarizes a snapshot. It is written ahead of the tree so it can be
+// read without decoding the whole scan.
+type Header struct {
+	Path      string
+	ScannedAt time.Time
+	Duration  time.Duration // How long the scan took, 0 if unknown
+	Files     int64
+	Dirs      int64
+	Bytes     int64
+	Env       Environment // Machine and settings, empty in snapshots from older versions
+	Format    int         // Layout version, 0 in snapshots written before it was recorded
+
+	// Snapshot file of the same key this one lists changes against, empty if
+	// it is complete, and how many such steps lead to a complete one
+	Base  string
+	Depth int
+
+	Label string // Note attached by the user, such as "before Xcode install"
+}
+
+// Snapshot is a scan ready to be written. It reads the tree as it is
+// written rather than copying it first, so a tree of millions of entries is
+// never held twice; the tree must not change until the snapshot releases it.
+type Snapshot struct {
+	Header Header
+	root   *model.Node
+
+	// History is the size history of the path as read before the scan, if
+	// it was, so Write can append to it without reading it again
+	History *History
+
+	released chan struct{} // Closed once Write no longer reads root
+	once     sync.Once
+}
+
+// NewSnapshot prepares root to be written. Wait for Released before changing
+// the tree again, by attaching estimates or letting a watcher at it.
+func NewSnapshot(root *model.Node, took time.Duration) *Snapshot {
+	s := snapshotOf(Header{Path: root.Path(), Duration: took, Bytes: root.TotalSize()}, root)
+	s.Header.Files, s.Header.Dirs = countNodes(root)
+	return s
+}
+
+// snapshotOf returns a snapshot of root with header as it is
+func snapshotOf(header Header, root *model.Node) *Snapshot {
+	return &Snapshot{Header: header, root: root, released: make(chan struct{})}
+}
+
+// Released returns a channel closed once Write is done reading the tree,
+// whether it succeeded or not. Applying the retention policy comes after.
+func (s *Snapshot) Released() <-chan struct{} {
+	return s.released
+}
+
+// release lets the tree change again
+func (s *Snapshot) release() {
+	s.once.Do(func() { close(s.released) })
+}
+
+// countNodes returns the number of files and directories below n
+func countNodes(n *model.Node) (files, dirs int64) {
+	for _, child := range n.Children {
+		if child.IsDir {
+			dirs++
+			f, d := countNodes(child)
+			files += f
+			dirs += d
+		} else {
+			files++
+		}
+	}
+	return files, dirs
+}
+
+// Key turns a scan path into a name safe to use in snapshot filenames: the
+// last maxKeyName characters of the path with separators, colons and
+// underscores as dashes, then a hash of the whole path. The hash keeps apart
+// paths that read the same once mapped, such as "/a/b" and "/a-b", or that
+// differ only in case on a filesystem that ignores it.
+func Key(path string) string {
+	path = filepath.Clean(path)
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, path)
+	if len(name) > maxKeyName {
+		name = name[len(name)-maxKeyName:]
+	}
+	h := fnv.New64a()
+	h.Write([]byte(path))
+	return fmt.Sprintf("%s-%016x", name, h.Sum64())
+}
+
+// legacyKey is the name Key gave path before it added a hash. Files saved
+// under it are still read, and go as retention removes them or, for a size
+// history, once it is written under the new name.
+func legacyKey(path string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '-'
+	}, path)
+}
+
+// samePath reports whether a snapshot recorded for path belongs to key, so
+// a file that ended up under another key's name is not taken for its own
+func samePath(path, key string) bool {
+	return filepath.Clean(path) == filepath.Clean(key)
+}
+
+// Retention decides which snapshots are removed after each save. The
+// newest snapshot of each key is always kept.
+type Retention struct {
+	Keep     int           // Newest snapshots kept per key
+	MaxAge   time.Duration // Older snapshots are removed; 0 sets no limit
+	MaxBytes int64         // Cap on the snapshots of all keys together; 0 sets none
+}
+
+// Pruned is a snapshot file the retention policy removed
+type Pruned struct {
+	File   string
+	Reason string
+}
+
 // Cache handles saving and loading scan results
 type Cache struct {
-	dir string
+	dir       string
+	clock     clock.Clock
+	retention Retention
+
+	key    []byte // Set by Unlock; nil writes plain files
+	keyErr error  // Why encryption was asked for but is unavailable
+
+	mu     sync.Mutex // Guards pruned; saves run in the background
+	pruned []Pruned
 }
 
 // New creates a new cache in the given directory
 func New(dir string) *Cache {
-	return &Cache{dir: dir}
+	return &Cache{dir: dir, clock: clock.Real(), retention: Retention{Keep: maxSnapshots}}
+}
+
+// SetRetention replaces the policy applied after each save
+func (c *Cache) SetRetention(r Retention) {
+	r.Keep = max(r.Keep, 1)
+	c.retention = r
+}
+
+// Pruned returns the snapshots removed by the retention policy since the
+// cache was created, oldest removal first
+func (c *Cache) Pruned() []Pruned {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.pruned)
+}
+
+// SetClock replaces the time source used for snapshot timestamps (for tests)
+func (c *Cache) SetClock(clk clock.Clock) {
+	c.clock = clk
 }
 
 // DefaultDir returns the default cache directory
@@ -34,95 +208,495 @@ func DefaultDir() string {
 
 // Save saves a scan result for the given drive
 func (c *Cache) Save(driveLetter string, root *model.Node) error {
+	return c.Write(driveLetter, NewSnapshot(root, 0))
+}
+
+// Write stores a snapshot under key, adds it to the size history of key and
+// applies the retention policy. The file is written under a temporary name
+// and renamed once complete, so an interrupted write never replaces a usable
+// snapshot. A history that cannot be updated is logged, not returned, as the
+// snapshot itself was stored.
+func (c *Cache) Write(key string, snap *Snapshot) error {
+	defer snap.release()
 	if err := os.MkdirAll(c.dir, 0755); err != nil {
 		return fmt.Errorf("create cache dir: %w", err)
 	}
 
-	filename := fmt.Sprintf("%s_%s.gob.gz",
-		driveLetter,
-		time.Now().Format("2006-01-02_150405"))
+	now := c.clock.Now()
+	snap.Header.ScannedAt = now
+	snap.Header.Path = key // Checked against the key it is looked up by
 
-	path := filepath.Join(c.dir, filename)
+	path := c.File(key, now)
+	if err := c.writeFile(path, snap, c.deltaBase(key, path)); err != nil {
+		return err
+	}
 
-	file, err := os.Create(path)
+	if err := c.updateHistory(key, snap); err != nil {
+		logging.Debug.Printf("[Cache] Size history of %s not updated: %v", filepath.Base(path), err)
+	}
+	snap.release()
+	c.prune(key)
+	return nil
+}
+
+// writeFile writes snap to path through a temporary file, stored against
+// base if there is one
+func (c *Cache) writeFile(path string, snap *Snapshot, base *deltaBase) error {
+	if c.keyErr != nil {
+		return fmt.Errorf("snapshot encryption: %w", c.keyErr)
+	}
+	snap.Header.Format = formatVersion
+	tmp := path + tempSuffix
+	file, err := os.Create(tmp)
 	if err != nil {
 		return fmt.Errorf("create file: %w", err)
 	}
-	defer file.Close()
+	if err := writeSnapshot(file, snap, base, c.key); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
 
-	gzWriter := gzip.NewWriter(file)
-	defer gzWriter.Close()
+// deltaBase picks the snapshot a new one of key, to be written to path, is
+// stored against: the newest, unless it cannot be read, is too old a format
+// or ends a chain already maxDeltaChain long. Nil means storing it complete.
+func (c *Cache) deltaBase(key, path string) *deltaBase {
+	// Only a file under the current name, as a legacy one may be removed
+	// without the snapshots stored against it being found
+	files, err := c.namedFiles(Key(key))
+	if err != nil || len(files) == 0 || files[len(files)-1] == path {
+		return nil
+	}
+	latest := files[len(files)-1]
+	base, err := c.openBase(latest)
+	if err == nil && !samePath(base.path, key) {
+		err = fmt.Errorf("snapshot of %s", base.path)
+	}
+	if err != nil {
+		logging.Debug.Printf("[Cache] Storing %s complete, %s unusable as a base: %v", filepath.Base(path), filepath.Base(latest), err)
+		return nil
+	}
+	if base.depth >= maxDeltaChain {
+		return nil
+	}
+	return base
+}
+
+// openBase reads what a snapshot stored against file needs of it
+func (c *Cache) openBase(file string) (*deltaBase, error) {
+	s, err := openSnapshot(file, c.key)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+	hashes, err := s.readHashes()
+	if err != nil {
+		return nil, err
+	}
+	return &deltaBase{name: filepath.Base(file), path: s.Header.Path, depth: s.Header.Depth, hashes: hashes}, nil
+}
 
-	// Convert to CacheNode to avoid circular references
-	cacheNode := root.ToCacheNode()
+// Label sets the label of the snapshot of key taken at t, or removes it if
+// label is empty. The snapshot is rewritten in place, against the same base.
+func (c *Cache) Label(key string, t time.Time, label string) error {
+	path := c.File(key, t)
+	s, err := openSnapshot(path, c.key)
+	if err != nil {
+		return err
+	}
+	root, err := s.readTree()
+	header := s.Header
+	s.Close()
+	if err != nil {
+		return err
+	}
 
-	encoder := gob.NewEncoder(gzWriter)
-	if err := encoder.Encode(cacheNode); err != nil {
-		return fmt.Errorf("encode: %w", err)
+	var base *deltaBase
+	if header.Base != "" {
+		if base, err = c.openBase(filepath.Join(c.dir, header.Base)); err != nil {
+			return fmt.Errorf("read base %s: %w", header.Base, err)
+		}
 	}
+	header.Label = label
+	return c.writeFile(path, snapshotOf(header, root), base)
+}
 
-	return nil
+// File returns where the snapshot of key taken at t is stored
+func (c *Cache) File(key string, t time.Time) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%s_%s.gob.gz", Key(key), t.Format(timestampFormat)))
 }
 
-// LoadLatest loads the most recent cache for a drive
+// LoadLatest loads the most recent cache for a drive, falling back to an
+// older snapshot when the newest one is incomplete or damaged
 func (c *Cache) LoadLatest(driveLetter string) (*model.Node, error) {
-	pattern := filepath.Join(c.dir, fmt.Sprintf("%s_*.gob.gz", driveLetter))
-	files, err := filepath.Glob(pattern)
+	var root *model.Node
+	err := c.newest(driveLetter, func(s *snapshotFile) error {
+		var err error
+		root, err = s.readTree()
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("glob: %w", err)
+		return nil, err
 	}
+	return root, nil
+}
 
-	if len(files) == 0 {
-		return nil, fmt.Errorf("no cache found for drive %s", driveLetter)
+// List returns the headers of the readable snapshots for key, newest first
+func (c *Cache) List(key string) ([]Header, error) {
+	files, err := c.snapshotFiles(key)
+	if err != nil {
+		return nil, err
 	}
+	var headers []Header
+	for i := len(files) - 1; i >= 0; i-- {
+		s, err := openSnapshot(files[i], c.key)
+		if err != nil {
+			continue
+		}
+		if !samePath(s.Header.Path, key) {
+			s.Close()
+			continue
+		}
+		headers = append(headers, s.Header)
+		s.Close()
+	}
+	return headers, nil
+}
 
-	// Sort to get latest (filenames include timestamp)
-	sort.Strings(files)
-	latest := files[len(files)-1]
+// LoadSubtree loads the folder at path from the most recent snapshot for key,
+// so a folder inside an already-scanned drive can be shown without scanning
+// it again. The header describes the whole snapshot.
+func (c *Cache) LoadSubtree(key, path string) (*model.Node, Header, error) {
+	var sub *model.Node
+	var header Header
+	err := c.newest(key, func(s *snapshotFile) error {
+		root, err := s.readTree()
+		if err != nil {
+			return err
+		}
+		sub, header = root.Find(path), s.Header
+		return nil
+	})
+	if err != nil {
+		return nil, header, err
+	}
+	if sub == nil || !sub.IsDir {
+		return nil, header, fmt.Errorf("%s is not a folder in the snapshot of %s", path, key)
+	}
+
+	if sub.Parent != nil {
+		sub.Parent.RemoveChild(sub)
+	}
+	sub.SetPath(path)
+	return sub, header, nil
+}
 
-	file, err := os.Open(latest)
+// LoadHeader reads only the summary of the most recent snapshot for key,
+// falling back to an older snapshot when the newest one is incomplete or damaged
+func (c *Cache) LoadHeader(key string) (Header, error) {
+	var header Header
+	err := c.newest(key, func(s *snapshotFile) error {
+		header = s.Header
+		return nil
+	})
+	return header, err
+}
+
+// LoadBefore loads the newest snapshot for key taken at or before t, for
+// comparing a scan with how the folder looked back then
+func (c *Cache) LoadBefore(key string, t time.Time) (*model.Node, Header, error) {
+	var root *model.Node
+	var header Header
+	err := c.newestBefore(key, t, func(s *snapshotFile) error {
+		var err error
+		root, err = s.readTree()
+		header = s.Header
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("open: %w", err)
+		return nil, header, err
 	}
-	defer file.Close()
+	return root, header, nil
+}
 
-	gzReader, err := gzip.NewReader(file)
+// newest calls read with the newest snapshot for key that opens and reads
+// cleanly, skipping ones that are incomplete, damaged, unreadable or written
+// by a newer version. If none is usable it returns the error of the newest.
+func (c *Cache) newest(key string, read func(s *snapshotFile) error) error {
+	return c.newestBefore(key, time.Time{}, read)
+}
+
+// newestBefore is newest limited to snapshots taken at or before t; a zero
+// t sets no limit
+func (c *Cache) newestBefore(key string, t time.Time, read func(s *snapshotFile) error) error {
+	files, err := c.snapshotFiles(key)
 	if err != nil {
-		return nil, fmt.Errorf("gzip reader: %w", err)
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no cache found for %s", key)
 	}
-	defer gzReader.Close()
 
-	var cacheNode model.CacheNode
-	decoder := gob.NewDecoder(gzReader)
-	if err := decoder.Decode(&cacheNode); err != nil {
-		return nil, fmt.Errorf("decode: %w", err)
+	var firstErr error
+	for i := len(files) - 1; i >= 0; i-- {
+		s, err := openSnapshot(files[i], c.key)
+		if err == nil && !samePath(s.Header.Path, key) {
+			s.Close()
+			err = fmt.Errorf("snapshot of %s, not %s", s.Header.Path, key)
+		}
+		if err == nil && !t.IsZero() && s.Header.ScannedAt.After(t) {
+			s.Close()
+			continue
+		}
+		if err == nil {
+			err = read(s)
+			s.Close()
+		}
+		if err == nil {
+			return nil
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", filepath.Base(files[i]), err)
+		}
 	}
+	if firstErr == nil {
+		return fmt.Errorf("no snapshot of %s from before %s", key, t.Format(time.DateTime))
+	}
+	return firstErr
+}
 
-	// Convert back to Node (this also sets Parent links)
-	return cacheNode.ToNode(nil), nil
+// Recover discards what interrupted writes left behind: temporary files and
+// snapshots cut short. Call it at startup. Another running instance may be
+// writing to the same cache, so a temporary file is only removed once it
+// has gone untouched for longer than a scan may go without a heartbeat.
+// It returns the files removed.
+func (c *Cache) Recover() []string {
+	var removed []string
+	now := c.clock.Now()
+	tmps, _ := filepath.Glob(filepath.Join(c.dir, "*.gob.gz"+tempSuffix))
+	histories, _ := filepath.Glob(filepath.Join(c.dir, "*"+historySuffix+tempSuffix))
+	claims, _ := filepath.Glob(filepath.Join(c.dir, "*"+scanSuffix+tempSuffix))
+	for _, f := range slices.Concat(tmps, histories, claims) {
+		info, err := os.Stat(f)
+		if err != nil || now.Sub(info.ModTime()) <= scanStale {
+			continue // Gone already, or still being written
+		}
+		if os.Remove(f) == nil {
+			removed = append(removed, f)
+		}
+	}
+
+	snaps, _ := filepath.Glob(filepath.Join(c.dir, "*.gob.gz"))
+	for _, f := range snaps {
+		s, err := openSnapshot(f, c.key)
+		if err == nil {
+			s.Close()
+			continue
+		}
+		if errors.Is(err, ErrIncomplete) && os.Remove(f) == nil {
+			removed = append(removed, f)
+		}
+	}
+	return removed
 }
 
 // Timestamp returns the timestamp of the latest cache
 func (c *Cache) Timestamp(driveLetter string) (time.Time, error) {
-	pattern := filepath.Join(c.dir, fmt.Sprintf("%s_*.gob.gz", driveLetter))
+	latest, err := c.latestFile(driveLetter)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Extract timestamp from filename
+	_, stamp := fileName(latest)
+	return time.Parse(timestampFormat, stamp)
+}
+
+// snapshotFiles returns the snapshot files for key, oldest first, including
+// those saved under its legacyKey name that were taken of key
+func (c *Cache) snapshotFiles(key string) ([]string, error) {
+	files, err := c.namedFiles(Key(key))
+	if err != nil {
+		return nil, err
+	}
+	legacy, err := c.namedFiles(legacyKey(key))
+	if err != nil || len(legacy) == 0 {
+		return files, err
+	}
+	for _, f := range legacy {
+		// Paths that map to the same legacy name share it
+		s, err := openSnapshot(f, c.key)
+		if err != nil {
+			continue
+		}
+		if samePath(s.Header.Path, key) {
+			files = append(files, f)
+		}
+		s.Close()
+	}
+	sort.SliceStable(files, func(i, j int) bool { return fileTime(files[i]).Before(fileTime(files[j])) })
+	return files, nil
+}
+
+// namedFiles returns the snapshot files whose names start with name, what
+// Key made of their path, oldest first
+func (c *Cache) namedFiles(name string) ([]string, error) {
+	pattern := filepath.Join(c.dir, fmt.Sprintf("%s_*.gob.gz", name))
 	files, err := filepath.Glob(pattern)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("glob error: %w", err)
+		return nil, fmt.Errorf("glob: %w", err)
+	}
+
+	// Filenames include the timestamp, so they sort chronologically
+	sort.Strings(files)
+	return files, nil
+}
+
+// latestFile returns the newest snapshot file for key
+func (c *Cache) latestFile(key string) (string, error) {
+	files, err := c.snapshotFiles(key)
+	if err != nil {
+		return "", err
 	}
 	if len(files) == 0 {
-		return time.Time{}, fmt.Errorf("no cache")
+		return "", fmt.Errorf("no cache found for %s", key)
 	}
+	return files[len(files)-1], nil
+}
 
-	sort.Strings(files)
-	latest := files[len(files)-1]
+// prune applies the retention policy after a snapshot of key was written
+func (c *Cache) prune(key string) {
+	r := c.retention
+	files, err := c.snapshotFiles(key)
+	if err != nil {
+		return
+	}
+	now := c.clock.Now()
+	for i, f := range files[:max(len(files)-1, 0)] {
+		switch {
+		case len(files)-i > r.Keep:
+			c.remove(f, fmt.Sprintf("more than %d snapshots of %s", r.Keep, key))
+		case r.MaxAge > 0 && now.Sub(fileTime(f)) > r.MaxAge:
+			c.remove(f, fmt.Sprintf("older than %s", r.MaxAge))
+		}
+	}
+	if r.MaxBytes > 0 {
+		c.pruneTotal(r.MaxBytes)
+	}
+}
 
-	// Extract timestamp from filename
-	base := filepath.Base(latest)
-	base = strings.TrimSuffix(base, ".gob.gz")
-	parts := strings.SplitN(base, "_", 2)
-	if len(parts) != 2 {
-		return time.Time{}, fmt.Errorf("invalid filename")
+// pruneTotal removes the oldest snapshots of any key until all of them
+// together take at most limit bytes, keeping the newest of each key
+func (c *Cache) pruneTotal(limit int64) {
+	files, _ := filepath.Glob(filepath.Join(c.dir, "*.gob.gz"))
+	sort.Slice(files, func(i, j int) bool { return fileTime(files[i]).Before(fileTime(files[j])) })
+
+	newest := make(map[string]string) // Key part of the name to its newest file
+	sizes := make(map[string]int64)
+	var total int64
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		newest[fileKey(f)] = f
+		sizes[f] = info.Size()
+		total += info.Size()
 	}
 
-	return time.Parse("2006-01-02_150405", parts[1])
+	for _, f := range files {
+		if total <= limit {
+			return
+		}
+		if newest[fileKey(f)] == f {
+			continue
+		}
+		if c.remove(f, fmt.Sprintf("snapshots over %d bytes in total", limit)) {
+			total -= sizes[f]
+		}
+	}
+}
+
+// remove deletes a snapshot file and records why, returning whether it went.
+// Snapshots stored against it are rewritten complete first; if that fails
+// it stays.
+func (c *Cache) remove(file, reason string) bool {
+	if err := c.detach(file); err != nil {
+		logging.Debug.Printf("[Cache] Keeping %s: %v", filepath.Base(file), err)
+		return false
+	}
+	if err := os.Remove(file); err != nil {
+		return false
+	}
+	c.mu.Lock()
+	c.pruned = append(c.pruned, Pruned{File: file, Reason: reason})
+	c.mu.Unlock()
+	return true
+}
+
+// detach rewrites the snapshots stored against file as complete ones, so
+// file can go
+func (c *Cache) detach(file string) error {
+	files, err := c.namedFiles(fileKey(file))
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(file)
+	for _, f := range files {
+		if f == file {
+			continue
+		}
+		s, err := openSnapshot(f, c.key)
+		if err != nil {
+			continue
+		}
+		if s.Header.Base != name {
+			s.Close()
+			continue
+		}
+		root, err := s.readTree()
+		header := s.Header
+		s.Close()
+		if err != nil {
+			return fmt.Errorf("read %s: %w", filepath.Base(f), err)
+		}
+		snap := snapshotOf(header, root)
+		if err := c.writeFile(f, snap, nil); err != nil {
+			return fmt.Errorf("rewrite %s: %w", filepath.Base(f), err)
+		}
+	}
+	return nil
+}
+
+// fileName splits a snapshot filename into its key and timestamp parts
+func fileName(file string) (key, stamp string) {
+	base := strings.TrimSuffix(filepath.Base(file), ".gob.gz")
+	if len(base) <= len(timestampFormat) {
+		return base, ""
+	}
+	return base[:len(base)-len(timestampFormat)-1], base[len(base)-len(timestampFormat):]
+}
+
+// fileKey returns the key part of a snapshot filename
+func fileKey(file string) string {
+	key, _ := fileName(file)
+	return key
+}
+
+// fileTime returns when a snapshot was written, from its filename, or the
+// zero time if the name holds none
+func fileTime(file string) time.Time {
+	_, stamp := fileName(file)
+	t, _ := time.ParseInLocation(timestampFormat, stamp, time.Local)
+	return t
 }
@@ -1,9 +1,15 @@
 package cache
 
 import (
+	"errors"
+	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/lumipallolabs/diskdive/internal/clock"
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
@@ -13,13 +19,13 @@ func TestSaveAndLoad(t *testing.T) {
 
 	// Create test tree
 	root := &model.Node{
-		Path:  "C:\\",
 		Name:  "C:",
 		IsDir: true,
 		Children: []*model.Node{
-			{Path: "C:\\file.txt", Name: "file.txt", Size: 100},
+			{Name: "file.txt", Size: 100},
 		},
 	}
+	root.SetPath("C:\\")
 
 	// Save
 	err := c.Save("C", root)
@@ -28,7 +34,7 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 
 	// Verify file exists
-	files, _ := filepath.Glob(filepath.Join(tmp, "C_*.gob.gz"))
+	files, _ := filepath.Glob(filepath.Join(tmp, Key("C")+"_*.gob.gz"))
 	if len(files) == 0 {
 		t.Fatal("no cache file created")
 	}
@@ -57,3 +63,447 @@ func TestLoadLatestNoCache(t *testing.T) {
 		t.Error("expected error for missing cache")
 	}
 }
+
+func TestLoadLatestPicksNewestSnapshot(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	if err := c.Save("D", &model.Node{Name: "old", IsDir: true}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	clk.Advance(time.Hour)
+	if err := c.Save("D", &model.Node{Name: "new", IsDir: true}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tmp, Key("D")+"_2024-01-01_*.gob.gz"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 timestamped snapshots, got %v", files)
+	}
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "new" {
+		t.Errorf("expected newest snapshot, got %s", loaded.Name)
+	}
+}
+
+func TestLoadBefore(t *testing.T) {
+	c := New(t.TempDir())
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	clk := clock.NewFake(start)
+	c.SetClock(clk)
+
+	for _, name := range []string{"monday", "tuesday", "wednesday"} {
+		if err := c.Save("D", &model.Node{Name: name, IsDir: true}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(24 * time.Hour)
+	}
+
+	loaded, h, err := c.LoadBefore("D", start.Add(36*time.Hour))
+	if err != nil {
+		t.Fatalf("LoadBefore failed: %v", err)
+	}
+	if loaded.Name != "tuesday" || !h.ScannedAt.Equal(start.Add(24*time.Hour)) {
+		t.Errorf("expected tuesday's snapshot, got %s from %v", loaded.Name, h.ScannedAt)
+	}
+	if _, _, err := c.LoadBefore("D", start.Add(-time.Minute)); err == nil {
+		t.Error("expected an error when every snapshot is newer")
+	}
+}
+
+func TestList(t *testing.T) {
+	c := New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	for _, size := range []int64{100, 200} {
+		root := &model.Node{Name: "data", IsDir: true}
+		root.SetPath("/data")
+		root.AddChild(&model.Node{Name: "a.bin", Size: size})
+		if err := c.Write(root.Path(), NewSnapshot(root, time.Second)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		clk.Advance(time.Hour)
+	}
+
+	headers, err := c.List("/data")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(headers) != 2 || headers[0].Bytes != 200 || headers[1].Bytes != 100 {
+		t.Errorf("expected both snapshots, newest first, got %+v", headers)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	c := New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	var times []time.Time
+	for i, size := range []int64{100, 200} {
+		root := &model.Node{Name: "data", IsDir: true}
+		root.SetPath("/data")
+		root.AddChild(&model.Node{Name: "a.bin", Size: size})
+		root.AddChild(&model.Node{Name: "b.bin", Size: int64(i)})
+		snap := NewSnapshot(root, 0)
+		if i == 0 {
+			snap.Header.Label = "before cleanup"
+		}
+		if err := c.Write(root.Path(), snap); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		times = append(times, clk.Now())
+		clk.Advance(time.Hour)
+	}
+
+	if err := c.Label("/data", times[1], "after cleanup"); err != nil {
+		t.Fatalf("Label failed: %v", err)
+	}
+	headers, err := c.List("/data")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if headers[0].Label != "after cleanup" || headers[1].Label != "before cleanup" {
+		t.Errorf("expected both labels, got %q and %q", headers[0].Label, headers[1].Label)
+	}
+	if headers[0].Base != filepath.Base(c.File("/data", times[0])) || !headers[0].ScannedAt.Equal(times[1]) {
+		t.Errorf("expected the relabeled snapshot still stored against the first, got %+v", headers[0])
+	}
+	loaded, _, err := c.LoadBefore("/data", times[1])
+	if err != nil {
+		t.Fatalf("LoadBefore failed: %v", err)
+	}
+	if loaded.Find("/data/a.bin").Size != 200 {
+		t.Errorf("expected the tree kept, got %+v", loaded.Children)
+	}
+
+	if err := c.Label("/data", times[0], ""); err != nil {
+		t.Fatalf("Label failed: %v", err)
+	}
+	if h, _ := c.List("/data"); h[1].Label != "" {
+		t.Errorf("expected the label removed, got %q", h[1].Label)
+	}
+	if err := c.Label("/data", times[0].Add(time.Minute), "x"); err == nil {
+		t.Error("expected an error labeling a snapshot that does not exist")
+	}
+}
+
+func TestLoadHeader(t *testing.T) {
+	c := New(t.TempDir())
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath("/data")
+	sub := &model.Node{Name: "sub", IsDir: true}
+	sub.AddChild(&model.Node{Name: "a.bin", Size: 300})
+	root.AddChild(sub)
+	root.AddChild(&model.Node{Name: "b.txt", Size: 100})
+
+	if err := c.Write(root.Path(), NewSnapshot(root, 5*time.Second)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	h, err := c.LoadHeader("/data")
+	if err != nil {
+		t.Fatalf("LoadHeader failed: %v", err)
+	}
+	if h.Files != 2 || h.Dirs != 1 || h.Bytes != 400 {
+		t.Errorf("expected 2 files, 1 dir, 400 bytes, got %+v", h)
+	}
+	if h.Duration != 5*time.Second || h.Path != "/data" {
+		t.Errorf("unexpected header %+v", h)
+	}
+}
+
+func TestWriteReleasesTree(t *testing.T) {
+	tmp := t.TempDir()
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath("/data")
+	root.AddChild(&model.Node{Name: "a.bin", Size: 300})
+
+	snap := NewSnapshot(root, time.Second)
+	select {
+	case <-snap.Released():
+		t.Fatal("expected the tree to be held before Write")
+	default:
+	}
+	if err := New(tmp).Write(root.Path(), snap); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	select {
+	case <-snap.Released():
+	default:
+		t.Error("expected Write to release the tree")
+	}
+
+	// A write that fails lets go of the tree too
+	blocked := filepath.Join(tmp, "file")
+	os.WriteFile(blocked, nil, 0644)
+	snap = NewSnapshot(root, time.Second)
+	if err := New(blocked).Write(root.Path(), snap); err == nil {
+		t.Fatal("expected Write into a file to fail")
+	}
+	select {
+	case <-snap.Released():
+	default:
+		t.Error("expected a failed Write to release the tree")
+	}
+}
+
+func TestLoadSubtree(t *testing.T) {
+	c := New(t.TempDir())
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath("/data")
+	sub := &model.Node{Name: "sub", IsDir: true}
+	sub.AddChild(&model.Node{Name: "a.bin", Size: 300})
+	root.AddChild(sub)
+	root.AddChild(&model.Node{Name: "b.txt", Size: 100})
+
+	if err := c.Write(root.Path(), NewSnapshot(root, time.Second)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	loaded, h, err := c.LoadSubtree("/data", filepath.Join("/data", "sub"))
+	if err != nil {
+		t.Fatalf("LoadSubtree failed: %v", err)
+	}
+	if loaded.Parent != nil || loaded.Path() != filepath.Join("/data", "sub") {
+		t.Errorf("expected a detached root at /data/sub, got %q", loaded.Path())
+	}
+	loaded.ComputeSizes()
+	if loaded.TotalSize() != 300 || len(loaded.Children) != 1 {
+		t.Errorf("expected the 300 byte folder, got %d bytes", loaded.TotalSize())
+	}
+	if got := loaded.Children[0].Path(); got != filepath.Join("/data", "sub", "a.bin") {
+		t.Errorf("unexpected child path %q", got)
+	}
+	if h.Path != "/data" {
+		t.Errorf("expected the header of the whole snapshot, got %+v", h)
+	}
+
+	for _, missing := range []string{"/data/none", "/data/b.txt", "/elsewhere"} {
+		if _, _, err := c.LoadSubtree("/data", filepath.FromSlash(missing)); err == nil {
+			t.Errorf("expected an error for %s", missing)
+		}
+	}
+}
+
+func TestHeaderEnvironment(t *testing.T) {
+	c := New(t.TempDir())
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath("/data")
+
+	snap := NewSnapshot(root, time.Second)
+	snap.Header.Env = CaptureEnvironment("1.2.3", []string{"crossMounts"})
+	if err := c.Write(root.Path(), snap); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	h, err := c.LoadHeader("/data")
+	if err != nil {
+		t.Fatalf("LoadHeader failed: %v", err)
+	}
+	env := h.Env
+	if env.Version != "1.2.3" || env.Platform == "" || len(env.Options) != 1 || env.Options[0] != "crossMounts" {
+		t.Errorf("unexpected environment %+v", env)
+	}
+	if s := (Environment{Platform: "linux/amd64", Version: "1.2.3"}).String(); s != "linux/amd64, diskdive 1.2.3" {
+		t.Errorf("unexpected summary %q", s)
+	}
+}
+
+func TestWritePrunesOldSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	for i := 0; i < maxSnapshots+2; i++ {
+		if err := c.Save("C:\\", &model.Node{Name: "C:", IsDir: true}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(time.Minute)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(tmp, Key("C:\\")+"_*.gob.gz"))
+	if len(files) != maxSnapshots {
+		t.Errorf("expected %d snapshots, got %d", maxSnapshots, len(files))
+	}
+}
+
+func TestRetention(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	// Age: a week-old snapshot goes once a newer one is saved
+	c.SetRetention(Retention{Keep: 10, MaxAge: 72 * time.Hour})
+	save := func(key string) {
+		t.Helper()
+		if err := c.Save(key, &model.Node{Name: "x", IsDir: true}); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	save("C:\\")
+	clk.Advance(7 * 24 * time.Hour)
+	save("C:\\")
+	if files, _ := filepath.Glob(filepath.Join(tmp, Key("C:\\")+"_*.gob.gz")); len(files) != 1 {
+		t.Errorf("expected the old snapshot removed, got %v", files)
+	}
+
+	// Count: the newest per key are kept
+	c.SetRetention(Retention{Keep: 2})
+	for range 3 {
+		clk.Advance(time.Minute)
+		save("D:\\")
+	}
+	if files, _ := filepath.Glob(filepath.Join(tmp, Key("D:\\")+"_*.gob.gz")); len(files) != 2 {
+		t.Errorf("expected 2 snapshots of D, got %v", files)
+	}
+
+	// Total size: older snapshots of any key go, but never the newest of a key
+	c.SetRetention(Retention{Keep: 10, MaxBytes: 1})
+	clk.Advance(time.Minute)
+	save("E:\\")
+	files, _ := filepath.Glob(filepath.Join(tmp, "*.gob.gz"))
+	if len(files) != 3 {
+		t.Errorf("expected one snapshot per key, got %v", files)
+	}
+
+	pruned := c.Pruned()
+	if len(pruned) != 3 {
+		t.Fatalf("expected 3 pruned snapshots, got %+v", pruned)
+	}
+	for i, want := range []string{"older than", "more than 2 snapshots", "in total"} {
+		if !strings.Contains(pruned[i].Reason, want) {
+			t.Errorf("pruned[%d]: expected a reason with %q, got %q", i, want, pruned[i].Reason)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := map[string]string{
+		"C":        "C-",
+		"C:\\":     "C---",
+		"/home/me": "-home-me-",
+		"my_drive": "my-drive-",
+	}
+	for in, want := range tests {
+		if got := Key(in); !strings.HasPrefix(got, want) || len(got) != len(want)+16 {
+			t.Errorf("Key(%q) = %q, want %q and a hash", in, got, want)
+		}
+	}
+
+	// Paths that read the same once mapped still get their own key
+	seen := make(map[string]string)
+	for _, path := range []string{"/data/a/b", "/data/a-b", "/data/a b", "/data/a_b", "/Data/a/b"} {
+		k := Key(path)
+		if other, ok := seen[k]; ok {
+			t.Errorf("Key(%q) = Key(%q) = %q", path, other, k)
+		}
+		seen[k] = path
+	}
+	if Key("/data/a/") != Key("/data/a") {
+		t.Error("expected a trailing separator not to change the key")
+	}
+	if long := Key("/" + strings.Repeat("x", 300)); len(long) > maxKeyName+17 {
+		t.Errorf("expected long paths cut short, got %d characters", len(long))
+	}
+}
+
+func TestLoadRejectsOtherPath(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	if err := c.Save("/data/a-b", &model.Node{Name: "a-b", IsDir: true}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A snapshot of another path filed under this key's name is not used
+	files, _ := filepath.Glob(filepath.Join(tmp, Key("/data/a-b")+"_*.gob.gz"))
+	if len(files) != 1 {
+		t.Fatalf("expected 1 snapshot, got %v", files)
+	}
+	moved := strings.Replace(files[0], Key("/data/a-b"), Key("/data/a/b"), 1)
+	if err := os.Rename(files[0], moved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LoadLatest("/data/a/b"); err == nil {
+		t.Error("expected the snapshot of another path to be refused")
+	}
+	if headers, _ := c.List("/data/a/b"); len(headers) != 0 {
+		t.Errorf("expected no snapshots listed, got %+v", headers)
+	}
+}
+
+func TestLegacyNames(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	c.SetRetention(Retention{Keep: 2})
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	save := func(path string) {
+		t.Helper()
+		root := &model.Node{Name: filepath.Base(path), IsDir: true}
+		root.SetPath(path)
+		root.AddChild(&model.Node{Name: "f", Size: 2 << 20})
+		root.ComputeSizes()
+		if err := c.Save(path, root); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(time.Hour)
+	}
+
+	// Files saved before keys were hashed, of two paths that share a legacy
+	// name, and the history of one of them
+	save("/data/a-b")
+	if err := os.Remove(c.historyFile("/data/a-b")); err != nil {
+		t.Fatal(err)
+	}
+	save("/data/a/b")
+	save("/data/a/b")
+	for _, pattern := range []string{"*.gob.gz", "*" + historySuffix} {
+		files, _ := filepath.Glob(filepath.Join(tmp, pattern))
+		for _, f := range files {
+			for _, path := range []string{"/data/a/b", "/data/a-b"} {
+				if strings.HasPrefix(filepath.Base(f), Key(path)) {
+					os.Rename(f, filepath.Join(tmp, legacyKey(path)+strings.TrimPrefix(filepath.Base(f), Key(path))))
+				}
+			}
+		}
+	}
+	if legacy, _ := filepath.Glob(filepath.Join(tmp, legacyKey("/data/a/b")+"_*.gob.gz")); len(legacy) != 3 {
+		t.Fatalf("expected 3 legacy snapshots, got %v", legacy)
+	}
+
+	// They are still read, each as its own path's
+	if headers, _ := c.List("/data/a/b"); len(headers) != 2 {
+		t.Errorf("expected 2 legacy snapshots of /data/a/b, got %+v", headers)
+	}
+	if h, err := c.History("/data/a/b"); err != nil || len(h.Times) != 2 {
+		t.Errorf("expected the legacy history of 2 scans, got %+v, %v", h, err)
+	}
+
+	// Retention removes them like any other, leaving those of the other path
+	// alone, and the history moves to the new name
+	save("/data/a/b")
+	save("/data/a/b")
+	if legacy, _ := filepath.Glob(filepath.Join(tmp, legacyKey("/data/a/b")+"_*.gob.gz")); len(legacy) != 1 {
+		t.Errorf("expected only the snapshot of /data/a-b left under the legacy name, got %v", legacy)
+	}
+	if _, err := os.Stat(c.historyFile("/data/a/b")); err != nil {
+		t.Errorf("expected the history under the new name: %v", err)
+	}
+	if _, err := os.Stat(c.legacyHistoryFile("/data/a/b")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the legacy history removed: %v", err)
+	}
+	if h, err := c.History("/data/a/b"); err != nil || len(h.Times) != 4 {
+		t.Errorf("expected a history of 4 scans, got %+v, %v", h, err)
+	}
+}
@@ -0,0 +1,354 @@
+package cache
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Ways of getting the secret snapshots are encrypted with, as set in the config
+const (
+	EncryptPassphrase = "passphrase" // Typed in, or from DISKDIVE_PASSPHRASE
+	EncryptKeychain   = "keychain"   // A random secret kept in the OS keychain
+)
+
+// PassphraseEnv names the environment variable a passphrase can be given in
+const PassphraseEnv = "DISKDIVE_PASSPHRASE"
+
+// Encrypted files start with cryptMagic and a random salt the file's key is
+// derived with, followed by chunks: a 4-byte length, the top bit set on the
+// last chunk, then that many bytes sealed with AES-GCM. The length is sealed
+// along, so chunks cannot be cut off or reordered unnoticed.
+const (
+	cryptMagic = "DDCRYPT1"
+	cryptSalt  = 16
+	cryptChunk = 64 << 10
+	cryptLast  = 1 << 31
+)
+
+// keyFile holds the salt the cache key is derived from and a value to tell
+// a wrong secret from damaged snapshots
+const keyFile = "crypt.json"
+
+// keyIterations is the PBKDF2 work per derivation of the cache key
+const keyIterations = 600_000
+
+// The keychain entry holding the secret of EncryptKeychain
+const (
+	keychainService = "diskdive"
+	keychainAccount = "snapshots"
+)
+
+// newKeychainSecret returns a random secret to keep in the keychain
+func newKeychainSecret() string {
+	return rand.Text()
+}
+
+// errNoKeychainSecret means the keychain answered that it holds no secret
+// for diskdive, as opposed to failing to answer
+var errNoKeychainSecret = errors.New("no snapshot secret in the keychain")
+
+// ErrLocked means a file is encrypted and the cache has no key to read it
+var ErrLocked = errors.New("encrypted, and no passphrase or keychain secret was given")
+
+// ErrWrongKey means a file was encrypted with another key than the cache's,
+// as when the key file was replaced
+var ErrWrongKey = errors.New("encrypted with another key")
+
+// keyParams is the content of keyFile
+type keyParams struct {
+	Salt       []byte
+	Iterations int
+	Check      []byte // HMAC of the key over checkLabel
+}
+
+const checkLabel = "diskdive cache key"
+
+var (
+	passphraseMu sync.Mutex
+	passphrase   []byte
+)
+
+// SetPassphrase sets the passphrase Encrypt uses, typed in at startup,
+// ahead of the environment variable
+func SetPassphrase(p []byte) {
+	passphraseMu.Lock()
+	defer passphraseMu.Unlock()
+	passphrase = p
+}
+
+// Secret returns the secret for an encryption mode set in the config. A
+// keychain secret is only created while the cache directory has none set,
+// so a keychain that fails to answer never replaces the one in use.
+func (c *Cache) Secret(mode string) ([]byte, error) {
+	switch mode {
+	case EncryptPassphrase:
+		passphraseMu.Lock()
+		p := passphrase
+		passphraseMu.Unlock()
+		if len(p) == 0 {
+			p = []byte(os.Getenv(PassphraseEnv))
+		}
+		if len(p) == 0 {
+			return nil, fmt.Errorf("no passphrase: set %s or run diskdive in a terminal", PassphraseEnv)
+		}
+		return p, nil
+	case EncryptKeychain:
+		return keychainSecret(!c.Keyed())
+	}
+	return nil, fmt.Errorf("unknown snapshot encryption %q (want %s or %s)", mode, EncryptPassphrase, EncryptKeychain)
+}
+
+// Encrypt makes the cache encrypt what it writes, with the key derived from
+// the secret of mode; an empty mode leaves it writing plain files. If the
+// secret is unavailable or wrong, writes fail rather than fall back to
+// plain files.
+func (c *Cache) Encrypt(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	secret, err := c.Secret(mode)
+	if err == nil {
+		err = c.Unlock(secret)
+	}
+	if err != nil {
+		c.keyErr = err
+	}
+	return err
+}
+
+// Unlock derives the cache key from secret. The first secret used on a
+// cache directory sets it; later ones must match.
+func (c *Cache) Unlock(secret []byte) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	path := filepath.Join(c.dir, keyFile)
+	var params keyParams
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &params); err != nil {
+			return fmt.Errorf("%s: %w", keyFile, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		params = keyParams{Salt: make([]byte, cryptSalt), Iterations: keyIterations}
+		rand.Read(params.Salt)
+	default:
+		return err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, string(secret), params.Salt, params.Iterations, 32)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(checkLabel))
+	check := mac.Sum(nil)
+	if params.Check != nil {
+		if !hmac.Equal(check, params.Check) {
+			return errors.New("wrong passphrase for the snapshot cache")
+		}
+	} else {
+		params.Check = check
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("write %s: %w", keyFile, err)
+		}
+	}
+	c.key = key
+	c.keyErr = nil
+	return nil
+}
+
+// Keyed reports whether a secret was set for the cache directory, so the
+// next one given must match it
+func (c *Cache) Keyed() bool {
+	_, err := os.Stat(filepath.Join(c.dir, keyFile))
+	return err == nil
+}
+
+// fileCipher derives the cipher of one file from the cache key and its salt
+func fileCipher(key, salt []byte) (cipher.AEAD, error) {
+	fk, err := hkdf.Key(sha256.New, key, salt, "diskdive snapshot", 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(fk)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealWriter encrypts what is written through it in chunks
+type sealWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	buf   []byte
+	n     uint64 // Chunks written, the nonce of the next
+	nonce []byte
+}
+
+// newSealWriter starts an encrypted stream on w; with no key, w is written
+// to as it is
+func newSealWriter(w io.Writer, key []byte) (io.WriteCloser, error) {
+	if key == nil {
+		return nopWriteCloser{w}, nil
+	}
+	salt := make([]byte, cryptSalt)
+	rand.Read(salt)
+	aead, err := fileCipher(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append([]byte(cryptMagic), salt...)); err != nil {
+		return nil, err
+	}
+	return &sealWriter{w: w, aead: aead, buf: make([]byte, 0, cryptChunk), nonce: make([]byte, aead.NonceSize())}, nil
+}
+
+func (s *sealWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(s.buf) == cryptChunk {
+			if err := s.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(s.buf[len(s.buf):cryptChunk], p)
+		s.buf = s.buf[:len(s.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last chunk, which may be empty
+func (s *sealWriter) Close() error {
+	return s.flush(true)
+}
+
+func (s *sealWriter) flush(last bool) error {
+	length := uint32(len(s.buf))
+	if last {
+		length |= cryptLast
+	}
+	head := binary.LittleEndian.AppendUint32(nil, length)
+	binary.LittleEndian.PutUint64(s.nonce[len(s.nonce)-8:], s.n)
+	s.n++
+	out := s.aead.Seal(head, s.nonce, s.buf, head)
+	s.buf = s.buf[:0]
+	_, err := s.w.Write(out)
+	return err
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+// openReader decrypts r if it is an encrypted stream and passes it through
+// as it is otherwise. Encrypted streams need the key.
+func openReader(r io.Reader, key []byte) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(cryptMagic))
+	if string(magic) != cryptMagic {
+		return br, nil
+	}
+	if key == nil {
+		return nil, ErrLocked
+	}
+	head := make([]byte, len(cryptMagic)+cryptSalt)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, err
+	}
+	aead, err := fileCipher(key, head[len(cryptMagic):])
+	if err != nil {
+		return nil, err
+	}
+	return &openedReader{r: br, aead: aead, nonce: make([]byte, aead.NonceSize())}, nil
+}
+
+// openedReader decrypts a stream written by sealWriter
+type openedReader struct {
+	r     io.Reader
+	aead  cipher.AEAD
+	n     uint64
+	nonce []byte
+	plain []byte // Decrypted and not yet read
+	done  bool   // The last chunk was read
+	in    []byte // Chunk being decrypted
+}
+
+func (o *openedReader) Read(p []byte) (int, error) {
+	for len(o.plain) == 0 {
+		if o.done {
+			return 0, io.EOF
+		}
+		if err := o.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, o.plain)
+	o.plain = o.plain[n:]
+	return n, nil
+}
+
+func (o *openedReader) next() error {
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(o.r, head); err != nil {
+		return noEOF(err)
+	}
+	length := binary.LittleEndian.Uint32(head)
+	o.done = length&cryptLast != 0
+	length &^= cryptLast
+	if length > cryptChunk {
+		return fmt.Errorf("encrypted chunk of %d bytes", length)
+	}
+	need := int(length) + o.aead.Overhead()
+	if cap(o.in) < need {
+		o.in = make([]byte, need)
+	}
+	o.in = o.in[:need]
+	if _, err := io.ReadFull(o.r, o.in); err != nil {
+		return noEOF(err)
+	}
+	binary.LittleEndian.PutUint64(o.nonce[len(o.nonce)-8:], o.n)
+	o.n++
+	plain, err := o.aead.Open(o.plain[:0], o.nonce, o.in, head)
+	if err != nil {
+		if o.n == 1 {
+			// Damage past the first chunk is caught by the checksum too;
+			// failing on the first most likely means another key
+			return ErrWrongKey
+		}
+		return errors.New("encrypted chunk damaged")
+	}
+	o.plain = plain
+	return nil
+}
+
+// noEOF turns the end of a stream that should go on into an error
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
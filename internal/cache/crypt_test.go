@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestEncryptedSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+	if c.Keyed() {
+		t.Fatal("expected a new cache to have no key")
+	}
+	if err := c.Unlock([]byte("correct horse")); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+
+	// Big enough for several encrypted chunks, and a second snapshot stored
+	// against the first
+	root := &model.Node{Name: "secret-folder", IsDir: true}
+	root.SetPath("/data")
+	for i := range 3 * recordChunk {
+		root.AddChild(&model.Node{Name: "payroll-" + time.Duration(i).String(), Size: int64(i), IsDir: i%100 == 0})
+	}
+	root.ComputeSizes()
+	for range 2 {
+		if err := c.Save("/data", root); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(time.Hour)
+	}
+	files, _ := filepath.Glob(filepath.Join(tmp, "*"))
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if bytes.HasPrefix(data, []byte(historyMagic)) {
+			data = data[len(historyMagic)+4:] // Each frame is encrypted on its own
+		}
+		if bytes.Contains(data, []byte("/data")) || filepath.Base(f) != keyFile && !bytes.HasPrefix(data, []byte(cryptMagic)) {
+			t.Errorf("expected %s encrypted", filepath.Base(f))
+		}
+	}
+
+	loaded, err := c.LoadLatest("/data")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "secret-folder" || len(loaded.Children) != 3*recordChunk {
+		t.Errorf("expected the tree back, got %s with %d entries", loaded.Name, len(loaded.Children))
+	}
+	if h, err := c.History("/data"); err != nil || len(h.Times) != 2 {
+		t.Errorf("expected an encrypted history of 2 scans, got %v, %v", h, err)
+	}
+
+	// Without the key the snapshots stay closed, and are not taken for damaged
+	locked := New(tmp)
+	if _, err := locked.LoadLatest("/data"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if removed := locked.Recover(); len(removed) != 0 {
+		t.Errorf("expected locked snapshots kept, got %v removed", removed)
+	}
+	if !locked.Keyed() {
+		t.Error("expected the cache to have a key")
+	}
+	if err := locked.Unlock([]byte("wrong")); err == nil {
+		t.Error("expected a wrong passphrase to be refused")
+	}
+
+	// Asked for but unavailable, encryption stops saves instead of writing plain
+	t.Setenv(PassphraseEnv, "")
+	if err := locked.Encrypt(EncryptPassphrase); err == nil {
+		t.Fatal("expected Encrypt to fail without a passphrase")
+	}
+	if err := locked.Save("/plain", root); err == nil {
+		t.Error("expected the save to fail")
+	}
+	t.Setenv(PassphraseEnv, "correct horse")
+	if err := locked.Encrypt(EncryptPassphrase); err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := locked.LoadLatest("/data"); err != nil {
+		t.Errorf("LoadLatest failed: %v", err)
+	}
+
+	// Another key, as after the key file is replaced
+	os.Remove(filepath.Join(tmp, keyFile))
+	other := New(tmp)
+	if err := other.Unlock([]byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.LoadLatest("/data"); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("expected ErrWrongKey, got %v", err)
+	}
+	if removed := other.Recover(); len(removed) != 0 {
+		t.Errorf("expected snapshots of another key kept, got %v removed", removed)
+	}
+}
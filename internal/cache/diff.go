@@ -8,8 +8,12 @@ import (
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
-// ApplyDiff compares current scan against previous and populates diff fields
-func ApplyDiff(current, previous *model.Node) {
+// ApplyDiff compares current scan against previous, which may be any two
+// scans of the same path, and populates diff fields. Items only previous
+// has are added to current marked deleted, with sizes kept consistent when
+// current's sizes were computed first. Files matching noise are neither new
+// nor deleted, only counted in sizes.
+func ApplyDiff(current, previous *model.Node, noise model.Noise) {
 	var counter int64
 	if previous == nil {
 		markAllNew(current, &counter)
@@ -26,10 +30,10 @@ func ApplyDiff(current, previous *model.Node) {
 	buildPathMap(current, currMap, &counter)
 
 	// Apply diff info to current tree
-	applyDiffRecursive(current, prevMap, &counter)
+	applyDiffRecursive(current, prevMap, noise, &counter)
 
 	// Add deleted items from previous tree into current tree
-	addDeletedItems(current, prevMap, currMap, &counter)
+	addDeletedItems(current, prevMap, currMap, noise, &counter)
 
 	// Verify deleted items were added by counting them
 	deletedCount := countDeletedNodes(current, &counter)
@@ -61,35 +65,35 @@ func yieldIfNeeded(counter *int64) {
 
 func buildPathMap(node *model.Node, m map[string]*model.Node, counter *int64) {
 	yieldIfNeeded(counter)
-	m[node.Path] = node
+	m[node.Path()] = node
 	for _, child := range node.Children {
 		buildPathMap(child, m, counter)
 	}
 }
 
-func applyDiffRecursive(node *model.Node, prevMap map[string]*model.Node, counter *int64) {
+func applyDiffRecursive(node *model.Node, prevMap map[string]*model.Node, noise model.Noise, counter *int64) {
 	yieldIfNeeded(counter)
-	prev, exists := prevMap[node.Path]
+	prev, exists := prevMap[node.Path()]
 	if exists {
 		node.PrevSize = prev.TotalSize()
 		node.IsNew = false
 	} else {
-		node.IsNew = true
+		node.IsNew = !noise.Is(node)
 		node.PrevSize = 0
 	}
 
 	for _, child := range node.Children {
-		applyDiffRecursive(child, prevMap, counter)
+		applyDiffRecursive(child, prevMap, noise, counter)
 	}
 }
 
 // addDeletedItems adds nodes from previous tree that don't exist in current tree
-func addDeletedItems(current *model.Node, prevMap, currMap map[string]*model.Node, counter *int64) {
+func addDeletedItems(current *model.Node, prevMap, currMap map[string]*model.Node, noise model.Noise, counter *int64) {
 	yieldIfNeeded(counter)
 	var deletedCount int
 	// For each node in previous tree, check if it exists in current
 	for prevPath, prevNode := range prevMap {
-		if _, exists := currMap[prevPath]; !exists {
+		if _, exists := currMap[prevPath]; !exists && !noise.Is(prevNode) {
 			// This node was deleted - add it to current tree
 			parentPath := getParentPath(prevPath)
 			if parentPath == "" {
@@ -99,26 +103,28 @@ func addDeletedItems(current *model.Node, prevMap, currMap map[string]*model.Nod
 
 			// Find parent in current tree
 			parent, parentExists := currMap[parentPath]
-			if !parentExists {
-				// Parent was also deleted, will be handled by its own entry
+			if !parentExists || parent.IsDeleted {
+				// Parent was also deleted and stands for its contents
 				continue
 			}
 
 			// Create a copy of the deleted node
 			deletedNode := &model.Node{
-				Path:      prevNode.Path,
-				Name:      prevNode.Name,
-				Size:      prevNode.TotalSize(),
-				IsDir:     prevNode.IsDir,
-				Parent:    parent,
-				Children:  nil, // Don't include children of deleted items
-				PrevSize:  prevNode.TotalSize(),
-				IsDeleted: true,
-				IsNew:     false,
+				Name:     prevNode.Name,
+				Size:     prevNode.TotalSize(),
+				Logical:  prevNode.Logical,
+				ModTime:  prevNode.ModTime,
+				UID:      prevNode.UID,
+				GID:      prevNode.GID,
+				IsDir:    prevNode.IsDir,
+				Category: prevNode.Category,
+				Children: nil, // Don't include children of deleted items
+				PrevSize: prevNode.TotalSize(),
 			}
 
-			// Add to parent's children
-			parent.Children = append(parent.Children, deletedNode)
+			// Add to parent's children, counted in its size as deleted
+			parent.AddChild(deletedNode)
+			deletedNode.MarkDeleted()
 
 			// Add to current map so we can find it as a parent for its children
 			currMap[prevPath] = deletedNode
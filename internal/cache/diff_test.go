@@ -9,27 +9,30 @@ import (
 func TestApplyDiff(t *testing.T) {
 	// Previous scan
 	prev := &model.Node{
-		Path:  "C:\\",
 		Name:  "C:",
 		IsDir: true,
 		Children: []*model.Node{
-			{Path: "C:\\old", Name: "old", Size: 100},
-			{Path: "C:\\same", Name: "same", Size: 200},
+			{Name: "old", Size: 100},
+			{Name: "same", Size: 200},
 		},
 	}
 
 	// Current scan
 	curr := &model.Node{
-		Path:  "C:\\",
 		Name:  "C:",
 		IsDir: true,
 		Children: []*model.Node{
-			{Path: "C:\\same", Name: "same", Size: 250}, // grew
-			{Path: "C:\\new", Name: "new", Size: 300},   // new
+			{Name: "same", Size: 250}, // grew
+			{Name: "new", Size: 300},  // new
 		},
 	}
 
-	ApplyDiff(curr, prev)
+	for _, root := range []*model.Node{prev, curr} {
+		root.SetPath("C:\\")
+		root.RebuildParentLinks()
+	}
+
+	ApplyDiff(curr, prev, model.Noise{})
 
 	// Check same folder has previous size
 	var same *model.Node
@@ -65,3 +68,65 @@ func TestApplyDiff(t *testing.T) {
 		t.Error("expected new folder to be marked IsNew")
 	}
 }
+
+func TestApplyDiffIgnoresNoise(t *testing.T) {
+	prev := &model.Node{
+		Name:     "C:",
+		IsDir:    true,
+		Children: []*model.Node{{Name: "Thumbs.db", Size: 10}},
+	}
+	curr := &model.Node{
+		Name:     "C:",
+		IsDir:    true,
+		Children: []*model.Node{{Name: ".DS_Store", Size: 20}},
+	}
+	for _, root := range []*model.Node{prev, curr} {
+		root.SetPath("C:\\")
+		root.RebuildParentLinks()
+	}
+
+	ApplyDiff(curr, prev, model.Noise{})
+
+	if len(curr.Children) != 1 {
+		t.Fatalf("deleted noise should not be added back, got %d children", len(curr.Children))
+	}
+	if curr.Children[0].IsNew {
+		t.Error("new noise file should not be marked IsNew")
+	}
+}
+
+func TestApplyDiffKeepsSizes(t *testing.T) {
+	build := func(children ...*model.Node) *model.Node {
+		root := &model.Node{Name: "data", IsDir: true, Children: children}
+		root.SetPath("/data")
+		root.RebuildParentLinks()
+		root.ComputeSizes()
+		return root
+	}
+	prev := build(
+		&model.Node{Name: "keep.txt", Size: 100},
+		&model.Node{Name: "old", IsDir: true, Children: []*model.Node{
+			{Name: "a.bin", Size: 300},
+			{Name: "b.bin", Size: 200},
+		}},
+	)
+	curr := build(&model.Node{Name: "keep.txt", Size: 150})
+
+	ApplyDiff(curr, prev, model.Noise{})
+
+	var old *model.Node
+	for _, c := range curr.Children {
+		if c.Name == "old" {
+			old = c
+		}
+	}
+	if old == nil || !old.IsDeleted || len(old.Children) != 0 {
+		t.Fatalf("expected old/ added back as deleted without its contents, got %+v", old)
+	}
+	if curr.TotalSize() != 650 || curr.DeletedSize != 500 {
+		t.Errorf("expected 650 bytes with 500 deleted, got %d/%d", curr.TotalSize(), curr.DeletedSize)
+	}
+	if !curr.HasGrew || !curr.HasShrunk {
+		t.Error("expected the root to show both growth and shrinkage")
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+// Environment records where and how a scan was made, so snapshots copied
+// between machines or compared across versions explain themselves
+type Environment struct {
+	Hostname string
+	Platform string   // GOOS/GOARCH
+	Version  string   // diskdive version that made the scan
+	Options  []string // Scan options that were switched on
+}
+
+// CaptureEnvironment describes the current machine for a scan made by
+// diskdive version with the given options
+func CaptureEnvironment(version string, options []string) Environment {
+	hostname, _ := os.Hostname()
+	return Environment{
+		Hostname: hostname,
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		Version:  version,
+		Options:  options,
+	}
+}
+
+// String summarizes the environment on one line, e.g.
+// "laptop, darwin/arm64, diskdive 1.4.0, crossMounts"
+func (e Environment) String() string {
+	var parts []string
+	if e.Hostname != "" {
+		parts = append(parts, e.Hostname)
+	}
+	if e.Platform != "" {
+		parts = append(parts, e.Platform)
+	}
+	if e.Version != "" {
+		parts = append(parts, "diskdive "+e.Version)
+	}
+	parts = append(parts, e.Options...)
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, ", ")
+}
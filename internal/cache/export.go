@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// Formats Export writes
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+)
+
+// ExportEntry is one path of an exported snapshot. Folder sizes are totals
+// of everything below them.
+type ExportEntry struct {
+	Path  string `json:"path"`
+	Size  int64  `json:"size"`
+	Type  string `json:"type"`            // "dir", "file", "link", or "group" for small files folded into one entry
+	MTime string `json:"mtime,omitempty"` // RFC 3339, empty if unknown
+}
+
+// exportHeader opens a JSON export
+type exportHeader struct {
+	Path      string     `json:"path"`
+	ScannedAt time.Time  `json:"scannedAt"`
+	Files     int64      `json:"files"`
+	Dirs      int64      `json:"dirs"`
+	Bytes     int64      `json:"bytes"`
+	Label     string     `json:"label,omitempty"`
+	Seconds   float64    `json:"seconds,omitempty"` // How long the scan took, omitted if unknown
+	Env       *exportEnv `json:"env,omitempty"`
+}
+
+// exportEnv is the Environment of an exported snapshot
+type exportEnv struct {
+	Hostname string   `json:"hostname,omitempty"`
+	Platform string   `json:"platform,omitempty"`
+	Version  string   `json:"version,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// Export writes the snapshot of key taken at or before t, the latest if t is
+// zero, to w as CSV or JSON, one entry per path with folders before their
+// contents. CSV starts with a row of column names; JSON is an object with the
+// snapshot summary and the entries.
+func (c *Cache) Export(w io.Writer, key string, t time.Time, format string) error {
+	if format != ExportCSV && format != ExportJSON {
+		return fmt.Errorf("unknown export format %q (want %s or %s)", format, ExportCSV, ExportJSON)
+	}
+	var root *model.Node
+	var header Header
+	err := c.newestBefore(key, t, func(s *snapshotFile) error {
+		var err error
+		root, err = s.readTree()
+		header = s.Header
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	path := root.Path()
+
+	bw := bufio.NewWriter(w)
+	if format == ExportCSV {
+		err = exportCSV(bw, root, path)
+	} else {
+		err = exportJSON(bw, root, path, header)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportCSV writes the entries below root as CSV rows
+func exportCSV(w io.Writer, root *model.Node, path string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"path", "size", "type", "mtime"}); err != nil {
+		return err
+	}
+	err := walkExport(root, path, func(e ExportEntry) error {
+		return cw.Write([]string{e.Path, strconv.FormatInt(e.Size, 10), e.Type, e.MTime})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportJSON writes the snapshot summary and the entries below root as one
+// JSON object, an entry per line
+func exportJSON(w io.Writer, root *model.Node, path string, h Header) error {
+	head := exportHeader{Path: path, ScannedAt: h.ScannedAt, Files: h.Files, Dirs: h.Dirs, Bytes: h.Bytes, Label: h.Label, Seconds: h.Duration.Seconds()}
+	if e := h.Env; e.Hostname != "" || e.Platform != "" || e.Version != "" || len(e.Options) > 0 {
+		head.Env = &exportEnv{Hostname: e.Hostname, Platform: e.Platform, Version: e.Version, Options: e.Options}
+	}
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "{\"snapshot\":%s,\"entries\":[", data); err != nil {
+		return err
+	}
+	sep := "\n"
+	err = walkExport(root, path, func(e ExportEntry) error {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s%s", sep, line)
+		sep = ",\n"
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n]}\n")
+	return err
+}
+
+// walkExport calls fn for n at path and everything below it, in pre-order
+func walkExport(n *model.Node, path string, fn func(ExportEntry) error) error {
+	e := ExportEntry{Path: path, Size: n.Size, Type: "file"}
+	switch {
+	case n.Attrs.IsLink():
+		e.Type = "link"
+	case n.Attrs.Has(model.AttrGrouped):
+		e.Type = "group"
+	case n.IsDir:
+		e.Type = "dir"
+	}
+	if n.ModTime != 0 {
+		e.MTime = time.Unix(n.ModTime, 0).Format(time.RFC3339)
+	}
+	if err := fn(e); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := walkExport(child, filepath.Join(path, child.Name), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestExport(t *testing.T) {
+	c := New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	base := filepath.Join(string(filepath.Separator), "data")
+	mtime := time.Date(2023, 5, 1, 8, 30, 0, 0, time.Local)
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath(base)
+	docs := &model.Node{Name: "docs", IsDir: true}
+	docs.AddChild(&model.Node{Name: "a,b.txt", Size: 100, ModTime: mtime.Unix()})
+	root.AddChild(docs)
+	root.AddChild(&model.Node{Name: "link", Size: 1, Attrs: model.AttrSymlink})
+	root.ComputeSizes()
+	snap := NewSnapshot(root, 3*time.Second)
+	snap.Header.Env = CaptureEnvironment("1.2.3", nil)
+	if err := c.Write(base, snap); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := c.Export(&out, base, time.Time{}, ExportCSV); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"path", "size", "type", "mtime"},
+		{base, "101", "dir", ""},
+		{filepath.Join(base, "docs"), "100", "dir", ""},
+		{filepath.Join(base, "docs", "a,b.txt"), "100", "file", mtime.Format(time.RFC3339)},
+		{filepath.Join(base, "link"), "1", "link", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %q", len(want), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d column %d: expected %q, got %q", i, j, want[i][j], rows[i][j])
+			}
+		}
+	}
+
+	out.Reset()
+	if err := c.Export(&out, base, time.Time{}, ExportJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var doc struct {
+		Snapshot struct {
+			Path    string  `json:"path"`
+			Files   int64   `json:"files"`
+			Bytes   int64   `json:"bytes"`
+			Seconds float64 `json:"seconds"`
+			Env     struct {
+				Hostname string `json:"hostname"`
+				Version  string `json:"version"`
+			} `json:"env"`
+		} `json:"snapshot"`
+		Entries []ExportEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if doc.Snapshot.Path != base || doc.Snapshot.Files != 2 || doc.Snapshot.Bytes != 101 {
+		t.Errorf("unexpected summary %+v", doc.Snapshot)
+	}
+	if doc.Snapshot.Seconds != 3 || doc.Snapshot.Env.Version != "1.2.3" {
+		t.Errorf("expected the scan's duration and environment, got %+v", doc.Snapshot)
+	}
+	if len(doc.Entries) != 4 || doc.Entries[2].Path != filepath.Join(base, "docs", "a,b.txt") || doc.Entries[2].Size != 100 {
+		t.Errorf("unexpected entries %+v", doc.Entries)
+	}
+
+	// Nothing from before the first snapshot
+	if err := c.Export(&out, base, clk.Now().Add(-time.Hour), ExportCSV); err == nil {
+		t.Error("expected an error for a time before any snapshot")
+	}
+	if err := c.Export(&out, base, time.Time{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
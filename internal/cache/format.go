@@ -0,0 +1,586 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// formatVersion is the snapshot layout written by this version. Snapshots
+// from format 1 on end in a trailer; older ones have none. From format 2 on
+// the tree is a stream of nodeRecord chunks instead of one CacheNode value.
+// From format 3 on folders carry a hash of their contents, and a snapshot may
+// leave out folders unchanged since its base, an earlier snapshot of the key.
+const formatVersion = 3
+
+// maxDeltaChain is how many snapshots in a row may lean on an earlier one
+// before one is stored complete again, bounding what a load has to read
+const maxDeltaChain = 7
+
+// recordChunk is how many tree entries are encoded together. Gob builds each
+// value in memory before writing it, so the tree goes out in pieces.
+const recordChunk = 4096
+
+// Trailer layout: magic, format version, body length, CRC-32 of the body.
+// The body is the gzip stream in front of it.
+const (
+	trailerMagic = "DDSNAP"
+	trailerSize  = len(trailerMagic) + 2 + 8 + 4
+)
+
+// ErrIncomplete means a snapshot was cut short or damaged, usually by the
+// process dying while writing it
+var ErrIncomplete = errors.New("snapshot incomplete or damaged")
+
+// trailer closes a snapshot file so partial writes can be detected
+type trailer struct {
+	version uint16
+	length  uint64
+	crc     uint32
+}
+
+func (t trailer) encode() []byte {
+	b := make([]byte, 0, trailerSize)
+	b = append(b, trailerMagic...)
+	b = binary.LittleEndian.AppendUint16(b, t.version)
+	b = binary.LittleEndian.AppendUint64(b, t.length)
+	b = binary.LittleEndian.AppendUint32(b, t.crc)
+	return b
+}
+
+// decodeTrailer parses the last bytes of a file, reporting false if they are not a trailer
+func decodeTrailer(b []byte) (trailer, bool) {
+	if len(b) != trailerSize || string(b[:len(trailerMagic)]) != trailerMagic {
+		return trailer{}, false
+	}
+	b = b[len(trailerMagic):]
+	return trailer{
+		version: binary.LittleEndian.Uint16(b),
+		length:  binary.LittleEndian.Uint64(b[2:]),
+		crc:     binary.LittleEndian.Uint32(b[10:]),
+	}, true
+}
+
+// nodeRecord is one entry of the tree as stored from format 2 on. Entries
+// come in pre-order, each folder followed by its Children entries and theirs,
+// so the tree can be rebuilt while it is read.
+type nodeRecord struct {
+	Name     string
+	Size     int64
+	Logical  int64
+	ModTime  int64
+	UID      uint32 // As OwnerID.Num gives it
+	GID      uint32
+	IsDir    bool
+	Attrs    model.NodeAttr
+	Children int
+
+	Hash uint64 // Of everything below a folder, from format 3 on
+	Same bool   // Holds what the base snapshot holds here; the entries are not repeated
+}
+
+// folderHash is the hash of a folder's contents and, in the pre-order list
+// of folders hashFolders returns, the index just past the folders below it
+type folderHash struct {
+	hash uint64
+	end  int32
+}
+
+// hashFolders returns the hash of n and of every folder below it, in
+// pre-order. A folder's hash covers the entries below it, so folders that
+// hash the same hold the same entries.
+func hashFolders(n *model.Node) []folderHash {
+	var folders []folderHash
+	var buf []byte
+	var walk func(n *model.Node) uint64
+	walk = func(n *model.Node) uint64 {
+		i := len(folders)
+		folders = append(folders, folderHash{})
+		h := fnv.New64a()
+		for _, c := range n.Children {
+			var sum uint64
+			if c.IsDir {
+				sum = walk(c)
+			}
+			buf = binary.LittleEndian.AppendUint64(buf[:0], uint64(len(c.Name)))
+			buf = append(buf, c.Name...)
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(c.Size))
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(c.Logical))
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(c.ModTime))
+			buf = binary.LittleEndian.AppendUint32(buf, c.UID.Num())
+			buf = binary.LittleEndian.AppendUint32(buf, c.GID.Num())
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(c.Attrs))
+			if c.IsDir {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+			buf = binary.LittleEndian.AppendUint64(buf, sum)
+			h.Write(buf)
+		}
+		folders[i] = folderHash{hash: h.Sum64(), end: int32(len(folders))}
+		return folders[i].hash
+	}
+	if n.IsDir {
+		walk(n)
+	}
+	return folders
+}
+
+// recordPath joins the names of the folders above an entry and its own into
+// the slash-separated path it has below the root
+func recordPath(names []string, name string) string {
+	if len(names) == 0 {
+		return name
+	}
+	return strings.Join(names, "/") + "/" + name
+}
+
+// deltaBase is the snapshot a new one is stored against: its file name,
+// scan path, place in the chain and the hash of each of its folders by path
+type deltaBase struct {
+	name   string
+	path   string
+	depth  int
+	hashes map[string]uint64
+}
+
+// record returns the entry n is stored as
+func record(n *model.Node) nodeRecord {
+	return nodeRecord{
+		Name:     n.Name,
+		Size:     n.Size,
+		Logical:  n.Logical,
+		ModTime:  n.ModTime,
+		UID:      n.UID.Num(),
+		GID:      n.GID.Num(),
+		IsDir:    n.IsDir,
+		Attrs:    n.Attrs,
+		Children: len(n.Children),
+	}
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+// writeSnapshot writes the compressed snapshot and its trailer to file and
+// flushes it to disk. With a base, folders whose hash matches the base's
+// folder at the same path are written without their entries. With a key,
+// the compressed stream is encrypted; the trailer stays readable.
+func writeSnapshot(file *os.File, snap *Snapshot, base *deltaBase, key []byte) error {
+	crc := crc32.NewIEEE()
+	body := &countingWriter{w: io.MultiWriter(file, crc)}
+
+	folders := hashFolders(snap.root)
+	snap.Header.Base, snap.Header.Depth = "", 0
+	if base != nil {
+		snap.Header.Base, snap.Header.Depth = base.name, base.depth+1
+	}
+
+	sealed, err := newSealWriter(body, key)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	gzWriter := gzip.NewWriter(sealed)
+	encoder := gob.NewEncoder(gzWriter)
+	if err := encoder.Encode(snap.Header); err != nil {
+		return fmt.Errorf("encode header: %w", err)
+	}
+	// The entries go out in pre-order straight from the tree, a chunk at a
+	// time, so a large tree is never copied whole
+	chunk := make([]nodeRecord, 0, recordChunk)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := encoder.Encode(chunk); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+	var names []string // Of the folders above the entry, below the root
+	folder := 0        // Index in folders of the next folder
+	var walk func(n *model.Node, top bool) error
+	walk = func(n *model.Node, top bool) error {
+		r := record(n)
+		var f folderHash
+		if n.IsDir {
+			f = folders[folder]
+			folder++
+			r.Hash = f.hash
+		}
+		descend := r.Children > 0
+		if !top && descend {
+			if h, ok := base.hash(recordPath(names, n.Name)); ok && h == r.Hash {
+				r.Same, r.Children = true, 0
+				folder, descend = int(f.end), false
+			}
+		}
+		chunk = append(chunk, r)
+		if len(chunk) == recordChunk {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if !descend {
+			return nil
+		}
+		if !top {
+			names = append(names, n.Name)
+			defer func() { names = names[:len(names)-1] }()
+		}
+		for _, child := range n.Children {
+			if err := walk(child, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(snap.root, true); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("compress: %w", err)
+	}
+	if err := sealed.Close(); err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	t := trailer{version: formatVersion, length: body.n, crc: crc.Sum32()}
+	if _, err := file.Write(t.encode()); err != nil {
+		return fmt.Errorf("write trailer: %w", err)
+	}
+	return file.Sync()
+}
+
+// hash returns the hash the base holds for the folder at path, false if it
+// has none there or there is no base
+func (b *deltaBase) hash(path string) (uint64, bool) {
+	if b == nil {
+		return 0, false
+	}
+	h, ok := b.hashes[path]
+	return h, ok
+}
+
+// snapshotFile is an open snapshot whose header has been read
+type snapshotFile struct {
+	Header Header
+
+	file    *os.File
+	key     []byte    // Decrypts this snapshot and its base, if encrypted
+	body    io.Reader // the gzip stream, hashed as it is read
+	gz      *gzip.Reader
+	decoder *gob.Decoder
+	crc     hash.Hash32
+	sealed  *trailer // nil for snapshots older than the trailer
+}
+
+// openSnapshot opens a snapshot and reads its header. Files whose trailer is
+// missing or does not match their length fail with ErrIncomplete; the body
+// checksum is only compared by verify, after the tree is read. Encrypted
+// snapshots need key and fail with ErrLocked without it.
+func openSnapshot(path string, key []byte) (*snapshotFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	s, err := readSnapshotHeader(file, key)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func readSnapshotHeader(file *os.File, key []byte) (*snapshotFile, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat: %w", err)
+	}
+	s := &snapshotFile{file: file, key: key, crc: crc32.NewIEEE()}
+
+	length := info.Size()
+	if length >= int64(trailerSize) {
+		b := make([]byte, trailerSize)
+		if _, err := file.ReadAt(b, length-int64(trailerSize)); err != nil {
+			return nil, fmt.Errorf("read trailer: %w", err)
+		}
+		if t, ok := decodeTrailer(b); ok {
+			if t.version > formatVersion {
+				return nil, fmt.Errorf("snapshot format %d is newer than this version reads", t.version)
+			}
+			if t.length != uint64(length-int64(trailerSize)) {
+				return nil, fmt.Errorf("%w: %d of %d bytes", ErrIncomplete, length-int64(trailerSize), t.length)
+			}
+			s.sealed = &t
+			length = int64(t.length)
+		}
+	}
+
+	s.body = io.TeeReader(io.LimitReader(file, length), s.crc)
+	plain, err := openReader(s.body, key)
+	if err != nil {
+		return nil, err
+	}
+	s.gz, err = gzip.NewReader(plain)
+	if errors.Is(err, ErrWrongKey) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: gzip reader: %v", ErrIncomplete, err)
+	}
+	s.decoder = gob.NewDecoder(s.gz)
+	if err := s.decoder.Decode(&s.Header); errors.Is(err, ErrWrongKey) {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: decode header: %v", ErrIncomplete, err)
+	}
+	if s.Header.Format > 0 && s.sealed == nil {
+		// Written by a version that seals snapshots, so the end is missing
+		return nil, fmt.Errorf("%w: no trailer", ErrIncomplete)
+	}
+	return s, nil
+}
+
+// readTree decodes the tree that follows the header and checks the body
+// against the trailer's checksum. Folders left out as unchanged are taken
+// from the base snapshot, which is read first.
+func (s *snapshotFile) readTree() (*model.Node, error) {
+	var root *model.Node
+	var err error
+	if s.Header.Format >= 2 {
+		var base *model.Node
+		if s.Header.Base != "" {
+			if base, err = s.readBase(); err != nil {
+				return nil, fmt.Errorf("base snapshot %s: %w", s.Header.Base, err)
+			}
+		}
+		root, err = s.readRecords(base)
+	} else {
+		var cn model.CacheNode
+		if err = s.decoder.Decode(&cn); err == nil {
+			root = cn.ToNode(nil)
+		}
+		if err == nil && s.Header.Format == 0 {
+			// Snapshots from before the format was recorded either have no
+			// owners or cannot tell them from root, so none are trusted
+			for n := range model.Walk(root, model.WalkOptions{}) {
+				n.UID, n.GID = model.NoOwner, model.NoOwner
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode: %v", ErrIncomplete, err)
+	}
+	if err := s.verify(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// verify reads the rest of the body and compares it with the trailer's
+// checksum. Snapshots without a trailer pass.
+func (s *snapshotFile) verify() error {
+	if s.sealed == nil {
+		return nil
+	}
+	if _, err := io.Copy(io.Discard, s.gz); err != nil {
+		return fmt.Errorf("%w: %v", ErrIncomplete, err)
+	}
+	if _, err := io.Copy(io.Discard, s.body); err != nil {
+		return fmt.Errorf("%w: %v", ErrIncomplete, err)
+	}
+	if s.crc.Sum32() != s.sealed.crc {
+		return fmt.Errorf("%w: checksum mismatch", ErrIncomplete)
+	}
+	return nil
+}
+
+// readBase loads the tree of the snapshot this one was stored against, which
+// lies next to it and sits earlier in the chain
+func (s *snapshotFile) readBase() (*model.Node, error) {
+	b, err := openSnapshot(filepath.Join(filepath.Dir(s.file.Name()), s.Header.Base), s.key)
+	if err != nil {
+		return nil, err
+	}
+	defer b.Close()
+	if b.Header.Depth >= s.Header.Depth {
+		return nil, fmt.Errorf("depth %d does not come before %d", b.Header.Depth, s.Header.Depth)
+	}
+	return b.readTree()
+}
+
+// readHashes reads the folder hashes of a format 3 snapshot by path below
+// the root, without building the tree, and checks the body
+func (s *snapshotFile) readHashes() (map[string]uint64, error) {
+	if s.Header.Format < 3 {
+		return nil, fmt.Errorf("format %d holds no hashes", s.Header.Format)
+	}
+	hashes := make(map[string]uint64)
+	var names []string
+	err := s.eachRecord(func(r *nodeRecord, depth int) error {
+		if depth == 0 {
+			return nil
+		}
+		names = names[:depth-1]
+		if r.IsDir {
+			hashes[recordPath(names, r.Name)] = r.Hash
+		}
+		names = append(names, r.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode: %v", ErrIncomplete, err)
+	}
+	if err := s.verify(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// eachRecord calls fn for the entries that follow the header, in order,
+// decoding one chunk at a time. depth is 0 for the root, 1 for its entries
+// and so on.
+func (s *snapshotFile) eachRecord(fn func(r *nodeRecord, depth int) error) error {
+	var left []int // Entries still to come in each open folder, innermost last
+	var chunk []nodeRecord
+	started := false
+	for !started || len(left) > 0 {
+		// Gob leaves out zero fields, so reused entries must start out empty
+		clear(chunk[:cap(chunk)])
+		chunk = chunk[:0]
+		if err := s.decoder.Decode(&chunk); err != nil {
+			return err
+		}
+		for i := range chunk {
+			r := &chunk[i]
+			if started && len(left) == 0 {
+				return errors.New("entries past the end of the tree")
+			}
+			if r.Children < 0 {
+				return fmt.Errorf("%s has %d entries", r.Name, r.Children)
+			}
+			depth := len(left)
+			if started {
+				left[len(left)-1]--
+			}
+			started = true
+			if err := fn(r, depth); err != nil {
+				return err
+			}
+			if r.Children > 0 {
+				left = append(left, r.Children)
+			}
+			for len(left) > 0 && left[len(left)-1] == 0 {
+				left = left[:len(left)-1]
+			}
+		}
+	}
+	return nil
+}
+
+// readRecords rebuilds the tree from the chunks that follow the header.
+// Folders marked Same take over the entries of the folder at the same path
+// in base, which is read only to be taken apart this way.
+func (s *snapshotFile) readRecords(base *model.Node) (*model.Node, error) {
+	var root *model.Node
+	var parents []*model.Node            // Folders above the entry, by depth
+	var matches []map[string]*model.Node // Entries of their counterparts in base, by name
+	// finish fills in the folders below depth, innermost first, now that
+	// their entries are all in
+	finish := func(depth int) {
+		for i := len(parents) - 1; i >= depth; i-- {
+			parents[i].Restore()
+		}
+		parents = parents[:depth]
+		matches = matches[:depth]
+	}
+	err := s.eachRecord(func(r *nodeRecord, depth int) error {
+		n := &model.Node{
+			Name:    r.Name,
+			Size:    r.Size,
+			Logical: r.Logical,
+			ModTime: r.ModTime,
+			UID:     model.KnownOwner(r.UID),
+			GID:     model.KnownOwner(r.GID),
+			IsDir:   r.IsDir,
+			Attrs:   r.Attrs,
+		}
+		var match *model.Node
+		if depth == 0 {
+			root = n
+			root.SetPath(s.Header.Path)
+			match = base
+		} else {
+			finish(depth)
+			parent := parents[depth-1]
+			n.Parent = parent
+			parent.Children = append(parent.Children, n)
+			if m := matches[depth-1]; m != nil {
+				match = m[r.Name]
+			}
+		}
+		if r.Same {
+			if match == nil {
+				return fmt.Errorf("%s is unchanged but missing from the base snapshot", r.Name)
+			}
+			n.Children = match.Children
+			for _, child := range n.Children {
+				child.Parent = n
+			}
+			n.Restore()
+			return nil
+		}
+		if r.Children == 0 {
+			n.Restore()
+			return nil
+		}
+		n.Children = make([]*model.Node, 0, r.Children)
+		var m map[string]*model.Node
+		if match != nil && len(match.Children) > 0 {
+			m = make(map[string]*model.Node, len(match.Children))
+			for _, child := range match.Children {
+				m[child.Name] = child
+			}
+		}
+		parents = append(parents, n)
+		matches = append(matches, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	finish(0)
+	return root, nil
+}
+
+// Close closes the snapshot file
+func (s *snapshotFile) Close() error {
+	s.gz.Close()
+	return s.file.Close()
+}
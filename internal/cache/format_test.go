@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// writeTwo saves an "old" and a "new" snapshot an hour apart and returns the newer file
+func writeTwo(t *testing.T, c *Cache, dir string) string {
+	t.Helper()
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+	if err := c.Save("D", &model.Node{Name: "old", IsDir: true}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	clk.Advance(time.Hour)
+	if err := c.Save("D", &model.Node{Name: "new", IsDir: true, Children: []*model.Node{{Name: "f", Size: 1}}}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, Key("D")+"_*.gob.gz"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 snapshots, got %v", files)
+	}
+	return files[1]
+}
+
+func TestTruncatedSnapshotFallsBack(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	newest := writeTwo(t, c, tmp)
+
+	info, _ := os.Stat(newest)
+	if err := os.Truncate(newest, info.Size()/2); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "old" {
+		t.Errorf("expected fallback to the older snapshot, got %s", loaded.Name)
+	}
+	if _, err := c.LoadHeader("D"); err != nil {
+		t.Errorf("LoadHeader failed: %v", err)
+	}
+
+	// Leftovers of an interrupted write are discarded on recovery, but a
+	// temp file another instance is still writing is left alone
+	abandoned := filepath.Join(tmp, Key("D")+"_2024-01-01_140000.gob.gz"+tempSuffix)
+	writing := filepath.Join(tmp, Key("D")+"_2024-01-01_150000.gob.gz"+tempSuffix)
+	for _, f := range []string{abandoned, writing} {
+		if err := os.WriteFile(f, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := c.clock.Now()
+	os.Chtimes(writing, now, now)
+	if err := os.Chtimes(abandoned, now.Add(-time.Hour), now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if removed := c.Recover(); len(removed) != 2 {
+		t.Errorf("expected the partial snapshot and temp file removed, got %v", removed)
+	}
+	if _, err := os.Stat(writing); err != nil {
+		t.Errorf("temp file still being written should be kept: %v", err)
+	}
+	if _, err := os.Stat(newest); !os.IsNotExist(err) {
+		t.Error("truncated snapshot should be gone")
+	}
+}
+
+func TestDamagedSnapshotFallsBack(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	newest := writeTwo(t, c, tmp)
+
+	data, err := os.ReadFile(newest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-trailerSize-12] ^= 0xFF // inside the gzip body
+	if err := os.WriteFile(newest, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "old" {
+		t.Errorf("expected fallback to the older snapshot, got %s", loaded.Name)
+	}
+}
+
+func TestNewerSnapshotFallsBack(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	newest := writeTwo(t, c, tmp)
+
+	// Mark the newest snapshot as written in a layout this version cannot read
+	data, err := os.ReadFile(newest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-trailerSize+len(trailerMagic)] = formatVersion + 1
+	if err := os.WriteFile(newest, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "old" {
+		t.Errorf("expected fallback to the older snapshot, got %s", loaded.Name)
+	}
+	if removed := c.Recover(); len(removed) != 0 {
+		t.Errorf("a snapshot from a newer version should be kept, removed %v", removed)
+	}
+}
+
+func TestLoadUnsealedSnapshot(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+
+	// Snapshots from before the trailer are plain gzip streams
+	file, err := os.Create(filepath.Join(tmp, Key("D")+"_2023-06-01_120000.gob.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	enc := gob.NewEncoder(gz)
+	enc.Encode(Header{Path: "D"})
+	enc.Encode(&model.CacheNode{Name: "legacy", IsDir: true, Children: []*model.CacheNode{{Name: "f", Size: 3}}})
+	gz.Close()
+	file.Close()
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "legacy" {
+		t.Errorf("expected legacy snapshot, got %s", loaded.Name)
+	}
+	// Owners of 0 there may just be missing, so they do not count as root
+	if f := loaded.Children[0]; f.UID != model.NoOwner || f.GID != model.NoOwner {
+		t.Errorf("expected the owner of a legacy entry to be unknown, got %d:%d", f.UID, f.GID)
+	}
+	if removed := c.Recover(); len(removed) != 0 {
+		t.Errorf("legacy snapshot should be kept, removed %v", removed)
+	}
+}
+
+func TestNoUsableSnapshot(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	if err := os.WriteFile(filepath.Join(tmp, Key("D")+"_2024-01-01_120000.gob.gz"), []byte("junk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.LoadLatest("D"); !errors.Is(err, ErrIncomplete) {
+		t.Errorf("expected ErrIncomplete, got %v", err)
+	}
+}
+
+func TestStreamedSnapshot(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+
+	// Enough entries for several chunks, nested so folders end mid-chunk
+	root := &model.Node{Name: "data", IsDir: true}
+	root.SetPath("/data")
+	for i := range 3 {
+		dir := &model.Node{Name: fmt.Sprintf("d%d", i), IsDir: true}
+		for j := range recordChunk {
+			dir.AddChild(&model.Node{Name: fmt.Sprintf("f%d", j), Size: int64(j)})
+		}
+		dir.AddChild(&model.Node{Name: "empty", IsDir: true})
+		root.AddChild(dir)
+	}
+	root.AddChild(&model.Node{Name: "last", Size: 7, Attrs: model.AttrHidden, UID: model.KnownOwner(0), GID: model.KnownOwner(20)})
+	root.ComputeSizes()
+	if err := c.Save("/data", root); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := c.LoadLatest("/data")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Path() != "/data" || len(loaded.Children) != 4 {
+		t.Fatalf("expected /data with 4 entries, got %s with %d", loaded.Path(), len(loaded.Children))
+	}
+	loaded.ComputeSizes()
+	if loaded.TotalSize() != root.TotalSize() || loaded.FileCount() != root.FileCount() {
+		t.Errorf("expected %d bytes in %d files, got %d in %d", root.TotalSize(), root.FileCount(), loaded.TotalSize(), loaded.FileCount())
+	}
+	d2 := loaded.Children[2]
+	if len(d2.Children) != recordChunk+1 || d2.Children[recordChunk].Name != "empty" || !d2.Children[recordChunk].IsDir {
+		t.Errorf("unexpected entries in %s: %d", d2.Name, len(d2.Children))
+	}
+	if last := loaded.Children[3]; last.Name != "last" || last.Size != 7 || !last.Attrs.Has(model.AttrHidden) {
+		t.Errorf("unexpected last entry %+v", last)
+	}
+	if last := loaded.Children[3]; last.UID != model.KnownOwner(0) || last.GID != model.KnownOwner(20) {
+		t.Errorf("expected root and group 20 to survive, got %d:%d", last.UID, last.GID)
+	}
+	if f := d2.Children[0]; f.UID != model.NoOwner || f.GID != model.NoOwner {
+		t.Errorf("expected entries without owners to stay unknown, got %d:%d", f.UID, f.GID)
+	}
+}
+
+func TestLoadFormatOneSnapshot(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+
+	// Sealed snapshots from before streaming hold the tree as one value
+	file, err := os.Create(filepath.Join(tmp, Key("D")+"_2024-06-01_120000.gob.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	crc := crc32.NewIEEE()
+	body := &countingWriter{w: io.MultiWriter(file, crc)}
+	gz := gzip.NewWriter(body)
+	enc := gob.NewEncoder(gz)
+	enc.Encode(Header{Path: "D", Format: 1})
+	enc.Encode(&model.CacheNode{Name: "sealed", IsDir: true, UID: model.NoOwner.Num(), Children: []*model.CacheNode{{Name: "f", Size: 3}}})
+	gz.Close()
+	file.Write(trailer{version: 1, length: body.n, crc: crc.Sum32()}.encode())
+	file.Close()
+
+	loaded, err := c.LoadLatest("D")
+	if err != nil {
+		t.Fatalf("LoadLatest failed: %v", err)
+	}
+	if loaded.Name != "sealed" || len(loaded.Children) != 1 || loaded.Children[0].Size != 3 {
+		t.Errorf("unexpected tree from a format 1 snapshot: %+v", loaded)
+	}
+	if loaded.UID != model.NoOwner || loaded.Children[0].UID != model.KnownOwner(0) {
+		t.Errorf("expected an unknown owner and root, got %d and %d", loaded.UID, loaded.Children[0].UID)
+	}
+}
+
+func TestDeltaSnapshots(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+	c.SetRetention(Retention{Keep: 2})
+
+	// Two large folders, of which only b changes between scans
+	tree := func(extra int) *model.Node {
+		root := &model.Node{Name: "data", IsDir: true}
+		root.SetPath("/data")
+		for _, name := range []string{"a", "b"} {
+			dir := &model.Node{Name: name, IsDir: true}
+			for i := range 500 {
+				name := fmt.Sprintf("f%d", i)
+				dir.AddChild(&model.Node{Name: name, Size: int64(crc32.ChecksumIEEE([]byte(dir.Name + name)))})
+			}
+			root.AddChild(dir)
+		}
+		for i := range extra {
+			root.Children[1].AddChild(&model.Node{Name: fmt.Sprintf("new%d", i), Size: 1})
+		}
+		root.ComputeSizes()
+		return root
+	}
+	save := func(extra int) string {
+		t.Helper()
+		clk.Advance(time.Hour)
+		if err := c.Save("/data", tree(extra)); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return c.File("/data", clk.Now())
+	}
+	check := func(at time.Time, extra int) {
+		t.Helper()
+		loaded, _, err := c.LoadBefore("/data", at)
+		if err != nil {
+			t.Fatalf("LoadBefore failed: %v", err)
+		}
+		if len(loaded.Children) != 2 || len(loaded.Children[0].Children) != 500 || len(loaded.Children[1].Children) != 500+extra {
+			t.Errorf("expected a with 500 entries and b with %d, got %+v", 500+extra, loaded.Children)
+		}
+	}
+
+	first := save(0)
+	second := save(1)
+	headers, _ := c.List("/data")
+	if headers[0].Base != filepath.Base(first) || headers[0].Depth != 1 || headers[1].Base != "" {
+		t.Fatalf("expected the second snapshot stored against the first, got %+v", headers)
+	}
+	a, _ := os.Stat(first)
+	b, _ := os.Stat(second)
+	if b.Size() >= a.Size()*3/4 {
+		t.Errorf("expected the second snapshot to leave out a, got %d bytes against %d", b.Size(), a.Size())
+	}
+	check(clk.Now(), 1)
+
+	// Pruning the first rewrites the second complete
+	save(2)
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("expected the first snapshot pruned, got %v", err)
+	}
+	headers, _ = c.List("/data")
+	if len(headers) != 2 || headers[1].Base != "" || headers[0].Base != filepath.Base(second) {
+		t.Errorf("expected the kept older snapshot complete, got %+v", headers)
+	}
+	check(clk.Now().Add(-time.Hour), 1)
+	check(clk.Now(), 2)
+
+	// Chains are cut at maxDeltaChain
+	c.SetRetention(Retention{Keep: 2 * maxDeltaChain})
+	for range maxDeltaChain {
+		save(2)
+	}
+	headers, _ = c.List("/data")
+	if headers[1].Base != "" || headers[2].Depth != maxDeltaChain || headers[0].Depth != 1 {
+		t.Errorf("expected a complete snapshot after %d in a chain, got %+v", maxDeltaChain, headers[:3])
+	}
+	check(clk.Now(), 2)
+}
@@ -0,0 +1,525 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// historySuffix names the size history kept next to the snapshots of a key
+const historySuffix = ".history.gz"
+
+// maxHistorySamples is how many scans the size history remembers
+const maxHistorySamples = 365
+
+// historyMagic starts a size history file made of frames: a 4-byte length,
+// then a gzipped gob, encrypted if the cache is. The first frame holds a
+// historyFile and each later one the historyColumn of a scan, so a scan
+// appends to the file instead of writing it again. Files without it hold a
+// single historyFile, as written before scans were appended.
+const historyMagic = "DDHIST2\n"
+
+// maxHistoryFrames is how many frames a size history file grows to before
+// it is written again as one
+const maxHistoryFrames = 32
+
+// minHistorySize is the smallest folder the size history follows; smaller
+// ones are too many to keep and too small to matter for trends
+const minHistorySize = 1 << 20
+
+// Sample is the size of a folder in one scan
+type Sample struct {
+	At    time.Time
+	Bytes int64
+}
+
+// History holds the size of the larger folders of a scan path across scans,
+// so trends can be drawn without loading every snapshot. It outlives the
+// snapshots the retention policy removes.
+type History struct {
+	Path  string      // The scanned path
+	Times []time.Time // When each scan was taken, oldest first
+	rows  map[string][]int64
+}
+
+// historyFile is the stored form of a History: one column per scan and one
+// row per folder, each row holding the change from the scan before, so
+// folders that stay the same size compress to almost nothing
+type historyFile struct {
+	Path  string
+	Times []int64  // Unix seconds
+	Dirs  []string // Relative to Path, "" for Path itself
+	Sizes [][]int64
+}
+
+// historyColumn is the stored form of one scan appended to a history: the
+// size of each folder the history follows, and of new ones big enough to
+// follow. A folder left out counts as missing from the scan.
+type historyColumn struct {
+	Time  int64 // Unix seconds
+	Dirs  []string
+	Sizes []int64
+}
+
+// Samples returns the size of the folder at path in each remembered scan,
+// oldest first, or nil if the history does not follow it. A scan in which
+// the folder was missing counts it as 0 bytes.
+func (h *History) Samples(path string) []Sample {
+	if h == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(h.Path, path)
+	if err != nil {
+		return nil
+	}
+	if rel == "." {
+		rel = ""
+	}
+	row, ok := h.rows[rel]
+	if !ok {
+		return nil
+	}
+	samples := make([]Sample, len(h.Times))
+	for i, t := range h.Times {
+		samples[i] = Sample{At: t, Bytes: row[i]}
+	}
+	return samples
+}
+
+// Children returns the folders directly below path that the history
+// follows, including ones gone from later scans, sorted by path
+func (h *History) Children(path string) []string {
+	if h == nil {
+		return nil
+	}
+	rel, err := filepath.Rel(h.Path, path)
+	if err != nil {
+		return nil
+	}
+	var children []string
+	for row := range h.rows {
+		if row == "" {
+			continue
+		}
+		if parent := filepath.Dir(row); parent == rel {
+			children = append(children, filepath.Join(h.Path, row))
+		}
+	}
+	slices.Sort(children)
+	return children
+}
+
+// add appends the folder sizes of one scan, keyed by path relative to the
+// scan root
+func (h *History) add(t time.Time, sizes map[string]int64) {
+	n := len(h.Times)
+	h.Times = append(h.Times, t)
+	for rel, row := range h.rows {
+		h.rows[rel] = append(row, sizes[rel])
+	}
+	for rel, size := range sizes {
+		if _, ok := h.rows[rel]; !ok && (rel == "" || size >= minHistorySize) {
+			row := make([]int64, n+1)
+			row[n] = size
+			h.rows[rel] = row
+		}
+	}
+
+	// Forget the oldest scans, and folders that no longer reach the minimum
+	if drop := len(h.Times) - maxHistorySamples; drop > 0 {
+		h.Times = h.Times[drop:]
+		for rel, row := range h.rows {
+			h.rows[rel] = row[drop:]
+		}
+	}
+	for rel, row := range h.rows {
+		if rel != "" && slices.Max(row) < minHistorySize {
+			delete(h.rows, rel)
+		}
+	}
+}
+
+// column returns what a scan at t with the given folder sizes adds to h,
+// as add would take it
+func (h *History) column(t time.Time, sizes map[string]int64) historyColumn {
+	col := historyColumn{Time: t.Unix()}
+	for rel := range h.rows {
+		col.Dirs = append(col.Dirs, rel)
+		col.Sizes = append(col.Sizes, sizes[rel])
+	}
+	for rel, size := range sizes {
+		if _, ok := h.rows[rel]; !ok && (rel == "" || size >= minHistorySize) {
+			col.Dirs = append(col.Dirs, rel)
+			col.Sizes = append(col.Sizes, size)
+		}
+	}
+	return col
+}
+
+// dirSizes records the total size of the folder n and every folder below
+// it under their path relative to the scan root, returning the total of n.
+// Totals are summed from the files, leaving out estimated space.
+func dirSizes(n *model.Node, rel string, sizes map[string]int64) int64 {
+	var total int64
+	for _, child := range n.Children {
+		if child.Attrs.Has(model.AttrVirtual) {
+			continue
+		}
+		if child.IsDir {
+			total += dirSizes(child, filepath.Join(rel, child.Name), sizes)
+		} else {
+			total += child.Size
+		}
+	}
+	sizes[rel] = total
+	return total
+}
+
+// History returns the size history of key, empty if none was recorded yet
+func (c *Cache) History(key string) (*History, error) {
+	h, err := readHistory(c.historyFile(key), c.key)
+	if errors.Is(err, fs.ErrNotExist) {
+		h, err = readHistory(c.legacyHistoryFile(key), c.key)
+	}
+	if errors.Is(err, fs.ErrNotExist) || err == nil && !samePath(h.Path, key) {
+		return &History{Path: key, rows: make(map[string][]int64)}, nil
+	}
+	return h, err
+}
+
+// historyFile returns where the size history of key is kept
+func (c *Cache) historyFile(key string) string {
+	return filepath.Join(c.dir, Key(key)+historySuffix)
+}
+
+// legacyHistoryFile returns where the size history of key was kept before
+// keys were hashed
+func (c *Cache) legacyHistoryFile(key string) string {
+	return filepath.Join(c.dir, legacyKey(key)+historySuffix)
+}
+
+// dropLegacyHistory removes the history of key kept under its legacy name,
+// once it is written under the new one, unless it is another path's
+func (c *Cache) dropLegacyHistory(key string) {
+	file := c.legacyHistoryFile(key)
+	h, err := readHistory(file, c.key)
+	if err != nil || !samePath(h.Path, key) {
+		return
+	}
+	if err := os.Remove(file); err == nil {
+		logging.Debug.Printf("[Cache] Moved size history %s to %s", filepath.Base(file), filepath.Base(c.historyFile(key)))
+	}
+}
+
+// updateHistory adds a snapshot just written to the size history of key,
+// appending a column to the file against snap.History, or the history read
+// back if the snapshot has none. The file is written again as one when it
+// holds maxHistoryFrames frames or was written before scans were appended.
+// A key without a history yet starts from the snapshots already saved.
+func (c *Cache) updateHistory(key string, snap *Snapshot) error {
+	sizes := make(map[string]int64)
+	dirSizes(snap.root, "", sizes)
+	file := c.historyFile(key)
+
+	frames := historyFrames(file)
+	if frames > 0 && frames < maxHistoryFrames {
+		h := snap.History
+		if h == nil || !samePath(h.Path, key) {
+			var err error
+			if h, err = c.History(key); err != nil {
+				return err
+			}
+		}
+		if len(h.Times) > 0 {
+			return appendHistory(file, h.column(snap.Header.ScannedAt, sizes), c.key)
+		}
+	}
+
+	h, err := c.History(key)
+	if err != nil {
+		return err
+	}
+	if len(h.Times) == 0 {
+		c.seedHistory(key, h, snap.Header.ScannedAt)
+	}
+	h.Path = snap.Header.Path
+	h.add(snap.Header.ScannedAt, sizes)
+	if err := writeHistory(file, h, c.key); err != nil {
+		return err
+	}
+	c.dropLegacyHistory(key)
+	return nil
+}
+
+// seedHistory adds the readable snapshots of key taken before t
+func (c *Cache) seedHistory(key string, h *History, t time.Time) {
+	files, err := c.snapshotFiles(key)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		s, err := openSnapshot(f, c.key)
+		if err != nil {
+			continue
+		}
+		if s.Header.ScannedAt.Before(t) {
+			if root, err := s.readTree(); err == nil {
+				sizes := make(map[string]int64)
+				dirSizes(root, "", sizes)
+				h.add(s.Header.ScannedAt, sizes)
+			}
+		}
+		s.Close()
+	}
+}
+
+// readHistory loads a size history file, decrypting it with key if it is
+// encrypted. A frame cut short by an interrupted append is left out.
+func readHistory(path string, key []byte) (*History, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	framed, err := hasHistoryMagic(file)
+	if err != nil {
+		return nil, fmt.Errorf("history %s: %w", filepath.Base(path), err)
+	}
+	if !framed {
+		var hf historyFile
+		if err := decodeFrame(file, key, &hf); err != nil {
+			return nil, fmt.Errorf("history %s: %w", filepath.Base(path), err)
+		}
+		return historyOf(path, hf)
+	}
+
+	var h *History
+	for {
+		frame, err := nextFrame(file)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("history %s: %w", filepath.Base(path), err)
+		}
+		if h == nil {
+			var hf historyFile
+			if err := decodeFrame(frame, key, &hf); err != nil {
+				return nil, fmt.Errorf("history %s: %w", filepath.Base(path), err)
+			}
+			if h, err = historyOf(path, hf); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var col historyColumn
+		if err := decodeFrame(frame, key, &col); err != nil {
+			return nil, fmt.Errorf("history %s: %w", filepath.Base(path), err)
+		}
+		if len(col.Dirs) != len(col.Sizes) {
+			return nil, fmt.Errorf("history %s: %d folders but %d sizes", filepath.Base(path), len(col.Dirs), len(col.Sizes))
+		}
+		sizes := make(map[string]int64, len(col.Dirs))
+		for i, rel := range col.Dirs {
+			sizes[rel] = col.Sizes[i]
+		}
+		h.add(time.Unix(col.Time, 0), sizes)
+	}
+	if h == nil {
+		return nil, fmt.Errorf("history %s: %w", filepath.Base(path), io.ErrUnexpectedEOF)
+	}
+	return h, nil
+}
+
+// historyOf turns the first frame of a history file into a History
+func historyOf(path string, hf historyFile) (*History, error) {
+	if len(hf.Dirs) != len(hf.Sizes) {
+		return nil, fmt.Errorf("history %s: %d folders but %d rows", filepath.Base(path), len(hf.Dirs), len(hf.Sizes))
+	}
+
+	h := &History{Path: hf.Path, rows: make(map[string][]int64, len(hf.Dirs))}
+	for _, t := range hf.Times {
+		h.Times = append(h.Times, time.Unix(t, 0))
+	}
+	for i, rel := range hf.Dirs {
+		deltas := hf.Sizes[i]
+		if len(deltas) != len(h.Times) {
+			return nil, fmt.Errorf("history %s: %s has %d of %d scans", filepath.Base(path), rel, len(deltas), len(h.Times))
+		}
+		row := make([]int64, len(deltas))
+		var size int64
+		for j, d := range deltas {
+			size += d
+			row[j] = size
+		}
+		h.rows[rel] = row
+	}
+	return h, nil
+}
+
+// hasHistoryMagic reports whether r starts with historyMagic, reading past
+// it if so and leaving r at its start otherwise
+func hasHistoryMagic(r io.ReadSeeker) (bool, error) {
+	magic := make([]byte, len(historyMagic))
+	if _, err := io.ReadFull(r, magic); err == nil && string(magic) == historyMagic {
+		return true, nil
+	}
+	_, err := r.Seek(0, io.SeekStart)
+	return false, err
+}
+
+// nextFrame returns the next frame of a history file
+func nextFrame(r io.Reader) (io.Reader, error) {
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return bytes.NewReader(frame), nil
+}
+
+// historyFrames returns how many whole frames the history file at path
+// holds, 0 if it is missing, unreadable or not made of frames
+func historyFrames(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+	if framed, err := hasHistoryMagic(file); err != nil || !framed {
+		return 0
+	}
+	frames := 0
+	for {
+		var length uint32
+		if err := binary.Read(file, binary.LittleEndian, &length); err != nil {
+			return frames
+		}
+		if _, err := io.CopyN(io.Discard, file, int64(length)); err != nil {
+			return frames
+		}
+		frames++
+	}
+}
+
+// decodeFrame decodes the gzipped gob in r into v, decrypting it with key
+// if it is encrypted
+func decodeFrame(r io.Reader, key []byte, v any) error {
+	r, err := openReader(r, key)
+	if err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return gob.NewDecoder(gz).Decode(v)
+}
+
+// encodeFrame returns v as a frame: its length, then v gob encoded,
+// gzipped and encrypted with key if it is set
+func encodeFrame(v any, key []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	sealed, err := newSealWriter(&buf, key)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(sealed)
+	if err := gob.NewEncoder(gz).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := sealed.Close(); err != nil {
+		return nil, err
+	}
+	frame := buf.Bytes()
+	binary.LittleEndian.PutUint32(frame, uint32(len(frame)-4))
+	return frame, nil
+}
+
+// appendHistory adds the column of a scan to the end of the history file
+// at path, in one write
+func appendHistory(path string, col historyColumn, key []byte) error {
+	frame, err := encodeFrame(col, key)
+	if err != nil {
+		return fmt.Errorf("append history: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("append history: %w", err)
+	}
+	_, err = file.Write(frame)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("append history: %w", err)
+	}
+	return nil
+}
+
+// writeHistory stores a size history as the first frame of a new file,
+// encrypted with key if it is set, replacing the file only once the new
+// one is complete
+func writeHistory(path string, h *History, key []byte) error {
+	hf := historyFile{Path: h.Path}
+	for _, t := range h.Times {
+		hf.Times = append(hf.Times, t.Unix())
+	}
+	for _, rel := range slices.Sorted(maps.Keys(h.rows)) {
+		row := h.rows[rel]
+		deltas := make([]int64, len(row))
+		var prev int64
+		for i, size := range row {
+			deltas[i] = size - prev
+			prev = size
+		}
+		hf.Dirs = append(hf.Dirs, rel)
+		hf.Sizes = append(hf.Sizes, deltas)
+	}
+
+	frame, err := encodeFrame(hf, key)
+	if err != nil {
+		return fmt.Errorf("write history: %w", err)
+	}
+	tmp := path + tempSuffix
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	if _, err = file.WriteString(historyMagic); err == nil {
+		_, err = file.Write(frame)
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestHistory(t *testing.T) {
+	tmp := t.TempDir()
+	c := New(tmp)
+	c.SetRetention(Retention{Keep: 1})
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	clk := clock.NewFake(start)
+	c.SetClock(clk)
+
+	base := filepath.Join(string(filepath.Separator), "data")
+	save := func(videos, notes int64) {
+		t.Helper()
+		root := &model.Node{Name: "data", IsDir: true}
+		root.SetPath(base)
+		for name, size := range map[string]int64{"videos": videos, "notes": notes} {
+			dir := &model.Node{Name: name, IsDir: true}
+			dir.AddChild(&model.Node{Name: "f", Size: size})
+			root.AddChild(dir)
+		}
+		root.ComputeSizes()
+		if err := c.Save(base, root); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(24 * time.Hour)
+	}
+
+	save(2<<20, 100)
+	save(5<<20, 200)
+	save(3<<20, 300)
+
+	h, err := c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Times) != 3 || !h.Times[0].Equal(start) {
+		t.Fatalf("expected 3 scans from %v, got %v", start, h.Times)
+	}
+	videos := h.Samples(filepath.Join(base, "videos"))
+	want := []int64{2 << 20, 5 << 20, 3 << 20}
+	if len(videos) != len(want) {
+		t.Fatalf("expected %d samples of videos, got %+v", len(want), videos)
+	}
+	for i, s := range videos {
+		if s.Bytes != want[i] {
+			t.Errorf("videos sample %d: expected %d, got %d", i, want[i], s.Bytes)
+		}
+	}
+	if root := h.Samples(base); len(root) != 3 || root[2].Bytes != 3<<20+300 {
+		t.Errorf("unexpected samples of the root: %+v", root)
+	}
+	if notes := h.Samples(filepath.Join(base, "notes")); notes != nil {
+		t.Errorf("expected small folders left out, got %+v", notes)
+	}
+	if children := h.Children(base); len(children) != 1 || children[0] != filepath.Join(base, "videos") {
+		t.Errorf("expected only videos followed below the root, got %v", children)
+	}
+
+	// A history lost or never written starts over from the saved snapshots
+	c.SetRetention(Retention{Keep: 10})
+	save(4<<20, 0)
+	if err := os.Remove(c.historyFile(base)); err != nil {
+		t.Fatal(err)
+	}
+	save(6<<20, 0)
+	h, err = c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if videos := h.Samples(filepath.Join(base, "videos")); len(videos) != 3 || videos[1].Bytes != 4<<20 || videos[2].Bytes != 6<<20 {
+		t.Errorf("expected the history seeded from snapshots, got %+v", videos)
+	}
+}
+
+func TestHistoryEmpty(t *testing.T) {
+	c := New(t.TempDir())
+	h, err := c.History("/nowhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Times) != 0 || h.Samples("/nowhere") != nil {
+		t.Errorf("expected an empty history, got %+v", h)
+	}
+}
+
+func TestHistoryAppend(t *testing.T) {
+	c := New(t.TempDir())
+	c.SetRetention(Retention{Keep: 1})
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	base := filepath.Join(string(filepath.Separator), "data")
+	save := func(size int64) {
+		t.Helper()
+		root := &model.Node{Name: "data", IsDir: true}
+		root.SetPath(base)
+		dir := &model.Node{Name: "videos", IsDir: true}
+		dir.AddChild(&model.Node{Name: "f", Size: size})
+		root.AddChild(dir)
+		root.ComputeSizes()
+		if err := c.Save(base, root); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(time.Hour)
+	}
+
+	// Each scan after the first is appended as a frame of its own
+	for i := range 3 {
+		save(int64(i+1) << 20)
+	}
+	file := c.historyFile(base)
+	if n := historyFrames(file); n != 3 {
+		t.Fatalf("expected 3 frames, got %d", n)
+	}
+
+	// A frame cut short by an interrupted append is left out
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	save(4 << 20)
+	whole, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, whole[:info.Size()+6], 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, err := c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if videos := h.Samples(filepath.Join(base, "videos")); len(videos) != 3 || videos[2].Bytes != 3<<20 {
+		t.Errorf("expected the cut frame left out, got %+v", videos)
+	}
+	if err := os.WriteFile(file, whole, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is written again as one frame once it holds enough of them
+	for i := historyFrames(file); i <= maxHistoryFrames; i++ {
+		save(5 << 20)
+	}
+	if n := historyFrames(file); n != 1 {
+		t.Errorf("expected the history compacted to 1 frame, got %d", n)
+	}
+	h, err = c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Times) != maxHistoryFrames+1 {
+		t.Errorf("expected %d scans, got %d", maxHistoryFrames+1, len(h.Times))
+	}
+}
+
+func TestHistoryLegacy(t *testing.T) {
+	c := New(t.TempDir())
+	base := filepath.Join(string(filepath.Separator), "data")
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A history written before scans were appended is one gzipped gob
+	file, err := os.Create(c.historyFile(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	hf := historyFile{Path: base, Times: []int64{1000, 2000}, Dirs: []string{""}, Sizes: [][]int64{{10, 10}}} // Deltas
+	if err := gob.NewEncoder(gz).Encode(hf); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := h.Samples(base); len(root) != 2 || root[1].Bytes != 20 {
+		t.Errorf("expected the legacy history read, got %+v", root)
+	}
+	if n := historyFrames(c.historyFile(base)); n != 0 {
+		t.Errorf("expected a legacy file to count no frames, got %d", n)
+	}
+}
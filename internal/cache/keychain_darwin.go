@@ -0,0 +1,40 @@
+//go:build darwin
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainSecret reads the snapshot secret from the login keychain. A
+// random one is added only if create is set and the keychain says it holds
+// none; any other failure, such as a locked keychain, is an error.
+func keychainSecret(create bool) ([]byte, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w").Output()
+	if err == nil {
+		return []byte(strings.TrimSpace(string(out))), nil
+	}
+	var exit *exec.ExitError
+	if !errors.As(err, &exit) || exit.ExitCode() != 44 { // 44: no such item
+		return nil, fmt.Errorf("read keychain: %w", err)
+	}
+	if !create {
+		return nil, errNoKeychainSecret
+	}
+
+	// Commands read from stdin keep the secret out of the argument list,
+	// where other users could see it; the secret is plain base32
+	secret := newKeychainSecret()
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -s %s -a %s -w %s\n", keychainService, keychainAccount, secret))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("add to keychain: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	if _, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount).Output(); err != nil {
+		return nil, fmt.Errorf("add to keychain: not found after adding: %w", err)
+	}
+	return []byte(secret), nil
+}
@@ -0,0 +1,59 @@
+//go:build !windows && !darwin
+
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainSecret reads the snapshot secret from the Secret Service keyring
+// (GNOME Keyring, KWallet) through secret-tool. A random one is stored only
+// if create is set and the keyring says it holds none; a keyring that is
+// locked or unreachable is an error.
+func keychainSecret(create bool) ([]byte, error) {
+	attrs := []string{"service", keychainService, "account", keychainAccount}
+	secret, err := lookupSecret(attrs)
+	if err == nil || !errors.Is(err, errNoKeychainSecret) || !create {
+		return secret, err
+	}
+
+	fresh := newKeychainSecret()
+	cmd := exec.Command("secret-tool", append([]string{"store", "--label=diskdive snapshots"}, attrs...)...)
+	cmd.Stdin = strings.NewReader(fresh)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("store in keyring: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return []byte(fresh), nil
+}
+
+// lookupSecret reads the secret stored under attrs. secret-tool exits with
+// status 1 and says nothing when there is none; anything it prints to
+// stderr means the lookup itself failed.
+func lookupSecret(attrs []string) ([]byte, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, fmt.Errorf("the keychain needs secret-tool (libsecret): %w", err)
+	}
+	cmd := exec.Command("secret-tool", append([]string{"lookup"}, attrs...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	msg := strings.TrimSpace(stderr.String())
+	var exit *exec.ExitError
+	switch {
+	case err == nil && len(bytes.TrimSpace(out)) > 0:
+		return bytes.TrimSpace(out), nil
+	case err == nil:
+		return nil, errors.New("read keyring: empty secret")
+	case errors.As(err, &exit) && exit.ExitCode() == 1 && msg == "":
+		return nil, errNoKeychainSecret
+	case msg != "":
+		return nil, fmt.Errorf("read keyring: %v: %s", err, msg)
+	}
+	return nil, fmt.Errorf("read keyring: %w", err)
+}
@@ -0,0 +1,64 @@
+//go:build !windows && !darwin
+
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSecretTool puts a secret-tool on PATH that runs script for lookup and
+// records each store in the file it returns
+func fakeSecretTool(t *testing.T, lookup string) string {
+	t.Helper()
+	dir := t.TempDir()
+	stored := filepath.Join(dir, "stored")
+	script := "#!/bin/sh\nif [ \"$1\" = store ]; then cat > " + stored + "; exit 0; fi\n" + lookup + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return stored
+}
+
+func TestKeychainSecretKeepsStoredSecret(t *testing.T) {
+	stored := fakeSecretTool(t, "echo kept; exit 0")
+	secret, err := keychainSecret(true)
+	if err != nil || string(secret) != "kept" {
+		t.Fatalf("expected the stored secret, got %q, %v", secret, err)
+	}
+	if _, err := os.Stat(stored); err == nil {
+		t.Error("expected nothing to be stored")
+	}
+}
+
+func TestKeychainSecretCreatesOnlyWhenMissing(t *testing.T) {
+	stored := fakeSecretTool(t, "exit 1")
+	if _, err := keychainSecret(false); !errors.Is(err, errNoKeychainSecret) {
+		t.Fatalf("expected no secret once the cache has a key, got %v", err)
+	}
+	if _, err := os.Stat(stored); err == nil {
+		t.Fatal("expected nothing stored for a cache that already has a key")
+	}
+
+	secret, err := keychainSecret(true)
+	if err != nil {
+		t.Fatalf("keychainSecret failed: %v", err)
+	}
+	data, err := os.ReadFile(stored)
+	if err != nil || string(data) != string(secret) {
+		t.Errorf("expected the new secret %q to be stored, got %q, %v", secret, data, err)
+	}
+}
+
+func TestKeychainSecretFailsOnLockedKeyring(t *testing.T) {
+	stored := fakeSecretTool(t, "echo 'Cannot autolaunch D-Bus without X11' >&2; exit 1")
+	if _, err := keychainSecret(true); err == nil || errors.Is(err, errNoKeychainSecret) {
+		t.Fatalf("expected the lookup failure to be reported, got %v", err)
+	}
+	if _, err := os.Stat(stored); err == nil {
+		t.Error("expected a failed lookup never to store a new secret")
+	}
+}
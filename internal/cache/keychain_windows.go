@@ -0,0 +1,11 @@
+//go:build windows
+
+package cache
+
+import "errors"
+
+// keychainSecret is not available on Windows, whose credential manager has
+// no command line to read secrets back
+func keychainSecret(create bool) ([]byte, error) {
+	return nil, errors.New("the keychain is not supported on Windows; encrypt snapshots with a passphrase")
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// scanSuffix names the file a running scan of a key keeps up to date
+const scanSuffix = ".scanning"
+
+// scanStale is how long a scan may go without a heartbeat before it is taken
+// for dead; the scanning instance refreshes its file every second or so
+const scanStale = 10 * time.Second
+
+// ScanStatus is what a running scan publishes about itself, so another
+// instance can follow it instead of walking the same path a second time
+type ScanStatus struct {
+	PID       int
+	Host      string
+	Path      string
+	Started   time.Time
+	Heartbeat time.Time // Last time the owner wrote the file
+
+	Files       int64
+	Bytes       int64
+	FilesPerSec float64
+	BytesPerSec float64
+	Current     string // Directory being scanned
+}
+
+// Stale reports whether the owner stopped refreshing the status, having
+// crashed or been killed
+func (s ScanStatus) Stale(now time.Time) bool {
+	return now.Sub(s.Heartbeat) > scanStale
+}
+
+// ClaimScan records that this process is about to scan key. If another
+// process holds a live claim, it returns false with that claim's status.
+// A claim left behind by a process that stopped heartbeating is taken over.
+func (c *Cache) ClaimScan(key string, status ScanStatus) (bool, ScanStatus, error) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return false, ScanStatus{}, fmt.Errorf("create cache dir: %w", err)
+	}
+	path := c.scanFile(key)
+	status.Path = key
+	status.Heartbeat = c.clock.Now()
+	data, err := json.Marshal(status)
+	if err != nil {
+		return false, ScanStatus{}, err
+	}
+
+	// Two tries: the second after clearing a stale claim
+	for range 2 {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, err = file.Write(data)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+				return false, ScanStatus{}, fmt.Errorf("write scan claim: %w", err)
+			}
+			return true, status, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return false, ScanStatus{}, fmt.Errorf("claim scan: %w", err)
+		}
+		other, err := readScanStatus(path)
+		if err == nil && !other.Stale(c.clock.Now()) {
+			return false, other, nil
+		}
+		os.Remove(path)
+	}
+	return false, ScanStatus{}, fmt.Errorf("claim scan: %s keeps reappearing", filepath.Base(path))
+}
+
+// UpdateScan refreshes the claim on key with the progress in status
+func (c *Cache) UpdateScan(key string, status ScanStatus) error {
+	status.Path = key
+	status.Heartbeat = c.clock.Now()
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	path := c.scanFile(key)
+	tmp := path + tempSuffix
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("update scan claim: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("update scan claim: %w", err)
+	}
+	return nil
+}
+
+// ReleaseScan removes the claim on key if status still owns it
+func (c *Cache) ReleaseScan(key string, status ScanStatus) {
+	path := c.scanFile(key)
+	if other, err := readScanStatus(path); err == nil && other.PID == status.PID && other.Started.Equal(status.Started) {
+		os.Remove(path)
+	}
+}
+
+// Scanning returns the status of a live scan of key by any process, false if
+// none is running
+func (c *Cache) Scanning(key string) (ScanStatus, bool) {
+	status, err := readScanStatus(c.scanFile(key))
+	if err != nil || status.Stale(c.clock.Now()) || !samePath(status.Path, key) {
+		return ScanStatus{}, false
+	}
+	return status, true
+}
+
+// scanFile returns where the claim on scanning key is kept
+func (c *Cache) scanFile(key string) string {
+	return filepath.Join(c.dir, Key(key)+scanSuffix)
+}
+
+// readScanStatus loads a scan claim file
+func readScanStatus(path string) (ScanStatus, error) {
+	var status ScanStatus
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return status, err
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return status, fmt.Errorf("scan claim %s: %w", filepath.Base(path), err)
+	}
+	return status, nil
+}
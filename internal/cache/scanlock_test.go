@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+)
+
+func TestClaimScan(t *testing.T) {
+	c := New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	first := ScanStatus{PID: 100, Path: "/data", Started: clk.Now()}
+	ok, _, err := c.ClaimScan("/data", first)
+	if err != nil || !ok {
+		t.Fatalf("expected the first claim to succeed, got %v, %v", ok, err)
+	}
+
+	// A second instance sees the first one's progress instead
+	first.Files = 42
+	if err := c.UpdateScan("/data", first); err != nil {
+		t.Fatal(err)
+	}
+	ok, other, err := c.ClaimScan("/data", ScanStatus{PID: 200, Path: "/data", Started: clk.Now()})
+	if err != nil || ok {
+		t.Fatalf("expected the second claim to be refused, got %v, %v", ok, err)
+	}
+	if other.PID != 100 || other.Files != 42 {
+		t.Errorf("expected the first claim's status, got %+v", other)
+	}
+	if status, running := c.Scanning("/data"); !running || status.PID != 100 {
+		t.Errorf("expected pid 100 scanning, got %+v, %v", status, running)
+	}
+
+	// Releasing someone else's claim does nothing
+	c.ReleaseScan("/data", ScanStatus{PID: 200, Started: clk.Now()})
+	if _, running := c.Scanning("/data"); !running {
+		t.Error("expected the claim to survive a release by another process")
+	}
+
+	c.ReleaseScan("/data", first)
+	if _, running := c.Scanning("/data"); running {
+		t.Error("expected no scan after the claim was released")
+	}
+}
+
+func TestClaimScanStale(t *testing.T) {
+	c := New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	if ok, _, err := c.ClaimScan("/data", ScanStatus{PID: 100}); err != nil || !ok {
+		t.Fatalf("expected the first claim to succeed, got %v, %v", ok, err)
+	}
+
+	// The owner died: once its heartbeat is old the claim is taken over
+	clk.Advance(scanStale + time.Second)
+	if _, running := c.Scanning("/data"); running {
+		t.Error("expected a stale claim not to count as scanning")
+	}
+	ok, _, err := c.ClaimScan("/data", ScanStatus{PID: 200})
+	if err != nil || !ok {
+		t.Fatalf("expected the stale claim to be taken over, got %v, %v", ok, err)
+	}
+	if status, _ := c.Scanning("/data"); status.PID != 200 {
+		t.Errorf("expected pid 200 to own the claim, got %+v", status)
+	}
+}
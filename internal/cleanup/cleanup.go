@@ -0,0 +1,343 @@
+// Package cleanup finds groups of files that are usually safe to remove, for
+// the guided cleanup wizard, and removes them on request.
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/fileops"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/shell"
+)
+
+const (
+	day = 24 * time.Hour
+
+	oldDownloadAge = 90 * day  // Downloads untouched this long are suggested
+	largeOldSize   = 100 << 20 // Files at least this big...
+	largeOldAge    = 365 * day // ...and untouched this long are suggested
+	duplicateMin   = 1 << 20   // Smaller duplicates are not worth hashing
+)
+
+// Step is one category of suggestions the wizard walks through
+type Step struct {
+	ID          string // Where items kept from the step are remembered
+	Title       string
+	Description string        // Plain-language explanation of what goes and why it is safe
+	Items       []*model.Node // What removing the step deletes, largest first
+	Severity    Severity
+	Command     string // Cleans up instead of removing the items; see Rule
+	Permanent   bool   // Deletes the items for good instead of moving them to the trash
+
+	home string // Where a command without {path} runs
+}
+
+// Removal is an item a step removed and the bytes it took
+type Removal struct {
+	Path string
+	Size int64
+}
+
+// Size returns the bytes removing the step would free
+func (s Step) Size() int64 {
+	var total int64
+	for _, n := range s.Items {
+		total += n.TotalSize()
+	}
+	return total
+}
+
+// Remove moves the step's items to the trash, deletes them for good if the
+// step is Permanent, or runs its command, and marks what is gone deleted in
+// the tree. It carries on past failures and returns what it removed along
+// with the first error.
+func (s Step) Remove() ([]Removal, error) {
+	if s.Command != "" {
+		return s.run()
+	}
+	remove := fileops.Trash
+	if s.Permanent {
+		remove = fileops.Delete
+	}
+	var removed []Removal
+	var firstErr error
+	for _, n := range s.Items {
+		path, size := n.Path(), n.TotalSize()
+		if err := remove(path); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		n.MarkDeleted()
+		removed = append(removed, Removal{Path: path, Size: size})
+	}
+	return removed, firstErr
+}
+
+// run runs the step's command, once per item if it takes {path} and once
+// otherwise, then marks the items it removed deleted
+func (s Step) run() ([]Removal, error) {
+	var firstErr error
+	exec := func(line string, env []string, dir string) {
+		cmd := shell.Command(line)
+		cmd.Env = append(cmd.Environ(), env...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %v: %s", line, err, strings.TrimSpace(string(out)))
+		}
+	}
+	if strings.Contains(s.Command, "{path}") {
+		for _, n := range s.Items {
+			line, env := shell.Expand(s.Command, "{path}", n.Path())
+			exec(line, env, filepath.Dir(n.Path()))
+		}
+	} else {
+		exec(s.Command, nil, s.home)
+	}
+
+	var removed []Removal
+	for _, n := range s.Items {
+		if _, err := os.Lstat(n.Path()); errors.Is(err, fs.ErrNotExist) {
+			removed = append(removed, Removal{Path: n.Path(), Size: n.TotalSize()})
+			n.MarkDeleted()
+		}
+	}
+	return removed, firstErr
+}
+
+// Plan returns the steps with something to suggest for root, a tree scanned
+// at or above home, in wizard order, with a step for each of rules that
+// matches something. Items already suggested by an earlier step are not
+// suggested again. Finding duplicates reads file contents, so ctx can cut
+// planning short; the steps found so far are returned with its error.
+func Plan(ctx context.Context, root *model.Node, home string, now time.Time, rules []Rule) ([]Step, error) {
+	p := planner{root: root, home: home, now: now, taken: make(map[*model.Node]bool)}
+
+	p.add(Step{
+		ID:          "trash",
+		Title:       "Empty the trash",
+		Description: "Files you already deleted are still kept in the trash and take up space until it is emptied.",
+		Items:       p.contents(trashDirs(root, home)),
+		Severity:    SeveritySafe,
+		Permanent:   true,
+	})
+	p.add(Step{
+		ID:          "caches",
+		Title:       "Clear caches",
+		Description: "Apps keep caches to start faster. They rebuild them when needed, so clearing them loses nothing.",
+		Items:       p.contents(cacheDirs(home)),
+		Severity:    SeveritySafe,
+	})
+	p.add(Step{
+		ID:          "downloads",
+		Title:       "Old downloads",
+		Description: "These downloads have not been touched in over three months. Installers and archives are usually safe to remove.",
+		Items:       p.oldDownloads(),
+		Severity:    SeverityReview,
+	})
+	dupes, err := p.duplicates(ctx)
+	p.add(Step{
+		ID:          "duplicates",
+		Title:       "Duplicate files",
+		Description: "These files are exact copies of other files, which are kept. Only the extra copies are removed.",
+		Items:       dupes,
+		Severity:    SeverityReview,
+	})
+	if err != nil {
+		return p.steps, err
+	}
+	for _, r := range rules {
+		severity := r.Severity
+		if r.Command == "" {
+			severity = SeverityCaution // Nothing but the pattern vouches for the items
+		}
+		p.add(Step{
+			ID:          "rule:" + r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Items:       matchRule(root, home, r),
+			Severity:    severity,
+			Command:     r.Command,
+			home:        home,
+		})
+	}
+	p.add(Step{
+		ID:          "large-old",
+		Title:       "Large old files",
+		Description: "These big files have not been changed in over a year. Check that you no longer need them.",
+		Items:       p.largeOld(),
+		Severity:    SeverityCaution,
+	})
+	return p.steps, nil
+}
+
+// Hide drops from each step the items whose paths dismissed lists for the
+// step's ID, or its title as items kept before steps had IDs were listed
+// under, then the steps left empty. It returns the steps kept and how many
+// items were hidden.
+func Hide(steps []Step, dismissed func(id string) []string) ([]Step, int) {
+	hidden := 0
+	kept := steps[:0]
+	for _, s := range steps {
+		paths := slices.Concat(dismissed(s.ID), dismissed(s.Title))
+		if len(paths) > 0 {
+			items := make([]*model.Node, 0, len(s.Items))
+			for _, n := range s.Items {
+				if slices.Contains(paths, n.Path()) {
+					hidden++
+				} else {
+					items = append(items, n)
+				}
+			}
+			s.Items = items
+		}
+		if len(s.Items) > 0 {
+			kept = append(kept, s)
+		}
+	}
+	return kept, hidden
+}
+
+// planner collects steps, keeping each node in at most one of them
+type planner struct {
+	root  *model.Node
+	home  string
+	now   time.Time
+	taken map[*model.Node]bool
+	steps []Step
+}
+
+// add keeps the step's items that no earlier step covers, and the step
+// itself if any remain
+func (p *planner) add(s Step) {
+	kept := s.Items[:0]
+	for _, n := range s.Items {
+		if !p.covered(n) {
+			kept = append(kept, n)
+		}
+	}
+	for _, n := range kept {
+		p.taken[n] = true
+	}
+	if len(kept) == 0 {
+		return
+	}
+	s.Items = kept
+	model.SortBySize(s.Items)
+	p.steps = append(p.steps, s)
+}
+
+// covered reports whether n or one of its ancestors is already suggested
+func (p *planner) covered(n *model.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if p.taken[n] {
+			return true
+		}
+	}
+	return false
+}
+
+// contents returns what sits directly inside the directories at paths
+func (p *planner) contents(paths []string) []*model.Node {
+	var items []*model.Node
+	for _, path := range paths {
+		dir := p.root.Find(path)
+		if dir == nil || !dir.IsDir {
+			continue
+		}
+		for _, child := range dir.Children {
+			if removable(child) {
+				items = append(items, child)
+			}
+		}
+	}
+	return items
+}
+
+// oldDownloads returns the entries of the downloads folder not modified
+// within oldDownloadAge, judging folders by their newest file
+func (p *planner) oldDownloads() []*model.Node {
+	dir := p.root.Find(filepath.Join(p.home, "Downloads"))
+	if dir == nil {
+		return nil
+	}
+	cutoff := p.now.Add(-oldDownloadAge).Unix()
+	var items []*model.Node
+	for _, child := range dir.Children {
+		if removable(child) {
+			if latest := newest(child); latest != 0 && latest < cutoff {
+				items = append(items, child)
+			}
+		}
+	}
+	return items
+}
+
+// largeOld returns files of at least largeOldSize not modified within largeOldAge
+func (p *planner) largeOld() []*model.Node {
+	cutoff := p.now.Add(-largeOldAge).Unix()
+	var items []*model.Node
+	for f := range p.root.Files() {
+		if f.Size >= largeOldSize && f.ModTime != 0 && f.ModTime < cutoff && removable(f) {
+			items = append(items, f)
+		}
+	}
+	return items
+}
+
+// removable reports whether n is a real item the wizard may delete
+func removable(n *model.Node) bool {
+	return !model.NotOnDisk(n) && !n.Attrs.IsSynthetic()
+}
+
+// newest returns the latest modification time in n's subtree, 0 if unknown
+func newest(n *model.Node) int64 {
+	latest := n.ModTime
+	for f := range n.Files() {
+		latest = max(latest, f.ModTime)
+	}
+	return latest
+}
+
+// trashDirs returns the directories whose contents are trashed files
+func trashDirs(root *model.Node, home string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, ".Trash")}
+	case "windows":
+		// The recycle bin keeps one folder per user at the drive root
+		var dirs []string
+		bin := root.Find(filepath.Join(filepath.VolumeName(home)+`\`, "$Recycle.Bin"))
+		if bin != nil {
+			for _, user := range bin.Children {
+				dirs = append(dirs, user.Path())
+			}
+		}
+		return dirs
+	default:
+		trash := filepath.Join(home, ".local", "share", "Trash")
+		return []string{filepath.Join(trash, "files"), filepath.Join(trash, "info")}
+	}
+}
+
+// cacheDirs returns the directories holding per-user application caches
+func cacheDirs(home string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Caches")}
+	case "windows":
+		return []string{filepath.Join(home, "AppData", "Local", "Temp")}
+	default:
+		return []string{filepath.Join(home, ".cache")}
+	}
+}
@@ -0,0 +1,142 @@
+package cleanup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+// writeFile creates path with size bytes of fill, last modified at mtime
+func writeFile(t *testing.T, path string, size int, fill byte, mtime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, bytes.Repeat([]byte{fill}, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func scan(t *testing.T, path string) *model.Node {
+	t.Helper()
+	root, err := scanner.NewWalker(2, scanner.ScanPolicy{}).Scan(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.ComputeSizes()
+	return root
+}
+
+func TestPlan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the recycle bin lives at the drive root")
+	}
+	home := t.TempDir()
+	now := time.Now()
+	recent := now.Add(-day)
+	ancient := now.Add(-2 * largeOldAge)
+
+	trash := filepath.Join(trashDirs(nil, home)[0], "gone.txt")
+	cache := filepath.Join(cacheDirs(home)[0], "app")
+	writeFile(t, trash, 10, 'a', recent)
+	writeFile(t, filepath.Join(cache, "blob"), 20, 'b', recent)
+	writeFile(t, filepath.Join(home, "Downloads", "setup.dmg"), 30, 'c', ancient)
+	writeFile(t, filepath.Join(home, "Downloads", "today.pdf"), 40, 'd', recent)
+	writeFile(t, filepath.Join(home, "photos", "a.jpg"), duplicateMin, 'e', ancient)
+	writeFile(t, filepath.Join(home, "backup", "a.jpg"), duplicateMin, 'e', recent)
+	writeFile(t, filepath.Join(home, "backup", "b.jpg"), duplicateMin, 'f', recent)
+	writeFile(t, filepath.Join(home, "video.mov"), largeOldSize, 'g', ancient)
+
+	root := scan(t, home)
+	steps, err := Plan(context.Background(), root, home, now, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"Empty the trash": {trash},
+		"Clear caches":    {cache},
+		"Old downloads":   {filepath.Join(home, "Downloads", "setup.dmg")},
+		"Duplicate files": {filepath.Join(home, "backup", "a.jpg")},
+		"Large old files": {filepath.Join(home, "video.mov")},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d", len(want), len(steps))
+	}
+	for _, s := range steps {
+		names := want[s.Title]
+		if len(s.Items) != len(names) {
+			t.Errorf("%s: expected %v, got %d items", s.Title, names, len(s.Items))
+			continue
+		}
+		for i, n := range s.Items {
+			if n.Path() != names[i] {
+				t.Errorf("%s: expected %s, got %s", s.Title, names[i], n.Path())
+			}
+		}
+	}
+
+	// Deleted for good, so the test leaves nothing in the user's trash
+	dupes := steps[3]
+	dupes.Permanent = true
+	removed, err := dupes.Remove()
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if freed := removedSize(removed); freed != dupes.Size() {
+		t.Errorf("expected %d bytes freed, got %d", dupes.Size(), freed)
+	}
+	if _, err := os.Stat(filepath.Join(home, "backup", "a.jpg")); !os.IsNotExist(err) {
+		t.Error("expected the extra copy to be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(home, "photos", "a.jpg")); err != nil {
+		t.Error("expected the original to be kept")
+	}
+	if !dupes.Items[0].IsDeleted {
+		t.Error("expected the removed node to be marked deleted")
+	}
+}
+
+// removedSize sums the bytes of removed
+func removedSize(removed []Removal) int64 {
+	var total int64
+	for _, r := range removed {
+		total += r.Size
+	}
+	return total
+}
+
+func TestHide(t *testing.T) {
+	root := &model.Node{Name: "home", IsDir: true}
+	root.SetPath("/home")
+	keep := &model.Node{Name: "keep", Size: 1}
+	gone := &model.Node{Name: "gone", Size: 2}
+	only := &model.Node{Name: "only", Size: 3}
+	root.AddChild(keep)
+	root.AddChild(gone)
+	root.AddChild(only)
+
+	steps := []Step{
+		{ID: "caches", Title: "Clear caches", Items: []*model.Node{keep, gone}},
+		{ID: "downloads", Title: "Old downloads", Items: []*model.Node{only}},
+	}
+	dismissed := map[string][]string{
+		"caches":        {keep.Path()},
+		"Old downloads": {only.Path()}, // Kept before steps had IDs
+		"other":         {gone.Path()}, // Dismissed under another suggestion only
+	}
+	kept, hidden := Hide(steps, func(title string) []string { return dismissed[title] })
+	if hidden != 2 || len(kept) != 1 || len(kept[0].Items) != 1 || kept[0].Items[0] != gone {
+		t.Errorf("expected only %s left, %d hidden; got %+v, %d hidden", gone.Path(), 2, kept, hidden)
+	}
+}
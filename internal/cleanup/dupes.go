@@ -0,0 +1,70 @@
+package cleanup
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// duplicates returns the extra copies among files of at least duplicateMin
+// bytes with identical contents. In each set the oldest file, or the first
+// by path when times tie, is kept and not returned.
+func (p *planner) duplicates(ctx context.Context) ([]*model.Node, error) {
+	// Only files sharing a size can match, so most are never read
+	bySize := make(map[int64][]*model.Node)
+	for f := range p.root.Files() {
+		if f.Size >= duplicateMin && removable(f) && !p.covered(f) {
+			bySize[f.Size] = append(bySize[f.Size], f)
+		}
+	}
+
+	var extra []*model.Node
+	for _, same := range bySize {
+		if len(same) < 2 {
+			continue
+		}
+		byHash := make(map[[sha256.Size]byte][]*model.Node)
+		for _, f := range same {
+			if err := ctx.Err(); err != nil {
+				return extra, err
+			}
+			sum, err := hashFile(f.Path())
+			if err != nil {
+				continue // Unreadable files cannot be shown to be copies
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+		for _, copies := range byHash {
+			if len(copies) < 2 {
+				continue
+			}
+			slices.SortFunc(copies, func(a, b *model.Node) int {
+				return cmp.Or(cmp.Compare(a.ModTime, b.ModTime), cmp.Compare(a.Path(), b.Path()))
+			})
+			extra = append(extra, copies[1:]...)
+		}
+	}
+	return extra, nil
+}
+
+// hashFile returns the SHA-256 of the file's contents
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -0,0 +1,280 @@
+package cleanup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity says how much care removing a step's items needs
+type Severity string
+
+const (
+	SeveritySafe    Severity = "safe"    // Nothing of value is lost
+	SeverityReview  Severity = "review"  // Usually fine, but worth a look first
+	SeverityCaution Severity = "caution" // Check each item before removing it
+)
+
+// Rule is a suggestion defined outside the code, in a YAML file in one of
+// the rules directories, so new reclaimable locations need no release:
+//
+//	rules:
+//	  - title: Go module cache
+//	    description: Go downloads modules again when a build needs them.
+//	    paths: ["~/go/pkg/mod"]
+//	    command: go clean -modcache
+//	    severity: safe
+//	  - title: Node.js dependencies
+//	    description: npm install brings these back for projects you work on.
+//	    paths: ["~/src/**/node_modules"]
+//
+// Paths are glob patterns over the scanned tree: ~ stands for the home
+// folder, relative patterns start there, and ** matches any number of
+// folders. The first name below home, or the first two of a pattern
+// elsewhere, must be written out, so a rule cannot take the home folder or
+// everything in it. A matched folder is suggested as a whole. Without a
+// command the matched items are moved to the trash, and the step always
+// asks for caution; with one, the command cleans up instead and the items
+// it removed count as freed. Items kept from a rule are remembered under
+// its id, so giving one lets the title change without bringing them back.
+// {path} in the command is replaced
+// with each item, running it once per item in the folder holding the item;
+// otherwise it runs once, in the home folder.
+type Rule struct {
+	ID          string   `yaml:"id"` // Where items kept from it are remembered; the file name and title if left out
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Paths       []string `yaml:"paths"`
+	Command     string   `yaml:"command"`
+	Severity    Severity `yaml:"severity"` // Review if left out
+}
+
+// ruleFile is the layout of a rules file
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// validate checks that a rule can be shown and matched, with patterns
+// narrow enough for home
+func (r Rule) validate(home string) error {
+	if strings.TrimSpace(r.Title) == "" {
+		return errors.New("rule without a title")
+	}
+	if len(r.Paths) == 0 {
+		return fmt.Errorf("rule %q has no paths", r.Title)
+	}
+	for _, p := range r.Paths {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("rule %q: path %q: %w", r.Title, p, err)
+		}
+		if tooBroad(expandHome(p, home), home) {
+			return fmt.Errorf("rule %q: path %q could match the home folder or everything in a folder near the top", r.Title, p)
+		}
+	}
+	switch r.Severity {
+	case SeveritySafe, SeverityReview, SeverityCaution:
+	default:
+		return fmt.Errorf("rule %q: severity %q, want %s, %s or %s", r.Title, r.Severity, SeveritySafe, SeverityReview, SeverityCaution)
+	}
+	return nil
+}
+
+// RuleDirs returns where rules files are looked for: directories a package
+// or administrator fills first, then the user's own
+func RuleDirs(home string) []string {
+	var dirs []string
+	switch runtime.GOOS {
+	case "darwin":
+		dirs = []string{"/Library/Application Support/diskdive/rules"}
+	case "windows":
+		if data := os.Getenv("ProgramData"); data != "" {
+			dirs = []string{filepath.Join(data, "diskdive", "rules")}
+		}
+	default:
+		dirs = []string{"/usr/share/diskdive/rules", "/etc/diskdive/rules"}
+	}
+	return append(dirs, filepath.Join(home, ".diskdive", "rules"))
+}
+
+// LoadRules reads the *.yaml and *.yml files in dirs, in name order within
+// each directory, for the home folder home. A file that cannot be read or
+// holds an invalid rule is left out and reported in errs, as is a rule whose
+// id an earlier one took; missing directories are not an error.
+func LoadRules(dirs []string, home string) (rules []Rule, errs []error) {
+	ids := make(map[string]bool)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		for _, e := range entries {
+			ext := filepath.Ext(e.Name())
+			if e.IsDir() || ext != ".yaml" && ext != ".yml" {
+				continue
+			}
+			path := filepath.Join(dir, e.Name())
+			loaded, err := readRules(path, home)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				continue
+			}
+			for _, r := range loaded {
+				if ids[r.ID] {
+					errs = append(errs, fmt.Errorf("%s: rule id %q is already taken", path, r.ID))
+					continue
+				}
+				ids[r.ID] = true
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules, errs
+}
+
+// readRules parses one rules file
+func readRules(path, home string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f ruleFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+	for i := range f.Rules {
+		if f.Rules[i].Severity == "" {
+			f.Rules[i].Severity = SeverityReview
+		}
+		if f.Rules[i].ID == "" {
+			f.Rules[i].ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "/" + f.Rules[i].Title
+		}
+		if err := f.Rules[i].validate(home); err != nil {
+			return nil, err
+		}
+	}
+	return f.Rules, nil
+}
+
+// matchRule returns the removable items of the tree below root whose path
+// matches one of the rule's patterns, outermost first
+func matchRule(root *model.Node, home string, r Rule) []*model.Node {
+	var items []*model.Node
+	for _, pattern := range r.Paths {
+		items = append(items, matchPattern(root, expandHome(pattern, home))...)
+	}
+	return items
+}
+
+// expandHome makes a pattern absolute: ~ and relative patterns start at home
+func expandHome(pattern, home string) string {
+	switch {
+	case pattern == "~":
+		return home
+	case strings.HasPrefix(pattern, "~/"), strings.HasPrefix(pattern, `~\`):
+		return filepath.Join(home, pattern[2:])
+	case !filepath.IsAbs(pattern):
+		return filepath.Join(home, pattern)
+	}
+	return pattern
+}
+
+// tooBroad reports whether an absolute pattern could match home or a folder
+// above it, or match by wildcard in home's first level. Patterns outside
+// home need their first two names written out.
+func tooBroad(pattern, home string) bool {
+	pat := segments(strings.TrimPrefix(pattern, filepath.VolumeName(pattern)))
+	base := segments(strings.TrimPrefix(home, filepath.VolumeName(home)))
+	if len(pat) <= len(base) && slices.Equal(pat, base[:len(pat)]) {
+		return true
+	}
+	if len(pat) > len(base) && slices.Equal(pat[:len(base)], base) {
+		return isWildcard(pat[len(base)])
+	}
+	if len(pat) < 2 {
+		return true
+	}
+	return isWildcard(pat[0]) || isWildcard(pat[1])
+}
+
+// isWildcard reports whether a pattern name matches more than itself
+func isWildcard(name string) bool {
+	return strings.ContainsAny(name, `*?[`)
+}
+
+// matchPattern walks the tree below root, only into folders that can still
+// lead to a match, and returns the matching nodes without descending into
+// them. Root itself is never suggested.
+func matchPattern(root *model.Node, pattern string) []*model.Node {
+	pat := segments(pattern)
+	var items []*model.Node
+	var walk func(n *model.Node, path []string)
+	walk = func(n *model.Node, path []string) {
+		if n != root && matchSegments(pat, path) {
+			if removable(n) {
+				items = append(items, n)
+			}
+			return
+		}
+		if !n.IsDir || !prefixMatches(pat, path) {
+			return
+		}
+		for _, child := range n.Children {
+			walk(child, append(path[:len(path):len(path)], child.Name))
+		}
+	}
+	walk(root, segments(root.Path()))
+	return items
+}
+
+// segments splits a path into its names, dropping empty ones
+func segments(path string) []string {
+	return strings.FieldsFunc(filepath.ToSlash(path), func(r rune) bool { return r == '/' })
+}
+
+// matchSegments reports whether path matches the pattern, name by name, with
+// ** standing for any number of names
+func matchSegments(pat, path []string) bool {
+	if len(pat) == 0 {
+		return len(path) == 0
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchSegments(pat[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	ok, _ := filepath.Match(pat[0], path[0])
+	return ok && matchSegments(pat[1:], path[1:])
+}
+
+// prefixMatches reports whether something below path could match the pattern
+func prefixMatches(pat, path []string) bool {
+	for i, name := range path {
+		if i == len(pat) {
+			return false
+		}
+		if pat[i] == "**" {
+			return true
+		}
+		if ok, _ := filepath.Match(pat[i], name); !ok {
+			return false
+		}
+	}
+	return len(path) < len(pat)
+}
@@ -0,0 +1,108 @@
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.yaml", `
+rules:
+  - id: build
+    title: Build output
+    description: Rebuilt on the next build.
+    paths: ["~/src/**/target"]
+    severity: safe
+  - title: Logs
+    paths: [/var/log/app/*.log]
+    command: logrotate -f app.conf
+`)
+	write("b.yml", "rules:\n  - title: No paths\n")
+	write("c.yaml", "rules:\n  - title: Odd\n    paths: [x]\n    severity: maybe\n")
+	write("d.yaml", "rules: [")
+	write("e.yaml", "rules:\n  - id: build\n    title: Taken\n    paths: [~/x]\n")
+	write("notes.txt", "not a rules file")
+	for i, p := range []string{`"**"`, `"*"`, `"~/*"`, `"~"`, `"~/**/x"`, `/*/x/y`, `/tmp/*`, `/home`} {
+		write(fmt.Sprintf("broad%d.yaml", i), "rules:\n  - title: Broad\n    paths: ["+p+"]\n")
+	}
+
+	rules, errs := LoadRules([]string{dir, filepath.Join(dir, "missing")}, "/home/me")
+	if len(rules) != 2 {
+		t.Fatalf("expected the 2 rules of a.yaml, got %+v", rules)
+	}
+	if rules[0].ID != "build" || rules[1].ID != "a/Logs" {
+		t.Errorf("expected ids build and a/Logs, got %q and %q", rules[0].ID, rules[1].ID)
+	}
+	if rules[0].Severity != SeveritySafe || rules[1].Severity != SeverityReview || rules[1].Command != "logrotate -f app.conf" {
+		t.Errorf("unexpected rules %+v", rules)
+	}
+	if len(errs) != 12 {
+		t.Errorf("expected b.yml, c.yaml, d.yaml, e.yaml and the broad patterns reported, got %v", errs)
+	}
+}
+
+func TestPlanRules(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the rule command is a sh command line")
+	}
+	home := t.TempDir()
+	now := time.Now()
+	for _, p := range []string{
+		"src/a/target/out.bin",
+		"src/deep/b/target/out.bin",
+		"src/a/target/nested/target/x", // Inside a match, so not matched again
+		"src/c/targets/out.bin",
+		"notes/target.txt",
+	} {
+		writeFile(t, filepath.Join(home, p), 10, 'a', now)
+	}
+	writeFile(t, filepath.Join(home, "tmp", "scratch.dat"), 20, 'b', now)
+
+	rules := []Rule{
+		{Title: "Build output", Paths: []string{"~/src/**/target"}, Severity: SeveritySafe},
+		{Title: "Scratch", Paths: []string{"tmp/*.dat"}, Command: "rm {path}", Severity: SeverityReview},
+		{Title: "Everything", Paths: []string{"~"}, Severity: SeverityCaution},
+	}
+	root := scan(t, home)
+	steps, err := Plan(context.Background(), root, home, now, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("expected 2 steps, never the home folder itself, got %+v", steps)
+	}
+
+	build := steps[0]
+	if build.Title != "Build output" || build.Severity != SeverityCaution || len(build.Items) != 2 {
+		t.Fatalf("expected both target folders, got %+v", build)
+	}
+	for _, n := range build.Items {
+		if filepath.Base(n.Path()) != "target" {
+			t.Errorf("unexpected item %s", n.Path())
+		}
+	}
+
+	scratch := steps[1]
+	removed, err := scratch.Remove()
+	if err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if freed := removedSize(removed); freed != scratch.Size() || !scratch.Items[0].IsDeleted {
+		t.Errorf("expected the command to free %d bytes, got %d", scratch.Size(), freed)
+	}
+	if _, err := os.Stat(filepath.Join(home, "tmp", "scratch.dat")); !os.IsNotExist(err) {
+		t.Error("expected the command to remove the file")
+	}
+}
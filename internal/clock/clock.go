@@ -0,0 +1,32 @@
+// Package clock abstracts time so debounce and timestamp logic can be tested
+// without sleeping.
+package clock
+
+import "time"
+
+// Clock provides the current time and timers
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a pending call scheduled with AfterFunc
+type Timer interface {
+	// Stop cancels the call, returning false if it already ran or was stopped
+	Stop() bool
+}
+
+// Real returns a Clock backed by the time package
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
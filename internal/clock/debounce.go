@@ -0,0 +1,97 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Debouncer runs a function once triggers have been quiet for a delay
+type Debouncer struct {
+	fn func()
+
+	mu      sync.Mutex
+	clock   Clock
+	delay   time.Duration
+	maxWait time.Duration // Longest a call waits after the first trigger, 0 for no limit
+	since   time.Time     // First trigger since the last call
+	timer   Timer
+}
+
+// NewDebouncer creates a debouncer that calls fn delay after the last Trigger
+func NewDebouncer(c Clock, delay time.Duration, fn func()) *Debouncer {
+	return &Debouncer{clock: c, delay: delay, fn: fn}
+}
+
+// SetDelay changes the delay used by subsequent triggers
+func (d *Debouncer) SetDelay(delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.delay = delay
+}
+
+// SetMaxWait bounds how long fn waits after the first of a run of triggers,
+// so triggers that never go quiet still call it every maxWait; 0 removes
+// the bound
+func (d *Debouncer) SetMaxWait(maxWait time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.maxWait = maxWait
+}
+
+// SetClock replaces the time source, cancelling any pending call
+func (d *Debouncer) SetClock(c Clock) {
+	d.Stop()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = c
+}
+
+// Trigger (re)starts the delay before fn runs
+func (d *Debouncer) Trigger() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delay := d.delay
+	if d.timer != nil {
+		d.timer.Stop()
+	} else {
+		d.since = d.clock.Now()
+	}
+	if d.maxWait > 0 {
+		delay = min(delay, d.since.Add(d.maxWait).Sub(d.clock.Now()))
+	}
+	var timer Timer
+	timer = d.clock.AfterFunc(delay, func() {
+		d.mu.Lock()
+		current := d.timer == timer
+		if current {
+			d.timer = nil
+		}
+		d.mu.Unlock()
+
+		if current {
+			d.fn()
+		}
+	})
+	d.timer = timer
+}
+
+// Stop cancels a pending call, returning whether one was pending
+func (d *Debouncer) Stop() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer == nil {
+		return false
+	}
+	d.timer.Stop()
+	d.timer = nil
+	return true
+}
+
+// Flush runs a pending call immediately
+func (d *Debouncer) Flush() {
+	if d.Stop() {
+		d.fn()
+	}
+}
@@ -0,0 +1,82 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebouncerCoalesces(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	calls := 0
+	d := NewDebouncer(clk, time.Second, func() { calls++ })
+
+	// Triggers closer together than the delay collapse into one call
+	d.Trigger()
+	clk.Advance(600 * time.Millisecond)
+	d.Trigger()
+	clk.Advance(600 * time.Millisecond)
+	if calls != 0 {
+		t.Fatalf("expected no call before quiet period, got %d", calls)
+	}
+
+	clk.Advance(400 * time.Millisecond)
+	if calls != 1 {
+		t.Fatalf("expected 1 call after quiet period, got %d", calls)
+	}
+	if clk.Pending() != 0 {
+		t.Errorf("expected no pending timers, got %d", clk.Pending())
+	}
+}
+
+func TestDebouncerFlushAndStop(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	calls := 0
+	d := NewDebouncer(clk, time.Second, func() { calls++ })
+
+	d.Flush()
+	if calls != 0 {
+		t.Errorf("flush without trigger should not call, got %d", calls)
+	}
+
+	d.Trigger()
+	d.Flush()
+	if calls != 1 {
+		t.Errorf("expected flush to call immediately, got %d", calls)
+	}
+
+	d.Trigger()
+	if !d.Stop() {
+		t.Error("expected stop to report a pending call")
+	}
+	clk.Advance(2 * time.Second)
+	if calls != 1 {
+		t.Errorf("expected stopped call not to run, got %d", calls)
+	}
+}
+
+func TestDebouncerMaxWait(t *testing.T) {
+	clk := NewFake(time.Unix(0, 0))
+	calls := 0
+	d := NewDebouncer(clk, time.Second, func() { calls++ })
+	d.SetMaxWait(2 * time.Second)
+
+	// Triggers that never go quiet still call once the wait runs out
+	for range 5 {
+		d.Trigger()
+		clk.Advance(500 * time.Millisecond)
+	}
+	if calls != 1 {
+		t.Fatalf("expected 1 call after the max wait, got %d", calls)
+	}
+
+	// The next run of triggers waits from its own first trigger
+	d.Trigger()
+	clk.Advance(900 * time.Millisecond)
+	if calls != 1 {
+		t.Errorf("expected no call before the delay, got %d", calls)
+	}
+	clk.Advance(100 * time.Millisecond)
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuickAction is a named shell command run on the selected item.
+// Placeholders in Command are replaced with the item before it runs:
+// {path} the full path, {dir} the containing folder, {name} the base name.
+type QuickAction struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"` // Optional single key in the actions palette
+	Command string `json:"command"`
+}
+
+// validate checks that an action can be shown and run
+func (a QuickAction) validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("quick action without a name")
+	}
+	if strings.TrimSpace(a.Command) == "" {
+		return fmt.Errorf("quick action %q has no command", a.Name)
+	}
+	if len([]rune(a.Key)) > 1 {
+		return fmt.Errorf("quick action %q: key %q must be a single character", a.Name, a.Key)
+	}
+	return nil
+}
+
+// validateActions rejects unusable actions and keys used twice
+func validateActions(actions []QuickAction) error {
+	seen := make(map[string]string)
+	for _, a := range actions {
+		if err := a.validate(); err != nil {
+			return err
+		}
+		if a.Key == "" {
+			continue
+		}
+		if other, ok := seen[a.Key]; ok {
+			return fmt.Errorf("quick actions %q and %q share key %q", other, a.Name, a.Key)
+		}
+		seen[a.Key] = a.Name
+	}
+	return nil
+}
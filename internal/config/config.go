@@ -0,0 +1,250 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Config holds user settings loaded from ~/.diskdive/config.json
+type Config struct {
+	Debounce  Debounce      `json:"debounce"`
+	Confirm   Confirm       `json:"confirm"`
+	Actions   []QuickAction `json:"actions"`
+	Locale    string        `json:"locale"` // e.g. "de-DE"; empty uses LANG
+	Scan      Scan          `json:"scan"`
+	Noise     Noise         `json:"noise"`
+	Snapshots Snapshots     `json:"snapshots"`
+	Watch     Watch         `json:"watch"`
+	Freed     Freed         `json:"freed"`
+
+	// WindowTitle keeps the terminal title up to date with scan progress
+	// and space freed, for when diskdive runs in a background tab
+	WindowTitle bool `json:"windowTitle"`
+}
+
+// Scan holds settings for the filesystem walk
+type Scan struct {
+	// NodeBudget caps the files and folders a scan keeps. Past it, the
+	// smallest files of each folder are grouped into one node. 0 keeps all.
+	NodeBudget int `json:"nodeBudget"`
+
+	CrossMounts     bool `json:"crossMounts"`     // Descend into other filesystems mounted below the scan path
+	FollowSymlinks  bool `json:"followSymlinks"`  // Descend into symlinked folders outside the scan path on the same device
+	FollowJunctions bool `json:"followJunctions"` // Descend into NTFS junctions (Windows only)
+	CountHardLinks  bool `json:"countHardLinks"`  // Count every hard link to a file, not only the first
+}
+
+// Snapshots holds how long scan snapshots stay in the cache. The newest
+// snapshot of each path is always kept.
+type Snapshots struct {
+	Keep     int      `json:"keep"`     // Newest snapshots kept per path
+	MaxAge   Duration `json:"maxAge"`   // Older snapshots are removed, e.g. "720h"; 0 keeps them
+	MaxTotal Size     `json:"maxTotal"` // Cap on all snapshots together, e.g. "2GB"; 0 sets none
+
+	// Encrypt encrypts snapshots and size histories with a key from a
+	// "passphrase" or the OS "keychain"; empty writes them plain
+	Encrypt string `json:"encrypt"`
+}
+
+// Debounce holds the delays used to coalesce bursts of activity
+type Debounce struct {
+	Focus     Duration `json:"focus"`     // Treemap refocus after tree navigation
+	Rescan    Duration `json:"rescan"`    // Directory rescan after watcher events
+	Size      Duration `json:"size"`      // Size update of files being written, at most this often
+	StatsSave Duration `json:"statsSave"` // Writing stats after they change
+}
+
+// Watch holds how changes found after a scan are coalesced before the tree
+// follows them. The quiet period before a rescan is Debounce.Rescan.
+type Watch struct {
+	Queue   int      `json:"queue"`   // Changes held for the tree to catch up with; past it they are dropped
+	MaxWait Duration `json:"maxWait"` // Longest a rescan waits for changes to go quiet
+	Batch   int      `json:"batch"`   // Folders rescanned at once past which they are shown as one update
+	Scope   string   `json:"scope"`   // WatchAll or WatchOpen
+	Backend string   `json:"backend"` // BackendNative, BackendFsnotify or BackendPoll
+}
+
+// Watch scopes
+const (
+	WatchAll  = "all"  // Every folder below the scanned path
+	WatchOpen = "open" // Only folders expanded in the tree or zoomed into
+)
+
+// Watch backends
+const (
+	BackendNative   = "native"   // The platform's own watcher, falling back to fsnotify
+	BackendFsnotify = "fsnotify" // The portable fsnotify watcher
+	BackendPoll     = "poll"     // Listing open folders again every few seconds
+)
+
+// Freed sets which deletions count as space freed
+type Freed struct {
+	// Deletions smaller than this, like editors' temporary files, count only
+	// toward all deletions, e.g. "200KB"; 0 counts every deletion as freed
+	MinSize Size `json:"minSize"`
+}
+
+// Noise extends the built-in list of OS metadata files, like .DS_Store,
+// that are left out of change highlighting
+type Noise struct {
+	Names     []string `json:"names"`     // File names or glob patterns, e.g. "*.swp"
+	Uncounted bool     `json:"uncounted"` // Leave noise files out of file counts too
+}
+
+// Default returns the built-in settings
+func Default() Config {
+	return Config{
+		Debounce: Debounce{
+			Focus:     Duration(300 * time.Millisecond),
+			Rescan:    Duration(1500 * time.Millisecond),
+			Size:      Duration(time.Second),
+			StatsSave: Duration(2 * time.Second),
+		},
+		Confirm: Confirm{
+			Move:   ConfirmRule{Mode: ConfirmSmart, MinSize: 1 * GB},
+			Trash:  ConfirmRule{Mode: ConfirmSmart, MinSize: 10 * MB, Dirs: true},
+			Delete: ConfirmRule{Mode: ConfirmAlways},
+		},
+		Snapshots: Snapshots{Keep: 3},
+		Watch:     Watch{Queue: 100, MaxWait: Duration(10 * time.Second), Batch: 16, Scope: WatchAll, Backend: BackendNative},
+		Freed:     Freed{MinSize: 200 * KB},
+	}
+}
+
+// DefaultPath returns the default config file path
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".diskdive-config.json"
+	}
+	return filepath.Join(home, ".diskdive", "config.json")
+}
+
+// Load reads the config file at path. A missing file yields the defaults;
+// settings left out of the file keep their default values.
+func Load(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Default(), fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := validateActions(cfg.Actions); err != nil {
+		return Default(), fmt.Errorf("%s: %w", path, err)
+	}
+	switch cfg.Snapshots.Encrypt {
+	case "", "passphrase", "keychain":
+	default:
+		return Default(), fmt.Errorf("%s: snapshots.encrypt is %q, want \"passphrase\" or \"keychain\"", path, cfg.Snapshots.Encrypt)
+	}
+	switch cfg.Watch.Scope {
+	case "", WatchAll, WatchOpen:
+	default:
+		return Default(), fmt.Errorf("%s: watch.scope is %q, want \"all\" or \"open\"", path, cfg.Watch.Scope)
+	}
+	switch cfg.Watch.Backend {
+	case "", BackendNative, BackendFsnotify, BackendPoll:
+	default:
+		return Default(), fmt.Errorf("%s: watch.backend is %q, want \"native\", \"fsnotify\" or \"poll\"", path, cfg.Watch.Backend)
+	}
+	if cfg.Scan.NodeBudget < 0 {
+		return Default(), fmt.Errorf("%s: scan.nodeBudget is %d, want 0 or more", path, cfg.Scan.NodeBudget)
+	}
+	for _, pattern := range cfg.Noise.Names {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return Default(), fmt.Errorf("%s: noise pattern %q: %w", path, pattern, err)
+		}
+	}
+	cfg.fillDefaults()
+	return cfg, nil
+}
+
+// fillDefaults replaces unusable values with the built-in defaults
+func (c *Config) fillDefaults() {
+	def := Default()
+	if c.Debounce.Focus <= 0 {
+		c.Debounce.Focus = def.Debounce.Focus
+	}
+	if c.Debounce.Rescan <= 0 {
+		c.Debounce.Rescan = def.Debounce.Rescan
+	}
+	if c.Debounce.Size <= 0 {
+		c.Debounce.Size = def.Debounce.Size
+	}
+	if c.Watch.Queue <= 0 {
+		c.Watch.Queue = def.Watch.Queue
+	}
+	if c.Watch.MaxWait <= 0 {
+		c.Watch.MaxWait = def.Watch.MaxWait
+	}
+	if c.Watch.Batch <= 0 {
+		c.Watch.Batch = def.Watch.Batch
+	}
+	if c.Watch.Scope == "" {
+		c.Watch.Scope = def.Watch.Scope
+	}
+	if c.Watch.Backend == "" {
+		c.Watch.Backend = def.Watch.Backend
+	}
+	if c.Debounce.StatsSave <= 0 {
+		c.Debounce.StatsSave = def.Debounce.StatsSave
+	}
+	if c.Confirm.Move.Mode == "" {
+		c.Confirm.Move.Mode = def.Confirm.Move.Mode
+	}
+	if c.Confirm.Trash.Mode == "" {
+		c.Confirm.Trash.Mode = def.Confirm.Trash.Mode
+	}
+	if c.Confirm.Delete.Mode == "" {
+		c.Confirm.Delete.Mode = def.Confirm.Delete.Mode
+	}
+	if c.Snapshots.Keep <= 0 {
+		c.Snapshots.Keep = def.Snapshots.Keep
+	}
+	if c.Snapshots.MaxAge < 0 {
+		c.Snapshots.MaxAge = def.Snapshots.MaxAge
+	}
+}
+
+// Duration is a time.Duration written in config files as a string like "300ms"
+type Duration time.Duration
+
+// Std returns the value as a time.Duration
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts a duration string ("1.5s") or a number of milliseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var ms int64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	*d = Duration(time.Duration(ms) * time.Millisecond)
+	return nil
+}
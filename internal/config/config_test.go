@@ -0,0 +1,218 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadDebounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"debounce": {"focus": "100ms", "rescan": 5000}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := cfg.Debounce.Focus.Std(); got != 100*time.Millisecond {
+		t.Errorf("focus: expected 100ms, got %v", got)
+	}
+	if got := cfg.Debounce.Rescan.Std(); got != 5*time.Second {
+		t.Errorf("rescan: expected 5s, got %v", got)
+	}
+	if cfg.Debounce.StatsSave != Default().Debounce.StatsSave {
+		t.Errorf("statsSave: expected default, got %v", cfg.Debounce.StatsSave.Std())
+	}
+}
+
+func TestLoadWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"watch": {"queue": 5000, "maxWait": "30s", "batch": -1, "scope": "open", "backend": "fsnotify"}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Watch.Queue != 5000 {
+		t.Errorf("queue: expected 5000, got %d", cfg.Watch.Queue)
+	}
+	if got := cfg.Watch.MaxWait.Std(); got != 30*time.Second {
+		t.Errorf("maxWait: expected 30s, got %v", got)
+	}
+	if cfg.Watch.Batch != Default().Watch.Batch {
+		t.Errorf("batch: expected default for an unusable value, got %d", cfg.Watch.Batch)
+	}
+	if cfg.Watch.Scope != WatchOpen {
+		t.Errorf("scope: expected %q, got %q", WatchOpen, cfg.Watch.Scope)
+	}
+	if cfg.Watch.Backend != BackendFsnotify {
+		t.Errorf("backend: expected %q, got %q", BackendFsnotify, cfg.Watch.Backend)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"watch": {"scope": "some"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+	if err := os.WriteFile(path, []byte(`{"watch": {"backend": "kqueue"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for an unknown backend")
+	}
+}
+
+func TestLoadNodeBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"scan": {"nodeBudget": 5000000}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if cfg.Scan.NodeBudget != 5000000 {
+		t.Errorf("expected 5000000, got %d", cfg.Scan.NodeBudget)
+	}
+}
+
+func TestLoadScanPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"scan": {"crossMounts": true, "countHardLinks": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !cfg.Scan.CrossMounts || !cfg.Scan.CountHardLinks || cfg.Scan.FollowSymlinks || cfg.Scan.FollowJunctions {
+		t.Errorf("unexpected scan settings %+v", cfg.Scan)
+	}
+
+	for _, bad := range []string{
+		`{"scan": {"followSymlinks": "yes"}}`,
+		`{"scan": {"nodeBudget": -1}}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+func TestLoadSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"snapshots": {"maxAge": "720h", "maxTotal": "2GB"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	s := cfg.Snapshots
+	if s.Keep != 3 || s.MaxAge.Std() != 720*time.Hour || s.MaxTotal != 2*GB || s.Encrypt != "" {
+		t.Errorf("expected keep 3, 720h, 2GB, no encryption, got %+v", s)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"snapshots": {"encrypt": "yes"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for an unknown encryption")
+	}
+}
+
+func TestLoadNoise(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"noise": {"names": ["*.swp"], "uncounted": true}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Noise, Noise{Names: []string{"*.swp"}, Uncounted: true}) {
+		t.Errorf("unexpected noise settings %+v", cfg.Noise)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"noise": {"names": ["[a-"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for a malformed pattern")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"debounce": {"focus": "soon"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err == nil {
+		t.Error("expected error for invalid duration")
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("expected defaults on error, got %+v", cfg)
+	}
+}
+
+func TestLoadActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"actions": [{"name": "git gc", "key": "g", "command": "git -C {dir} gc"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(cfg.Actions) != 1 || cfg.Actions[0].Key != "g" || cfg.Actions[0].Command != "git -C {dir} gc" {
+		t.Errorf("unexpected actions %+v", cfg.Actions)
+	}
+}
+
+func TestLoadActionsInvalid(t *testing.T) {
+	tests := []string{
+		`{"actions": [{"name": "", "command": "true"}]}`,
+		`{"actions": [{"name": "noop"}]}`,
+		`{"actions": [{"name": "a", "key": "ab", "command": "true"}]}`,
+		`{"actions": [{"name": "a", "key": "x", "command": "true"}, {"name": "b", "key": "x", "command": "true"}]}`,
+	}
+	for _, data := range tests {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
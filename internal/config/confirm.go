@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Confirm holds when actions that change files ask before running
+type Confirm struct {
+	Move   ConfirmRule `json:"move"`
+	Trash  ConfirmRule `json:"trash"`
+	Delete ConfirmRule `json:"delete"`
+}
+
+// ConfirmMode is when an action asks for confirmation
+type ConfirmMode string
+
+const (
+	ConfirmNever  ConfirmMode = "never"
+	ConfirmSmart  ConfirmMode = "smart" // Only for large items or directories, per the rule
+	ConfirmAlways ConfirmMode = "always"
+)
+
+// UnmarshalJSON rejects unknown modes
+func (m *ConfirmMode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch mode := ConfirmMode(strings.ToLower(s)); mode {
+	case ConfirmNever, ConfirmSmart, ConfirmAlways:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("invalid confirm mode %q (want never, smart or always)", s)
+}
+
+// ConfirmRule decides whether one kind of action needs confirmation
+type ConfirmRule struct {
+	Mode    ConfirmMode `json:"mode"`
+	MinSize Size        `json:"minSize"` // smart: confirm when the items total at least this much
+	Dirs    bool        `json:"dirs"`    // smart: confirm whenever a directory is involved
+}
+
+// Needs reports whether acting on items totalling size bytes needs confirmation
+func (r ConfirmRule) Needs(size int64, hasDir bool) bool {
+	switch r.Mode {
+	case ConfirmNever:
+		return false
+	case ConfirmSmart:
+		return (r.Dirs && hasDir) || (r.MinSize > 0 && size >= int64(r.MinSize))
+	}
+	return true
+}
+
+// Size is a byte count written in config files as a string like "10MB" or a number of bytes
+type Size int64
+
+// Size units, binary like the sizes shown in the UI
+const (
+	KB Size = 1 << (10 * (iota + 1))
+	MB
+	GB
+	TB
+)
+
+var sizeUnits = []struct {
+	suffix string
+	size   Size
+}{
+	{"TB", TB},
+	{"GB", GB},
+	{"MB", MB},
+	{"KB", KB},
+	{"B", 1},
+}
+
+// String formats the size with the largest unit that divides it evenly
+func (s Size) String() string {
+	for _, u := range sizeUnits {
+		if s != 0 && s%u.size == 0 {
+			return fmt.Sprintf("%d%s", s/u.size, u.suffix)
+		}
+	}
+	return "0B"
+}
+
+// MarshalJSON encodes the size as a string
+func (s Size) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON accepts a size string ("1.5GB", "500 MB") or a number of bytes
+func (s *Size) UnmarshalJSON(data []byte) error {
+	var n int64
+	if err := json.Unmarshal(data, &n); err == nil {
+		*s = Size(n)
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("invalid size %s", data)
+	}
+	parsed, err := ParseSize(str)
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// ParseSize parses a size such as "10MB", "1.5 GB", "250M" or "4096"
+func ParseSize(str string) (Size, error) {
+	text := strings.ToUpper(strings.TrimSpace(str))
+	for _, u := range sizeUnits {
+		suffix := u.suffix
+		if !strings.HasSuffix(text, suffix) {
+			// The B may be left off, as in "250M"
+			suffix = strings.TrimSuffix(suffix, "B")
+			if suffix == "" || !strings.HasSuffix(text, suffix) {
+				continue
+			}
+		}
+		num := strings.TrimSpace(strings.TrimSuffix(text, suffix))
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil || v < 0 {
+			return 0, fmt.Errorf("invalid size %q", str)
+		}
+		return Size(v * float64(u.size)), nil
+	}
+	v, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q", str)
+	}
+	return Size(v), nil
+}
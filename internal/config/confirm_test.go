@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfirmRuleNeeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   ConfirmRule
+		size   int64
+		hasDir bool
+		want   bool
+	}{
+		{"never", ConfirmRule{Mode: ConfirmNever}, int64(TB), true, false},
+		{"always", ConfirmRule{Mode: ConfirmAlways}, 1, false, true},
+		{"smart small file", ConfirmRule{Mode: ConfirmSmart, MinSize: 10 * MB, Dirs: true}, int64(MB), false, false},
+		{"smart large file", ConfirmRule{Mode: ConfirmSmart, MinSize: 10 * MB}, int64(10 * MB), false, true},
+		{"smart directory", ConfirmRule{Mode: ConfirmSmart, MinSize: 10 * MB, Dirs: true}, 1, true, true},
+		{"smart no threshold", ConfirmRule{Mode: ConfirmSmart}, int64(TB), true, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.Needs(tt.size, tt.hasDir); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]Size{
+		"4096":   4096,
+		"10MB":   10 * MB,
+		"1.5 gb": GB + GB/2,
+		"2TB":    2 * TB,
+		"512B":   512,
+		"250M":   250 * MB,
+		"1.5g":   GB + GB/2,
+		"64 k":   64 * KB,
+	}
+	for in, want := range tests {
+		got, err := ParseSize(in)
+		if err != nil {
+			t.Errorf("ParseSize(%q) failed: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseSize(%q) = %d, want %d", in, got, want)
+		}
+	}
+
+	if _, err := ParseSize("lots"); err == nil {
+		t.Error("expected error for invalid size")
+	}
+}
+
+func TestLoadConfirm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"confirm": {"delete": {"mode": "smart", "minSize": "1GB", "dirs": true}, "trash": {"mode": "never"}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	want := ConfirmRule{Mode: ConfirmSmart, MinSize: GB, Dirs: true}
+	if cfg.Confirm.Delete != want {
+		t.Errorf("delete: expected %+v, got %+v", want, cfg.Confirm.Delete)
+	}
+	if cfg.Confirm.Trash.Mode != ConfirmNever {
+		t.Errorf("trash: expected never, got %s", cfg.Confirm.Trash.Mode)
+	}
+	if cfg.Confirm.Move != Default().Confirm.Move {
+		t.Errorf("move: expected default, got %+v", cfg.Confirm.Move)
+	}
+}
+
+func TestLoadConfirmInvalidMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"confirm": {"move": {"mode": "sometimes"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
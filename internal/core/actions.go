@@ -0,0 +1,400 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lumipallolabs/diskdive/internal/fileops"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// maxBatchOpen is the most marked items OpenMarked opens at once
+const maxBatchOpen = 10
+
+// ToggleMark marks or unmarks a node for batch operations and returns the new state
+func (c *Controller) ToggleMark(node *model.Node) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if node == nil || node.IsDeleted || node.Attrs.IsSynthetic() {
+		return false
+	}
+	if c.marked[node] {
+		delete(c.marked, node)
+		return false
+	}
+	c.marked[node] = true
+	return true
+}
+
+// IsMarked returns whether a node is marked
+func (c *Controller) IsMarked(node *model.Node) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.marked[node]
+}
+
+// Marked returns the marked nodes, omitting any whose ancestor is also
+// marked and any deleted since they were marked
+func (c *Controller) Marked() []*model.Node {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*model.Node
+	for node := range c.marked {
+		if node.IsDeleted {
+			continue
+		}
+		covered := false
+		for p := node.Parent; p != nil; p = p.Parent {
+			if c.marked[p] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			result = append(result, node)
+		}
+	}
+	model.SortBySize(result)
+	return result
+}
+
+// ClearMarks unmarks all nodes
+func (c *Controller) ClearMarks() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.marked = make(map[*model.Node]bool)
+}
+
+// MarkNodes marks every node that can be marked, as for a range selected
+// in the tree, and returns how many were not marked before
+func (c *Controller) MarkNodes(nodes []*model.Node) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	added := 0
+	for _, node := range nodes {
+		if node == nil || node.Parent == nil || node.IsDeleted || node.Attrs.IsSynthetic() || c.marked[node] {
+			continue
+		}
+		c.marked[node] = true
+		added++
+	}
+	return added
+}
+
+// MarkedTotal returns how many items are marked and their total size,
+// counting items inside a marked folder once
+func (c *Controller) MarkedTotal() (count int, size int64) {
+	marked := c.Marked()
+	for _, node := range marked {
+		size += node.TotalSize()
+	}
+	return len(marked), size
+}
+
+// WriteMarked lists the marked items to w, largest first, one per line as
+// du does: the size in bytes, a tab and the path
+func (c *Controller) WriteMarked(w io.Writer) error {
+	for _, node := range c.Marked() {
+		if _, err := fmt.Fprintf(w, "%d\t%s\n", node.TotalSize(), node.Path()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenMarked calls open on the path of each marked item, largest first, up
+// to maxBatchOpen so a large selection does not flood the desktop with
+// windows. It returns how many were opened and the first error.
+func (c *Controller) OpenMarked(open func(path string) error) (int, error) {
+	marked := c.Marked()
+	if len(marked) > maxBatchOpen {
+		marked = marked[:maxBatchOpen]
+	}
+	opened := 0
+	var firstErr error
+	for _, node := range marked {
+		if err := open(node.Path()); err != nil {
+			logging.Debug.Printf("[Controller] Open failed: %s: %v", node.Path(), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		opened++
+	}
+	return opened, firstErr
+}
+
+// CreateDirectory creates a new directory under parent and adds it to the tree
+func (c *Controller) CreateDirectory(parent *model.Node, name string) (*model.Node, error) {
+	if parent == nil || !parent.IsDir || parent.IsDeleted || parent.Attrs.Has(model.AttrVirtual) {
+		return nil, fmt.Errorf("not a directory")
+	}
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(parent.Path(), name)
+	if err := fileops.CreateDir(path); err != nil {
+		return nil, err
+	}
+
+	node := &model.Node{
+		Name:  name,
+		IsDir: true,
+		IsNew: true,
+		UID:   model.NoOwner,
+		GID:   model.NoOwner,
+	}
+	if info, err := os.Stat(path); err == nil {
+		node.UID, node.GID = model.OwnerOf(info)
+	}
+	parent.AddChild(node)
+	c.pathIndex().Add(node)
+	logging.Debug.Printf("[Controller] Created directory %s", path)
+	return node, nil
+}
+
+// MoveItems moves nodes into destDir in the background, streaming progress events
+func (c *Controller) MoveItems(nodes []*model.Node, destDir string) (<-chan Event, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("nothing to move")
+	}
+
+	destDir = filepath.Clean(destDir)
+	info, err := os.Stat(destDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", destDir)
+	}
+
+	for _, node := range nodes {
+		if node.IsDeleted {
+			return nil, fmt.Errorf("already deleted: %s", node.Name)
+		}
+		if node.Attrs.Has(model.AttrVirtual) {
+			return nil, fmt.Errorf("%s is estimated space, not a file", node.Name)
+		}
+		if node.Attrs.Has(model.AttrGrouped) {
+			return nil, fmt.Errorf("%s stands for several files, not one", node.Name)
+		}
+		if isWithin(destDir, node.Path()) {
+			return nil, fmt.Errorf("cannot move %s into itself", node.Name)
+		}
+	}
+
+	eventCh := make(chan Event, 100)
+	go c.runMove(nodes, destDir, eventCh)
+	return eventCh, nil
+}
+
+// runMove executes a move operation in a goroutine
+func (c *Controller) runMove(nodes []*model.Node, destDir string, eventCh chan Event) {
+	defer close(eventCh)
+
+	var total int64
+	for _, node := range nodes {
+		total += node.TotalSize()
+	}
+
+	var done, freed int64
+	var moved int
+	var firstErr error
+
+	for _, node := range nodes {
+		src := node.Path()
+		base := done
+		crossDevice, err := fileops.Move(src, filepath.Join(destDir, node.Name), func(n int64) {
+			select {
+			case eventCh <- MoveProgressEvent{Path: src, BytesDone: base + n, BytesTotal: total}:
+			default:
+				// Drop progress updates the UI hasn't caught up with
+			}
+		})
+		done = base + node.TotalSize()
+
+		if err != nil {
+			logging.Debug.Printf("[Controller] Move failed: %s: %v", src, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			// Show what is left of a folder only partly removed after copying
+			var partial *fileops.PartialMoveError
+			if errors.As(err, &partial) && node.IsDir {
+				if _, rerr := c.RescanSubtree(node); rerr != nil {
+					logging.Debug.Printf("[Controller] Rescan after partial move: %s: %v", src, rerr)
+				}
+			}
+			continue
+		}
+
+		moved++
+		c.mu.Lock()
+		inTree := c.relocateNodeLocked(node, destDir)
+		c.mu.Unlock()
+		if crossDevice && !inTree {
+			freed += node.TotalSize()
+		}
+		logging.Debug.Printf("[Controller] Moved %s -> %s (cross-device: %v)", src, destDir, crossDevice)
+	}
+
+	c.mu.Lock()
+	if freed > 0 {
+		c.recordFreedLocked(freed)
+	}
+	for _, node := range nodes {
+		delete(c.marked, node)
+	}
+	diskFree := c.getDiskFree()
+	c.mu.Unlock()
+
+	eventCh <- MoveCompletedEvent{
+		Moved:    moved,
+		Freed:    freed,
+		DiskFree: diskFree,
+		Err:      firstErr,
+	}
+}
+
+// TrashItems moves nodes to the platform trash in the background; the
+// channel gets a TrashCompletedEvent when done
+func (c *Controller) TrashItems(nodes []*model.Node) (<-chan Event, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("nothing to move to the trash")
+	}
+	for _, node := range nodes {
+		if node.IsDeleted {
+			return nil, fmt.Errorf("already deleted: %s", node.Name)
+		}
+		if node.Attrs.Has(model.AttrVirtual) {
+			return nil, fmt.Errorf("%s is estimated space, not a file", node.Name)
+		}
+		if node.Attrs.Has(model.AttrGrouped) {
+			return nil, fmt.Errorf("%s stands for several files, not one", node.Name)
+		}
+	}
+
+	eventCh := make(chan Event, 1)
+	go c.runTrash(nodes, eventCh)
+	return eventCh, nil
+}
+
+// runTrash executes a move to the trash in a goroutine. Trashed nodes are
+// marked deleted as the watcher would, which then ignores their deletion.
+func (c *Controller) runTrash(nodes []*model.Node, eventCh chan Event) {
+	defer close(eventCh)
+
+	var trashed []Deletion
+	var firstErr error
+	for _, node := range nodes {
+		path, size := node.Path(), node.TotalSize()
+		if err := fileops.Trash(path); err != nil {
+			logging.Debug.Printf("[Controller] Trash failed: %s: %v", path, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		c.mu.Lock()
+		node.MarkDeleted()
+		c.mu.Unlock()
+		trashed = append(trashed, Deletion{Path: path, Size: size})
+		logging.Debug.Printf("[Controller] Trashed %s (size: %d)", path, size)
+	}
+
+	var freed int64
+	c.mu.Lock()
+	for _, d := range trashed {
+		freed += d.Size
+		c.recordFreedLocked(d.Size)
+		c.noteDeletionLocked(d.Path, d.Size)
+		if c.statsManager != nil {
+			c.statsManager.LogDeletion(d.Path, d.Size)
+		}
+	}
+	for _, node := range nodes {
+		delete(c.marked, node)
+	}
+	diskFree := c.getDiskFree()
+	c.mu.Unlock()
+
+	eventCh <- TrashCompletedEvent{
+		Trashed:  len(trashed),
+		Freed:    freed,
+		DiskFree: diskFree,
+		Err:      firstErr,
+	}
+}
+
+// relocateNodeLocked reattaches a moved node under destDir, returning false
+// if the destination lies outside the scanned tree (caller must hold lock)
+func (c *Controller) relocateNodeLocked(node *model.Node, destDir string) bool {
+	c.index.Remove(node)
+	if node.Parent != nil {
+		node.Parent.RemoveChild(node)
+	}
+	if c.root == nil {
+		return false
+	}
+
+	var dest *model.Node
+	if c.index != nil {
+		dest = c.index.Lookup(destDir)
+	} else {
+		dest = c.root.Find(destDir)
+	}
+	if dest == nil {
+		return false
+	}
+	dest.AddChild(node)
+	c.index.Add(node)
+	return true
+}
+
+// recordFreedLocked adds to the deleted counters, and to the freed ones
+// unless size is under freed.minSize (caller must hold lock)
+func (c *Controller) recordFreedLocked(size int64) {
+	c.freed.Deleted += size
+	c.freed.DeletedLifetime += size
+	if c.statsManager != nil {
+		c.statsManager.AddDeleted(size)
+	}
+	if size < int64(c.config.Freed.MinSize) {
+		return
+	}
+	c.freed.Session += size
+	c.freed.Lifetime += size
+	if c.statsManager != nil {
+		c.statsManager.AddFreed(c.statsDriveLocked(), size)
+	}
+}
+
+// validateName checks that name is usable as a single path element
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("invalid name %q", name)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("name must not contain path separators")
+	}
+	return nil
+}
+
+// isWithin reports whether path equals dir or lies inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
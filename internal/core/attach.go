@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// heartbeatInterval is how often a running scan refreshes its claim, and how
+// often an instance following it reads the claim back
+const heartbeatInterval = time.Second
+
+// claimScan records that this instance scans path. When another instance is
+// already scanning it, it returns false with that instance's status. A cache
+// that cannot hold the claim does not stop the scan.
+func (c *Controller) claimScan(path string) (cache.ScanStatus, cache.ScanStatus, bool) {
+	host, _ := os.Hostname()
+	claim := cache.ScanStatus{PID: os.Getpid(), Host: host, Path: path, Started: c.clock.Now()}
+	ok, other, err := c.cache.ClaimScan(path, claim)
+	if err != nil {
+		logging.Debug.Printf("[Controller] Scanning %s without a claim: %v", path, err)
+		return cache.ScanStatus{}, cache.ScanStatus{}, true
+	}
+	return claim, other, ok
+}
+
+// holdScan keeps the claim on path fresh with the progress of the scan until
+// the returned function is called, which gives the claim up
+func (c *Controller) holdScan(path string, claim cache.ScanStatus) func() {
+	if claim.PID == 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			c.mu.RLock()
+			claim.Files = c.scan.FilesScanned
+			claim.Bytes = c.scan.BytesFound
+			claim.FilesPerSec = c.scan.FilesPerSec
+			claim.BytesPerSec = c.scan.BytesPerSec
+			claim.Current = c.scan.CurrentPath
+			c.mu.RUnlock()
+			if err := c.cache.UpdateScan(path, claim); err != nil {
+				logging.Debug.Printf("[Controller] Failed to refresh scan claim: %v", err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		c.cache.ReleaseScan(path, claim)
+	}
+}
+
+// followScan shows the scan of path another instance is running, then loads
+// the snapshot it saves and finishes it as its own, with growth measured
+// against samples. It returns false if that instance stopped without saving
+// one, leaving the scan to this instance.
+func (c *Controller) followScan(ctx context.Context, path string, other cache.ScanStatus, samples []cache.Sample, eventCh chan Event) bool {
+	logging.Debug.Printf("[Controller] Following the scan of %s by pid %d on %s", path, other.PID, other.Host)
+
+	c.mu.Lock()
+	c.scan.Path = path
+	c.scan.StartTime = other.Started
+	c.scan.Following = other.PID
+	c.mu.Unlock()
+
+	eventCh <- ScanStartedEvent{Path: path}
+
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.mu.Lock()
+			c.scan.Phase = PhaseIdle
+			c.scan.Following = 0
+			c.mu.Unlock()
+
+			eventCh <- ScanCompletedEvent{Err: ctx.Err()}
+			eventCh <- ErrorEvent{Err: ctx.Err()}
+			return true
+		case <-ticker.C:
+		}
+
+		status, running := c.cache.Scanning(path)
+		if running {
+			c.mu.Lock()
+			c.scan.FilesScanned = status.Files
+			c.scan.BytesFound = status.Bytes
+			c.scan.FilesPerSec = status.FilesPerSec
+			c.scan.BytesPerSec = status.BytesPerSec
+			c.scan.CurrentPath = status.Current
+			c.mu.Unlock()
+
+			eventCh <- ScanProgressEvent{
+				FilesScanned: status.Files,
+				BytesFound:   status.Bytes,
+				FilesPerSec:  status.FilesPerSec,
+				BytesPerSec:  status.BytesPerSec,
+				CurrentPath:  status.Current,
+			}
+			continue
+		}
+		break
+	}
+
+	// The other instance is done; its snapshot is the result, unless it
+	// was stopped before saving one. Snapshot names drop sub-second time.
+	header, err := c.cache.LoadHeader(path)
+	if err != nil || header.ScannedAt.Before(other.Started.Truncate(time.Second)) {
+		logging.Debug.Printf("[Controller] Pid %d stopped without saving a snapshot of %s", other.PID, path)
+		c.mu.Lock()
+		c.scan.Following = 0
+		c.mu.Unlock()
+		return false
+	}
+	root, err := c.cache.LoadLatest(path)
+	if err != nil {
+		c.mu.Lock()
+		c.scan.Phase = PhaseIdle
+		c.scan.Following = 0
+		c.mu.Unlock()
+
+		eventCh <- ScanCompletedEvent{Err: err}
+		eventCh <- ErrorEvent{Err: err}
+		return true
+	}
+	root.ComputeSizes()
+
+	c.mu.Lock()
+	c.scan.FilesScanned = header.Files
+	c.scan.BytesFound = header.Bytes
+	c.mu.Unlock()
+
+	// What the other walk skipped and where it was slow stay with it
+	released := make(chan struct{})
+	close(released)
+	c.finishScan(path, root, header.Duration, samples, scanFindings{
+		extensions: model.BuildExtHistogram(root),
+	}, released, eventCh)
+	return true
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/lumipallolabs/diskdive/internal/cleanup"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+)
+
+// CleanUp removes a step of the cleanup wizard in the background, counting
+// what goes as freed and logging it like any other deletion; the channel
+// gets a CleanupCompletedEvent when done
+func (c *Controller) CleanUp(step cleanup.Step) (<-chan Event, error) {
+	if len(step.Items) == 0 {
+		return nil, fmt.Errorf("nothing to clean up")
+	}
+	eventCh := make(chan Event, 1)
+	go c.runCleanUp(step, eventCh)
+	return eventCh, nil
+}
+
+// runCleanUp removes the step's items in a goroutine
+func (c *Controller) runCleanUp(step cleanup.Step, eventCh chan Event) {
+	defer close(eventCh)
+
+	removed, err := step.Remove()
+	if err != nil {
+		logging.Debug.Printf("[Controller] Cleanup %q failed: %v", step.Title, err)
+	}
+
+	var freed int64
+	c.mu.Lock()
+	for _, r := range removed {
+		freed += r.Size
+		c.recordFreedLocked(r.Size)
+		c.noteDeletionLocked(r.Path, r.Size)
+		if c.statsManager != nil {
+			c.statsManager.LogDeletion(r.Path, r.Size)
+		}
+	}
+	diskFree := c.getDiskFree()
+	c.mu.Unlock()
+
+	eventCh <- CleanupCompletedEvent{
+		Removed:  len(removed),
+		Freed:    freed,
+		DiskFree: diskFree,
+		Err:      err,
+	}
+}
+
+// Dismissed returns the paths the cleanup wizard was told to keep under rule
+func (c *Controller) Dismissed(rule string) []string {
+	if c.statsManager == nil {
+		return nil
+	}
+	return c.statsManager.Dismissed(rule)
+}
+
+// Dismiss keeps the cleanup wizard from suggesting paths under rule again
+func (c *Controller) Dismiss(rule string, paths []string) {
+	if c.statsManager != nil {
+		c.statsManager.Dismiss(rule, paths)
+	}
+}
+
+// ResetDismissed lets the cleanup wizard suggest everything it was told
+// to keep again
+func (c *Controller) ResetDismissed() {
+	if c.statsManager != nil {
+		c.statsManager.ResetDismissed()
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// SnapshotInfo describes a saved scan of the current path
+type SnapshotInfo struct {
+	ScannedAt time.Time
+	Files     int64
+	Bytes     int64
+	Label     string // Attached by the user, empty if none
+}
+
+// Comparison names the two scans whose differences are shown in place of
+// the live tree, with their labels. A zero To stands for the current scan.
+type Comparison struct {
+	From      time.Time
+	To        time.Time
+	FromLabel string
+	ToLabel   string
+}
+
+// Snapshots returns the saved scans of the current path, newest first
+func (c *Controller) Snapshots() []SnapshotInfo {
+	c.mu.RLock()
+	path := c.scanPathLocked()
+	c.mu.RUnlock()
+
+	headers, err := c.cache.List(path)
+	if err != nil {
+		logging.Debug.Printf("[Controller] Listing snapshots of %s: %v", path, err)
+		return nil
+	}
+	infos := make([]SnapshotInfo, len(headers))
+	for i, h := range headers {
+		infos[i] = SnapshotInfo{ScannedAt: h.ScannedAt, Files: h.Files, Bytes: h.Bytes, Label: h.Label}
+	}
+	return infos
+}
+
+// LabelSnapshot sets the label of the saved scan of the current path taken
+// at t, or removes it if label is empty
+func (c *Controller) LabelSnapshot(t time.Time, label string) error {
+	c.mu.RLock()
+	path := c.scanPathLocked()
+	c.mu.RUnlock()
+	return c.cache.Label(path, t, label)
+}
+
+// SizeHistory returns the size history of the current path, empty if no
+// scan of it was saved yet
+func (c *Controller) SizeHistory() (*cache.History, error) {
+	c.mu.RLock()
+	path := c.scanPathLocked()
+	c.mu.RUnlock()
+	return c.cache.History(path)
+}
+
+// Compare shows what changed between two scans of the current path: the
+// snapshots taken at from and to, or the current scan when to is zero. The
+// older of the two is the baseline. The returned tree holds the newer scan
+// with sizes before and after, new items flagged and removed items added
+// back as deleted. It replaces the live tree, so the watcher stops until
+// the next scan.
+func (c *Controller) Compare(from, to time.Time) (*model.Node, error) {
+	if from.Equal(to) {
+		return nil, fmt.Errorf("pick two different scans")
+	}
+	if from.IsZero() || (!to.IsZero() && to.Before(from)) {
+		from, to = to, from
+	}
+
+	c.mu.RLock()
+	path := c.scanPathLocked()
+	current := c.root
+	comparing := !c.scan.Comparison.From.IsZero()
+	c.mu.RUnlock()
+
+	older, olderHeader, err := c.loadSnapshot(path, from)
+	if err != nil {
+		return nil, err
+	}
+	var newer *model.Node
+	var newerHeader cache.Header
+	if to.IsZero() {
+		if current == nil || comparing {
+			return nil, fmt.Errorf("rescan to compare with the current scan")
+		}
+		newer = current.Extract()
+		newer.Prune()
+		for _, child := range newer.Children {
+			if child.Attrs.Has(model.AttrVirtual) {
+				newer.RemoveChild(child) // Estimates were never saved, so they would show as new
+				break
+			}
+		}
+	} else if newer, newerHeader, err = c.loadSnapshot(path, to); err != nil {
+		return nil, err
+	}
+
+	cache.ApplyDiff(newer, older, c.Noise())
+	index := model.NewPathIndex(newer)
+	extensions := model.BuildExtHistogram(newer)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watcher != nil {
+		_ = c.watcher.Stop()
+		c.watcher, c.scoped = nil, nil
+	}
+	c.root = newer
+	c.index = index
+	c.tree = NewTreeState()
+	c.tree.Root = newer
+	c.tree.Expanded[newer.Path()] = true
+	c.marked = make(map[*model.Node]bool)
+	c.extensions = extensions
+	c.areas = nil
+	c.scan.Comparison = Comparison{From: from, To: to, FromLabel: olderHeader.Label, ToLabel: newerHeader.Label}
+	return newer, nil
+}
+
+// loadSnapshot loads the snapshot of path taken at t, with sizes computed
+func (c *Controller) loadSnapshot(path string, t time.Time) (*model.Node, cache.Header, error) {
+	root, header, err := c.cache.LoadBefore(path, t)
+	if err != nil {
+		return nil, header, err
+	}
+	if !header.ScannedAt.Equal(t) {
+		return nil, header, fmt.Errorf("the snapshot from %s is gone", t.Format(time.DateTime))
+	}
+	root.ComputeSizes()
+	return root, header, nil
+}
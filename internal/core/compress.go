@@ -0,0 +1,184 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lumipallolabs/diskdive/internal/fileops"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+// CompressItem writes the folder node to a new archive at dest in the
+// background, streaming progress events, and deletes the folder once the
+// archive is complete if deleteAfter is set. The archive is a zip or a
+// gzip-compressed tar depending on dest's extension.
+func (c *Controller) CompressItem(node *model.Node, dest string, deleteAfter bool) (<-chan Event, error) {
+	if node == nil || !node.IsDir {
+		return nil, fmt.Errorf("only folders can be compressed")
+	}
+	if node.Parent == nil {
+		return nil, fmt.Errorf("the scanned folder itself cannot be compressed")
+	}
+	if node.IsDeleted {
+		return nil, fmt.Errorf("already deleted: %s", node.Name)
+	}
+	if node.Attrs.IsSynthetic() {
+		return nil, fmt.Errorf("%s is not a folder on disk", node.Name)
+	}
+
+	dest = filepath.Clean(dest)
+	if _, err := os.Lstat(dest); err == nil {
+		return nil, fmt.Errorf("destination exists: %s", dest)
+	}
+
+	eventCh := make(chan Event, 100)
+	go c.runCompress(node, dest, deleteAfter, eventCh)
+	return eventCh, nil
+}
+
+// runCompress executes a compression in a goroutine
+func (c *Controller) runCompress(node *model.Node, dest string, deleteAfter bool, eventCh chan Event) {
+	defer close(eventCh)
+
+	src, size, total := node.Path(), node.TotalSize(), logicalSize(node)
+	var read int64
+	skipped, err := fileops.Compress(src, dest, func(done int64) {
+		read = done
+		select {
+		case eventCh <- CompressProgressEvent{Path: src, BytesDone: done, BytesTotal: total}:
+		default:
+			// Drop progress updates the UI hasn't caught up with
+		}
+	})
+	if err != nil {
+		logging.Debug.Printf("[Controller] Compress failed: %s: %v", src, err)
+		eventCh <- CompressCompletedEvent{Err: err}
+		return
+	}
+
+	result := CompressCompletedEvent{Archive: dest, Size: c.addArchiveNode(dest)}
+	logging.Debug.Printf("[Controller] Compressed %s (%d bytes) to %s (%d bytes)", src, size, dest, result.Size)
+	if deleteAfter {
+		if len(skipped) > 0 {
+			result.Err = fmt.Errorf("archive written, but %d items such as %s cannot go in an archive, so %s was kept", len(skipped), skipped[0], src)
+		} else if err := fileops.VerifyArchive(dest, read); err != nil {
+			result.Err = fmt.Errorf("archive written, but it does not read back, so %s was kept: %w", src, err)
+		} else if err := c.removeFolder(node); err != nil {
+			result.Err = fmt.Errorf("archive written, but deleting %s failed: %w", src, err)
+		} else {
+			result.Deleted = true
+			result.Freed = max(0, size-result.Size)
+		}
+	}
+
+	c.mu.Lock()
+	if result.Freed > 0 {
+		c.recordFreedLocked(result.Freed)
+		c.noteDeletionLocked(src, result.Freed)
+		if c.statsManager != nil {
+			c.statsManager.LogDeletion(src, result.Freed)
+		}
+	}
+	delete(c.marked, node)
+	result.DiskFree = c.getDiskFree()
+	c.mu.Unlock()
+
+	eventCh <- result
+}
+
+// removeFolder deletes the folder node from disk and marks it deleted once
+// it is gone. The caller counts the space freed, so the watcher's reports of
+// its contents going meanwhile are ignored. A delete that fails partway has
+// the folder rescanned to show what is left.
+func (c *Controller) removeFolder(node *model.Node) error {
+	path := node.Path()
+	c.mu.Lock()
+	if c.removing == nil {
+		c.removing = make(map[string]bool)
+	}
+	c.removing[path] = true
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.removing, path)
+		c.mu.Unlock()
+	}()
+
+	if err := fileops.Delete(path); err != nil {
+		if _, rerr := c.RescanSubtree(node); rerr != nil {
+			logging.Debug.Printf("[Controller] Rescan after failed delete: %s: %v", path, rerr)
+		}
+		return err
+	}
+	node.MarkDeleted()
+	return nil
+}
+
+// beingRemoved reports whether path is inside a folder removeFolder is
+// deleting
+func (c *Controller) beingRemoved(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for dir := range c.removing {
+		if isWithin(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// addArchiveNode puts a newly written archive in the tree, or updates its
+// size if the watcher saw it first, and returns its size on disk
+func (c *Controller) addArchiveNode(path string) int64 {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0
+	}
+	disk, logical := scanner.FileSize(path, info, c.ScanPolicy())
+
+	c.mu.RLock()
+	root := c.root
+	c.mu.RUnlock()
+	if root == nil {
+		return disk
+	}
+	if node := c.findNodeByPath(root, path); node != nil {
+		node.Resize(disk, logical)
+		return disk
+	}
+	parent := c.findNodeByPath(root, filepath.Dir(path))
+	if parent == nil || !parent.IsDir || parent.IsDeleted {
+		return disk // Written outside the scanned tree
+	}
+
+	node := &model.Node{
+		Name:    info.Name(),
+		Size:    disk,
+		Logical: logical,
+		ModTime: info.ModTime().Unix(),
+		IsNew:   true,
+	}
+	node.Category = model.CategoryOf(node.Name)
+	node.UID, node.GID = model.OwnerOf(info)
+	parent.AddChild(node)
+	c.pathIndex().Add(node)
+	return disk
+}
+
+// logicalSize returns the bytes of the files below node, what compressing
+// it reads
+func logicalSize(node *model.Node) int64 {
+	if !node.IsDir {
+		return node.Logical
+	}
+	var total int64
+	for _, child := range node.Children {
+		if !child.IsDeleted {
+			total += logicalSize(child)
+		}
+	}
+	return total
+}
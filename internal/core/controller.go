@@ -2,11 +2,16 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/config"
 	"github.com/lumipallolabs/diskdive/internal/logging"
 	"github.com/lumipallolabs/diskdive/internal/model"
 	"github.com/lumipallolabs/diskdive/internal/scanner"
@@ -24,14 +29,36 @@ type Controller struct {
 	selectedDrive int
 	customPath    string
 	root          *model.Node
+	index         *model.PathIndex // Directories of root by path, for watcher lookups
 	tree          *TreeState
 	scan          ScanState
 	freed         FreedState
+	deletions     deletionHeap // Largest of this session, for the summary
+	marked        map[*model.Node]bool
+	removing      map[string]bool // Folders diskdive itself is deleting
+	extensions    model.ExtHistogram
+	skipped       scanner.SkipStats
+	slowPaths     []scanner.SlowPath // Directories the last scan spent longest on
+	areas         []model.SystemArea // Hidden system areas, measured once per drive scan
+	growth        float64            // Bytes a day the scanned tree grew over recent scans
+	growthKnown   bool
+	history       *cache.History // Size history from before the scan, for the summary
+
+	// Settings
+	config  config.Config
+	noise   model.Noise // OS metadata files left out of change highlighting
+	clock   clock.Clock
+	version string // Recorded in snapshots
+	label   string // Label of the snapshot the next scan saves
 
 	// Internal services
 	scanner      scanner.Scanner
-	watcher      *watcher.Watcher
+	policy       scanner.ScanPolicy
+	watcher      watcher.Backend
+	scoped       watcher.Scoped // The watcher, when it follows only some folders
+	watchStats   *WatchStats    // Activity of the current watcher
 	statsManager *stats.Manager
+	cache        *cache.Cache
 
 	// Event handling
 	eventCh   chan Event
@@ -45,24 +72,60 @@ type Controller struct {
 func NewController(customPath string) *Controller {
 	drives, _ := model.GetDrives()
 
+	// Load settings
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		logging.Debug.Printf("Failed to load config: %v", err)
+	}
+
 	// Load stats
 	statsMgr := stats.NewManager()
+	statsMgr.SetSaveDelay(cfg.Debounce.StatsSave.Std())
 	if err := statsMgr.Load(); err != nil {
 		logging.Debug.Printf("Failed to load stats: %v", err)
 	}
 
+	policy := scanner.ScanPolicy{
+		CrossMounts:     cfg.Scan.CrossMounts,
+		FollowSymlinks:  cfg.Scan.FollowSymlinks,
+		FollowJunctions: cfg.Scan.FollowJunctions,
+		CountHardLinks:  cfg.Scan.CountHardLinks,
+		NodeBudget:      cfg.Scan.NodeBudget,
+	}
+
 	c := &Controller{
 		drives:       drives,
 		customPath:   customPath,
+		config:       cfg,
+		noise:        model.NewNoise(cfg.Noise.Names),
+		clock:        clock.Real(),
 		tree:         NewTreeState(),
-		scanner:      scanner.NewWalker(8),
+		marked:       make(map[*model.Node]bool),
+		scanner:      scanner.NewWalker(8, policy),
+		policy:       policy,
 		statsManager: statsMgr,
+		cache:        cache.New(cache.DefaultDir()),
 		eventCh:      make(chan Event, 100),
 		freed: FreedState{
-			Lifetime: statsMgr.FreedLifetime(),
+			Lifetime:        statsMgr.FreedLifetime(),
+			DeletedLifetime: statsMgr.DeletedLifetime(),
 		},
 	}
 
+	c.cache.SetRetention(cache.Retention{
+		Keep:     cfg.Snapshots.Keep,
+		MaxAge:   cfg.Snapshots.MaxAge.Std(),
+		MaxBytes: int64(cfg.Snapshots.MaxTotal),
+	})
+	if err := c.cache.Encrypt(cfg.Snapshots.Encrypt); err != nil {
+		logging.Debug.Printf("Snapshots will not be saved: %v", err)
+	}
+
+	// Drop snapshots a previous run left half-written
+	for _, f := range c.cache.Recover() {
+		logging.Debug.Printf("Discarded incomplete snapshot %s", f)
+	}
+
 	// Find saved default drive
 	if customPath == "" {
 		defaultDrive := statsMgr.DefaultDrive()
@@ -87,7 +150,7 @@ func (c *Controller) State() AppState {
 		SelectedDrive: c.selectedDrive,
 		CustomPath:    c.customPath,
 		Scan:          c.scan,
-		Freed:         c.freed,
+		Freed:         c.freedLocked(),
 		Tree:          c.tree,
 	}
 }
@@ -156,14 +219,142 @@ func (c *Controller) ScanState() ScanState {
 	return c.scan
 }
 
+// Extensions returns the per-extension histogram of the last completed scan
+func (c *Controller) Extensions() model.ExtHistogram {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.extensions
+}
+
+// SlowPaths returns the directories the last completed scan took longest to read
+func (c *Controller) SlowPaths() []scanner.SlowPath {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.slowPaths
+}
+
+// Skipped returns what the last completed scan could not or would not read
+func (c *Controller) Skipped() scanner.SkipStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.skipped
+}
+
+// Noise returns the OS metadata files left out of change highlighting
+func (c *Controller) Noise() model.Noise {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.noise
+}
+
 // FreedState returns the current freed space state
 func (c *Controller) FreedState() FreedState {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.freed
+	return c.freedLocked()
+}
+
+// freedLocked returns the freed counters with the drive's filled in from
+// the stats (caller must hold lock)
+func (c *Controller) freedLocked() FreedState {
+	freed := c.freed
+	if c.statsManager != nil {
+		freed.Drive = c.statsManager.Drive(c.statsDriveLocked()).Freed
+	}
+	return freed
+}
+
+// ResetFreed forgets all space freed, this session's and all time's
+func (c *Controller) ResetFreed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.freed = FreedState{}
+	c.deletions = nil
+	if c.statsManager != nil {
+		c.statsManager.ResetFreed()
+	}
+}
+
+// CorrectFreed takes bytes the watcher counted as freed by mistake, such as
+// temporary files written and removed again, off the freed totals
+func (c *Controller) CorrectFreed(bytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.freed.Session = max(c.freed.Session-bytes, 0)
+	c.freed.Lifetime = max(c.freed.Lifetime-bytes, 0)
+	if c.statsManager != nil {
+		c.statsManager.CorrectFreed(c.statsDriveLocked(), bytes)
+	}
+}
+
+// RecentDeletions returns the latest sizable deletions seen, oldest first
+func (c *Controller) RecentDeletions() []stats.Deletion {
+	if c.statsManager == nil {
+		return nil
+	}
+	return c.statsManager.Deletions()
+}
+
+// FreedHistory returns when space was freed over the last year, oldest first
+func (c *Controller) FreedHistory() []stats.FreedEvent {
+	if c.statsManager == nil {
+		return nil
+	}
+	return c.statsManager.FreedHistory()
+}
+
+// Config returns the user settings
+func (c *Controller) Config() config.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
+}
+
+// SetVersion sets the application version recorded in scan snapshots
+func (c *Controller) SetVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = version
+}
+
+// SetSnapshotLabel sets the label of the snapshot the next scan saves
+func (c *Controller) SetSnapshotLabel(label string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.label = label
+}
+
+// SetClock replaces the time source used for timestamps and debouncing (for tests)
+func (c *Controller) SetClock(clk clock.Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clk
+	c.statsManager.SetClock(clk)
+	c.cache.SetClock(clk)
+}
+
+// ScanPolicy returns the link and mount policy used for scans
+func (c *Controller) ScanPolicy() scanner.ScanPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.policy
+}
+
+// SetScanPolicy sets the link and mount policy used by subsequent scans
+func (c *Controller) SetScanPolicy(policy scanner.ScanPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+}
+
+// SetMinFreed overrides freed.minSize, the smallest deletion that counts as
+// space freed
+func (c *Controller) SetMinFreed(size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.Freed.MinSize = config.Size(size)
 }
 
-// IsShowingDiff returns whether diff mode is enabled
 // SelectDrive selects a drive by index and prepares for scanning
 func (c *Controller) SelectDrive(idx int) error {
 	c.mu.Lock()
@@ -174,9 +365,14 @@ func (c *Controller) SelectDrive(idx int) error {
 	}
 
 	c.selectedDrive = idx
+	c.customPath = "" // Picking a drive leaves a folder opened on its own
 	c.freed.Session = 0
+	c.freed.Deleted = 0
+	c.deletions = nil
 	c.root = nil
+	c.index = nil
 	c.tree = NewTreeState()
+	c.marked = make(map[*model.Node]bool)
 
 	// Save as default
 	c.statsManager.SetDefaultDrive(c.drives[idx].Path)
@@ -193,26 +389,13 @@ func (c *Controller) SelectDrive(idx int) error {
 func (c *Controller) StartScan(ctx context.Context) (<-chan Event, error) {
 	c.mu.Lock()
 
-	var scanPath string
-	if c.customPath != "" {
-		scanPath = c.customPath
-	} else if c.selectedDrive >= 0 && c.selectedDrive < len(c.drives) {
-		scanPath = c.drives[c.selectedDrive].Path
-	}
-
+	scanPath := c.scanPathLocked()
 	if scanPath == "" {
 		c.mu.Unlock()
 		return nil, nil
 	}
 
-	// Reset state for new scan
-	c.scanner = scanner.NewWalker(8)
-	c.scan = ScanState{
-		Phase: PhaseScanning,
-	}
-	c.root = nil
-	c.tree = NewTreeState()
-
+	c.resetScanLocked()
 	c.mu.Unlock()
 
 	// Create event channel for this scan
@@ -223,14 +406,67 @@ func (c *Controller) StartScan(ctx context.Context) (<-chan Event, error) {
 	return eventCh, nil
 }
 
+// resetScanLocked clears the previous tree for a new scan (caller must hold lock)
+func (c *Controller) resetScanLocked() {
+	c.scanner = scanner.NewWalker(8, c.policy)
+	c.scan = ScanState{
+		Phase: PhaseScanning,
+	}
+	c.root = nil
+	c.index = nil
+	c.tree = NewTreeState()
+	c.marked = make(map[*model.Node]bool)
+	c.extensions = nil
+	c.skipped = scanner.SkipStats{}
+	c.slowPaths = nil
+	c.areas = nil
+	c.growth = 0
+	c.growthKnown = false
+	c.history = nil
+}
+
 // runScan executes the scan in a goroutine
 func (c *Controller) runScan(ctx context.Context, path string, eventCh chan Event) {
 	defer close(eventCh)
 
+	// The previous snapshot of this path tells how much work is ahead
+	prev, err := c.cache.LoadHeader(path)
+	if err != nil {
+		logging.Debug.Printf("[Controller] No previous snapshot: %v", err)
+	}
+
+	// Earlier sizes of the tree tell how fast it fills up. Before the size
+	// history was kept, only the previous snapshot has one.
+	history, err := c.cache.History(path)
+	if err != nil {
+		logging.Debug.Printf("[Controller] No size history: %v", err)
+	}
+	samples := history.Samples(path)
+	if len(samples) == 0 && !prev.ScannedAt.IsZero() {
+		samples = []cache.Sample{{At: prev.ScannedAt, Bytes: prev.Bytes}}
+	}
+
+	c.mu.Lock()
+	c.history = history
+	c.mu.Unlock()
+
+	// Another instance already scanning this path does the work for both
+	claim, other, ok := c.claimScan(path)
+	for !ok {
+		if c.followScan(ctx, path, other, samples, eventCh) {
+			return
+		}
+		claim, other, ok = c.claimScan(path)
+	}
+	defer c.holdScan(path, claim)()
+
 	logging.Debug.Printf("[Controller] Starting scan of %s", path)
 
 	c.mu.Lock()
-	c.scan.StartTime = time.Now()
+	c.scan.Path = path
+	c.scan.StartTime = c.clock.Now()
+	c.scan.ExpectedFiles = prev.Files
+	c.scan.ExpectedBytes = prev.Bytes
 	c.mu.Unlock()
 
 	eventCh <- ScanStartedEvent{Path: path}
@@ -241,11 +477,21 @@ func (c *Controller) runScan(ctx context.Context, path string, eventCh chan Even
 			c.mu.Lock()
 			c.scan.FilesScanned = progress.FilesScanned
 			c.scan.BytesFound = progress.BytesFound
+			c.scan.FilesPerSec = progress.FilesPerSec
+			c.scan.BytesPerSec = progress.BytesPerSec
+			c.scan.CurrentPath = progress.CurrentPath
+			c.scan.Busy = progress.Busy
+			c.scan.Workers = progress.Workers
+			c.scan.Stalled = progress.Stalled
+			c.scan.Interference = progress.Interference
 			c.mu.Unlock()
 
 			eventCh <- ScanProgressEvent{
 				FilesScanned: progress.FilesScanned,
 				BytesFound:   progress.BytesFound,
+				FilesPerSec:  progress.FilesPerSec,
+				BytesPerSec:  progress.BytesPerSec,
+				CurrentPath:  progress.CurrentPath,
 			}
 		}
 	}()
@@ -273,18 +519,97 @@ func (c *Controller) runScan(ctx context.Context, path string, eventCh chan Even
 	logging.Debug.Printf("[Controller] Computing sizes...")
 	root.ComputeSizes()
 
-	// Complete
+	// Snapshot before estimates are attached, then write it in the background.
+	// The write reads the tree rather than a copy of it, so the tree is left
+	// alone until the snapshot releases it.
+	c.mu.Lock()
+	took := c.clock.Now().Sub(c.scan.StartTime)
+	env := cache.CaptureEnvironment(c.version, c.policy.Options())
+	label := c.label
+	c.label = ""
+	c.mu.Unlock()
+	snap := cache.NewSnapshot(root, took)
+	snap.Header.Env = env
+	snap.Header.Label = label
+	snap.History = history
+	saved := make(chan SnapshotSavedEvent, 1)
+	go func() {
+		seen := len(c.cache.Pruned())
+		err := c.cache.Write(path, snap)
+		if err != nil {
+			logging.Debug.Printf("[Controller] Failed to save snapshot: %v", err)
+		}
+		for _, p := range c.cache.Pruned()[seen:] {
+			logging.Debug.Printf("[Controller] Pruned snapshot %s: %s", p.File, p.Reason)
+		}
+		saved <- SnapshotSavedEvent{File: c.cache.File(path, snap.Header.ScannedAt), Err: err}
+	}()
+
+	c.finishScan(path, root, took, samples, scanFindings{
+		extensions: c.scanner.Extensions(),
+		skipped:    c.scanner.Skipped(),
+		slowPaths:  c.scanner.SlowPaths(),
+	}, snap.Released(), eventCh)
+
+	logging.Debug.Printf("[Controller] Scan complete")
+	eventCh <- <-saved
+}
+
+// scanFindings is what a walk learns besides the tree
+type scanFindings struct {
+	extensions model.ExtHistogram
+	skipped    scanner.SkipStats
+	slowPaths  []scanner.SlowPath
+}
+
+// finishScan makes root, a finished scan of path by this instance or
+// another, the current tree. It notes the growth since samples, accounts for
+// the space the walk cannot see when a whole drive was scanned, indexes the
+// tree and records the scan in the stats. released is closed once root may
+// change, after a snapshot of it has been written.
+func (c *Controller) finishScan(path string, root *model.Node, took time.Duration, samples []cache.Sample, found scanFindings, released <-chan struct{}, eventCh chan Event) {
+	c.mu.Lock()
+	if growth, ok := growthRate(samples, c.clock.Now(), root.TotalSize()); ok {
+		c.growth = growth
+		c.growthKnown = true
+	}
+	driveRoot := c.isDriveRootLocked(path)
+	c.mu.Unlock()
+
+	// Measuring system areas can be slow, so it happens once here, while
+	// the snapshot is written
+	var areas []model.SystemArea
+	if driveRoot {
+		areas = model.GetSystemAreas(path)
+	}
+	<-released
+	if driveRoot {
+		attachSystemNode(root, c.buildIntegrity(root, path, found.skipped, areas, true))
+	}
+
+	// Index directories so watcher events find their nodes without a walk
+	index := model.NewPathIndex(root)
+
 	c.mu.Lock()
 	c.scan.Phase = PhaseComplete
+	c.scan.Took = took
 	c.root = root
+	c.index = index
 	c.tree.Root = root
-	c.tree.Expanded[root.Path] = true
+	c.tree.Expanded[root.Path()] = true
+	c.extensions = found.extensions
+	c.skipped = found.skipped
+	c.slowPaths = found.slowPaths
+	c.areas = areas
+	drive := c.statsDriveLocked()
 	c.mu.Unlock()
 
-	eventCh <- ScanPhaseChangedEvent{Phase: PhaseComplete}
-	eventCh <- ScanCompletedEvent{Root: root}
+	// Usage of the whole drive, not just the scanned tree, for the trend
+	total, free := model.GetDiskSpace(drive)
+	c.statsManager.RecordScan(drive, total-free, took)
 
-	logging.Debug.Printf("[Controller] Scan complete")
+	eventCh <- ScanPhaseChangedEvent{Phase: PhaseComplete}
+	eventCh <- ScanCompletedEvent{Root: root, Extensions: found.extensions}
 }
 
 // FinalizeScan marks the scan as fully complete (after UI delay)
@@ -313,82 +638,415 @@ func (c *Controller) StartWatching() (<-chan Event, error) {
 	// Stop existing watcher
 	if c.watcher != nil {
 		_ = c.watcher.Stop()
+		c.watcher, c.scoped = nil, nil
 	}
-
-	// Create new watcher
-	w, err := watcher.New()
-	if err != nil {
-		c.mu.Unlock()
-		return nil, err
-	}
-
-	c.watcher = w
 	root := c.root
+	network := c.onNetworkDrive(watchPath)
+	queue := c.config.Watch.Queue
+	openOnly := c.config.Watch.Scope == config.WatchOpen
+	backend := c.config.Watch.Backend
 	c.mu.Unlock()
 
-	if err := w.AddRecursive(watchPath); err != nil {
-		logging.Debug.Printf("Failed to add recursive watch: %v", err)
+	// Network filesystems report no changes made by other machines, so
+	// they are polled; so is any path watching fails on
+	var w, scoped watcher.Scoped
+	mode := "polling"
+	reason := "network drive"
+	switch {
+	case network:
+	case backend == config.BackendPoll:
+		reason = "watch.backend is \"poll\""
+	default:
+		w, mode, reason = eventWatcher(watchPath, queue, openOnly, backend == config.BackendFsnotify)
+	}
+	polling := w == nil
+	if polling {
+		poller := watcher.NewPoller(pollInterval, queue)
+		if err := poller.AddRecursive(watchPath); err != nil {
+			c.mu.Lock()
+			c.watchStats = &WatchStats{Path: watchPath, Reason: err.Error()}
+			c.mu.Unlock()
+			return nil, err
+		}
+		w, mode = poller, "polling"
+		logging.Debug.Printf("Polling %s every %s: %s", watchPath, pollInterval, reason)
+	}
+	if polling || openOnly {
+		scoped = w
 	}
 	w.Start()
 	logging.Debug.Printf("Filesystem watcher started for %s", watchPath)
 
+	stats := &WatchStats{Path: watchPath, Mode: mode, Scoped: scoped != nil, Started: c.clock.Now(), Running: true}
+	if polling {
+		stats.Reason = reason
+	}
+
+	c.mu.Lock()
+	c.watcher, c.scoped = w, scoped
+	c.watchStats = stats
+	c.mu.Unlock()
+
 	// Create event channel
-	eventCh := make(chan Event, 100)
+	eventCh := make(chan Event, queue)
+	if polling {
+		eventCh <- WatchPollingEvent{Interval: pollInterval, Reason: reason}
+	}
+
+	go c.watchLoop(w.Events(), root, eventCh, stats)
+
+	return eventCh, nil
+}
+
+// pollInterval is how often a polling watcher lists the folders it follows
+const pollInterval = 5 * time.Second
+
+// rootWatcher is a watcher that can watch a root alone, following only the
+// folders it is told to
+type rootWatcher interface {
+	watcher.Scoped
+	AddScoped(root string) error
+}
+
+// eventWatcher returns a watcher the system tells of changes below path and
+// its mode: the platform's own, or fsnotify when portable or there is none.
+// Without one it returns nil and why. A scoped one watches only path until
+// told which folders to follow.
+func eventWatcher(path string, queue int, scoped, portable bool) (watcher.Scoped, string, string) {
+	var w rootWatcher
+	mode := "fsnotify"
+	if !portable {
+		native, err := watcher.New(queue)
+		switch {
+		case err == nil:
+			w, mode = native, "native"
+		case !errors.Is(err, watcher.ErrUnsupported):
+			return nil, "", err.Error()
+		}
+	}
+	if w == nil {
+		notify, err := watcher.NewNotify(queue)
+		if err != nil {
+			return nil, "", err.Error()
+		}
+		w = notify
+	}
+	add := w.AddRecursive
+	if scoped {
+		add = w.AddScoped
+	}
+	if err := add(path); err != nil {
+		_ = w.Stop()
+		return nil, "", err.Error()
+	}
+	return w, mode, ""
+}
+
+// onNetworkDrive reports whether path is on a network share, going by the
+// innermost drive that holds it
+func (c *Controller) onNetworkDrive(path string) bool {
+	drive := c.driveOfLocked(path)
+	return drive != nil && drive.Kind == model.DriveNetwork
+}
+
+// driveOfLocked returns the innermost drive holding path, or nil (caller
+// must hold lock)
+func (c *Controller) driveOfLocked(path string) *model.Drive {
+	var drive *model.Drive
+	for i := range c.drives {
+		d := &c.drives[i]
+		if isWithin(path, d.Path) && (drive == nil || len(d.Path) > len(drive.Path)) {
+			drive = d
+		}
+	}
+	return drive
+}
+
+// statsDriveLocked returns the path per-drive statistics of the scanned
+// path are kept under: its drive's, or its own outside any known drive
+// (caller must hold lock)
+func (c *Controller) statsDriveLocked() string {
+	path := c.scanPathLocked()
+	if drive := c.driveOfLocked(path); drive != nil {
+		return drive.Path
+	}
+	return path
+}
+
+// DriveStats returns the statistics of the drive being viewed
+func (c *Controller) DriveStats() stats.DriveStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.statsManager == nil {
+		return stats.DriveStats{}
+	}
+	return c.statsManager.Drive(c.statsDriveLocked())
+}
+
+// AllDriveStats returns the statistics of every drive seen, by the drive's
+// path
+func (c *Controller) AllDriveStats() map[string]stats.DriveStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.statsManager == nil {
+		return nil
+	}
+	return c.statsManager.Drives()
+}
 
-	go c.watchLoop(w, root, eventCh)
+// Scoped reports whether the watcher follows only the folders passed to
+// FollowFolders, because it polls or watch.scope is "open"
+func (c *Controller) Scoped() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.scoped != nil
+}
+
+// FollowFolders sets the folders a scoped watcher follows besides the scan
+// root, usually those open in the tree. Without one it does nothing.
+func (c *Controller) FollowFolders(dirs []string) {
+	c.mu.RLock()
+	scoped := c.scoped
+	c.mu.RUnlock()
+	if scoped != nil {
+		scoped.Follow(dirs)
+	}
+}
+
+// SimulateWatching applies the filesystem changes sent on changes to the
+// current tree as if the watcher had reported them, until changes is closed,
+// and reports through the same events as StartWatching. The self test uses
+// it to check that the tree follows changes on any platform, with or
+// without a watcher.
+func (c *Controller) SimulateWatching(changes <-chan watcher.Event) (<-chan Event, error) {
+	c.mu.RLock()
+	root := c.root
+	c.mu.RUnlock()
+	if root == nil {
+		return nil, fmt.Errorf("nothing scanned to watch")
+	}
 
+	c.mu.Lock()
+	eventCh := make(chan Event, c.config.Watch.Queue)
+	stats := &WatchStats{Path: root.Path(), Mode: "simulated", Started: c.clock.Now(), Running: true}
+	c.watchStats = stats
+	c.mu.Unlock()
+	go c.watchLoop(changes, root, eventCh, stats)
 	return eventCh, nil
 }
 
-// watchLoop processes filesystem events
-func (c *Controller) watchLoop(w *watcher.Watcher, root *model.Node, eventCh chan Event) {
+// watchLoop processes filesystem events, keeping count of them in stats
+func (c *Controller) watchLoop(changes <-chan watcher.Event, root *model.Node, eventCh chan Event, stats *WatchStats) {
 	defer close(eventCh)
+	defer c.noteWatch(stats, func(s *WatchStats) { s.Running = false })
 
-	// Track directories needing rescan (debounced)
+	// Track directories needing rescan (debounced), and directories whose
+	// events were lost and need rescanning whole
 	pendingDirs := make(map[string]bool)
-	var debounceTimer *time.Timer
-	const debounceDelay = 1500 * time.Millisecond
+	pendingStale := make(map[string]bool)
+	var pendingMu sync.Mutex
 
 	flushPending := func() {
-		if len(pendingDirs) == 0 {
+		pendingMu.Lock()
+		if len(pendingDirs) == 0 && len(pendingStale) == 0 {
+			pendingMu.Unlock()
 			return
 		}
 
 		// Find topmost directories (remove children if parent is in set)
+		stale := c.findTopmostDirs(pendingStale)
+		for dir := range pendingDirs {
+			for _, s := range stale {
+				if isWithin(dir, s) {
+					delete(pendingDirs, dir) // Covered by rescanning s whole
+					break
+				}
+			}
+		}
 		toScan := c.findTopmostDirs(pendingDirs)
 		pendingDirs = make(map[string]bool)
+		pendingStale = make(map[string]bool)
+		pendingMu.Unlock()
+		c.noteWatch(stats, func(s *WatchStats) { s.Rescans += len(stale) + len(toScan) })
+
+		for _, dir := range stale {
+			c.resyncDirectory(dir, root, eventCh)
+		}
 
-		// Scan each directory
+		// Scan each directory; past the batch size, report them all at once
+		// so a build touching many folders does not flood the interface
+		c.mu.RLock()
+		batch := len(toScan) > c.config.Watch.Batch
+		c.mu.RUnlock()
+		var added []*model.Node
 		for _, dir := range toScan {
-			c.rescanDirectory(dir, root, eventCh)
+			nodes, ok := c.rescanDirectory(dir, root)
+			if !ok {
+				continue
+			}
+			if batch {
+				added = append(added, nodes...)
+			} else {
+				c.reportCreated(dir, nodes, eventCh)
+			}
+		}
+		if batch {
+			c.reportCreated(root.Path(), added, eventCh)
 		}
 	}
 
-	for event := range w.Events() {
+	// Track files written to, whose sizes are updated at most once a delay
+	pendingFiles := make(map[string]bool)
+	flushSizes := func() {
+		pendingMu.Lock()
+		paths := pendingFiles
+		pendingFiles = make(map[string]bool)
+		pendingMu.Unlock()
+		c.updateSizes(paths, root, eventCh)
+	}
+
+	c.mu.RLock()
+	debouncer := clock.NewDebouncer(c.clock, c.config.Debounce.Rescan.Std(), flushPending)
+	debouncer.SetMaxWait(c.config.Watch.MaxWait.Std())
+	sizeDebouncer := clock.NewDebouncer(c.clock, c.config.Debounce.Size.Std(), flushSizes)
+	sizeDebouncer.SetMaxWait(c.config.Debounce.Size.Std())
+	c.mu.RUnlock()
+
+	created := func(path string) {
+		// Add parent directory to pending set
+		parentDir := filepath.Dir(path)
+		if c.findNodeByPath(root, parentDir) != nil {
+			pendingMu.Lock()
+			pendingDirs[parentDir] = true
+			pendingMu.Unlock()
+		}
+
+		// Reset debounce timer
+		debouncer.Trigger()
+	}
+
+	for event := range changes {
+		now := c.clock.Now()
+		c.noteWatch(stats, func(s *WatchStats) {
+			s.Events++
+			s.LastEvent = now
+			if event.Type == watcher.EventOverflow {
+				s.Dropped++
+			}
+		})
+
 		switch event.Type {
 		case watcher.EventDeleted:
 			c.handleDeletion(event.Path, root, eventCh)
 
 		case watcher.EventCreated:
-			// Add parent directory to pending set
-			parentDir := filepath.Dir(event.Path)
-			if c.findNodeByPath(root, parentDir) != nil {
-				pendingDirs[parentDir] = true
+			created(event.Path)
+
+		case watcher.EventRenamed:
+			if !c.handleRename(event.OldPath, event.Path, root, eventCh) {
+				c.handleDeletion(event.OldPath, root, eventCh)
+				created(event.Path)
 			}
 
-			// Reset debounce timer
-			if debounceTimer != nil {
-				debounceTimer.Stop()
+		case watcher.EventModified:
+			pendingMu.Lock()
+			pendingFiles[event.Path] = true
+			pendingMu.Unlock()
+			sizeDebouncer.Trigger()
+
+		case watcher.EventOverflow:
+			logging.Debug.Printf("Watcher: events lost below %s", event.Path)
+			node := c.staleDirectory(event.Path, root)
+			if node == nil {
+				// Nothing smaller than the whole tree to rescan
+				eventCh <- WatchOverflowEvent{Path: event.Path}
+				continue
 			}
-			debounceTimer = time.AfterFunc(debounceDelay, flushPending)
+			node.IsStale = true
+			pendingMu.Lock()
+			pendingStale[node.Path()] = true
+			pendingMu.Unlock()
+			debouncer.Trigger()
+			eventCh <- WatchOverflowEvent{Path: node.Path(), Rescan: true}
 		}
 	}
 
 	// Flush any remaining on shutdown
-	if debounceTimer != nil {
-		debounceTimer.Stop()
-	}
+	debouncer.Stop()
+	sizeDebouncer.Stop()
 	flushPending()
+	flushSizes()
+}
+
+// updateSizes stats the files at paths and updates the sizes of those
+// whose size changed, for files written to after the scan
+func (c *Controller) updateSizes(paths map[string]bool, root *model.Node, eventCh chan Event) {
+	policy := c.ScanPolicy()
+	var changed []*model.Node
+	var grew int64
+	for path := range paths {
+		node := c.findNodeByPath(root, path)
+		if node == nil || node.IsDir || node.IsDeleted {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || info.IsDir() {
+			continue // Gone; its deletion event follows
+		}
+		disk, logical := scanner.FileSize(path, info, policy)
+		if disk == node.Size && logical == node.Logical {
+			continue
+		}
+		grew += disk - node.Size
+		node.Resize(disk, logical)
+		node.ModTime = info.ModTime().Unix()
+		changed = append(changed, node)
+		logging.Debug.Printf("Watcher: RESIZED: %s (size: %d)", path, disk)
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	diskFree := c.getDiskFree()
+	c.mu.Unlock()
+
+	eventCh <- SizeChangedEvent{
+		Nodes:    changed,
+		Grew:     grew,
+		DiskFree: diskFree,
+	}
+}
+
+// handleRename moves the node at oldPath to path in place, so it keeps its
+// flags and marks instead of turning into a deleted node and a new one.
+// Returns false if the move cannot be applied to the tree that way: the
+// item or its new folder is not in the tree, or something is already there.
+func (c *Controller) handleRename(oldPath, path string, root *model.Node, eventCh chan Event) bool {
+	node := c.findNodeByPath(root, oldPath)
+	if node == nil || node.IsDeleted || node == root {
+		return false
+	}
+	parent := c.findNodeByPath(root, filepath.Dir(path))
+	if parent == nil || !parent.IsDir || parent.IsDeleted {
+		return false
+	}
+	if existing := c.findNodeByPath(root, path); existing != nil && !existing.IsDeleted {
+		return false // Replaced an item; let its deletion be counted
+	}
+
+	index := c.pathIndex()
+	index.Remove(node)
+	node.Move(parent, filepath.Base(path))
+	index.Add(node)
+	logging.Debug.Printf("Watcher: MOVED: %s -> %s", oldPath, path)
+
+	eventCh <- MoveDetectedEvent{
+		OldPath: oldPath,
+		Path:    path,
+		Node:    node,
+	}
+	return true
 }
 
 // handleDeletion processes a deletion event
@@ -399,29 +1057,41 @@ func (c *Controller) handleDeletion(path string, root *model.Node, eventCh chan
 		return
 	}
 
-	if node.IsDeleted {
+	if node.IsDeleted || deletedBelow(node) || c.beingRemoved(path) {
 		return
 	}
 
 	size := node.TotalSize()
-	node.MarkDeleted()
-	logging.Debug.Printf("Watcher: MARKED DELETED: %s (size: %d, isDir: %v)", path, size, node.IsDir)
+	noise := c.noise.Is(node)
+	if noise && node.Parent != nil {
+		// Metadata files come and go as folders are browsed; drop them quietly
+		node.Parent.RemoveChild(node)
+		logging.Debug.Printf("Watcher: REMOVED NOISE: %s (size: %d)", path, size)
+	} else {
+		node.MarkDeleted()
+		logging.Debug.Printf("Watcher: MARKED DELETED: %s (size: %d, isDir: %v)", path, size, node.IsDir)
+	}
 
 	c.mu.Lock()
-	c.freed.Session += size
-	c.freed.Lifetime += size
-	if c.statsManager != nil {
-		c.statsManager.AddFreed(size)
+	c.recordFreedLocked(size)
+	if !noise {
+		c.noteDeletionLocked(path, size)
+		if c.statsManager != nil {
+			c.statsManager.LogDeletion(path, size)
+		}
 	}
-	freed := c.freed
+	freed := c.freedLocked()
 	diskFree := c.getDiskFree()
 	c.mu.Unlock()
 
 	eventCh <- DeletionDetectedEvent{
 		Path:         path,
+		Node:         node,
 		Size:         size,
+		Noise:        noise,
 		SessionFreed: freed.Session,
 		TotalFreed:   freed.Lifetime,
+		DriveFreed:   freed.Drive,
 		DiskFree:     diskFree,
 	}
 
@@ -429,6 +1099,17 @@ func (c *Controller) handleDeletion(path string, root *model.Node, eventCh chan
 		size, freed.Session, freed.Lifetime)
 }
 
+// deletedBelow reports whether node is inside a folder already marked
+// deleted, whose size counted it
+func deletedBelow(node *model.Node) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if p.IsDeleted {
+			return true
+		}
+	}
+	return false
+}
+
 // findTopmostDirs returns directories that don't have a parent in the set
 func (c *Controller) findTopmostDirs(dirs map[string]bool) []string {
 	var result []string
@@ -453,28 +1134,71 @@ func (c *Controller) findTopmostDirs(dirs map[string]bool) []string {
 	return result
 }
 
-// rescanDirectory rescans a directory and updates the tree
-func (c *Controller) rescanDirectory(dirPath string, root *model.Node, eventCh chan Event) {
+// staleDirectory returns the directory in the tree to rescan after events
+// below path were lost: path itself or its nearest ancestor in the tree.
+// Returns nil if that is the root, which takes a full rescan.
+func (c *Controller) staleDirectory(path string, root *model.Node) *model.Node {
+	if !isWithin(path, root.Path()) {
+		return nil
+	}
+	for {
+		if node := c.findNodeByPath(root, path); node != nil && node.IsDir && !node.IsDeleted {
+			if node.Parent == nil {
+				return nil
+			}
+			return node
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return nil
+		}
+		path = parent
+	}
+}
+
+// resyncDirectory rescans a directory whose events were lost and splices
+// the result into the tree
+func (c *Controller) resyncDirectory(dirPath string, root *model.Node, eventCh chan Event) {
+	node := c.findNodeByPath(root, dirPath)
+	if node == nil || !node.IsDir || node.IsDeleted {
+		return
+	}
+	fresh, err := c.RescanSubtree(node)
+	if err != nil {
+		logging.Debug.Printf("Watcher: cannot rescan stale dir: %s: %v", dirPath, err)
+		node.IsStale = false
+		eventCh <- WatchOverflowEvent{Path: dirPath}
+		return
+	}
+	eventCh <- ResyncedEvent{Old: node, Node: fresh}
+}
+
+// rescanDirectory rescans a directory, adds what is new in it to the tree
+// and returns the new nodes, or false if the directory could not be rescanned
+func (c *Controller) rescanDirectory(dirPath string, root *model.Node) ([]*model.Node, bool) {
 	parent := c.findNodeByPath(root, dirPath)
 	if parent == nil {
 		logging.Debug.Printf("Watcher: rescan dir not in tree: %s", dirPath)
-		return
+		return nil, false
 	}
 
+	policy := c.ScanPolicy()
+
 	// Get current children paths for comparison
 	oldChildren := make(map[string]*model.Node)
 	for _, child := range parent.Children {
-		oldChildren[child.Path] = child
+		oldChildren[child.Path()] = child
 	}
 
 	// Read current directory contents
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		logging.Debug.Printf("Watcher: cannot read dir for rescan: %s: %v", dirPath, err)
-		return
+		return nil, false
 	}
 
 	// Find new entries
+	var added []*model.Node
 	for _, entry := range entries {
 		childPath := filepath.Join(dirPath, entry.Name())
 		if _, exists := oldChildren[childPath]; exists {
@@ -484,14 +1208,12 @@ func (c *Controller) rescanDirectory(dirPath string, root *model.Node, eventCh c
 		var node *model.Node
 		if entry.IsDir() {
 			// Directory - use scanner for recursive scan
-			w := scanner.NewWalker(4)
 			var err error
-			node, err = w.Scan(context.Background(), childPath)
+			node, err = c.scanSubtree(childPath)
 			if err != nil {
 				logging.Debug.Printf("Watcher: cannot scan new dir: %s: %v", childPath, err)
 				continue
 			}
-			node.ComputeSizes()
 		} else {
 			// File - create node directly
 			info, err := entry.Info()
@@ -499,38 +1221,150 @@ func (c *Controller) rescanDirectory(dirPath string, root *model.Node, eventCh c
 				logging.Debug.Printf("Watcher: cannot stat new file: %s: %v", childPath, err)
 				continue
 			}
+			disk, logical := scanner.FileSize(childPath, info, policy)
 			node = &model.Node{
-				Name:  entry.Name(),
-				Path:  childPath,
-				IsDir: false,
-				Size:  info.Size(),
+				Name:    entry.Name(),
+				IsDir:   false,
+				Size:    disk,
+				Logical: logical,
+				ModTime: info.ModTime().Unix(),
 			}
+			node.Category = model.CategoryOf(node.Name)
+			node.UID, node.GID = model.OwnerOf(info)
 		}
+		node.Attrs |= scanner.EntryAttrs(childPath, entry)
 
-		node.IsNew = true
 		parent.AddChild(node)
+		c.pathIndex().Add(node)
+		if c.noise.Is(node) {
+			continue // Counted in sizes, but not highlighted as new
+		}
+		node.IsNew = true
+		added = append(added, node)
 		logging.Debug.Printf("Watcher: CREATED: %s (size: %d, isDir: %v)", childPath, node.TotalSize(), node.IsDir)
 		logging.Debug.Printf("Watcher: Parent %s now has %d children", parent.Name, len(parent.Children))
 	}
+	return added, true
+}
 
+// reportCreated tells the interface about nodes added below path
+func (c *Controller) reportCreated(path string, added []*model.Node, eventCh chan Event) {
 	c.mu.Lock()
 	diskFree := c.getDiskFree()
 	c.mu.Unlock()
 
 	eventCh <- CreationDetectedEvent{
-		Path:     dirPath,
+		Path:     path,
+		Nodes:    added,
 		DiskFree: diskFree,
 	}
 }
 
-// getDiskFree returns current free disk space (caller must hold lock)
-func (c *Controller) getDiskFree() int64 {
-	var watchPath string
+// scanSubtree scans a directory on its own and returns its tree with sizes computed
+func (c *Controller) scanSubtree(path string) (*model.Node, error) {
+	c.mu.RLock()
+	w := scanner.NewWalker(4, c.policy)
+	c.mu.RUnlock()
+
+	node, err := w.Scan(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+	node.ComputeSizes()
+	return node, nil
+}
+
+// RescanSubtree rescans a single directory and splices the fresh result into
+// the tree in place of node, returning the replacement. Blocks until done.
+func (c *Controller) RescanSubtree(node *model.Node) (*model.Node, error) {
+	if node == nil || !node.IsDir || node.IsDeleted {
+		return nil, fmt.Errorf("not a directory")
+	}
+	if node.Attrs.Has(model.AttrVirtual) {
+		return nil, fmt.Errorf("%s is estimated space, not a folder on disk", node.Name)
+	}
+	parent := node.Parent
+	if parent == nil {
+		return nil, fmt.Errorf("use a full rescan for the scan root")
+	}
+
+	fresh, err := c.scanSubtree(node.Path())
+	if err != nil {
+		return nil, err
+	}
+	fresh.Name = node.Name
+	fresh.Attrs = node.Attrs // A scan gives its root no attributes of its own
+
+	index := c.pathIndex()
+	index.Remove(node)
+	parent.RemoveChild(node)
+	parent.AddChild(fresh)
+	index.Add(fresh)
+
+	// Marks on the replaced nodes no longer refer to anything in the tree
+	c.mu.Lock()
+	for marked := range c.marked {
+		if isWithin(marked.Path(), node.Path()) {
+			delete(c.marked, marked)
+		}
+	}
+	c.mu.Unlock()
+
+	logging.Debug.Printf("[Controller] Rescanned %s: %d -> %d bytes", node.Path(), node.TotalSize(), fresh.TotalSize())
+	return fresh, nil
+}
+
+// PruneDeleted drops files and folders marked deleted from the tree so their
+// memory can be released, and returns how many items and bytes were removed.
+// Long watcher sessions otherwise keep every deleted subtree around.
+func (c *Controller) PruneDeleted() (int, int64) {
+	c.mu.RLock()
+	root := c.root
+	c.mu.RUnlock()
+	if root == nil {
+		return 0, 0
+	}
+
+	pruned := root.Prune()
+	index := c.pathIndex()
+	var bytes int64
+	for _, node := range pruned {
+		index.Remove(node)
+		bytes += node.TotalSize()
+	}
+	root.Compact()
+
+	// Marks inside pruned subtrees no longer refer to anything in the tree
+	c.mu.Lock()
+	for marked := range c.marked {
+		top := marked
+		for top.Parent != nil {
+			top = top.Parent
+		}
+		if top != root {
+			delete(c.marked, marked)
+		}
+	}
+	c.mu.Unlock()
+
+	logging.Debug.Printf("[Controller] Pruned %d deleted items (%d bytes)", len(pruned), bytes)
+	return len(pruned), bytes
+}
+
+// scanPathLocked returns the custom path or the selected drive's path (caller must hold lock)
+func (c *Controller) scanPathLocked() string {
 	if c.customPath != "" {
-		watchPath = c.customPath
-	} else if c.selectedDrive >= 0 && c.selectedDrive < len(c.drives) {
-		watchPath = c.drives[c.selectedDrive].Path
+		return c.customPath
+	}
+	if c.selectedDrive >= 0 && c.selectedDrive < len(c.drives) {
+		return c.drives[c.selectedDrive].Path
 	}
+	return ""
+}
+
+// getDiskFree returns current free disk space (caller must hold lock)
+func (c *Controller) getDiskFree() int64 {
+	watchPath := c.scanPathLocked()
 	if watchPath == "" {
 		return 0
 	}
@@ -538,17 +1372,24 @@ func (c *Controller) getDiskFree() int64 {
 	return free
 }
 
-// findNodeByPath searches for a node by its path
-func (c *Controller) findNodeByPath(node *model.Node, path string) *model.Node {
-	if node.Path == path {
-		return node
-	}
-	for _, child := range node.Children {
-		if found := c.findNodeByPath(child, path); found != nil {
-			return found
-		}
+// findNodeByPath looks up a node by its path under root, using the path
+// index when root is the current tree
+func (c *Controller) findNodeByPath(root *model.Node, path string) *model.Node {
+	c.mu.RLock()
+	index, current := c.index, c.root
+	c.mu.RUnlock()
+
+	if index != nil && root == current {
+		return index.Lookup(path)
 	}
-	return nil
+	return root.Find(path)
+}
+
+// pathIndex returns the path index of the current tree, nil before a scan completes
+func (c *Controller) pathIndex() *model.PathIndex {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.index
 }
 
 // Stop cleans up resources
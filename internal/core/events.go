@@ -1,6 +1,10 @@
 package core
 
-import "github.com/lumipallolabs/diskdive/internal/model"
+import (
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
 
 // Event represents a state change from the controller
 type Event interface {
@@ -18,6 +22,9 @@ func (ScanStartedEvent) isEvent() {}
 type ScanProgressEvent struct {
 	FilesScanned int64
 	BytesFound   int64
+	FilesPerSec  float64
+	BytesPerSec  float64
+	CurrentPath  string
 }
 
 func (ScanProgressEvent) isEvent() {}
@@ -31,12 +38,22 @@ func (ScanPhaseChangedEvent) isEvent() {}
 
 // ScanCompletedEvent is emitted when scan finishes
 type ScanCompletedEvent struct {
-	Root *model.Node
-	Err  error
+	Root       *model.Node
+	Extensions model.ExtHistogram // Per-extension file counts and bytes
+	Err        error
 }
 
 func (ScanCompletedEvent) isEvent() {}
 
+// SnapshotSavedEvent is emitted after ScanCompletedEvent, once the snapshot
+// of the scan is written
+type SnapshotSavedEvent struct {
+	File string // Where the snapshot went
+	Err  error
+}
+
+func (SnapshotSavedEvent) isEvent() {}
+
 // SelectionChangedEvent is emitted when selection changes
 type SelectionChangedEvent struct {
 	Node    *model.Node
@@ -65,9 +82,12 @@ func (DriveChangedEvent) isEvent() {}
 // DeletionDetectedEvent is emitted when a file/folder deletion is detected
 type DeletionDetectedEvent struct {
 	Path         string
+	Node         *model.Node // The node marked as deleted
 	Size         int64
+	Noise        bool // An OS metadata file, removed from the tree instead of marked
 	SessionFreed int64
 	TotalFreed   int64
+	DriveFreed   int64 // Freed all time on the drive being viewed
 	DiskFree     int64 // Updated free disk space
 }
 
@@ -76,12 +96,59 @@ func (DeletionDetectedEvent) isEvent() {}
 // CreationDetectedEvent is emitted when a new file/folder is created
 type CreationDetectedEvent struct {
 	Path     string
-	Node     *model.Node // The newly created node
+	Nodes    []*model.Node // The newly created nodes
 	DiskFree int64       // Updated free disk space
 }
 
 func (CreationDetectedEvent) isEvent() {}
 
+// SizeChangedEvent is emitted when files written to after the scan, such
+// as a growing log or a download, changed size
+type SizeChangedEvent struct {
+	Nodes    []*model.Node // The files, already resized
+	Grew     int64         // Change in space on disk over all of them
+	DiskFree int64         // Updated free disk space
+}
+
+func (SizeChangedEvent) isEvent() {}
+
+// MoveDetectedEvent is emitted when a file/folder was renamed or moved
+// within the watched tree, after its node was moved to match
+type MoveDetectedEvent struct {
+	OldPath string
+	Path    string
+	Node    *model.Node // The moved node, the same one as before the move
+}
+
+func (MoveDetectedEvent) isEvent() {}
+
+// ResyncedEvent is emitted when a folder whose events were lost has been
+// rescanned and put in the tree in place of the stale one
+type ResyncedEvent struct {
+	Old  *model.Node // The stale folder, now detached
+	Node *model.Node // Its replacement
+}
+
+func (ResyncedEvent) isEvent() {}
+
+// WatchPollingEvent is emitted first when the watcher polls, because the
+// path is on a network drive or native watching failed
+type WatchPollingEvent struct {
+	Interval time.Duration
+	Reason   string
+}
+
+func (WatchPollingEvent) isEvent() {}
+
+// WatchOverflowEvent is emitted when the watcher lost events, so changes
+// below Path may be missing from the tree until it is rescanned
+type WatchOverflowEvent struct {
+	Path   string
+	Rescan bool // Path is marked stale and a ResyncedEvent follows; otherwise the user has to rescan
+}
+
+func (WatchOverflowEvent) isEvent() {}
+
 // TreeExpandedEvent is emitted when a tree node is expanded/collapsed
 type TreeExpandedEvent struct {
 	Node     *model.Node
@@ -90,6 +157,67 @@ type TreeExpandedEvent struct {
 
 func (TreeExpandedEvent) isEvent() {}
 
+// MoveProgressEvent is emitted while items are copied across filesystems
+type MoveProgressEvent struct {
+	Path       string // Item currently being moved
+	BytesDone  int64
+	BytesTotal int64
+}
+
+func (MoveProgressEvent) isEvent() {}
+
+// MoveCompletedEvent is emitted when a move operation finishes
+type MoveCompletedEvent struct {
+	Moved    int   // Number of items moved successfully
+	Freed    int64 // Bytes that left this drive (cross-device moves)
+	DiskFree int64 // Updated free disk space
+	Err      error // First error encountered, if any
+}
+
+func (MoveCompletedEvent) isEvent() {}
+
+// CompressProgressEvent is emitted while a folder is written to an archive
+type CompressProgressEvent struct {
+	Path       string // Folder being compressed
+	BytesDone  int64
+	BytesTotal int64
+}
+
+func (CompressProgressEvent) isEvent() {}
+
+// CompressCompletedEvent is emitted when compressing a folder finishes
+type CompressCompletedEvent struct {
+	Archive  string // Archive written, empty if compressing failed
+	Size     int64  // Size of the archive on disk
+	Deleted  bool   // Whether the folder was deleted afterwards
+	Freed    int64  // Bytes saved by replacing the folder with the archive
+	DiskFree int64  // Updated free disk space
+	Err      error  // Why compressing or deleting failed, if it did
+}
+
+func (CompressCompletedEvent) isEvent() {}
+
+// CleanupCompletedEvent is emitted when a step of the cleanup wizard has
+// been removed
+type CleanupCompletedEvent struct {
+	Removed  int   // Number of items removed
+	Freed    int64 // Bytes the removed items took
+	DiskFree int64 // Updated free disk space
+	Err      error // First error encountered, if any
+}
+
+func (CleanupCompletedEvent) isEvent() {}
+
+// TrashCompletedEvent is emitted when a move to the trash finishes
+type TrashCompletedEvent struct {
+	Trashed  int   // Number of items moved to the trash
+	Freed    int64 // Bytes that left the scanned tree
+	DiskFree int64 // Updated free disk space
+	Err      error // First error encountered, if any
+}
+
+func (TrashCompletedEvent) isEvent() {}
+
 // ErrorEvent is emitted when an error occurs
 type ErrorEvent struct {
 	Err error
@@ -0,0 +1,182 @@
+package core
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+// IntegrityReport compares a scan with the filesystem's own usage figures
+type IntegrityReport struct {
+	Applicable bool   // False when the scan covered only part of a filesystem
+	Path       string // Scanned path
+	Scanned    int64  // Bytes found by the scan
+	Used       int64  // Bytes in use according to the filesystem
+	Skipped    scanner.SkipStats
+	Sources    []DiscrepancySource // Known and likely reasons for the difference
+}
+
+// DiscrepancySource is one reason the scan total differs from used space
+type DiscrepancySource struct {
+	Label  string
+	Bytes  int64 // 0 when the amount cannot be measured
+	Detail string
+}
+
+// Missing returns how many used bytes the scan did not find
+func (r IntegrityReport) Missing() int64 {
+	return r.Used - r.Scanned
+}
+
+// systemNodeName names the virtual folder holding space the scan cannot see
+const systemNodeName = "[System]"
+
+// Integrity compares the scanned total against the drive's used bytes and
+// explains where the difference is likely to be
+func (c *Controller) Integrity() IntegrityReport {
+	c.mu.RLock()
+	root := c.root
+	path := c.scanPathLocked()
+	skipped := c.skipped
+	areas := c.areas
+	applicable := c.isDriveRootLocked(path)
+	c.mu.RUnlock()
+
+	return c.buildIntegrity(root, path, skipped, areas, applicable)
+}
+
+// buildIntegrity measures the gap between a scanned tree and the filesystem's
+// used space. areas are the system areas measured when the scan finished.
+func (c *Controller) buildIntegrity(root *model.Node, path string, skipped scanner.SkipStats, areas []model.SystemArea, applicable bool) IntegrityReport {
+	report := IntegrityReport{Path: path, Skipped: skipped}
+	if root == nil || path == "" {
+		return report
+	}
+	report.Scanned = root.TotalSize() - root.DeletedSize
+	if system := systemNode(root); system != nil {
+		report.Scanned -= system.TotalSize()
+	}
+	if !applicable {
+		return report
+	}
+
+	total, free := model.GetDiskSpace(path)
+	if total == 0 {
+		return report
+	}
+	report.Applicable = true
+	report.Used = total - free
+
+	remaining := report.Missing()
+	if reserved := model.GetReservedSpace(path); reserved > 0 {
+		report.Sources = append(report.Sources, DiscrepancySource{
+			Label:  "Reserved space",
+			Bytes:  reserved,
+			Detail: "Free space held back for the system; counted as used but holds no files",
+		})
+		remaining -= reserved
+	}
+	for _, area := range areas {
+		// Areas backed by a visible file were already counted by the scan
+		if area.Path != "" && c.findNodeByPath(root, area.Path) != nil {
+			continue
+		}
+		report.Sources = append(report.Sources, DiscrepancySource{
+			Label:  area.Name,
+			Bytes:  area.Size,
+			Detail: area.Detail,
+		})
+		remaining -= area.Size
+	}
+	if skipped.Inaccessible > 0 {
+		report.Sources = append(report.Sources, DiscrepancySource{
+			Label:  "Inaccessible folders",
+			Detail: fmt.Sprintf("%d folder(s) could not be read; run with more privileges to include them", skipped.Inaccessible),
+		})
+	}
+	if remaining > 0 {
+		report.Sources = append(report.Sources, DiscrepancySource{
+			Label:  "Not visible to a file scan",
+			Bytes:  remaining,
+			Detail: platformDiscrepancyHint(),
+		})
+	}
+	return report
+}
+
+// attachSystemNode adds a virtual folder to the scan root holding the
+// measured sources from report, so the tree and treemap add up to the
+// drive's used space. Any previous system folder is replaced.
+func attachSystemNode(root *model.Node, report IntegrityReport) {
+	if old := systemNode(root); old != nil {
+		root.RemoveChild(old)
+	}
+	if !report.Applicable || report.Missing() <= 0 {
+		return
+	}
+
+	system := &model.Node{
+		Name:  systemNodeName,
+		IsDir: true,
+		Attrs: model.AttrVirtual,
+		UID:   model.NoOwner,
+		GID:   model.NoOwner,
+	}
+	for _, s := range report.Sources {
+		if s.Bytes <= 0 {
+			continue
+		}
+		system.Children = append(system.Children, &model.Node{
+			Name:   s.Label,
+			Size:   s.Bytes,
+			Attrs:  model.AttrVirtual,
+			UID:    model.NoOwner,
+			GID:    model.NoOwner,
+			Parent: system,
+		})
+	}
+	if len(system.Children) == 0 {
+		return
+	}
+	system.ComputeSizes()
+	root.AddChild(system)
+}
+
+// systemNode returns the virtual system folder under root, if present
+func systemNode(root *model.Node) *model.Node {
+	for _, child := range root.Children {
+		if child.Attrs.Has(model.AttrVirtual) {
+			return child
+		}
+	}
+	return nil
+}
+
+// isDriveRootLocked reports whether path is the root of a known drive (caller must hold lock)
+func (c *Controller) isDriveRootLocked(path string) bool {
+	if path == "" {
+		return false
+	}
+	clean := filepath.Clean(path)
+	for _, d := range c.drives {
+		if filepath.Clean(d.Path) == clean {
+			return true
+		}
+	}
+	return false
+}
+
+// platformDiscrepancyHint describes space a file scan cannot see on this OS
+func platformDiscrepancyHint() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "APFS local snapshots (tmutil listlocalsnapshots /), purgeable space and other volumes sharing the container (VM, Preboot, Recovery)"
+	case "windows":
+		return "System Volume Information (restore points, shadow copies), NTFS metadata ($MFT, journal) and protected system files"
+	default:
+		return "Filesystem metadata (inodes, journal), files deleted while still open (lsof +L1) and data hidden under mount points"
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/config"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/shell"
+)
+
+const maxActionOutput = 64 * 1024 // Output kept from a quick action, from the end
+
+// QuickActionResult is the outcome of running a quick action
+type QuickActionResult struct {
+	Action   config.QuickAction
+	Command  string // Command line after placeholder substitution
+	Output   string // Combined stdout and stderr, truncated from the front
+	ExitCode int
+	Duration time.Duration
+	Err      error // Set when the command could not be started
+}
+
+// OK reports whether the command ran and exited successfully
+func (r QuickActionResult) OK() bool {
+	return r.Err == nil && r.ExitCode == 0
+}
+
+// QuickActions returns the configured quick actions
+func (c *Controller) QuickActions() []config.QuickAction {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.Actions
+}
+
+// RunQuickAction runs an action's command through the shell with the node's
+// path substituted. It blocks until the command exits.
+func (c *Controller) RunQuickAction(action config.QuickAction, node *model.Node) QuickActionResult {
+	result := QuickActionResult{Action: action}
+	if node == nil || node.IsDeleted || node.Attrs.IsSynthetic() {
+		result.Err = fmt.Errorf("no item on disk selected")
+		return result
+	}
+
+	dir := node.Path()
+	if !node.IsDir {
+		dir = filepath.Dir(node.Path())
+	}
+	var env []string
+	result.Command, env = expandAction(action.Command, node.Path())
+
+	var out tailBuffer
+	cmd := shell.Command(result.Command)
+	cmd.Env = append(cmd.Environ(), env...)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	start := c.clock.Now()
+	err := cmd.Run()
+	result.Duration = c.clock.Now().Sub(start)
+	result.Output = out.String()
+
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	} else if err != nil {
+		result.Err = err
+	}
+	logging.Debug.Printf("[Controller] Quick action %q exited %d after %v: %s", action.Name, result.ExitCode, result.Duration, result.Command)
+	return result
+}
+
+// expandAction substitutes the placeholders in an action command, quoting
+// each value for the shell, and returns the environment the command needs
+// to see them
+func expandAction(command, path string) (string, []string) {
+	return shell.Expand(command,
+		"{path}", path,
+		"{dir}", filepath.Dir(path),
+		"{name}", filepath.Base(path),
+	)
+}
+
+// tailBuffer keeps the last maxActionOutput bytes written to it
+type tailBuffer struct {
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	t.buf.Write(p)
+	if extra := t.buf.Len() - maxActionOutput; extra > 0 {
+		t.buf.Next(extra)
+		t.truncated = true
+	}
+	return n, nil
+}
+
+func (t *tailBuffer) String() string {
+	if t.truncated {
+		return "…\n" + t.buf.String()
+	}
+	return t.buf.String()
+}
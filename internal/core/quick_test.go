@@ -0,0 +1,36 @@
+package core
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/lumipallolabs/diskdive/internal/shell"
+)
+
+func TestExpandAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the action is a sh command line")
+	}
+	tests := []struct {
+		path, dir, name string
+	}{
+		{"/home/me/file.txt", "/home/me", "file.txt"},
+		{"/home/me/My Files/a b.txt", "/home/me/My Files", "a b.txt"},
+		{"/home/me/it's/'q'", "/home/me/it's", "'q'"},
+		{"/home/me/$HOME/$(id)", "/home/me/$HOME", "$(id)"},
+		{"/home/me/100%/%PATH%", "/home/me/100%", "%PATH%"},
+		{"/home/me/{dir}/{name}", "/home/me/{dir}", "{name}"},
+	}
+	for _, tt := range tests {
+		line, env := expandAction("printf '%s|%s|%s' {path} {dir} {name}", tt.path)
+		cmd := shell.Command(line)
+		cmd.Env = append(cmd.Environ(), env...)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if want := tt.path + "|" + tt.dir + "|" + tt.name; string(out) != want {
+			t.Errorf("%s printed %q, want %q", line, out, want)
+		}
+	}
+}
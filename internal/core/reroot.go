@@ -0,0 +1,55 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+// Reroot makes the folder at node the root of the view, as if diskdive had
+// been started on it, and returns the new root. The folder is copied out of
+// the current tree with its sizes and changes, so nothing is scanned again;
+// rescans and the watcher cover only the folder from then on.
+func (c *Controller) Reroot(node *model.Node) (*model.Node, error) {
+	if node == nil || !node.IsDir || node.IsDeleted {
+		return nil, fmt.Errorf("not a directory")
+	}
+	if node.Attrs.IsSynthetic() {
+		return nil, fmt.Errorf("%s is estimated space, not a folder on disk", node.Name)
+	}
+	if node.Parent == nil {
+		return nil, fmt.Errorf("already the scan root")
+	}
+
+	root := node.Extract()
+	index := model.NewPathIndex(root)
+	extensions := model.BuildExtHistogram(root)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.customPath = root.Path()
+	c.root = root
+	c.index = index
+	c.tree = NewTreeState()
+	c.tree.Root = root
+	c.tree.Expanded[root.Path()] = true
+	c.marked = make(map[*model.Node]bool)
+	c.extensions = extensions
+	c.skipped = scanner.SkipStats{}
+	c.areas = nil
+	c.growthKnown = false
+
+	// Slow folders outside the new root no longer apply
+	var slow []scanner.SlowPath
+	for _, p := range c.slowPaths {
+		if isWithin(p.Path, c.customPath) {
+			slow = append(slow, p)
+		}
+	}
+	c.slowPaths = slow
+
+	c.scan.Path = c.customPath
+	return root, nil
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// maxReuseAge is how old a snapshot may be and still be offered in place of
+// scanning a folder it covers
+const maxReuseAge = 24 * time.Hour
+
+// SnapshotOffer is a recent snapshot covering the custom path, which can be
+// shown at once instead of scanning the path again
+type SnapshotOffer struct {
+	From      string // Path the snapshot was taken of: the drive, or the custom path itself
+	ScannedAt time.Time
+	Own       bool // The snapshot is of the custom path, as a folder saved on its own
+}
+
+// CoveringSnapshot returns the newest recent snapshot of the custom path or
+// of the drive that contains it, or false if there is no custom path or no
+// such snapshot
+func (c *Controller) CoveringSnapshot() (SnapshotOffer, bool) {
+	c.mu.RLock()
+	path := c.customPath
+	drives := make([]string, 0, len(c.drives))
+	for _, d := range c.drives {
+		drives = append(drives, d.Path)
+	}
+	now := c.clock.Now()
+	c.mu.RUnlock()
+
+	if path == "" {
+		return SnapshotOffer{}, false
+	}
+
+	var offer SnapshotOffer
+	if header, err := c.cache.LoadHeader(path); err == nil {
+		offer = SnapshotOffer{From: path, ScannedAt: header.ScannedAt, Own: true}
+	}
+
+	// Only the innermost drive holds the path; scans of the drives around it
+	// stop at its mount point
+	sort.Slice(drives, func(i, j int) bool { return len(drives[i]) > len(drives[j]) })
+	for _, drive := range drives {
+		if drive == path || !isWithin(path, drive) {
+			continue
+		}
+		if header, err := c.cache.LoadHeader(drive); err == nil && header.ScannedAt.After(offer.ScannedAt) {
+			offer = SnapshotOffer{From: drive, ScannedAt: header.ScannedAt}
+		}
+		break
+	}
+	if offer.From == "" || now.Sub(offer.ScannedAt) > maxReuseAge {
+		return SnapshotOffer{}, false
+	}
+	return offer, true
+}
+
+// ReuseSnapshot shows the custom path's folder from the offered snapshot
+// instead of scanning it. It reports through the same events as StartScan,
+// and a rescan later walks the path as usual.
+func (c *Controller) ReuseSnapshot(offer SnapshotOffer) (<-chan Event, error) {
+	c.mu.Lock()
+	path := c.customPath
+	if path == "" {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	c.resetScanLocked()
+	c.mu.Unlock()
+
+	eventCh := make(chan Event, 100)
+	go c.runReuse(path, offer, eventCh)
+	return eventCh, nil
+}
+
+// runReuse loads the subtree in a goroutine
+func (c *Controller) runReuse(path string, offer SnapshotOffer, eventCh chan Event) {
+	defer close(eventCh)
+
+	logging.Debug.Printf("[Controller] Loading %s from the snapshot of %s", path, offer.From)
+
+	c.mu.Lock()
+	c.scan.Path = path
+	c.scan.StartTime = c.clock.Now()
+	c.mu.Unlock()
+
+	eventCh <- ScanStartedEvent{Path: path}
+
+	root, header, err := c.cache.LoadSubtree(offer.From, path)
+	if err != nil {
+		c.mu.Lock()
+		c.scan.Phase = PhaseIdle
+		c.mu.Unlock()
+
+		eventCh <- ScanCompletedEvent{Err: err}
+		eventCh <- ErrorEvent{Err: err}
+		return
+	}
+	root.ComputeSizes()
+	index := model.NewPathIndex(root)
+	extensions := model.BuildExtHistogram(root)
+
+	c.mu.Lock()
+	c.scan.Phase = PhaseComplete
+	c.scan.ReusedFrom = header.ScannedAt
+	c.root = root
+	c.index = index
+	c.tree.Root = root
+	c.tree.Expanded[root.Path()] = true
+	c.extensions = extensions
+	c.mu.Unlock()
+
+	eventCh <- ScanPhaseChangedEvent{Phase: PhaseComplete}
+	eventCh <- ScanCompletedEvent{Root: root, Extensions: extensions}
+}
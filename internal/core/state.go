@@ -35,9 +35,36 @@ func (p ScanPhase) String() string {
 // ScanState holds the current scan state
 type ScanState struct {
 	Phase        ScanPhase
+	Path         string // Root of the scan
 	StartTime    time.Time
+	Took         time.Duration // How long the scan took, once complete
 	FilesScanned int64
 	BytesFound   int64
+	FilesPerSec  float64 // Throughput over the last second
+	BytesPerSec  float64
+	CurrentPath  string // Directory currently being scanned
+
+	// Disk activity over the last second
+	Busy    float64       // Workers waiting on the filesystem
+	Workers int           // Parallel workers scanning
+	Stalled time.Duration // Time since the scan last counted an entry
+
+	// Throughput collapsed while the CPU idled, hinting at antivirus or an indexer
+	Interference bool
+
+	// Totals from the previous snapshot of the same path, 0 if none
+	ExpectedFiles int64
+	ExpectedBytes int64
+
+	// When the drive snapshot shown in place of a scan was taken, zero after a real scan
+	ReusedFrom time.Time
+
+	// Process ID of another diskdive whose scan of the same path is shown in
+	// place of running a second one, 0 otherwise
+	Following int
+
+	// Scans whose differences are shown in place of the live tree; From is zero otherwise
+	Comparison Comparison
 }
 
 // IsScanning returns true if a scan is in progress (including the brief "Complete" display)
@@ -53,10 +80,41 @@ func (s ScanState) Elapsed() time.Duration {
 	return time.Since(s.StartTime).Truncate(time.Second)
 }
 
-// FreedState tracks space recovered from deletions
+// Fraction returns how much of the scan is done (0-1) judged by the previous
+// snapshot's file count, or -1 if there is no snapshot to compare against
+func (s ScanState) Fraction() float64 {
+	if s.ExpectedFiles <= 0 {
+		return -1
+	}
+	f := float64(s.FilesScanned) / float64(s.ExpectedFiles)
+	if f > 0.99 {
+		f = 0.99 // The tree may have grown; stay short of done until the walk ends
+	}
+	return f
+}
+
+// ETA estimates the time left from the previous snapshot and the current rate,
+// or 0 if it cannot be estimated
+func (s ScanState) ETA() time.Duration {
+	if s.ExpectedFiles <= 0 || s.FilesPerSec <= 0 {
+		return 0
+	}
+	remaining := s.ExpectedFiles - s.FilesScanned
+	if remaining <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) / s.FilesPerSec * float64(time.Second)).Truncate(time.Second)
+}
+
+// FreedState tracks space recovered from deletions. Deletions smaller than
+// freed.minSize count only toward the Deleted totals.
 type FreedState struct {
 	Session  int64 // Bytes freed this session
 	Lifetime int64 // Bytes freed all time
+	Drive    int64 // Bytes freed all time on the drive being viewed, from the stats
+
+	Deleted         int64 // Bytes of every deletion this session
+	DeletedLifetime int64 // Bytes of every deletion all time
 }
 
 // TreeState holds tree navigation state
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/logging"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// SaveSubtree stores the folder at node as a snapshot of its own, labeled
+// with label unless it is empty, under its path as if it had been scanned by
+// itself, and returns the file written. Small, often changing folders can so
+// be kept without snapshotting the whole drive, and opening the folder later
+// offers the snapshot. Deleted items and estimated space are left out.
+func (c *Controller) SaveSubtree(node *model.Node, label string) (string, error) {
+	if node == nil || !node.IsDir || node.IsDeleted {
+		return "", fmt.Errorf("not a directory")
+	}
+	if node.Attrs.IsSynthetic() {
+		return "", fmt.Errorf("%s is estimated space, not a folder on disk", node.Name)
+	}
+
+	root := node.Extract()
+	root.Prune()
+	if system := systemNode(root); system != nil {
+		root.RemoveChild(system)
+	}
+
+	c.mu.RLock()
+	env := cache.CaptureEnvironment(c.version, c.policy.Options())
+	c.mu.RUnlock()
+	snap := cache.NewSnapshot(root, 0)
+	snap.Header.Env = env
+	snap.Header.Label = label
+
+	path := root.Path()
+	if err := c.cache.Write(path, snap); err != nil {
+		return "", err
+	}
+	logging.Debug.Printf("[Controller] Saved snapshot of %s", path)
+	return c.cache.File(path, snap.Header.ScannedAt), nil
+}
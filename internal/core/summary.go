@@ -0,0 +1,137 @@
+package core
+
+import (
+	"cmp"
+	"container/heap"
+	"slices"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// summaryDeletions is how many of the largest deletions a summary lists
+const summaryDeletions = 5
+
+// Deletion is an item that went away while it was being watched
+type Deletion struct {
+	Path string
+	Size int64
+}
+
+// SessionSummary is what happened since the drive or folder was picked,
+// for printing on quit
+type SessionSummary struct {
+	Path      string        // Scanned path, empty if nothing was scanned
+	ScanTook  time.Duration // How long the scan took, 0 if shown from a snapshot
+	Freed     int64         // Bytes freed this session
+	Deleted   int64         // Bytes of every deletion this session, those under freed.minSize included
+	Deletions []Deletion    // Largest first
+	Grew      string        // Folder where the tree grew most since the previous scan, empty if unknown
+	GrewBy    int64         // Bytes Grew grew by
+}
+
+// Summary sums up the session
+func (c *Controller) Summary() SessionSummary {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	s := SessionSummary{
+		Freed:     c.freed.Session,
+		Deleted:   c.freed.Deleted,
+		Deletions: slices.Clone([]Deletion(c.deletions)),
+	}
+	if c.root != nil {
+		s.Path = c.scanPathLocked()
+		s.ScanTook = c.scan.Took
+		if grew := biggestGrowth(c.root, c.history); grew != nil {
+			s.Grew, s.GrewBy = grew.Path(), grewBy(grew, c.history)
+		}
+	}
+	slices.SortFunc(s.Deletions, func(a, b Deletion) int { return cmp.Compare(b.Size, a.Size) })
+	return s
+}
+
+// noteDeletionLocked remembers a deletion for the summary, keeping only the
+// summaryDeletions largest and none under freed.minSize. Deletions inside
+// a folder noted already are part of it, and a folder replaces those noted
+// inside it (caller must hold lock).
+func (c *Controller) noteDeletionLocked(path string, size int64) {
+	if size < int64(c.config.Freed.MinSize) {
+		return
+	}
+	for _, d := range c.deletions {
+		if isWithin(path, d.Path) {
+			return
+		}
+	}
+	noted := len(c.deletions)
+	c.deletions = slices.DeleteFunc(c.deletions, func(d Deletion) bool {
+		return isWithin(d.Path, path)
+	})
+	if len(c.deletions) < noted {
+		heap.Init(&c.deletions)
+	}
+
+	d := Deletion{Path: path, Size: size}
+	if c.deletions.Len() < summaryDeletions {
+		heap.Push(&c.deletions, d)
+	} else if c.deletions[0].Size < size {
+		c.deletions[0] = d
+		heap.Fix(&c.deletions, 0)
+	}
+}
+
+// deletionHeap is a min-heap of deletions, keeping the smallest of the
+// largest ones noted at the root
+type deletionHeap []Deletion
+
+func (h deletionHeap) Len() int           { return len(h) }
+func (h deletionHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h deletionHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deletionHeap) Push(x any)        { *h = append(*h, x.(Deletion)) }
+func (h *deletionHeap) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return d
+}
+
+// biggestGrowth follows the growth since the last scan in history down
+// from root, into the folder that grew most for as long as it accounts for
+// at least half the growth, so the result names where the growth is rather
+// than the root. It returns nil without an earlier scan or if the tree did
+// not grow.
+func biggestGrowth(root *model.Node, history *cache.History) *model.Node {
+	if history == nil || len(history.Times) == 0 || grewBy(root, history) <= 0 {
+		return nil
+	}
+	node := root
+	for {
+		var next *model.Node
+		var most int64
+		for _, child := range node.Children {
+			if !child.IsDir || child.IsDeleted {
+				continue
+			}
+			if grew := grewBy(child, history); grew > most {
+				next, most = child, grew
+			}
+		}
+		if next == nil || most*2 < grewBy(node, history) {
+			return node
+		}
+		node = next
+	}
+}
+
+// grewBy returns how many bytes dir grew since the last scan in history. A
+// folder the history does not follow was new or too small to follow, and
+// counts as having been empty.
+func grewBy(dir *model.Node, history *cache.History) int64 {
+	var before int64
+	if samples := history.Samples(dir.Path()); len(samples) > 0 {
+		before = samples[len(samples)-1].Bytes
+	}
+	return dir.TotalSize() - before
+}
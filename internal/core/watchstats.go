@@ -0,0 +1,52 @@
+package core
+
+import (
+	"time"
+)
+
+// WatchStats describes what the filesystem watcher has done since it
+// started, to tell why changes stop showing up
+type WatchStats struct {
+	Path      string        // Folder being watched; empty before watching starts
+	Mode      string        // "native", "fsnotify", "polling" or "simulated" by the self test
+	Scoped    bool          // Only the scanned folder and open folders are followed
+	Reason    string        // Why the watcher polls, or why it failed to start
+	Started   time.Time     // When the watcher started
+	Running   bool          // False once the watcher has stopped
+	Events    int           // Changes received from the watcher
+	Dropped   int           // Times the watcher reported changes it lost
+	Rescans   int           // Folders rescanned to follow changes
+	LastEvent time.Time     // Zero before the first change
+	Uptime    time.Duration // Time since Started, as of the call to WatchStats
+	Quiet     time.Duration // Time since LastEvent, as of the call to WatchStats
+	Queued    int           // Changes waiting to be handled
+	Queue     int           // Changes the watcher holds before it has to drop them
+}
+
+// WatchStats returns the activity of the current watcher
+func (c *Controller) WatchStats() WatchStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.watchStats == nil {
+		return WatchStats{}
+	}
+	stats := *c.watchStats
+	now := c.clock.Now()
+	stats.Uptime = now.Sub(stats.Started)
+	if !stats.LastEvent.IsZero() {
+		stats.Quiet = now.Sub(stats.LastEvent)
+	}
+	if stats.Running && c.watcher != nil {
+		events := c.watcher.Events()
+		stats.Queued, stats.Queue = len(events), cap(events)
+	}
+	return stats
+}
+
+// noteWatch updates stats, the activity of one watch loop, under the lock.
+// A loop that outlived its watcher keeps updating its own, replaced stats.
+func (c *Controller) noteWatch(stats *WatchStats, update func(*WatchStats)) {
+	c.mu.Lock()
+	update(stats)
+	c.mu.Unlock()
+}
@@ -0,0 +1,70 @@
+package core
+
+import (
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// minGrowthSpan is the shortest gap between scans that gives a usable growth rate
+const minGrowthSpan = time.Hour
+
+// growthWindow is how far back the growth rate looks for an earlier scan
+const growthWindow = 30 * 24 * time.Hour
+
+// WhatIfState projects the drive as if the marked nodes were deleted
+type WhatIfState struct {
+	Projection   model.Projection
+	Free         int64   // Free bytes on the scanned drive now
+	Total        int64   // Size of the scanned drive
+	GrowthPerDay float64 // Bytes a day the scanned tree grew over recent scans
+	GrowthKnown  bool    // False without an earlier scan far enough back
+}
+
+// ProjectedFree returns the free bytes after the deletion
+func (w WhatIfState) ProjectedFree() int64 {
+	return w.Free + w.Projection.Freed()
+}
+
+// DaysLeft returns how many days until the drive fills at the current growth,
+// or false if that cannot be told
+func (w WhatIfState) DaysLeft() (float64, bool) {
+	return model.DaysUntilFull(w.Free, w.GrowthPerDay)
+}
+
+// ProjectedDaysLeft returns DaysLeft after the deletion
+func (w WhatIfState) ProjectedDaysLeft() (float64, bool) {
+	return model.DaysUntilFull(w.ProjectedFree(), w.GrowthPerDay)
+}
+
+// growthRate returns how many bytes a day a tree now of size bytes grew
+// since the earliest of its earlier scans within growthWindow, skipping
+// scans less than minGrowthSpan ago. Looking back over several scans evens
+// out a one-off download or cleanup between the last two.
+func growthRate(samples []cache.Sample, now time.Time, bytes int64) (float64, bool) {
+	for _, s := range samples {
+		if since := now.Sub(s.At); since <= growthWindow && since >= minGrowthSpan {
+			return model.GrowthPerDay(s.Bytes, bytes, since), true
+		}
+	}
+	return 0, false
+}
+
+// WhatIf projects deleting the marked nodes. Nothing on disk changes.
+func (c *Controller) WhatIf() WhatIfState {
+	marked := c.Marked()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	w := WhatIfState{
+		Projection:   model.NewProjection(marked),
+		GrowthPerDay: c.growth,
+		GrowthKnown:  c.growthKnown,
+	}
+	if path := c.scanPathLocked(); path != "" {
+		w.Total, w.Free = model.GetDiskSpace(path)
+	}
+	return w
+}
@@ -0,0 +1,295 @@
+package fileops
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveExts are the archive types Compress writes, by extension; the
+// first is the default
+var ArchiveExts = []string{".zip", ".tar.gz", ".tgz"}
+
+// Compress writes the folder src and everything below it to a new archive
+// at dst, a zip file or a gzip-compressed tar depending on dst's extension.
+// Entries are named from src's own name down, so extracting the archive
+// brings the folder back. Progress reports the bytes of files read so far.
+// dst must not exist yet, nor be inside src; an archive left unfinished by
+// an error is removed. The archive is flushed to disk before Compress returns.
+// Devices, sockets and pipes cannot be archived; their paths are returned.
+func Compress(src, dst string, progress ProgressFunc) (skipped []string, err error) {
+	isZip, ok := archiveKind(dst)
+	if !ok {
+		return nil, fmt.Errorf("unknown archive type %s (want %s)", filepath.Base(dst), strings.Join(ArchiveExts, ", "))
+	}
+	if rel, err := filepath.Rel(src, dst); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("cannot write the archive of %s inside it", src)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err == nil {
+			err = out.Sync()
+		}
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	var done int64
+	count := func(n int64) {
+		done += n
+		if progress != nil {
+			progress(done)
+		}
+	}
+	skip := func(path string) {
+		skipped = append(skipped, path)
+	}
+	if isZip {
+		return skipped, writeZip(out, src, count, skip)
+	}
+	return skipped, writeTarGz(out, src, count, skip)
+}
+
+// VerifyArchive reads the archive Compress wrote at path from start to end,
+// checking every entry against its checksum, and that its files add up to
+// size bytes, what Compress read. Run it before deleting what was archived.
+func VerifyArchive(path string, size int64) error {
+	isZip, ok := archiveKind(path)
+	if !ok {
+		return fmt.Errorf("unknown archive type %s", filepath.Base(path))
+	}
+	var total int64
+	var err error
+	if isZip {
+		total, err = readZip(path)
+	} else {
+		total, err = readTarGz(path)
+	}
+	if err != nil {
+		return fmt.Errorf("read back %s: %w", filepath.Base(path), err)
+	}
+	if total != size {
+		return fmt.Errorf("%s holds %d bytes of files, want %d", filepath.Base(path), total, size)
+	}
+	return nil
+}
+
+// readZip reads every entry of a zip archive and returns the bytes of its
+// regular files. The zip reader checks each entry's CRC as it ends.
+func readZip(path string) (int64, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		n, err := io.Copy(io.Discard, rc)
+		rc.Close()
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if f.Mode().IsRegular() {
+			total += n
+		}
+	}
+	return total, nil
+}
+
+// readTarGz reads a gzip-compressed tar archive to its end and returns the
+// bytes of its regular files. The gzip reader checks the CRC at the end.
+func readTarGz(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return 0, err
+	}
+	defer gr.Close()
+
+	var total int64
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return total, err
+		}
+		n, err := io.Copy(io.Discard, tr)
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", header.Name, err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			total += n
+		}
+	}
+	// The padding after the tar's end, then the gzip trailer
+	if _, err := io.Copy(io.Discard, gr); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// archiveKind reports whether path names a zip archive rather than a tar
+// one, and whether it names an archive Compress can write at all
+func archiveKind(path string) (isZip, ok bool) {
+	lower := strings.ToLower(path)
+	for _, ext := range ArchiveExts {
+		if strings.HasSuffix(lower, ext) && len(lower) > len(ext) {
+			return ext == ".zip", true
+		}
+	}
+	return false, false
+}
+
+// walkArchive calls add for each entry below src, named by its slash
+// separated path from src's parent. Devices, sockets and pipes are passed
+// to skip instead.
+func walkArchive(src string, add func(path, name string, info fs.FileInfo) error, skip func(path string)) error {
+	base := filepath.Dir(src)
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Type()&fs.ModeSymlink == 0 && !d.Type().IsRegular() {
+			skip(path)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		return add(path, filepath.ToSlash(rel), info)
+	})
+}
+
+// writeZip writes src to w as a deflated zip archive
+func writeZip(w io.Writer, src string, count func(n int64), skip func(path string)) error {
+	zw := zip.NewWriter(w)
+	err := walkArchive(src, func(path, name string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+		entry, err := zw.CreateHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			// A zip symlink holds its target as its contents
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(entry, link)
+			return err
+		}
+		return copyInto(entry, path, count)
+	}, skip)
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// writeTarGz writes src to w as a gzip-compressed tar archive
+func writeTarGz(w io.Writer, src string, count func(n int64), skip func(path string)) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	err := walkArchive(src, func(path, name string, info fs.FileInfo) error {
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			var err error
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyInto(tw, path, count)
+	}, skip)
+	if err == nil {
+		err = tw.Close()
+	}
+	if cerr := gw.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// copyInto copies the file at path to w, counting each chunk read
+func copyInto(w io.Writer, path string, count func(n int64)) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	buf := make([]byte, copyBufferSize)
+	for {
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			count(int64(n))
+		}
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
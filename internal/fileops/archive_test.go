@@ -0,0 +1,158 @@
+package fileops
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// archiveFixture creates a folder holding two files, one in a subfolder
+func archiveFixture(t *testing.T) (tmp, src string) {
+	tmp = t.TempDir()
+	src = filepath.Join(tmp, "data")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(src, "sub", "b.txt"), make([]byte, 300), 0644)
+	return tmp, src
+}
+
+func TestCompressZip(t *testing.T) {
+	tmp, src := archiveFixture(t)
+	dst := filepath.Join(tmp, "data.zip")
+
+	var last int64
+	if _, err := Compress(src, dst, func(done int64) { last = done }); err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	if last != 305 {
+		t.Errorf("expected progress to reach 305 bytes, got %d", last)
+	}
+
+	zr, err := zip.OpenReader(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "data/a.txt" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(data) != "hello" {
+				t.Errorf("expected a.txt to hold hello, got %q", data)
+			}
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"data/", "data/a.txt", "data/sub/", "data/sub/b.txt"}; !slices.Equal(names, want) {
+		t.Errorf("expected entries %v, got %v", want, names)
+	}
+}
+
+func TestCompressTarGz(t *testing.T) {
+	tmp, src := archiveFixture(t)
+	dst := filepath.Join(tmp, "data.tar.gz")
+	if _, err := Compress(src, dst, nil); err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+
+	f, err := os.Open(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var names []string
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, h.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"data/", "data/a.txt", "data/sub/", "data/sub/b.txt"}; !slices.Equal(names, want) {
+		t.Errorf("expected entries %v, got %v", want, names)
+	}
+}
+
+func TestCompressReportsSkipped(t *testing.T) {
+	tmp, src := archiveFixture(t)
+	sock := filepath.Join(src, "s")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("no unix sockets: %v", err)
+	}
+	defer l.Close()
+
+	skipped, err := Compress(src, filepath.Join(tmp, "data.zip"), nil)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	if !slices.Equal(skipped, []string{sock}) {
+		t.Errorf("expected the socket reported skipped, got %v", skipped)
+	}
+}
+
+func TestCompressRefusesBadDestinations(t *testing.T) {
+	tmp, src := archiveFixture(t)
+	existing := filepath.Join(tmp, "old.zip")
+	os.WriteFile(existing, []byte("keep"), 0644)
+
+	for _, dst := range []string{
+		existing,                         // Taken
+		filepath.Join(tmp, "data.rar"),   // Unknown type
+		filepath.Join(src, "inside.zip"), // Would archive itself
+	} {
+		if _, err := Compress(src, dst, nil); err == nil {
+			t.Errorf("expected Compress to %s to be refused", dst)
+		}
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Error("expected the existing file to be left alone")
+	}
+	if _, err := os.Stat(filepath.Join(src, "inside.zip")); !os.IsNotExist(err) {
+		t.Error("expected no archive inside the folder")
+	}
+}
+
+func TestVerifyArchive(t *testing.T) {
+	for _, name := range []string{"data.zip", "data.tar.gz"} {
+		tmp, src := archiveFixture(t)
+		dst := filepath.Join(tmp, name)
+		if _, err := Compress(src, dst, nil); err != nil {
+			t.Fatalf("Compress failed: %v", err)
+		}
+		if err := VerifyArchive(dst, 305); err != nil {
+			t.Errorf("%s: expected the archive to verify, got %v", name, err)
+		}
+		if err := VerifyArchive(dst, 306); err == nil {
+			t.Errorf("%s: expected a size mismatch to be reported", name)
+		}
+
+		// Damage the compressed data inside the archive
+		data, err := os.ReadFile(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(dst, data[:len(data)-40], 0644)
+		if err := VerifyArchive(dst, 305); err == nil {
+			t.Errorf("%s: expected a truncated archive to fail", name)
+		}
+	}
+}
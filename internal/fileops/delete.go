@@ -0,0 +1,30 @@
+package fileops
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Delete permanently removes path and, for a directory, everything below it.
+// Relative paths and filesystem roots are refused, so a bad path cannot wipe
+// a drive.
+func Delete(path string) error {
+	clean, err := removable(path, "delete")
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(clean)
+}
+
+// removable cleans path, refusing relative paths and filesystem roots
+func removable(path, verb string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("refusing to %s relative path %q", verb, path)
+	}
+	clean := filepath.Clean(path)
+	if clean == filepath.Dir(clean) {
+		return "", fmt.Errorf("refusing to %s filesystem root %s", verb, clean)
+	}
+	return clean, nil
+}
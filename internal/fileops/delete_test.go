@@ -0,0 +1,30 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDelete(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "dir")
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	os.WriteFile(filepath.Join(dir, "sub", "file.txt"), []byte("hello"), 0644)
+
+	if err := Delete(dir); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("expected directory to be gone")
+	}
+}
+
+func TestDeleteRefusesRootsAndRelativePaths(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	for _, path := range []string{root, "relative/dir", ""} {
+		if err := Delete(path); err == nil {
+			t.Errorf("expected Delete(%q) to be refused", path)
+		}
+	}
+}
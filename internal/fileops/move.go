@@ -0,0 +1,143 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// copyBufferSize is the chunk size used when copying file contents
+const copyBufferSize = 1024 * 1024
+
+// ProgressFunc receives the number of bytes copied so far
+type ProgressFunc func(done int64)
+
+// PartialMoveError reports a move across devices that copied everything to
+// Dst but could not remove all of Src, so some of it is left in both places
+type PartialMoveError struct {
+	Src, Dst string
+	Err      error
+}
+
+func (e *PartialMoveError) Error() string {
+	return fmt.Sprintf("copied to %s, but some of %s could not be removed: %v", e.Dst, e.Src, e.Err)
+}
+
+func (e *PartialMoveError) Unwrap() error {
+	return e.Err
+}
+
+// Move moves src to dst. A plain rename is attempted first; when src and dst
+// are on different filesystems the tree is copied (reporting progress) and
+// the original removed, failing with a *PartialMoveError if it cannot all be.
+// Returns true if the data was copied across devices.
+func Move(src, dst string, progress ProgressFunc) (bool, error) {
+	if _, err := os.Lstat(dst); err == nil {
+		return false, fmt.Errorf("destination exists: %s", dst)
+	}
+
+	err := os.Rename(src, dst)
+	if err == nil {
+		return false, nil
+	}
+	if !isCrossDevice(err) {
+		return false, err
+	}
+
+	if err := CopyTree(src, dst, progress); err != nil {
+		// Leave the source intact and clean up the partial copy
+		_ = os.RemoveAll(dst)
+		return true, err
+	}
+	if err := os.RemoveAll(src); err != nil {
+		return true, &PartialMoveError{Src: src, Dst: dst, Err: err}
+	}
+	return true, nil
+}
+
+// CopyTree recursively copies src to dst, preserving modes and modification times
+func CopyTree(src, dst string, progress ProgressFunc) error {
+	var done int64
+	buf := make([]byte, copyBufferSize)
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case !d.Type().IsRegular():
+			return nil // Skip devices, sockets and pipes
+		}
+
+		if err := copyFile(path, target, info, buf, func(n int64) {
+			done += n
+			if progress != nil {
+				progress(done)
+			}
+		}); err != nil {
+			return err
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// copyFile copies a single regular file, reporting each written chunk
+func copyFile(src, dst string, info fs.FileInfo, buf []byte, onChunk func(n int64)) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	for {
+		n, readErr := in.Read(buf)
+		if n > 0 {
+			if _, err := out.Write(buf[:n]); err != nil {
+				out.Close()
+				return err
+			}
+			onChunk(int64(n))
+		}
+		if errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil {
+			out.Close()
+			return readErr
+		}
+	}
+	return out.Close()
+}
+
+// CreateDir creates a single new directory, failing if it already exists
+func CreateDir(path string) error {
+	return os.Mkdir(path, 0755)
+}
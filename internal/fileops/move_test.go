@@ -0,0 +1,55 @@
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMove(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(filepath.Join(src, "sub"), 0755)
+	os.WriteFile(filepath.Join(src, "sub", "file.txt"), []byte("hello"), 0644)
+
+	dst := filepath.Join(tmp, "dst")
+	if _, err := Move(src, dst, nil); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Error("expected source to be gone")
+	}
+	data, err := os.ReadFile(filepath.Join(dst, "sub", "file.txt"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("expected moved file contents, got %q (%v)", data, err)
+	}
+}
+
+func TestMoveRefusesExistingDestination(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "a")
+	dst := filepath.Join(tmp, "b")
+	os.WriteFile(src, []byte("a"), 0644)
+	os.WriteFile(dst, []byte("b"), 0644)
+
+	if _, err := Move(src, dst, nil); err == nil {
+		t.Error("expected error when destination exists")
+	}
+}
+
+func TestCopyTreeReportsProgress(t *testing.T) {
+	tmp := t.TempDir()
+	src := filepath.Join(tmp, "src")
+	os.MkdirAll(src, 0755)
+	os.WriteFile(filepath.Join(src, "a"), make([]byte, 300), 0644)
+	os.WriteFile(filepath.Join(src, "b"), make([]byte, 200), 0644)
+
+	var last int64
+	if err := CopyTree(src, filepath.Join(tmp, "dst"), func(done int64) { last = done }); err != nil {
+		t.Fatalf("CopyTree failed: %v", err)
+	}
+	if last != 500 {
+		t.Errorf("expected 500 bytes reported, got %d", last)
+	}
+}
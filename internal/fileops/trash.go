@@ -0,0 +1,11 @@
+package fileops
+
+// Trash moves path to the platform's trash, or the Recycle Bin on Windows,
+// from where it can be restored. The same paths as Delete are refused.
+func Trash(path string) error {
+	clean, err := removable(path, "trash")
+	if err != nil {
+		return err
+	}
+	return trash(clean)
+}
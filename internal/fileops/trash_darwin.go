@@ -0,0 +1,46 @@
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// trash moves path to ~/.Trash, or to .Trashes/<uid> at the top of its
+// volume when it is on another one, the way Finder does. Names taken
+// already get a number, as in Finder.
+func trash(path string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(home, ".Trash")
+	if !sameDevice(path, dir) {
+		dir = filepath.Join(mountRoot(path), ".Trashes", strconv.Itoa(os.Getuid()))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	if ext == base {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(base, ext)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s %d%s", stem, i, ext)
+		}
+		dst := filepath.Join(dir, name)
+		if _, err := os.Lstat(dst); !errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return os.Rename(path, dst)
+	}
+}
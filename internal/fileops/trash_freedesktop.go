@@ -0,0 +1,76 @@
+//go:build !darwin && !windows
+
+package fileops
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// trash follows the freedesktop.org trash spec: the item goes to files/ in
+// the home trash, or in $topdir/.Trash-$uid when it is on another
+// filesystem, with an info/ entry saying where it came from
+func trash(path string) error {
+	dir, err := trashDir(path)
+	if err != nil {
+		return err
+	}
+	files, info := filepath.Join(dir, "files"), filepath.Join(dir, "info")
+	for _, d := range []string{files, info} {
+		if err := os.MkdirAll(d, 0700); err != nil {
+			return err
+		}
+	}
+
+	// Creating the info entry claims the name
+	base := filepath.Base(path)
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name = fmt.Sprintf("%s.%d", base, i)
+		}
+		infoPath := filepath.Join(info, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			(&url.URL{Path: path}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = os.Rename(path, filepath.Join(files, name))
+		}
+		if err != nil {
+			os.Remove(infoPath)
+		}
+		return err
+	}
+}
+
+// trashDir returns the trash path goes to: the home trash if it is on the
+// same filesystem, otherwise one at the top of path's filesystem
+func trashDir(path string) (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	homeTrash := filepath.Join(dataHome, "Trash")
+	if err := os.MkdirAll(homeTrash, 0700); err == nil && sameDevice(path, homeTrash) {
+		return homeTrash, nil
+	}
+	return filepath.Join(mountRoot(path), fmt.Sprintf(".Trash-%d", os.Getuid())), nil
+}
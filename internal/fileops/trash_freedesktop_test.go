@@ -0,0 +1,51 @@
+//go:build !darwin && !windows
+
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTrash(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	trashDir := filepath.Join(tmp, "data", "Trash")
+
+	for i := 0; i < 2; i++ {
+		dir := filepath.Join(tmp, "my dir")
+		os.MkdirAll(dir, 0755)
+		os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello"), 0644)
+		if err := Trash(dir); err != nil {
+			t.Fatalf("Trash failed: %v", err)
+		}
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Error("expected directory to be gone")
+		}
+	}
+
+	// The second item with the same name gets a new one
+	for _, name := range []string{"my dir", "my dir.2"} {
+		if _, err := os.Stat(filepath.Join(trashDir, "files", name, "file.txt")); err != nil {
+			t.Errorf("expected %s in the trash: %v", name, err)
+		}
+		info, err := os.ReadFile(filepath.Join(trashDir, "info", name+".trashinfo"))
+		if err != nil {
+			t.Fatalf("expected info for %s: %v", name, err)
+		}
+		want := "Path=" + filepath.ToSlash(tmp) + "/my%20dir\n"
+		if !strings.Contains(string(info), want) {
+			t.Errorf("info for %s = %q, want it to contain %q", name, info, want)
+		}
+	}
+}
+
+func TestTrashRefusesRootsAndRelativePaths(t *testing.T) {
+	for _, path := range []string{"/", "relative/dir", ""} {
+		if err := Trash(path); err == nil {
+			t.Errorf("expected Trash(%q) to be refused", path)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !windows
+
+package fileops
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// sameDevice reports whether a and b are on the same filesystem, so one can
+// be renamed next to the other
+func sameDevice(a, b string) bool {
+	da, okA := device(a)
+	db, okB := device(b)
+	return okA && okB && da == db
+}
+
+// device returns the filesystem path is on, without following a final
+// symlink
+func device(path string) (uint64, bool) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// mountRoot returns the topmost folder above path on path's filesystem
+func mountRoot(path string) string {
+	dev, ok := device(path)
+	if !ok {
+		return filepath.Dir(path)
+	}
+	dir := filepath.Dir(path)
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		if d, ok := device(parent); !ok || d != dev {
+			return dir
+		}
+		dir = parent
+	}
+}
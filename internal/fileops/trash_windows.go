@@ -0,0 +1,67 @@
+package fileops
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	shell32          = syscall.NewLazyDLL("shell32.dll")
+	shFileOperationW = shell32.NewProc("SHFileOperationW")
+)
+
+// SHFileOperationW operation and flags
+const (
+	foDelete          = 0x3
+	fofSilent         = 0x4
+	fofNoConfirmation = 0x10
+	fofAllowUndo      = 0x40
+	fofNoErrorUI      = 0x400
+	fofWantNukeWarn   = 0x4000
+)
+
+// shFileOpStruct is SHFILEOPSTRUCTW. Its 64-bit layout is the natural one;
+// the 32-bit shell packs it on 2 bytes, which Go cannot express, so 386
+// builds fall back to failing the call.
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+// trash sends path to the Recycle Bin without asking, as the shell's
+// delete with undo. Where it cannot be recycled, such as on a drive without
+// a Recycle Bin or when it is too large for it, the shell would delete it for
+// good instead; the nuke warning makes it ask first, and declining leaves
+// path in place and is reported as an error.
+func trash(path string) error {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		return fmt.Errorf("moving to the Recycle Bin needs a 64-bit build")
+	}
+	from, err := windows.UTF16FromString(path)
+	if err != nil {
+		return err
+	}
+	from = append(from, 0) // The list of paths ends with an empty one
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofSilent | fofNoErrorUI | fofWantNukeWarn,
+	}
+	ret, _, _ := shFileOperationW.Call(uintptr(unsafe.Pointer(&op)))
+	if ret != 0 {
+		return fmt.Errorf("moving %s to the Recycle Bin failed (error %#x)", path, ret)
+	}
+	if op.fAnyOperationsAborted != 0 {
+		return fmt.Errorf("%s was not moved: it cannot go to the Recycle Bin, and deleting it for good was declined", path)
+	}
+	return nil
+}
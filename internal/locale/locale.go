@@ -0,0 +1,140 @@
+// Package locale formats numbers and dates the way the user's locale writes them.
+package locale
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// dateOrder is the order a locale writes day, month and year in
+type dateOrder int
+
+const (
+	orderMDY dateOrder = iota
+	orderDMY
+	orderYMD
+)
+
+// ymdRegions write dates year first; regions not listed here or in
+// mdyRegions write the day first
+var ymdRegions = map[string]bool{
+	"CN": true, "JP": true, "KR": true, "TW": true, "HU": true,
+	"LT": true, "MN": true, "IR": true, "SE": true, "CA": true,
+}
+
+// mdyRegions write the month first
+var mdyRegions = map[string]bool{"US": true, "PH": true, "FM": true, "PW": true}
+
+// Locale formats values for one language and region
+type Locale struct {
+	tag     language.Tag
+	printer *message.Printer
+	order   dateOrder
+}
+
+// New returns the locale for a BCP 47 tag like "de-DE" or a POSIX name like
+// "de_DE.UTF-8". An empty name is read from the environment. Unknown names
+// fall back to American English, which matches diskdive's original output.
+func New(name string) Locale {
+	if name == "" {
+		name = FromEnv()
+	}
+	tag, err := language.Parse(normalize(name))
+	if err != nil || tag == language.Und {
+		tag = language.AmericanEnglish
+	}
+
+	region, _ := tag.Region()
+	order := orderDMY
+	switch {
+	case mdyRegions[region.String()]:
+		order = orderMDY
+	case ymdRegions[region.String()]:
+		order = orderYMD
+	}
+
+	return Locale{tag: tag, printer: message.NewPrinter(tag), order: order}
+}
+
+// FromEnv returns the locale name set in LC_ALL, LC_NUMERIC or LANG
+func FromEnv() string {
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// normalize turns a POSIX locale name into a BCP 47 tag
+func normalize(name string) string {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "C" || name == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// Tag returns the language tag in use
+func (l Locale) Tag() language.Tag {
+	return l.tag
+}
+
+// Count formats an integer with the locale's digit grouping, e.g. "1,234,567"
+func (l Locale) Count(n int64) string {
+	return l.printer.Sprint(number.Decimal(n))
+}
+
+// Decimal formats x with exactly digits fraction digits and the locale's
+// decimal separator, e.g. "1.5" or "1,5"
+func (l Locale) Decimal(x float64, digits int) string {
+	return l.printer.Sprint(number.Decimal(x, number.Scale(digits)))
+}
+
+// DateTime formats a date and time in the locale's date order. The year is
+// left out when withYear is false.
+func (l Locale) DateTime(t time.Time, withYear bool) string {
+	return t.Format(l.dateLayout(withYear) + " 15:04")
+}
+
+// Date formats a date in the locale's date order, like DateTime without
+// the time
+func (l Locale) Date(t time.Time, withYear bool) string {
+	return t.Format(l.dateLayout(withYear))
+}
+
+// dateLayout returns the time package layout of a date in the locale's order
+func (l Locale) dateLayout(withYear bool) string {
+	english := isEnglish(l.tag)
+	switch {
+	case l.order == orderMDY && withYear:
+		return "Jan 2, 2006"
+	case l.order == orderMDY:
+		return "Jan 2"
+	case l.order == orderYMD && withYear:
+		return "2006-01-02"
+	case l.order == orderYMD:
+		return "01-02"
+	case english && withYear:
+		return "2 Jan 2006"
+	case english:
+		return "2 Jan"
+	case withYear:
+		return "02.01.2006"
+	default:
+		return "02.01."
+	}
+}
+
+// isEnglish reports whether month names from the time package fit the locale
+func isEnglish(tag language.Tag) bool {
+	base, _ := tag.Base()
+	return base.String() == "en"
+}
@@ -0,0 +1,59 @@
+package locale
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-US", "1,234,567"},
+		{"de_DE.UTF-8", "1.234.567"},
+		{"C", "1,234,567"},
+		{"not a locale", "1,234,567"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.locale).Count(1234567); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.locale, tt.want, got)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	if got := New("en-US").Decimal(1.25, 1); got != "1.2" && got != "1.3" {
+		t.Errorf("en-US: unexpected %q", got)
+	}
+	if got := New("de-DE").Decimal(1.5, 1); got != "1,5" {
+		t.Errorf("de-DE: expected 1,5, got %q", got)
+	}
+	if got := New("en-US").Decimal(3, 1); got != "3.0" {
+		t.Errorf("expected trailing zero kept, got %q", got)
+	}
+}
+
+func TestDateTime(t *testing.T) {
+	date := time.Date(2024, 3, 7, 14, 5, 0, 0, time.UTC)
+	tests := []struct {
+		locale   string
+		withYear bool
+		want     string
+	}{
+		{"en-US", true, "Mar 7, 2024 14:05"},
+		{"en-US", false, "Mar 7 14:05"},
+		{"en-GB", true, "7 Mar 2024 14:05"},
+		{"de-DE", true, "07.03.2024 14:05"},
+		{"ja-JP", true, "2024-03-07 14:05"},
+		{"sv_SE", false, "03-07 14:05"},
+	}
+	for _, tt := range tests {
+		if got := New(tt.locale).DateTime(date, tt.withYear); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.locale, tt.want, got)
+		}
+	}
+	if got := New("de-DE").Date(date, false); got != "07.03." {
+		t.Errorf("date alone: expected %q, got %q", "07.03.", got)
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+const day = 24 * time.Hour
+
+// AgeThreshold is an age that files are bucketed by
+type AgeThreshold struct {
+	Label string
+	Age   time.Duration
+}
+
+// AgeThresholds are the buckets reported by AgeUsage, youngest first
+var AgeThresholds = [...]AgeThreshold{
+	{Label: "30d", Age: 30 * day},
+	{Label: "6m", Age: 182 * day},
+	{Label: "1y", Age: 365 * day},
+}
+
+// AgeUsage is how much of a subtree has not been modified for a while
+type AgeUsage struct {
+	Total   int64                     // Bytes in files considered
+	Stale   [len(AgeThresholds)]int64 // Bytes not modified within each threshold
+	Unknown int64                     // Bytes in files without a modification time
+}
+
+// AgeUsage buckets the files of the subtree by how long ago they were
+// modified, relative to now. Deleted and estimated nodes are left out.
+func (n *Node) AgeUsage(now time.Time) AgeUsage {
+	var u AgeUsage
+	for node := range n.Files() {
+		u.Total += node.Size
+		if node.ModTime == 0 {
+			u.Unknown += node.Size
+			continue
+		}
+		age := now.Sub(node.Modified())
+		for i, t := range AgeThresholds {
+			if age >= t.Age {
+				u.Stale[i] += node.Size
+			}
+		}
+	}
+	return u
+}
+
+// Oldest returns the oldest threshold that some bytes are past, or false
+// when everything was modified recently
+func (u AgeUsage) Oldest() (AgeThreshold, int64, bool) {
+	for i := len(AgeThresholds) - 1; i >= 0; i-- {
+		if u.Stale[i] > 0 {
+			return AgeThresholds[i], u.Stale[i], true
+		}
+	}
+	return AgeThreshold{}, 0, false
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeUsage(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	file := func(name string, size int64, age time.Duration) *Node {
+		return &Node{Name: name, Size: size, ModTime: now.Add(-age).Unix()}
+	}
+	root := &Node{Name: "r", IsDir: true, Children: []*Node{
+		file("fresh", 1, time.Hour),
+		file("month", 10, 40*day),
+		file("half", 100, 200*day),
+		&Node{Name: "sub", IsDir: true, Children: []*Node{file("ancient", 1000, 800*day)}},
+		{Name: "nomtime", Size: 5},
+	}}
+
+	u := root.AgeUsage(now)
+	if u.Total != 1116 || u.Unknown != 5 {
+		t.Errorf("expected total 1116 and unknown 5, got %+v", u)
+	}
+	want := [len(AgeThresholds)]int64{1110, 1100, 1000}
+	if u.Stale != want {
+		t.Errorf("expected stale %v, got %v", want, u.Stale)
+	}
+
+	oldest, bytes, ok := u.Oldest()
+	if !ok || oldest.Label != "1y" || bytes != 1000 {
+		t.Errorf("expected 1000 bytes past 1y, got %s %d %v", oldest.Label, bytes, ok)
+	}
+	if _, _, ok := (AgeUsage{}).Oldest(); ok {
+		t.Error("expected nothing stale in an empty usage")
+	}
+}
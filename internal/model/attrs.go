@@ -0,0 +1,32 @@
+package model
+
+// NodeAttr flags special filesystem properties of a scanned entry
+type NodeAttr uint16
+
+const (
+	AttrSymlink    NodeAttr = 1 << iota // Symbolic link
+	AttrJunction                        // NTFS junction (directory mount point)
+	AttrCloud                           // Cloud placeholder, e.g. OneDrive online-only
+	AttrReparse                         // Other reparse point
+	AttrVirtual                         // Estimated space with no file behind it
+	AttrGrouped                         // Several small files folded into one node
+	AttrSparse                          // Unwritten ranges take no space on disk
+	AttrCompressed                      // Compressed by the filesystem
+	AttrHidden                          // Hidden: a dotfile, or flagged hidden or system on Windows
+)
+
+// Has reports whether all bits of flag are set
+func (a NodeAttr) Has(flag NodeAttr) bool {
+	return a&flag == flag
+}
+
+// IsLink reports whether the entry points somewhere else (symlink or junction)
+func (a NodeAttr) IsLink() bool {
+	return a&(AttrSymlink|AttrJunction) != 0
+}
+
+// IsSynthetic reports whether the node stands for no single file on disk
+// (estimated space or grouped small files), so it cannot be opened or moved
+func (a NodeAttr) IsSynthetic() bool {
+	return a&(AttrVirtual|AttrGrouped) != 0
+}
@@ -0,0 +1,195 @@
+package model
+
+import "strings"
+
+// Category groups files by what they are, for coloring, filtering and breakdowns
+type Category uint8
+
+const (
+	CategoryOther Category = iota
+	CategoryVideo
+	CategoryImage
+	CategoryAudio
+	CategoryCode
+	CategoryArchive
+	CategoryInstaller
+)
+
+// Categories lists all categories in display order
+var Categories = []Category{
+	CategoryVideo,
+	CategoryImage,
+	CategoryAudio,
+	CategoryCode,
+	CategoryArchive,
+	CategoryInstaller,
+	CategoryOther,
+}
+
+// String returns the category name
+func (c Category) String() string {
+	switch c {
+	case CategoryVideo:
+		return "video"
+	case CategoryImage:
+		return "image"
+	case CategoryAudio:
+		return "audio"
+	case CategoryCode:
+		return "code"
+	case CategoryArchive:
+		return "archive"
+	case CategoryInstaller:
+		return "installer"
+	default:
+		return "other"
+	}
+}
+
+// categoryByExt maps lowercase extensions to categories
+var categoryByExt = map[string]Category{}
+
+func init() {
+	register := func(c Category, exts ...string) {
+		for _, ext := range exts {
+			categoryByExt[ext] = c
+		}
+	}
+	register(CategoryVideo, ".mp4", ".mkv", ".mov", ".avi", ".wmv", ".webm", ".m4v", ".flv", ".mpg", ".mpeg", ".3gp", ".vob")
+	register(CategoryImage, ".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tif", ".tiff", ".webp", ".heic", ".heif", ".raw", ".cr2", ".nef", ".arw", ".dng", ".psd", ".svg", ".ico")
+	register(CategoryAudio, ".mp3", ".wav", ".flac", ".aac", ".m4a", ".ogg", ".opus", ".wma", ".aiff", ".alac", ".mid")
+	register(CategoryCode, ".go", ".c", ".h", ".cc", ".cpp", ".hpp", ".rs", ".py", ".js", ".mjs", ".ts", ".tsx", ".jsx", ".java", ".kt", ".swift", ".m", ".rb", ".php", ".cs", ".sh", ".ps1", ".lua", ".sql", ".html", ".css", ".scss", ".json", ".yaml", ".yml", ".toml", ".xml", ".md", ".o", ".a", ".pyc", ".class", ".jar", ".wasm")
+	register(CategoryArchive, ".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".7z", ".rar", ".lz4", ".iso", ".img")
+	register(CategoryInstaller, ".dmg", ".pkg", ".msi", ".exe", ".deb", ".rpm", ".apk", ".appimage", ".msix", ".appx", ".snap", ".flatpak")
+}
+
+// CategoryOf classifies a file by its name's extension
+func CategoryOf(name string) Category {
+	return categoryByExt[Extension(name)]
+}
+
+// CategoryFromMIME classifies a file by its detected MIME type, for files
+// whose extension says nothing
+func CategoryFromMIME(mime string) Category {
+	switch {
+	case strings.HasPrefix(mime, "video/"):
+		return CategoryVideo
+	case strings.HasPrefix(mime, "image/"):
+		return CategoryImage
+	case strings.HasPrefix(mime, "audio/"):
+		return CategoryAudio
+	case strings.HasPrefix(mime, "text/x-"), mime == "application/x-executable", mime == "application/x-mach-binary":
+		return CategoryCode
+	case strings.Contains(mime, "zip"), strings.Contains(mime, "compressed"), strings.Contains(mime, "tar"),
+		mime == "application/gzip", mime == "application/x-xz", mime == "application/zstd":
+		return CategoryArchive
+	case mime == "application/x-msi", mime == "application/vnd.debian.binary-package", mime == "application/x-rpm",
+		mime == "application/x-apple-diskimage":
+		return CategoryInstaller
+	}
+	return CategoryOther
+}
+
+// categoryCount is the number of categories, for arrays indexed by Category
+const categoryCount = int(CategoryInstaller) + 1
+
+// CategorySizes holds bytes per category, indexed by Category
+type CategorySizes [categoryCount]int64
+
+// Sum returns the bytes in the categories of set
+func (s CategorySizes) Sum(set CategorySet) int64 {
+	var total int64
+	for c, bytes := range s {
+		if set.Has(Category(c)) {
+			total += bytes
+		}
+	}
+	return total
+}
+
+// Total returns the bytes across all categories
+func (s CategorySizes) Total() int64 {
+	var total int64
+	for _, bytes := range s {
+		total += bytes
+	}
+	return total
+}
+
+// CategorySet is a set of categories, used to filter by kind of file
+type CategorySet uint16
+
+// MediaCategories holds video, images and audio
+var MediaCategories = NewCategorySet(CategoryVideo, CategoryImage, CategoryAudio)
+
+// NewCategorySet returns the set of the given categories
+func NewCategorySet(categories ...Category) CategorySet {
+	var set CategorySet
+	for _, c := range categories {
+		set |= 1 << c
+	}
+	return set
+}
+
+// Has reports whether c is in the set
+func (s CategorySet) Has(c Category) bool {
+	return s&(1<<c) != 0
+}
+
+// CategorySizes returns the bytes of each category in the node's subtree.
+// A file counts its own size; directories leave out deleted items.
+func (n *Node) CategorySizes() CategorySizes {
+	if !n.IsDir {
+		var s CategorySizes
+		s[n.Category] = n.Size
+		return s
+	}
+	if n.categories == nil {
+		return CategorySizes{}
+	}
+	return *n.categories
+}
+
+// HasCategory reports whether the subtree holds any bytes in set
+func (n *Node) HasCategory(set CategorySet) bool {
+	return n.CategorySizes().Sum(set) > 0
+}
+
+// categoryShare returns what the node adds to its parent's breakdown
+func (n *Node) categoryShare() CategorySizes {
+	if n.IsDeleted {
+		return CategorySizes{}
+	}
+	return n.CategorySizes()
+}
+
+// sumCategories rebuilds a directory's breakdown from its children
+func (n *Node) sumCategories() {
+	if !n.IsDir {
+		return
+	}
+	var s CategorySizes
+	for _, child := range n.Children {
+		share := child.categoryShare()
+		for c := range s {
+			s[c] += share[c]
+		}
+	}
+	n.categories = &s
+}
+
+// shiftCategories adds sign times s to the breakdown of n and its ancestors.
+// It stops at a deleted node, whose ancestors no longer count its bytes.
+func (n *Node) shiftCategories(s CategorySizes, sign int64) {
+	for node := n; node != nil; node = node.Parent {
+		if node.categories == nil {
+			node.categories = &CategorySizes{}
+		}
+		for c := range s {
+			node.categories[c] += sign * s[c]
+		}
+		if node.IsDeleted {
+			break
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package model
+
+import "testing"
+
+func TestCategoryOf(t *testing.T) {
+	tests := map[string]Category{
+		"movie.MKV":     CategoryVideo,
+		"photo.jpeg":    CategoryImage,
+		"song.flac":     CategoryAudio,
+		"main.go":       CategoryCode,
+		"backup.tar.gz": CategoryArchive,
+		"setup.msi":     CategoryInstaller,
+		"notes.txt":     CategoryOther,
+		"Makefile":      CategoryOther,
+		".bashrc":       CategoryOther,
+	}
+	for name, want := range tests {
+		if got := CategoryOf(name); got != want {
+			t.Errorf("CategoryOf(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestCategoryFromMIME(t *testing.T) {
+	tests := map[string]Category{
+		"video/mp4":       CategoryVideo,
+		"image/png":       CategoryImage,
+		"audio/mpeg":      CategoryAudio,
+		"application/zip": CategoryArchive,
+		"text/plain":      CategoryOther,
+	}
+	for mime, want := range tests {
+		if got := CategoryFromMIME(mime); got != want {
+			t.Errorf("CategoryFromMIME(%q) = %s, want %s", mime, got, want)
+		}
+	}
+}
+
+func TestCategorySizes(t *testing.T) {
+	movie := &Node{Name: "movie.mp4", Size: 700, Category: CategoryVideo}
+	photo := &Node{Name: "photo.jpg", Size: 200, Category: CategoryImage}
+	code := &Node{Name: "main.go", Size: 100, Category: CategoryCode}
+	media := &Node{Name: "media", IsDir: true, Children: []*Node{movie, photo}}
+	root := &Node{Name: "root", IsDir: true, Children: []*Node{media, code}}
+	root.RebuildParentLinks()
+	root.ComputeSizes()
+
+	s := root.CategorySizes()
+	if s[CategoryVideo] != 700 || s[CategoryImage] != 200 || s[CategoryCode] != 100 {
+		t.Fatalf("unexpected breakdown %v", s)
+	}
+	if got := s.Sum(MediaCategories); got != 900 {
+		t.Errorf("expected 900 media bytes, got %d", got)
+	}
+	if s.Total() != root.TotalSize() {
+		t.Errorf("breakdown total %d differs from size %d", s.Total(), root.TotalSize())
+	}
+
+	song := &Node{Name: "song.mp3", Size: 50, Category: CategoryAudio}
+	media.AddChild(song)
+	if got := root.CategorySizes()[CategoryAudio]; got != 50 {
+		t.Errorf("expected 50 audio bytes after add, got %d", got)
+	}
+
+	media.MarkDeleted()
+	if root.HasCategory(MediaCategories) {
+		t.Errorf("deleted media still counted: %v", root.CategorySizes())
+	}
+	// Deleting inside a deleted folder must not subtract twice
+	movie.MarkDeleted()
+	if got := root.CategorySizes()[CategoryCode]; got != 100 || root.CategorySizes().Total() != 100 {
+		t.Errorf("expected only 100 code bytes, got %v", root.CategorySizes())
+	}
+
+	root.RemoveChild(code)
+	if root.CategorySizes().Total() != 0 {
+		t.Errorf("expected empty breakdown, got %v", root.CategorySizes())
+	}
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// Change is a path whose size differs between two scans
+type Change struct {
+	Path   string `json:"path"`
+	Dir    bool   `json:"dir,omitempty"`
+	Before int64  `json:"before"` // bytes in the earlier scan, 0 if the path is new
+	After  int64  `json:"after"`  // bytes now, 0 if the path is gone
+}
+
+// Delta returns how many bytes the path grew, negative if it shrank
+func (c Change) Delta() int64 {
+	return c.After - c.Before
+}
+
+// Changes lists the paths under two scans of the same folder whose size
+// moved by at least min bytes, folders before their contents. Paths only
+// one scan has count as 0 bytes in the other. Deleted nodes and estimated
+// system space are left out, as in exports.
+func Changes(before, after *Node, min int64) []Change {
+	var changes []Change
+	collectChanges(before, after, after.Path(), max(min, 1), &changes)
+	return changes
+}
+
+// collectChanges compares one path in both scans; either node may be nil
+func collectChanges(before, after *Node, path string, min int64, changes *[]Change) {
+	c := Change{Path: path}
+	if before != nil {
+		c.Before, c.Dir = diskSize(before), before.IsDir
+	}
+	if after != nil {
+		c.After, c.Dir = diskSize(after), after.IsDir
+	}
+	if d := c.Delta(); d >= min || -d >= min {
+		*changes = append(*changes, c)
+	}
+
+	// Pair children by name, in the order of the newer scan
+	old := onDisk(before)
+	earlier := make(map[string]*Node, len(old))
+	for _, child := range old {
+		earlier[child.Name] = child
+	}
+	for _, child := range onDisk(after) {
+		collectChanges(earlier[child.Name], child, filepath.Join(path, child.Name), min, changes)
+		delete(earlier, child.Name)
+	}
+	for _, child := range old {
+		if _, gone := earlier[child.Name]; gone {
+			collectChanges(child, nil, filepath.Join(path, child.Name), min, changes)
+		}
+	}
+}
+
+// diskSize returns the bytes below n that are on disk, leaving out deleted
+// nodes and estimated space
+func diskSize(n *Node) int64 {
+	size := n.TotalSize() - n.DeletedSize
+	for _, child := range n.Children {
+		if child.Attrs.Has(AttrVirtual) {
+			size -= child.TotalSize() - child.DeletedSize
+		}
+	}
+	return size
+}
+
+// onDisk returns the children of n that are on disk, none for nil
+func onDisk(n *Node) []*Node {
+	if n == nil {
+		return nil
+	}
+	var on []*Node
+	for _, child := range n.Children {
+		if !NotOnDisk(child) {
+			on = append(on, child)
+		}
+	}
+	return on
+}
+
+// ChangesHeader describes a differential export
+type ChangesHeader struct {
+	Progname  string `json:"progname"`
+	Progver   string `json:"progver"`
+	Path      string `json:"path"`
+	Since     int64  `json:"since"`     // Unix seconds the earlier scan finished
+	Timestamp int64  `json:"timestamp"` // Unix seconds the newer scan finished
+	MinChange int64  `json:"minChange"` // Smallest change listed, in bytes
+}
+
+// NewChangesHeader returns the header for a differential export by this
+// diskdive version
+func NewChangesHeader(version, path string, since, scannedAt time.Time, min int64) ChangesHeader {
+	return ChangesHeader{
+		Progname:  "diskdive",
+		Progver:   version,
+		Path:      path,
+		Since:     since.Unix(),
+		Timestamp: scannedAt.Unix(),
+		MinChange: min,
+	}
+}
+
+// WriteChanges writes a differential export as JSON lines: the header on
+// the first line, then one change per line
+func WriteChanges(w io.Writer, header ChangesHeader, changes []Change) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err := enc.Encode(c); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
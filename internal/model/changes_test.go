@@ -0,0 +1,92 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	build := func(files map[string]int64) *Node {
+		root := &Node{Name: "data", IsDir: true}
+		root.SetPath(filepath.Join("/", "data"))
+		dirs := map[string]*Node{}
+		for name, size := range files {
+			dir, file := filepath.Split(filepath.FromSlash(name))
+			parent := root
+			if dir != "" {
+				dir = filepath.Clean(dir)
+				if dirs[dir] == nil {
+					dirs[dir] = &Node{Name: dir, IsDir: true}
+					root.AddChild(dirs[dir])
+				}
+				parent = dirs[dir]
+			}
+			parent.AddChild(&Node{Name: file, Size: size})
+		}
+		root.ComputeSizes()
+		return root
+	}
+	before := build(map[string]int64{"logs/a.log": 1000, "logs/b.log": 10, "old.iso": 5000, "same.txt": 700})
+	after := build(map[string]int64{"logs/a.log": 3000, "logs/b.log": 20, "new.bin": 4000, "same.txt": 700})
+	after.AddChild(&Node{Name: "[System]", Size: 9999, Attrs: AttrVirtual})
+
+	got := map[string]Change{}
+	for _, c := range Changes(before, after, 100) {
+		rel, _ := filepath.Rel(after.Path(), c.Path)
+		got[filepath.ToSlash(rel)] = c
+	}
+
+	want := map[string]Change{
+		".":          {Dir: true, Before: 6710, After: 7720},
+		"logs":       {Dir: true, Before: 1010, After: 3020},
+		"logs/a.log": {Before: 1000, After: 3000},
+		"new.bin":    {After: 4000},
+		"old.iso":    {Before: 5000},
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d changes, got %v", len(want), got)
+	}
+	for path, w := range want {
+		c, ok := got[path]
+		if !ok {
+			t.Errorf("missing change for %s", path)
+			continue
+		}
+		if c.Dir != w.Dir || c.Before != w.Before || c.After != w.After {
+			t.Errorf("%s: expected %+v, got %+v", path, w, c)
+		}
+	}
+	if got["old.iso"].Delta() != -5000 {
+		t.Errorf("expected old.iso to shrink by 5000, got %d", got["old.iso"].Delta())
+	}
+}
+
+func TestWriteChanges(t *testing.T) {
+	header := NewChangesHeader("1.0", "/data", time.Unix(1700000000, 0), time.Unix(1700086400, 0), 1<<20)
+	changes := []Change{{Path: "/data", Dir: true, Before: 10, After: 20}, {Path: "/data/x", After: 10}}
+
+	var buf bytes.Buffer
+	if err := WriteChanges(&buf, header, changes); err != nil {
+		t.Fatal(err)
+	}
+
+	sc := bufio.NewScanner(&buf)
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 changes, got %q", lines)
+	}
+	var h ChangesHeader
+	if err := json.Unmarshal([]byte(lines[0]), &h); err != nil || h.Since != 1700000000 || h.MinChange != 1<<20 {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if lines[2] != `{"path":"/data/x","before":0,"after":10}` {
+		t.Errorf("unexpected change line %s", lines[2])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 )
 
 // Drive represents a mounted drive/volume
@@ -13,6 +14,80 @@ type Drive struct {
 	Label      string // volume label
 	TotalBytes int64
 	FreeBytes  int64
+
+	// Filesystem metadata, left empty where the platform cannot tell
+	FSType   string    // e.g. "apfs", "ext4", "NTFS"
+	Device   string    // backing device, e.g. "/dev/disk3s1"
+	ReadOnly bool      // mounted read-only, so nothing can be deleted
+	Kind     DriveKind // how the drive is attached
+}
+
+// DriveKind classifies how a drive is attached to the machine
+type DriveKind int
+
+const (
+	DriveFixed     DriveKind = iota // internal disk
+	DriveRemovable                  // USB stick, SD card, optical disc
+	DriveNetwork                    // network share
+)
+
+// String returns a short lowercase name for the kind
+func (k DriveKind) String() string {
+	switch k {
+	case DriveRemovable:
+		return "removable"
+	case DriveNetwork:
+		return "network"
+	default:
+		return "fixed"
+	}
+}
+
+// networkFilesystems lists filesystem types that live on another machine
+var networkFilesystems = []string{"smbfs", "smb3", "nfs", "nfs4", "afpfs", "webdav", "cifs", "9p", "fuse.sshfs"}
+
+// IsNetworkFilesystem reports whether fsType names a network filesystem
+func IsNetworkFilesystem(fsType string) bool {
+	for _, nfs := range networkFilesystems {
+		if strings.EqualFold(fsType, nfs) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountEntry is one line of a Linux mount table
+type mountEntry struct {
+	device   string
+	dir      string
+	fsType   string
+	readOnly bool
+}
+
+// findMount returns the entry in table, in /proc/self/mounts format, that
+// dir is mounted from. Later lines win, as they mount over earlier ones.
+func findMount(table, dir string) (mountEntry, bool) {
+	var found mountEntry
+	ok := false
+	for _, line := range strings.Split(table, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		// Spaces and tabs in paths are written as octal escapes
+		mountDir := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\134`, `\`).Replace(fields[1])
+		if mountDir != dir {
+			continue
+		}
+		found = mountEntry{device: fields[0], dir: mountDir, fsType: fields[2]}
+		for _, opt := range strings.Split(fields[3], ",") {
+			if opt == "ro" {
+				found.readOnly = true
+			}
+		}
+		ok = true
+	}
+	return found, ok
 }
 
 // UsedBytes returns bytes used on this drive
@@ -8,6 +8,13 @@ import (
 	"syscall"
 )
 
+// Mount flags from <sys/mount.h>, which package syscall does not export
+const (
+	mntReadOnly  = 0x00000001
+	mntRemovable = 0x00000200
+	mntLocal     = 0x00001000
+)
+
 // GetDiskSpace returns disk space information for a given path using statfs
 func GetDiskSpace(path string) (total, free int64) {
 	var stat syscall.Statfs_t
@@ -21,6 +28,17 @@ func GetDiskSpace(path string) (total, free int64) {
 	return total, free
 }
 
+// GetReservedSpace returns the free blocks statfs counts beyond those open
+// to unprivileged users, which count as used but hold no files. HFS+ keeps
+// such a reserve; APFS volumes usually report none.
+func GetReservedSpace(path string) int64 {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0
+	}
+	return int64(stat.Bfree-stat.Bavail) * int64(stat.Bsize)
+}
+
 func getPlatformDrives() ([]Drive, error) {
 	var drives []Drive
 
@@ -31,6 +49,14 @@ func getPlatformDrives() ([]Drive, error) {
 		Label:  "Macintosh HD",
 	}
 	rootDrive.TotalBytes, rootDrive.FreeBytes = GetDiskSpace("/")
+	// The sealed system volume at / is read-only; user files live on the
+	// data volume that firmlinks splice into it
+	var rootStat syscall.Statfs_t
+	if err := syscall.Statfs("/System/Volumes/Data", &rootStat); err == nil {
+		describeVolume(&rootDrive, &rootStat)
+	} else if err := syscall.Statfs("/", &rootStat); err == nil {
+		describeVolume(&rootDrive, &rootStat)
+	}
 	drives = append(drives, rootDrive)
 
 	// Scan /Volumes for mounted drives
@@ -67,6 +93,7 @@ func getPlatformDrives() ([]Drive, error) {
 			Label:  entry.Name(),
 		}
 		drive.TotalBytes, drive.FreeBytes = GetDiskSpace(volumePath)
+		describeVolume(&drive, &stat)
 
 		// Only add if we got valid disk space info
 		if drive.TotalBytes > 0 {
@@ -77,6 +104,19 @@ func getPlatformDrives() ([]Drive, error) {
 	return drives, nil
 }
 
+// describeVolume fills in filesystem metadata from a statfs result
+func describeVolume(d *Drive, stat *syscall.Statfs_t) {
+	d.FSType = int8ArrayToString(stat.Fstypename[:])
+	d.Device = int8ArrayToString(stat.Mntfromname[:])
+	d.ReadOnly = stat.Flags&mntReadOnly != 0
+	switch {
+	case stat.Flags&mntLocal == 0 || IsNetworkFilesystem(d.FSType):
+		d.Kind = DriveNetwork
+	case stat.Flags&mntRemovable != 0:
+		d.Kind = DriveRemovable
+	}
+}
+
 // int8ArrayToString converts an int8 array to a string
 func int8ArrayToString(arr []int8) string {
 	b := make([]byte, 0, len(arr))
@@ -92,11 +132,8 @@ func int8ArrayToString(arr []int8) string {
 // isFilteredFilesystem returns true if the filesystem type should be filtered out
 func isFilteredFilesystem(fsType string) bool {
 	// Network filesystems
-	networkFS := []string{"smbfs", "nfs", "afpfs", "webdav", "cifs"}
-	for _, nfs := range networkFS {
-		if fsType == nfs {
-			return true
-		}
+	if IsNetworkFilesystem(fsType) {
+		return true
 	}
 
 	// Pseudo filesystems
@@ -2,10 +2,62 @@
 
 package model
 
-import "syscall"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
 
 func getPlatformDrives() ([]Drive, error) {
-	return getUnixMounts()
+	drives, err := getUnixMounts()
+	for i := range drives {
+		describeMount(&drives[i])
+	}
+	return drives, err
+}
+
+// describeMount fills in filesystem metadata from the Linux mount table;
+// elsewhere the table is missing and the drive is left as it is
+func describeMount(d *Drive) {
+	table, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return
+	}
+	m, ok := findMount(string(table), d.Path)
+	if !ok {
+		return
+	}
+	d.FSType = m.fsType
+	d.Device = m.device
+	d.ReadOnly = m.readOnly
+	switch {
+	case IsNetworkFilesystem(m.fsType):
+		d.Kind = DriveNetwork
+	case isRemovableDevice(m.device):
+		d.Kind = DriveRemovable
+	}
+}
+
+// isRemovableDevice reports whether the kernel flags the block device, or
+// the disk holding the partition, as removable
+func isRemovableDevice(device string) bool {
+	if !strings.HasPrefix(device, "/dev/") {
+		return false
+	}
+	dev, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		return false
+	}
+	sys, err := filepath.EvalSymlinks(filepath.Join("/sys/class/block", filepath.Base(dev)))
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(sys, "partition")); err == nil {
+		sys = filepath.Dir(sys)
+	}
+	data, err := os.ReadFile(filepath.Join(sys, "removable"))
+	return err == nil && strings.TrimSpace(string(data)) == "1"
 }
 
 // GetDiskSpace returns disk space information for a given path using statfs
@@ -18,3 +70,13 @@ func GetDiskSpace(path string) (total, free int64) {
 	free = int64(stat.Bavail) * int64(stat.Bsize)
 	return total, free
 }
+
+// GetReservedSpace returns free space held back from unprivileged users
+// (e.g. ext4 root-reserved blocks), which counts as used but holds no files
+func GetReservedSpace(path string) int64 {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0
+	}
+	return int64(stat.Bfree-stat.Bavail) * int64(stat.Bsize)
+}
@@ -31,3 +31,34 @@ func TestGetDrives(t *testing.T) {
 		t.Error("expected C: drive to exist")
 	}
 }
+
+func TestFindMount(t *testing.T) {
+	table := `sysfs /sys sysfs rw,nosuid 0 0
+/dev/sda2 / ext4 rw,relatime 0 0
+/dev/sdb1 /media/usb\040stick vfat ro,nosuid 0 0
+overlay / overlay ro,relatime 0 0
+server:/export /mnt/share nfs4 rw 0 0
+`
+
+	m, ok := findMount(table, "/")
+	if !ok || m.device != "overlay" || m.fsType != "overlay" || !m.readOnly {
+		t.Errorf("expected the later overlay mount on /, got %+v", m)
+	}
+
+	m, ok = findMount(table, "/media/usb stick")
+	if !ok || m.device != "/dev/sdb1" || m.fsType != "vfat" {
+		t.Errorf("expected escaped mount point to match, got %+v", m)
+	}
+
+	if _, ok := findMount(table, "/home"); ok {
+		t.Error("expected no mount for /home")
+	}
+
+	m, _ = findMount(table, "/mnt/share")
+	if !IsNetworkFilesystem(m.fsType) {
+		t.Errorf("expected %s to be a network filesystem", m.fsType)
+	}
+	if IsNetworkFilesystem("ext4") {
+		t.Error("ext4 is not a network filesystem")
+	}
+}
@@ -13,7 +13,11 @@ var (
 )
 
 func getPlatformDrives() ([]Drive, error) {
-	return getWindowsDrives()
+	drives, err := getWindowsDrives()
+	for i := range drives {
+		describeVolume(&drives[i])
+	}
+	return drives, err
 }
 
 // GetDiskSpace returns disk space information for a given path
@@ -35,3 +39,84 @@ func GetDiskSpace(path string) (total, free int64) {
 
 	return totalBytes, freeBytesAvailable
 }
+
+// GetReservedSpace returns free space withheld from the current user by
+// disk quotas, which counts as used but holds none of their files
+func GetReservedSpace(path string) int64 {
+	pathPtr, _ := syscall.UTF16PtrFromString(path)
+
+	var freeBytesAvailable, totalBytes, totalFreeBytes int64
+
+	ret, _, _ := getDiskFreeSpaceExW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&freeBytesAvailable)),
+		uintptr(unsafe.Pointer(&totalBytes)),
+		uintptr(unsafe.Pointer(&totalFreeBytes)),
+	)
+
+	if ret == 0 {
+		return 0
+	}
+
+	return totalFreeBytes - freeBytesAvailable
+}
+
+var (
+	getDriveTypeW         = kernel32.NewProc("GetDriveTypeW")
+	getVolumeInformationW = kernel32.NewProc("GetVolumeInformationW")
+	queryDosDeviceW       = kernel32.NewProc("QueryDosDeviceW")
+)
+
+// Values from GetDriveTypeW and GetVolumeInformationW
+const (
+	driveRemovable     = 2
+	driveRemote        = 4
+	driveCDROM         = 5
+	fileReadOnlyVolume = 0x00080000
+)
+
+// describeVolume fills in the label and filesystem metadata of a drive
+func describeVolume(d *Drive) {
+	pathPtr, err := syscall.UTF16PtrFromString(d.Path)
+	if err != nil {
+		return
+	}
+
+	switch kind, _, _ := getDriveTypeW.Call(uintptr(unsafe.Pointer(pathPtr))); kind {
+	case driveRemovable, driveCDROM:
+		d.Kind = DriveRemovable
+	case driveRemote:
+		d.Kind = DriveNetwork
+	}
+
+	var label, fsName [syscall.MAX_PATH + 1]uint16
+	var flags uint32
+	ret, _, _ := getVolumeInformationW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&label[0])),
+		uintptr(len(label)),
+		0, 0,
+		uintptr(unsafe.Pointer(&flags)),
+		uintptr(unsafe.Pointer(&fsName[0])),
+		uintptr(len(fsName)),
+	)
+	if ret != 0 {
+		d.Label = syscall.UTF16ToString(label[:])
+		d.FSType = syscall.UTF16ToString(fsName[:])
+		d.ReadOnly = flags&fileReadOnlyVolume != 0
+	}
+
+	// QueryDosDeviceW takes the drive without its trailing backslash
+	namePtr, err := syscall.UTF16PtrFromString(d.Letter + ":")
+	if err != nil {
+		return
+	}
+	var device [syscall.MAX_PATH + 1]uint16
+	if ret, _, _ := queryDosDeviceW.Call(
+		uintptr(unsafe.Pointer(namePtr)),
+		uintptr(unsafe.Pointer(&device[0])),
+		uintptr(len(device)),
+	); ret != 0 {
+		d.Device = syscall.UTF16ToString(device[:])
+	}
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ReadDu builds a tree from the output of du -ab: one line per file and
+// folder holding a size in bytes, whitespace and a path, each folder after
+// its contents. The last line names the root. Relative paths, as printed by
+// du -ab ., are taken relative to dir.
+//
+// du does not say which entries are folders, so an entry counts as one when
+// others lie below it; empty folders come back as empty files. Folder sizes
+// are summed from their contents again by ComputeSizes, leaving out what du
+// counts for the folder entries themselves.
+func ReadDu(r io.Reader, dir string) (*Node, error) {
+	type entry struct {
+		path string
+		size int64
+	}
+	var entries []entry
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimRight(sc.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		i := strings.IndexAny(text, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: want a size and a path, got %q", line, text)
+		}
+		size, err := strconv.ParseInt(text[:i], 10, 64)
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("line %d: invalid size %q", line, text[:i])
+		}
+		path := strings.TrimLeft(text[i:], " \t")
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		entries = append(entries, entry{path: filepath.Clean(path), size: size})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no du output to read")
+	}
+
+	rootPath := entries[len(entries)-1].path
+	root := &Node{Name: filepath.Base(rootPath), IsDir: true, UID: NoOwner, GID: NoOwner}
+	root.SetPath(rootPath)
+	nodes := map[string]*Node{rootPath: root}
+
+	// node returns the node at path, adding it and any missing folders above it
+	var node func(path string) *Node
+	node = func(path string) *Node {
+		if n, ok := nodes[path]; ok {
+			return n
+		}
+		n := &Node{Name: filepath.Base(path), UID: NoOwner, GID: NoOwner}
+		parent := node(filepath.Dir(path))
+		parent.IsDir = true
+		parent.AddChild(n)
+		nodes[path] = n
+		return n
+	}
+	for _, e := range entries[:len(entries)-1] {
+		if _, ok := relNames(rootPath, e.path); !ok || e.path == rootPath {
+			return nil, fmt.Errorf("%s is not below %s, the last path listed", e.path, rootPath)
+		}
+		n := node(e.path)
+		n.Size = e.size
+		n.Logical = e.size
+	}
+
+	// What was read as a size for folders is recomputed from their contents
+	for _, n := range nodes {
+		if n.IsDir {
+			n.Size, n.Logical = 0, 0
+		} else {
+			n.Category = CategoryOf(n.Name)
+		}
+	}
+	root.ComputeSizes()
+	return root, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadDu(t *testing.T) {
+	out := strings.Join([]string{
+		"100\t./docs/a.txt",
+		"250\t./docs/b c.txt",
+		"4096\t./docs/empty",
+		"8542\t./docs",
+		"7\t./x/y/z.bin",
+		"12745\t.",
+	}, "\n")
+	base := filepath.Join(string(filepath.Separator), "srv", "data")
+	root, err := ReadDu(strings.NewReader(out), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Path() != base || !root.IsDir {
+		t.Fatalf("expected the root at %s, got %s", base, root.Path())
+	}
+
+	// Folders are summed from their contents, not from what du counted for them
+	if root.TotalSize() != 100+250+4096+7 {
+		t.Errorf("expected %d bytes, got %d", 100+250+4096+7, root.TotalSize())
+	}
+	docs := root.Find(filepath.Join(base, "docs"))
+	if docs == nil || !docs.IsDir || docs.TotalSize() != 4446 {
+		t.Fatalf("unexpected docs: %+v", docs)
+	}
+	if f := root.Find(filepath.Join(base, "docs", "b c.txt")); f == nil || f.Size != 250 || f.IsDir {
+		t.Errorf("expected a 250 byte file with a space in its name, got %+v", f)
+	}
+
+	// Folders du skipped are filled in
+	if y := root.Find(filepath.Join(base, "x", "y")); y == nil || !y.IsDir || y.Parent.Name != "x" {
+		t.Errorf("expected x/y added as folders")
+	}
+}
+
+func TestReadDuErrors(t *testing.T) {
+	for _, out := range []string{
+		"",
+		"abc\t/a",
+		"/a/b",
+		"10\t/b/c\n20\t/a",
+	} {
+		if _, err := ReadDu(strings.NewReader(out), "/"); err == nil {
+			t.Errorf("expected an error for %q", out)
+		}
+	}
+}
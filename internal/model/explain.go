@@ -0,0 +1,55 @@
+package model
+
+// Explanation breaks a directory's total down into the parts that answer
+// "why is this so big". Deleted items are left out of every figure.
+type Explanation struct {
+	Total     int64   // live bytes below the directory
+	Top       []*Node // largest live children, biggest first
+	FileBytes int64   // bytes in files directly inside the directory
+	DirBytes  int64   // bytes in subdirectories
+	NewBytes  int64   // bytes in items that appeared since the scan
+}
+
+// Explain breaks n's size down, listing up to limit of its largest children
+func (n *Node) Explain(limit int) Explanation {
+	e := Explanation{Total: liveSize(n)}
+
+	for _, child := range n.Children {
+		if child.IsDeleted {
+			continue
+		}
+		if child.IsDir {
+			e.DirBytes += liveSize(child)
+		} else {
+			e.FileBytes += child.TotalSize()
+		}
+		e.Top = append(e.Top, child)
+	}
+	SortBySize(e.Top)
+	if len(e.Top) > limit {
+		e.Top = e.Top[:limit]
+	}
+
+	// A new directory is new as a whole, so its contents are not added again
+	skip := func(node *Node) bool {
+		if node.IsDeleted {
+			return true
+		}
+		if node.IsNew && node != n {
+			e.NewBytes += liveSize(node)
+			return true
+		}
+		return false
+	}
+	for range Walk(n, WalkOptions{Skip: skip}) {
+	}
+	if n.IsNew {
+		e.NewBytes = e.Total
+	}
+	return e
+}
+
+// liveSize returns the bytes below n that are not marked deleted
+func liveSize(n *Node) int64 {
+	return n.TotalSize() - n.DeletedSize
+}
@@ -0,0 +1,34 @@
+package model
+
+import "testing"
+
+func TestExplain(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	videos := &Node{Name: "videos", IsDir: true}
+	cache := &Node{Name: "cache", IsDir: true, IsNew: true}
+	root.AddChild(videos)
+	root.AddChild(cache)
+	root.AddChild(&Node{Name: "notes.txt", Size: 10})
+	videos.AddChild(&Node{Name: "a.mp4", Size: 500})
+	videos.AddChild(&Node{Name: "b.mp4", Size: 300, IsNew: true})
+	old := &Node{Name: "old.mp4", Size: 1000}
+	videos.AddChild(old)
+	cache.AddChild(&Node{Name: "blob", Size: 200, IsNew: true})
+	root.ComputeSizes()
+	old.MarkDeleted()
+
+	e := root.Explain(2)
+
+	if e.Total != 1010 {
+		t.Errorf("expected 1010 live bytes, got %d", e.Total)
+	}
+	if e.FileBytes != 10 || e.DirBytes != 1000 {
+		t.Errorf("expected 10 in files and 1000 in folders, got %d and %d", e.FileBytes, e.DirBytes)
+	}
+	if e.NewBytes != 500 {
+		t.Errorf("expected 500 new bytes, got %d", e.NewBytes)
+	}
+	if len(e.Top) != 2 || e.Top[0] != videos || e.Top[1] != cache {
+		t.Errorf("expected videos then cache on top, got %v", e.Top)
+	}
+}
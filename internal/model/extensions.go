@@ -0,0 +1,66 @@
+package model
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExtStat holds the file count and total bytes for one file extension
+type ExtStat struct {
+	Ext   string // lowercase, including the dot ("" for files without one)
+	Count int64
+	Bytes int64
+}
+
+// ExtHistogram aggregates file counts and sizes by extension
+type ExtHistogram map[string]*ExtStat
+
+// Extension returns the normalized extension of a file name.
+// Dotfiles like ".bashrc" are treated as having no extension.
+func Extension(name string) string {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return ""
+	}
+	return strings.ToLower(ext)
+}
+
+// Add records one file in the histogram
+func (h ExtHistogram) Add(name string, size int64) {
+	ext := Extension(name)
+	stat, ok := h[ext]
+	if !ok {
+		stat = &ExtStat{Ext: ext}
+		h[ext] = stat
+	}
+	stat.Count++
+	stat.Bytes += size
+}
+
+// Sorted returns the stats ordered by bytes descending, then by extension
+func (h ExtHistogram) Sorted() []ExtStat {
+	stats := make([]ExtStat, 0, len(h))
+	for _, stat := range h {
+		stats = append(stats, *stat)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Bytes != stats[j].Bytes {
+			return stats[i].Bytes > stats[j].Bytes
+		}
+		return stats[i].Ext < stats[j].Ext
+	})
+	return stats
+}
+
+// BuildExtHistogram builds a histogram from an existing tree (e.g. a loaded snapshot).
+// Deleted nodes are skipped.
+func BuildExtHistogram(root *Node) ExtHistogram {
+	h := make(ExtHistogram)
+	for n := range Walk(root, WalkOptions{Skip: func(n *Node) bool { return n.IsDeleted }}) {
+		if !n.IsDir {
+			h.Add(n.Name, n.Size)
+		}
+	}
+	return h
+}
@@ -0,0 +1,38 @@
+package model
+
+import "testing"
+
+func TestExtension(t *testing.T) {
+	cases := map[string]string{
+		"movie.MKV":   ".mkv",
+		"archive.tar": ".tar",
+		".bashrc":     "",
+		"Makefile":    "",
+	}
+	for name, want := range cases {
+		if got := Extension(name); got != want {
+			t.Errorf("Extension(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuildExtHistogram(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	root.AddChild(&Node{Name: "a.mp4", Size: 1000})
+	root.AddChild(&Node{Name: "b.MP4", Size: 500})
+	root.AddChild(&Node{Name: "notes.txt", Size: 10})
+	gone := &Node{Name: "old.txt", Size: 99}
+	root.AddChild(gone)
+	gone.MarkDeleted()
+
+	stats := BuildExtHistogram(root).Sorted()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 extensions, got %d", len(stats))
+	}
+	if stats[0].Ext != ".mp4" || stats[0].Count != 2 || stats[0].Bytes != 1500 {
+		t.Errorf("unexpected top entry: %+v", stats[0])
+	}
+	if stats[1].Ext != ".txt" || stats[1].Count != 1 {
+		t.Errorf("deleted file should not be counted: %+v", stats[1])
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+// Extract copies n's subtree into a new tree rooted at a copy of n, which
+// keeps n's full path. Sizes, file counts, category totals and change
+// tracking only ever describe a node's own subtree, so they carry over as
+// they are and the copy shows the same sizes and the same growth or
+// deletions as the original, and shares are taken of the new root. The
+// original tree is left untouched, so a watcher updating it does not
+// race with the copy.
+func (n *Node) Extract() *Node {
+	root := n.clone(nil)
+	root.path = n.Path()
+	return root
+}
+
+// clone copies n and its descendants, hanging the copy under parent
+func (n *Node) clone(parent *Node) *Node {
+	c := *n
+	c.Parent = parent
+	c.path = ""
+	if n.categories != nil {
+		categories := *n.categories
+		c.categories = &categories
+	}
+	c.Children = nil
+	if len(n.Children) > 0 {
+		c.Children = make([]*Node, len(n.Children))
+		for i, child := range n.Children {
+			c.Children[i] = child.clone(&c)
+		}
+	}
+	return &c
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	gone := &Node{Name: "gone.bin", Size: 300}
+	photo := &Node{Name: "photo.jpg", Size: 100, PrevSize: 40}
+	docs := &Node{Name: "docs", IsDir: true, Children: []*Node{gone, photo}}
+	root := &Node{Name: "root", IsDir: true, Children: []*Node{docs, {Name: "other.txt", Size: 600}}}
+	root.SetPath(filepath.Join("tmp", "root"))
+	root.RebuildParentLinks()
+	root.ComputeSizes()
+	gone.MarkDeleted()
+
+	sub := docs.Extract()
+	if sub == docs || sub.Parent != nil {
+		t.Fatal("expected a detached copy")
+	}
+	if sub.Path() != filepath.Join("tmp", "root", "docs") {
+		t.Errorf("expected the original path, got %q", sub.Path())
+	}
+	if sub.TotalSize() != 400 || sub.DeletedSize != 300 || sub.FileCount() != 2 {
+		t.Errorf("expected 400 bytes, 300 deleted, 2 files, got %d/%d/%d", sub.TotalSize(), sub.DeletedSize, sub.FileCount())
+	}
+	if sub.CategorySizes() != docs.CategorySizes() {
+		t.Errorf("category sizes differ: %v vs %v", sub.CategorySizes(), docs.CategorySizes())
+	}
+	if sub.ShareOfRoot() != 1 {
+		t.Errorf("expected the new root to hold 100%%, got %v", sub.ShareOfRoot())
+	}
+
+	copied := sub.Find(filepath.Join("tmp", "root", "docs", "photo.jpg"))
+	if copied == nil || copied == photo || copied.Parent != sub {
+		t.Fatal("expected a copy of photo.jpg under the new root")
+	}
+	if copied.SizeChange() != 60 || copied.ShareOfRoot() != 0.25 {
+		t.Errorf("expected growth 60 and a quarter of the root, got %d and %v", copied.SizeChange(), copied.ShareOfRoot())
+	}
+	if share := photo.ShareOfRoot(); share < 0.099 || share > 0.101 {
+		t.Errorf("original shares changed: %v", photo.ShareOfRoot())
+	}
+
+	// Changes to the copy stay in the copy
+	sub.RemoveChild(copied)
+	if docs.TotalSize() != 400 || len(docs.Children) != 2 {
+		t.Errorf("original changed with the copy: %d bytes, %d children", docs.TotalSize(), len(docs.Children))
+	}
+}
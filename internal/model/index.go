@@ -0,0 +1,103 @@
+package model
+
+import (
+	"path/filepath"
+	"sync"
+)
+
+// PathIndex finds nodes by path without walking the tree. Only directories
+// are stored, which keeps the index small; a file is found by name among
+// its directory's children. The index is safe for concurrent use.
+type PathIndex struct {
+	mu   sync.RWMutex
+	dirs map[string]*Node
+}
+
+// NewPathIndex indexes every directory under root
+func NewPathIndex(root *Node) *PathIndex {
+	x := &PathIndex{dirs: make(map[string]*Node)}
+	if root != nil {
+		x.addLocked(root, root.Path())
+	}
+	return x
+}
+
+// Add indexes node and the directories below it. Call it after the node is
+// attached to its parent, so its path is final. A nil index ignores it.
+func (x *PathIndex) Add(node *Node) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.addLocked(node, node.Path())
+}
+
+func (x *PathIndex) addLocked(node *Node, path string) {
+	if !node.IsDir {
+		return
+	}
+	x.dirs[path] = node
+	for _, child := range node.Children {
+		if child.IsDir {
+			x.addLocked(child, filepath.Join(path, child.Name))
+		}
+	}
+}
+
+// Remove drops node and the directories below it from the index.
+// A nil index ignores it.
+func (x *PathIndex) Remove(node *Node) {
+	if x == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.removeLocked(node, node.Path())
+}
+
+func (x *PathIndex) removeLocked(node *Node, path string) {
+	if !node.IsDir {
+		return
+	}
+	// Only drop the entry if it still refers to this node; a replacement may
+	// already have been indexed under the same path
+	if x.dirs[path] == node {
+		delete(x.dirs, path)
+	}
+	for _, child := range node.Children {
+		if child.IsDir {
+			x.removeLocked(child, filepath.Join(path, child.Name))
+		}
+	}
+}
+
+// Lookup returns the node at path, or nil if the index does not know it
+func (x *PathIndex) Lookup(path string) *Node {
+	path = filepath.Clean(path)
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if dir, ok := x.dirs[path]; ok {
+		return dir
+	}
+	parent, ok := x.dirs[filepath.Dir(path)]
+	if !ok {
+		return nil
+	}
+	name := filepath.Base(path)
+	for _, child := range parent.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Len returns the number of indexed directories
+func (x *PathIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.dirs)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPathIndex(t *testing.T) {
+	root := &Node{Name: "data", IsDir: true}
+	root.SetPath(filepath.FromSlash("/data"))
+	sub := &Node{Name: "sub", IsDir: true}
+	root.AddChild(sub)
+	file := &Node{Name: "a.bin", Size: 10}
+	sub.AddChild(file)
+
+	x := NewPathIndex(root)
+	if x.Len() != 2 {
+		t.Errorf("expected 2 directories indexed, got %d", x.Len())
+	}
+	if x.Lookup(root.Path()) != root || x.Lookup(sub.Path()) != sub || x.Lookup(file.Path()) != file {
+		t.Error("lookup did not return the indexed nodes")
+	}
+	if x.Lookup(filepath.Join(sub.Path(), "missing")) != nil {
+		t.Error("expected nil for a missing file")
+	}
+
+	// Nodes added later are found once indexed
+	newDir := &Node{Name: "new", IsDir: true}
+	sub.AddChild(newDir)
+	x.Add(newDir)
+	if x.Lookup(newDir.Path()) != newDir {
+		t.Error("expected added directory to be found")
+	}
+
+	// Removing a subtree drops it and everything below
+	x.Remove(sub)
+	root.RemoveChild(sub)
+	if x.Lookup(sub.Path()) != nil || x.Lookup(newDir.Path()) != nil {
+		t.Error("expected removed directories to be gone")
+	}
+
+	// A replacement indexed before the old node is removed stays indexed
+	replacement := &Node{Name: "sub", IsDir: true}
+	root.AddChild(replacement)
+	x.Add(replacement)
+	x.Remove(sub)
+	if x.Lookup(replacement.Path()) != replacement {
+		t.Error("expected replacement to survive removal of the old node")
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestWriteNcdu(t *testing.T) {
+	root := &Node{Name: "home", IsDir: true, UID: KnownOwner(501), GID: KnownOwner(20)}
+	root.SetPath("/home")
+	docs := &Node{Name: "docs", IsDir: true, UID: NoOwner, GID: NoOwner}
+	root.AddChild(docs)
+	docs.AddChild(&Node{Name: `a "quoted".txt`, Size: 4096, Logical: 10, ModTime: 1700000000, UID: KnownOwner(501), GID: KnownOwner(20)})
+	gone := &Node{Name: "gone", Size: 100, Logical: 100}
+	docs.AddChild(gone)
+	root.AddChild(&Node{Name: "link", Attrs: AttrSymlink, UID: NoOwner, GID: NoOwner})
+	root.AddChild(&Node{Name: "[System]", Size: 999, Attrs: AttrVirtual})
+	root.ComputeSizes()
+	gone.MarkDeleted()
+
+	var buf bytes.Buffer
+	if err := WriteNcdu(&buf, root, NewNcduHeader("1.0", time.Unix(1700000100, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc []json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("export is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(doc) != 4 || string(doc[0]) != "1" || string(doc[1]) != "2" {
+		t.Fatalf("expected [1,2,header,root], got %s", buf.String())
+	}
+
+	var header NcduHeader
+	json.Unmarshal(doc[2], &header)
+	if header.Progname != "diskdive" || header.Timestamp != 1700000100 {
+		t.Errorf("unexpected header %+v", header)
+	}
+
+	// The root array holds its own entry, docs and the link, but not the
+	// estimated system space
+	var tree []json.RawMessage
+	json.Unmarshal(doc[3], &tree)
+	if len(tree) != 3 {
+		t.Fatalf("expected root entry plus 2 children, got %d: %s", len(tree), doc[3])
+	}
+	var rootEntry ncduEntry
+	json.Unmarshal(tree[0], &rootEntry)
+	if rootEntry.Name != "/home" || rootEntry.UID == nil || *rootEntry.UID != 501 {
+		t.Errorf("unexpected root entry %s", tree[0])
+	}
+
+	var docsTree []json.RawMessage
+	json.Unmarshal(tree[1], &docsTree)
+	if len(docsTree) != 2 {
+		t.Fatalf("expected the deleted file to be left out: %s", tree[1])
+	}
+	var file ncduEntry
+	json.Unmarshal(docsTree[1], &file)
+	if file.Name != `a "quoted".txt` || file.Asize != 10 || file.Dsize != 4096 || file.Mtime != 1700000000 {
+		t.Errorf("unexpected file entry %s", docsTree[1])
+	}
+
+	var link ncduEntry
+	json.Unmarshal(tree[2], &link)
+	if !link.Notreg || link.UID != nil {
+		t.Errorf("expected link to be marked notreg without owner: %s", tree[2])
+	}
+}
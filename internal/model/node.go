@@ -1,35 +1,193 @@
 package model
 
-import "runtime"
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
 
-// Node represents a file or directory in the scanned tree
+// Node represents a file or directory in the scanned tree.
+// Only the root of a tree stores its full path; every other node derives
+// its path from its ancestors' names, so big trees hold each name once.
 type Node struct {
-	Path     string  `json:"path"`
-	Name     string  `json:"name"`
-	Size     int64   `json:"size"` // size in bytes (cached total for dirs, direct size for files)
-	IsDir    bool    `json:"isDir"`
-	Children []*Node `json:"children,omitempty"`
-	Parent   *Node   `json:"-"` // skip to avoid circular reference
+	path     string   // full path, kept only while the node has no parent
+	Name     string   `json:"name"`
+	Size     int64    `json:"size"`              // size in bytes (cached total for dirs, direct size for files)
+	Logical  int64    `json:"logical,omitempty"` // apparent size before compression/sparseness, aggregated like Size
+	ModTime  int64    `json:"modTime,omitempty"` // modification time in Unix seconds, 0 if unknown
+	UID      OwnerID  `json:"uid"`               // owning user, NoOwner if unknown
+	GID      OwnerID  `json:"gid"`               // owning group, NoOwner if unknown
+	IsDir    bool     `json:"isDir"`
+	Attrs    NodeAttr `json:"attrs,omitempty"`    // links, junctions, cloud placeholders
+	Category Category `json:"category,omitempty"` // kind of file, from its extension (files only)
+	Children []*Node  `json:"children,omitempty"`
+	Parent   *Node    `json:"-"` // skip to avoid circular reference
+
+	categories *CategorySizes // bytes per category below a directory, nil for files
+	fileCount  int64          // files below a directory, cached like Size
 
 	// Change tracking (not persisted)
 	PrevSize    int64 `json:"-"`
 	IsNew       bool  `json:"-"`
 	IsDeleted   bool  `json:"-"`
+	IsStale     bool  `json:"-"` // changes below were missed; a rescan is on its way
 	HasGrew     bool  `json:"-"` // this node or descendant grew/is new
 	HasShrunk   bool  `json:"-"` // this node or descendant shrunk/deleted
 	DeletedSize int64 `json:"-"` // total size of deleted items in this subtree
 }
 
+// Path returns the node's full path, built from the names of its ancestors
+func (n *Node) Path() string {
+	if n.Parent == nil {
+		if n.path != "" {
+			return n.path
+		}
+		return n.Name
+	}
+
+	var names []string
+	top := n
+	for ; top.Parent != nil; top = top.Parent {
+		names = append(names, top.Name)
+	}
+	base := top.Path()
+
+	size := len(base)
+	for _, name := range names {
+		size += len(name) + 1
+	}
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteString(base)
+	for i := len(names) - 1; i >= 0; i-- {
+		if b.Len() > 0 && !os.IsPathSeparator(b.String()[b.Len()-1]) {
+			b.WriteByte(filepath.Separator)
+		}
+		b.WriteString(names[i])
+	}
+	return b.String()
+}
+
+// SetPath sets the full path of a node without a parent, such as a scan root
+func (n *Node) SetPath(path string) {
+	n.path = path
+}
+
+// Find returns the node at path within n's subtree, or nil if there is none
+func (n *Node) Find(path string) *Node {
+	names, ok := relNames(n.Path(), path)
+	if !ok {
+		return nil
+	}
+	node := n
+	for _, name := range names {
+		var next *Node
+		for _, child := range node.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// relNames splits path into the names leading to it from base, or returns
+// false if path is not within base
+func relNames(base, path string) ([]string, bool) {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, false
+	}
+	if rel == "." {
+		return nil, true
+	}
+	return strings.Split(rel, string(filepath.Separator)), true
+}
+
 // AddChild adds a child node and propagates size up the tree
 func (n *Node) AddChild(child *Node) {
 	child.Parent = n
+	child.path = "" // Derived from n from now on
 	n.Children = append(n.Children, child)
 
 	// Propagate size up to ancestors
 	size := child.TotalSize()
 	for parent := n; parent != nil; parent = parent.Parent {
 		parent.Size += size
+		parent.Logical += child.Logical
+		parent.DeletedSize += child.DeletedSize
+		parent.fileCount += child.FileCount()
+	}
+	n.shiftCategories(child.categoryShare(), 1)
+}
+
+// RemoveChild detaches a child node and propagates the size change up the tree.
+// Returns false if child is not a direct child of n.
+func (n *Node) RemoveChild(child *Node) bool {
+	for i, c := range n.Children {
+		if c != child {
+			continue
+		}
+		n.Children = append(n.Children[:i], n.Children[i+1:]...)
+		child.path = child.Path() // Keep the path now that it cannot be derived
+		child.Parent = nil
+
+		size := child.TotalSize()
+		for parent := n; parent != nil; parent = parent.Parent {
+			parent.Size -= size
+			parent.Logical -= child.Logical
+			parent.DeletedSize -= child.DeletedSize
+			parent.fileCount -= child.FileCount()
+		}
+		n.shiftCategories(child.categoryShare(), -1)
+		return true
+	}
+	return false
+}
+
+// Resize sets the sizes of a file that changed on disk and carries the
+// difference up to its ancestors
+func (n *Node) Resize(size, logical int64) {
+	if n.Parent != nil {
+		n.Parent.shiftCategories(n.categoryShare(), -1)
+	}
+	grew, grewLogical := size-n.Size, logical-n.Logical
+	n.Size, n.Logical = size, logical
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		parent.Size += grew
+		parent.Logical += grewLogical
+	}
+	if n.Parent != nil {
+		n.Parent.shiftCategories(n.categoryShare(), 1)
+	}
+}
+
+// Move detaches n from its parent and attaches it under parent as name,
+// as when a file or folder is renamed or moved on disk. The node and its
+// descendants stay the same objects, so flags such as IsNew survive.
+func (n *Node) Move(parent *Node, name string) {
+	if n.Parent != nil {
+		n.Parent.RemoveChild(n)
 	}
+	n.Name = name
+	if !n.IsDir {
+		n.Category = CategoryOf(name)
+	}
+	parent.AddChild(n)
+}
+
+// Rebase moves a detached node and, through it, all descendants to live
+// under parentPath
+func (n *Node) Rebase(parentPath string) {
+	n.path = filepath.Join(parentPath, n.Name)
 }
 
 // MarkDeleted marks this node as deleted and propagates the size change up the tree
@@ -38,6 +196,9 @@ func (n *Node) MarkDeleted() {
 		return // Already marked
 	}
 
+	if n.Parent != nil {
+		n.Parent.shiftCategories(n.categoryShare(), -1)
+	}
 	size := n.TotalSize()
 	n.IsDeleted = true
 	n.DeletedSize = size
@@ -53,14 +214,21 @@ func (n *Node) TotalSize() int64 {
 	return n.Size
 }
 
-// ComputeSizes calculates and caches sizes for the entire tree
-// Call this once after building/loading the tree
+// ComputeSizes calculates and caches sizes for the entire tree, spreading
+// subtrees over the available CPUs. Call this once after building/loading the tree
 func (n *Node) ComputeSizes() int64 {
+	c := sizeComputer{workers: make(chan struct{}, runtime.GOMAXPROCS(0)-1)}
 	var counter int64
-	return n.computeSizesWithYield(&counter)
+	return c.compute(n, &counter)
 }
 
-func (n *Node) computeSizesWithYield(counter *int64) int64 {
+// sizeComputer hands subtrees to idle workers. Subtrees share no nodes, so
+// each one is summed without locks and its parent only reads the result.
+type sizeComputer struct {
+	workers chan struct{} // One slot per goroutine besides the caller's
+}
+
+func (c *sizeComputer) compute(n *Node, counter *int64) int64 {
 	*counter++
 	if *counter%500 == 0 {
 		runtime.Gosched()
@@ -68,14 +236,106 @@ func (n *Node) computeSizesWithYield(counter *int64) int64 {
 	if !n.IsDir {
 		return n.Size
 	}
-	var total int64
+
+	var wg sync.WaitGroup
+	for _, child := range n.Children {
+		if len(child.Children) > 0 && c.acquire() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.release()
+				var counter int64
+				c.compute(child, &counter)
+			}()
+			continue
+		}
+		c.compute(child, counter)
+	}
+	wg.Wait()
+
+	var total, logical, files int64
 	for _, child := range n.Children {
-		total += child.computeSizesWithYield(counter)
+		total += child.Size
+		logical += child.Logical
+		files += child.FileCount()
 	}
 	n.Size = total
+	n.Logical = logical
+	n.fileCount = files
+	n.sumCategories()
 	return total
 }
 
+// acquire claims an idle worker, or returns false if all are busy
+func (c *sizeComputer) acquire() bool {
+	select {
+	case c.workers <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *sizeComputer) release() {
+	<-c.workers
+}
+
+// FileCount returns the number of files at or below the node: 1 for a file,
+// the cached count for a directory (call ComputeSizes first)
+func (n *Node) FileCount() int64 {
+	if !n.IsDir {
+		return 1
+	}
+	return n.fileCount
+}
+
+// HiddenChildren returns how many of the node's direct children are hidden
+// and how many bytes they hold, leaving out deleted ones
+func (n *Node) HiddenChildren() (count int, bytes int64) {
+	for _, child := range n.Children {
+		if child.Attrs.Has(AttrHidden) && !child.IsDeleted {
+			count++
+			bytes += child.TotalSize()
+		}
+	}
+	return count, bytes
+}
+
+// Modified returns the node's modification time, or the zero time if unknown
+func (n *Node) Modified() time.Time {
+	if n.ModTime == 0 {
+		return time.Time{}
+	}
+	return time.Unix(n.ModTime, 0)
+}
+
+// CompressionRatio returns logical size divided by on-disk size, or 0 if
+// either is unknown. Values above 1 mean the data takes less space than it holds.
+func (n *Node) CompressionRatio() float64 {
+	if n.Size <= 0 || n.Logical <= 0 {
+		return 0
+	}
+	return float64(n.Logical) / float64(n.Size)
+}
+
+// savingsBlock is the least a node must hold beyond its size on disk for the
+// difference to count as saved rather than lost to rounding to blocks
+const savingsBlock = 4096
+
+// StoredSmaller reports whether the node takes less space on disk than it
+// holds by a real margin: it is flagged compressed or sparse, or its logical
+// size is at least a tenth and a block above its size on disk.
+func (n *Node) StoredSmaller() bool {
+	if n.Size <= 0 || n.Logical <= n.Size {
+		return false
+	}
+	if n.Attrs.Has(AttrCompressed) || n.Attrs.Has(AttrSparse) {
+		return true
+	}
+	saved := n.Logical - n.Size
+	return saved >= savingsBlock && saved*10 >= n.Size
+}
+
 // SizeChange returns the difference between current and previous size
 func (n *Node) SizeChange() int64 {
 	return n.TotalSize() - n.PrevSize
@@ -97,25 +357,42 @@ func (n *Node) RebuildParentLinks() {
 	}
 }
 
-// CacheNode is a serializable version of Node (no Parent pointer)
+// CacheNode is a serializable version of Node (no Parent pointer).
+// Path is only set on the root; children derive theirs from their names.
 type CacheNode struct {
 	Path     string
 	Name     string
 	Size     int64
+	Logical  int64
+	ModTime  int64
+	UID      uint32 // As OwnerID.Num gives it
+	GID      uint32
 	IsDir    bool
+	Attrs    NodeAttr
 	Children []*CacheNode
 }
 
 // ToCacheNode converts a Node tree to a CacheNode tree for serialization
 func (n *Node) ToCacheNode() *CacheNode {
+	cn := n.toCacheNode()
+	cn.Path = n.Path()
+	return cn
+}
+
+// toCacheNode converts n and its descendants without storing paths
+func (n *Node) toCacheNode() *CacheNode {
 	cn := &CacheNode{
-		Path:  n.Path,
-		Name:  n.Name,
-		Size:  n.Size,
-		IsDir: n.IsDir,
+		Name:    n.Name,
+		Size:    n.Size,
+		Logical: n.Logical,
+		ModTime: n.ModTime,
+		UID:     n.UID.Num(),
+		GID:     n.GID.Num(),
+		IsDir:   n.IsDir,
+		Attrs:   n.Attrs,
 	}
 	for _, child := range n.Children {
-		cn.Children = append(cn.Children, child.ToCacheNode())
+		cn.Children = append(cn.Children, child.toCacheNode())
 	}
 	return cn
 }
@@ -123,14 +400,55 @@ func (n *Node) ToCacheNode() *CacheNode {
 // ToNode converts a CacheNode tree back to a Node tree
 func (cn *CacheNode) ToNode(parent *Node) *Node {
 	n := &Node{
-		Path:   cn.Path,
-		Name:   cn.Name,
-		Size:   cn.Size,
-		IsDir:  cn.IsDir,
-		Parent: parent,
+		Name:    cn.Name,
+		Size:    cn.Size,
+		Logical: cn.Logical,
+		ModTime: cn.ModTime,
+		UID:     KnownOwner(cn.UID),
+		GID:     KnownOwner(cn.GID),
+		IsDir:   cn.IsDir,
+		Attrs:   cn.Attrs,
+		Parent:  parent,
+	}
+	if parent == nil {
+		n.path = cn.Path
 	}
 	for _, child := range cn.Children {
 		n.Children = append(n.Children, child.ToNode(n))
 	}
+	n.Restore()
 	return n
 }
+
+// Restore fills in what a node read back from storage does not keep, once
+// its children are in place: a file's category, derived from its name, or
+// a folder's bytes per category
+func (n *Node) Restore() {
+	if !n.IsDir {
+		n.Category = CategoryOf(n.Name)
+	}
+	n.sumCategories()
+}
+
+// Find returns the entry at path below a root CacheNode, or nil if there is none
+func (cn *CacheNode) Find(path string) *CacheNode {
+	names, ok := relNames(cn.Path, path)
+	if !ok {
+		return nil
+	}
+	node := cn
+	for _, name := range names {
+		var next *CacheNode
+		for _, child := range node.Children {
+			if child.Name == name {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
@@ -1,6 +1,9 @@
 package model
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+)
 
 func TestNodeSize(t *testing.T) {
 	child1 := &Node{Name: "file1.txt", Size: 100, IsDir: false}
@@ -19,6 +22,50 @@ func TestNodeSize(t *testing.T) {
 	}
 }
 
+func TestNodeLogicalSize(t *testing.T) {
+	compressed := &Node{Name: "log.txt", Size: 100, Logical: 400}
+	plain := &Node{Name: "photo.jpg", Size: 100, Logical: 100}
+	parent := &Node{
+		Name:     "folder",
+		IsDir:    true,
+		Children: []*Node{compressed, plain},
+	}
+	parent.ComputeSizes()
+
+	if parent.Logical != 500 {
+		t.Errorf("expected logical 500, got %d", parent.Logical)
+	}
+	if ratio := parent.CompressionRatio(); ratio != 2.5 {
+		t.Errorf("expected ratio 2.5, got %.2f", ratio)
+	}
+
+	parent.RemoveChild(compressed)
+	if parent.Logical != 100 {
+		t.Errorf("expected logical 100 after removal, got %d", parent.Logical)
+	}
+}
+
+func TestNodeStoredSmaller(t *testing.T) {
+	tests := []struct {
+		name string
+		node Node
+		want bool
+	}{
+		{"same size", Node{Size: 8192, Logical: 8192}, false},
+		{"rounded up to blocks", Node{Size: 4096, Logical: 100}, false},
+		{"within a block", Node{Size: 1 << 20, Logical: 1<<20 + 2048}, false},
+		{"under a tenth", Node{Size: 1 << 30, Logical: 1<<30 + 1<<20}, false},
+		{"compressed", Node{Size: 1 << 20, Logical: 4 << 20}, true},
+		{"flagged sparse", Node{Size: 8192, Logical: 9000, Attrs: AttrSparse}, true},
+		{"flagged but full size", Node{Size: 8192, Logical: 8192, Attrs: AttrCompressed}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.node.StoredSmaller(); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
 func TestNodeSizeChange(t *testing.T) {
 	node := &Node{Name: "folder", Size: 0, PrevSize: 100, IsDir: true}
 	node.Size = 150
@@ -51,3 +98,169 @@ func TestSortBySize(t *testing.T) {
 	}
 }
 
+func TestRemoveChild(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	dir := &Node{Name: "dir", IsDir: true}
+	root.AddChild(dir)
+	dir.AddChild(&Node{Name: "a", Size: 100})
+	dir.AddChild(&Node{Name: "b", Size: 50})
+
+	b := dir.Children[1]
+	if !dir.RemoveChild(b) {
+		t.Fatal("expected child to be removed")
+	}
+	if b.Parent != nil {
+		t.Error("removed child should have no parent")
+	}
+	if dir.TotalSize() != 100 || root.TotalSize() != 100 {
+		t.Errorf("expected sizes 100/100, got %d/%d", dir.TotalSize(), root.TotalSize())
+	}
+	if dir.RemoveChild(b) {
+		t.Error("removing a non-child should return false")
+	}
+}
+
+func TestResize(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	dir := &Node{Name: "dir", IsDir: true}
+	root.AddChild(dir)
+	log := &Node{Name: "app.log", Size: 100, Logical: 90}
+	log.Category = CategoryOf(log.Name)
+	dir.AddChild(log)
+	dir.AddChild(&Node{Name: "clip.mov", Size: 10, Logical: 10, Category: CategoryOf("clip.mov")})
+
+	log.Resize(400, 390)
+
+	if dir.TotalSize() != 410 || root.TotalSize() != 410 {
+		t.Errorf("expected sizes 410/410, got %d/%d", dir.TotalSize(), root.TotalSize())
+	}
+	if root.Logical != 400 {
+		t.Errorf("expected logical size 400, got %d", root.Logical)
+	}
+	if got := root.CategorySizes()[log.Category]; got != 400 {
+		t.Errorf("expected the category breakdown to follow, got %d", got)
+	}
+}
+
+func TestMove(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	root.SetPath("/root")
+	from := &Node{Name: "from", IsDir: true}
+	to := &Node{Name: "to", IsDir: true}
+	root.AddChild(from)
+	root.AddChild(to)
+	file := &Node{Name: "clip.txt", Size: 100, IsNew: true}
+	file.Category = CategoryOf(file.Name)
+	from.AddChild(file)
+	from.AddChild(&Node{Name: "other", Size: 10})
+
+	file.Move(to, "clip.mov")
+
+	if file.Parent != to || len(from.Children) != 1 {
+		t.Fatal("expected the file under its new parent only")
+	}
+	if from.TotalSize() != 10 || to.TotalSize() != 100 || root.TotalSize() != 110 {
+		t.Errorf("expected sizes 10/100/110, got %d/%d/%d", from.TotalSize(), to.TotalSize(), root.TotalSize())
+	}
+	if got := file.Path(); got != filepath.Join("/root", "to", "clip.mov") {
+		t.Errorf("unexpected path %s", got)
+	}
+	if file.Category != CategoryOf("clip.mov") || !file.IsNew {
+		t.Error("expected the category to follow the name and IsNew to be kept")
+	}
+}
+
+func TestRebase(t *testing.T) {
+	dir := &Node{path: "/old/dir", Name: "dir", IsDir: true}
+	dir.AddChild(&Node{Name: "file", Size: 10})
+
+	dir.Rebase("/new")
+
+	if dir.Path() != "/new/dir" {
+		t.Errorf("expected /new/dir, got %s", dir.Path())
+	}
+	if dir.Children[0].Path() != "/new/dir/file" {
+		t.Errorf("expected /new/dir/file, got %s", dir.Children[0].Path())
+	}
+}
+
+func TestPathDerivedFromParents(t *testing.T) {
+	root := &Node{Name: "/", IsDir: true}
+	root.SetPath("/")
+	home := &Node{Name: "home", IsDir: true}
+	root.AddChild(home)
+	file := &Node{Name: "notes.txt", Size: 1}
+	home.AddChild(file)
+
+	if got := file.Path(); got != filepath.Join("/", "home", "notes.txt") {
+		t.Errorf("unexpected path %s", got)
+	}
+	if root.Find(file.Path()) != file || root.Find("/home") != home || root.Find("/") != root {
+		t.Error("Find did not return the nodes by path")
+	}
+	if root.Find("/home/missing") != nil || home.Find("/elsewhere") != nil {
+		t.Error("Find returned a node for a path outside the tree")
+	}
+
+	// A removed node keeps its path even though it has no parent
+	home.RemoveChild(file)
+	if got := file.Path(); got != filepath.Join("/", "home", "notes.txt") {
+		t.Errorf("expected detached node to keep its path, got %s", got)
+	}
+}
+
+func TestComputeSizesParallel(t *testing.T) {
+	// Enough folders at several levels that subtrees go to other workers
+	var build func(depth int) *Node
+	build = func(depth int) *Node {
+		dir := &Node{Name: "d", IsDir: true}
+		for i := range 4 {
+			dir.Children = append(dir.Children, &Node{Name: "f.jpg", Size: int64(i + 1), Logical: int64(2 * (i + 1)), Category: CategoryImage})
+			if depth > 0 {
+				dir.Children = append(dir.Children, build(depth-1))
+			}
+		}
+		return dir
+	}
+	root := build(5)
+	root.RebuildParentLinks()
+
+	// 4^0 + ... + 4^5 folders, each holding files of 1+2+3+4 bytes
+	const want = (1 + 4 + 16 + 64 + 256 + 1024) * 10
+	if got := root.ComputeSizes(); got != want {
+		t.Fatalf("ComputeSizes() = %d, want %d", got, want)
+	}
+	if root.Size != want || root.Logical != 2*want {
+		t.Errorf("root size %d logical %d, want %d and %d", root.Size, root.Logical, want, 2*want)
+	}
+	if got := root.CategorySizes()[CategoryImage]; got != want {
+		t.Errorf("images = %d, want %d", got, want)
+	}
+
+	// Every folder sums its own children
+	for dir := range Walk(root, WalkOptions{}) {
+		if !dir.IsDir {
+			continue
+		}
+		var sum int64
+		for _, child := range dir.Children {
+			sum += child.Size
+		}
+		if dir.Size != sum {
+			t.Fatalf("folder size %d, children sum to %d", dir.Size, sum)
+		}
+	}
+}
+
+func TestHiddenChildren(t *testing.T) {
+	root := &Node{Name: "home", IsDir: true}
+	root.AddChild(&Node{Name: ".cache", Size: 300, Attrs: AttrHidden})
+	root.AddChild(&Node{Name: ".profile", Size: 20, Attrs: AttrHidden})
+	root.AddChild(&Node{Name: ".old", Size: 50, Attrs: AttrHidden, IsDeleted: true})
+	root.AddChild(&Node{Name: "notes.txt", Size: 1000})
+	root.ComputeSizes()
+
+	if count, bytes := root.HiddenChildren(); count != 2 || bytes != 320 {
+		t.Errorf("HiddenChildren() = %d, %d, want 2, 320", count, bytes)
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// NoiseNames are metadata files operating systems drop into folders on their own
+var NoiseNames = []string{".DS_Store", "Thumbs.db", "desktop.ini", ".localized"}
+
+// Noise matches OS metadata files, which change highlighting leaves out so
+// browsing a folder does not speckle it with new and deleted entries.
+// The zero value matches NoiseNames.
+type Noise struct {
+	extra []string // Lowercase glob patterns added to NoiseNames
+}
+
+// NewNoise returns a Noise that also matches the extra names or glob patterns
+func NewNoise(extra []string) Noise {
+	n := Noise{extra: make([]string, len(extra))}
+	for i, p := range extra {
+		n.extra[i] = strings.ToLower(p)
+	}
+	return n
+}
+
+// Match reports whether a file called name is noise, ignoring case
+func (n Noise) Match(name string) bool {
+	for _, noise := range NoiseNames {
+		if strings.EqualFold(noise, name) {
+			return true
+		}
+	}
+	lower := strings.ToLower(name)
+	for _, p := range n.extra {
+		if ok, _ := filepath.Match(p, lower); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether node is a noise file. Folders are never noise.
+func (n Noise) Is(node *Node) bool {
+	return !node.IsDir && n.Match(node.Name)
+}
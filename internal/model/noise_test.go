@@ -0,0 +1,26 @@
+package model
+
+import "testing"
+
+func TestNoise(t *testing.T) {
+	var builtin Noise
+	for _, name := range []string{".DS_Store", "thumbs.db", "Desktop.ini", ".localized"} {
+		if !builtin.Match(name) {
+			t.Errorf("%s should be noise", name)
+		}
+	}
+	if builtin.Match("notes.txt") {
+		t.Error("notes.txt should not be noise")
+	}
+
+	extra := NewNoise([]string{"*.SWP", "Icon?"})
+	for _, name := range []string{".DS_Store", "main.go.swp", "Icon\r"} {
+		if !extra.Match(name) {
+			t.Errorf("%q should be noise", name)
+		}
+	}
+
+	if builtin.Is(&Node{Name: ".localized", IsDir: true}) {
+		t.Error("folders should never be noise")
+	}
+}
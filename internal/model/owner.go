@@ -0,0 +1,111 @@
+package model
+
+import (
+	"os/user"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// OwnerID is a user or group ID kept plus one, so that the zero value, which
+// a node built without an owner holds, means unknown rather than root
+type OwnerID uint32
+
+// NoOwner marks a node whose owner is unknown (e.g. on Windows)
+const NoOwner OwnerID = 0
+
+// KnownOwner returns the OwnerID of a numeric user or group ID
+func KnownOwner(id uint32) OwnerID {
+	return OwnerID(id + 1)
+}
+
+// Num returns the numeric user or group ID, or ^uint32(0) if it is unknown,
+// which KnownOwner turns back into NoOwner. Snapshots store IDs this way.
+func (o OwnerID) Num() uint32 {
+	return uint32(o) - 1
+}
+
+// OwnerUsage is the space one user's files take up in a subtree
+type OwnerUsage struct {
+	UID   OwnerID
+	Bytes int64
+	Files int64
+}
+
+// OwnerUsage returns how much of the subtree each owner's files take,
+// largest first. Deleted nodes and files of unknown owners are left out.
+func (n *Node) OwnerUsage() []OwnerUsage {
+	byOwner := make(map[OwnerID]*OwnerUsage)
+	for node := range n.Files() {
+		if node.UID == NoOwner {
+			continue
+		}
+		u := byOwner[node.UID]
+		if u == nil {
+			u = &OwnerUsage{UID: node.UID}
+			byOwner[node.UID] = u
+		}
+		u.Bytes += node.Size
+		u.Files++
+	}
+
+	result := make([]OwnerUsage, 0, len(byOwner))
+	for _, u := range byOwner {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bytes != result[j].Bytes {
+			return result[i].Bytes > result[j].Bytes
+		}
+		return result[i].UID < result[j].UID
+	})
+	return result
+}
+
+var (
+	namesMu    sync.Mutex
+	userNames  = make(map[OwnerID]string)
+	groupNames = make(map[OwnerID]string)
+)
+
+// OwnerName resolves a user ID to a user name, falling back to the number.
+// Lookups are cached since the same few owners repeat across the tree.
+func OwnerName(uid OwnerID) string {
+	return lookupName(userNames, uid, func(id string) (string, error) {
+		u, err := user.LookupId(id)
+		if err != nil {
+			return "", err
+		}
+		return u.Username, nil
+	})
+}
+
+// GroupName resolves a group ID to a group name, falling back to the number
+func GroupName(gid OwnerID) string {
+	return lookupName(groupNames, gid, func(id string) (string, error) {
+		g, err := user.LookupGroupId(id)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	})
+}
+
+// lookupName returns the cached name for id, resolving it on first use
+func lookupName(cache map[OwnerID]string, id OwnerID, lookup func(string) (string, error)) string {
+	if id == NoOwner {
+		return ""
+	}
+	namesMu.Lock()
+	defer namesMu.Unlock()
+	if name, ok := cache[id]; ok {
+		return name
+	}
+	idStr := strconv.FormatUint(uint64(id.Num()), 10)
+	name, err := lookup(idStr)
+	if err != nil || name == "" {
+		name = idStr
+	}
+	cache[id] = name
+	return name
+}
@@ -0,0 +1,45 @@
+package model
+
+import "testing"
+
+func TestOwnerUsage(t *testing.T) {
+	root := &Node{Name: "shared", IsDir: true, UID: NoOwner}
+	alice := &Node{Name: "alice", IsDir: true, UID: KnownOwner(1000)}
+	alice.AddChild(&Node{Name: "a.bin", Size: 300, UID: KnownOwner(1000)})
+	alice.AddChild(&Node{Name: "b.bin", Size: 50, UID: KnownOwner(1001)})
+	root.AddChild(alice)
+	root.AddChild(&Node{Name: "c.bin", Size: 200, UID: KnownOwner(1001)})
+	root.AddChild(&Node{Name: "gone.bin", Size: 999, UID: KnownOwner(1002), IsDeleted: true})
+	root.AddChild(&Node{Name: "unknown.bin", Size: 10, UID: NoOwner})
+	root.AddChild(&Node{Name: "unset.bin", Size: 20}) // Built without an owner
+
+	usage := root.OwnerUsage()
+	if len(usage) != 2 {
+		t.Fatalf("expected 2 owners, got %+v", usage)
+	}
+	if usage[0].UID != KnownOwner(1000) || usage[0].Bytes != 300 || usage[0].Files != 1 {
+		t.Errorf("unexpected first owner %+v", usage[0])
+	}
+	if usage[1].UID != KnownOwner(1001) || usage[1].Bytes != 250 || usage[1].Files != 2 {
+		t.Errorf("unexpected second owner %+v", usage[1])
+	}
+}
+
+func TestOwnerIDRoot(t *testing.T) {
+	root := KnownOwner(0)
+	if root == NoOwner || root.Num() != 0 {
+		t.Errorf("expected root to be a known owner with ID 0, got %d", root)
+	}
+	if NoOwner.Num() != ^uint32(0) || KnownOwner(NoOwner.Num()) != NoOwner {
+		t.Errorf("expected an unknown owner to round-trip through its number")
+	}
+	if name := OwnerName(root); name != "root" && name != "0" {
+		t.Errorf("expected root's name, got %q", name)
+	}
+}
+
+func TestOwnerNameUnknown(t *testing.T) {
+	if name := OwnerName(NoOwner); name != "" {
+		t.Errorf("expected empty name for unknown owner, got %q", name)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package model
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// OwnerOf returns the user and group IDs that own a file
+func OwnerOf(info fs.FileInfo) (uid, gid OwnerID) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return NoOwner, NoOwner
+	}
+	return KnownOwner(stat.Uid), KnownOwner(stat.Gid)
+}
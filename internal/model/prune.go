@@ -0,0 +1,52 @@
+package model
+
+// Prune detaches every node marked deleted from n's subtree and returns the
+// detached nodes, which keep their paths for cleanup. Ancestors lose the
+// pruned sizes along with the matching deleted sizes, so the space they show
+// stays the same.
+func (n *Node) Prune() []*Node {
+	var pruned []*Node
+	n.prune(&pruned)
+	return pruned
+}
+
+func (n *Node) prune(pruned *[]*Node) {
+	if len(n.Children) == 0 {
+		return
+	}
+	kept := n.Children[:0]
+	for _, child := range n.Children {
+		if !child.IsDeleted {
+			child.prune(pruned)
+			kept = append(kept, child)
+			continue
+		}
+		child.path = child.Path() // Keep the path now that it cannot be derived
+		child.Parent = nil
+		for parent := n; parent != nil; parent = parent.Parent {
+			parent.Size -= child.Size
+			parent.Logical -= child.Logical
+			parent.DeletedSize -= child.DeletedSize
+			parent.fileCount -= child.FileCount()
+		}
+		*pruned = append(*pruned, child)
+	}
+	// Drop references in the unused tail so the pruned nodes can be collected
+	clear(n.Children[len(kept):])
+	n.Children = kept
+}
+
+// Compact trims the spare capacity left in children slices by appends and
+// removals, releasing memory held by long-lived trees
+func (n *Node) Compact() {
+	if cap(n.Children) > len(n.Children) {
+		if len(n.Children) == 0 {
+			n.Children = nil
+		} else {
+			n.Children = append([]*Node(nil), n.Children...)
+		}
+	}
+	for _, child := range n.Children {
+		child.Compact()
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	gone := &Node{Name: "gone.bin", Size: 300}
+	cache := &Node{Name: "cache", IsDir: true, Children: []*Node{
+		{Name: "a.tmp", Size: 50},
+		{Name: "b.tmp", Size: 50},
+	}}
+	keep := &Node{Name: "keep.txt", Size: 100}
+	docs := &Node{Name: "docs", IsDir: true, Children: []*Node{gone, keep}}
+	root := &Node{Name: "root", IsDir: true, Children: []*Node{docs, cache}}
+	root.SetPath(filepath.Join("tmp", "root"))
+	root.RebuildParentLinks()
+	root.ComputeSizes()
+
+	gone.MarkDeleted()
+	cache.MarkDeleted()
+	visible := root.TotalSize() - root.DeletedSize
+
+	pruned := root.Prune()
+	if len(pruned) != 2 {
+		t.Fatalf("expected 2 pruned nodes, got %d", len(pruned))
+	}
+	if root.TotalSize()-root.DeletedSize != visible {
+		t.Errorf("visible size changed: %d -> %d", visible, root.TotalSize()-root.DeletedSize)
+	}
+	if root.TotalSize() != 100 || root.DeletedSize != 0 {
+		t.Errorf("expected size 100 with nothing deleted, got %d/%d", root.TotalSize(), root.DeletedSize)
+	}
+	if len(root.Children) != 1 || len(docs.Children) != 1 || docs.Children[0] != keep {
+		t.Errorf("unexpected children after prune: %v / %v", root.Children, docs.Children)
+	}
+	if gone.Parent != nil || gone.Path() != filepath.Join("tmp", "root", "docs", "gone.bin") {
+		t.Errorf("pruned node should be detached with its path, got %q", gone.Path())
+	}
+	if len(root.Prune()) != 0 {
+		t.Error("second prune should find nothing")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	dir := &Node{Name: "dir", IsDir: true, Children: make([]*Node, 0, 8)}
+	for _, name := range []string{"a", "b", "c"} {
+		dir.AddChild(&Node{Name: name, Size: 1})
+	}
+	dir.RemoveChild(dir.Children[1])
+	empty := &Node{Name: "empty", IsDir: true, Children: make([]*Node, 0, 4)}
+	dir.AddChild(empty)
+
+	dir.Compact()
+	if cap(dir.Children) != len(dir.Children) {
+		t.Errorf("expected no spare capacity, got len %d cap %d", len(dir.Children), cap(dir.Children))
+	}
+	if empty.Children != nil {
+		t.Error("expected empty children slice to be released")
+	}
+	if dir.Children[0].Name != "a" || dir.Children[1].Name != "c" || dir.Children[2] != empty {
+		t.Errorf("compact changed order: %v", dir.Children)
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+// fraction returns part/whole, or 0 when whole is empty
+func fraction(part, whole int64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return float64(part) / float64(whole)
+}
+
+// ShareOfParent returns the fraction of its parent's size the node takes,
+// worked out from the current sizes when it is drawn. Deleted nodes have
+// no share, and a root has all of it.
+func (n *Node) ShareOfParent() float64 {
+	switch {
+	case n.IsDeleted:
+		return 0
+	case n.Parent == nil:
+		return 1
+	}
+	return fraction(n.TotalSize(), n.Parent.TotalSize())
+}
+
+// ShareOfRoot returns the fraction of the whole tree the node takes. It
+// follows the parents up to the root, so it costs the node's depth.
+func (n *Node) ShareOfRoot() float64 {
+	if n.IsDeleted {
+		return 0
+	}
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return fraction(n.TotalSize(), root.TotalSize())
+}
@@ -0,0 +1,31 @@
+package model
+
+import "testing"
+
+func TestShares(t *testing.T) {
+	root := walkTree() // a (a1 10, a2 300), b 200, sys 50
+	a, a2, b := root.Children[0], root.Children[0].Children[1], root.Children[1]
+
+	if root.ShareOfRoot() != 1 || root.ShareOfParent() != 1 {
+		t.Errorf("root share = %v, want 1", root.ShareOfRoot())
+	}
+	if got := a.ShareOfParent(); got != 310.0/560 {
+		t.Errorf("a share of parent = %v", got)
+	}
+	if got := a2.ShareOfParent(); got != 300.0/310 {
+		t.Errorf("a2 share of parent = %v", got)
+	}
+	if got := a2.ShareOfRoot(); got != 300.0/560 {
+		t.Errorf("a2 share of root = %v", got)
+	}
+
+	// Shares follow the sizes as they change
+	b.MarkDeleted()
+	if b.ShareOfParent() != 0 || b.ShareOfRoot() != 0 {
+		t.Error("deleted nodes should have no share")
+	}
+	a2.Resize(100, 100)
+	if got := a2.ShareOfRoot(); got != 100/float64(root.TotalSize()) {
+		t.Errorf("a2 share of root after resizing = %v", got)
+	}
+}
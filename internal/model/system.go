@@ -0,0 +1,16 @@
+package model
+
+// SystemArea is space on a filesystem that a file scan cannot see or measure,
+// such as swap, hibernation files or filesystem metadata
+type SystemArea struct {
+	Name   string
+	Path   string // File holding the area, or "" when it has no path
+	Size   int64
+	Detail string
+}
+
+// GetSystemAreas estimates hidden system areas on the filesystem containing
+// root. Areas that cannot be measured are left out.
+func GetSystemAreas(root string) []SystemArea {
+	return getSystemAreas(root)
+}
@@ -0,0 +1,64 @@
+//go:build darwin
+
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// vmDirs hold swap files and the sleep image, on their own APFS volume since Catalina
+var vmDirs = []string{"/System/Volumes/VM", "/private/var/vm"}
+
+// getSystemAreas measures swap and the sleep image when root is the startup
+// volume. They live on the VM volume, which shares the APFS container's space
+// but is a separate mount the scan does not enter.
+func getSystemAreas(root string) []SystemArea {
+	if filepath.Clean(root) != "/" {
+		return nil
+	}
+
+	seen := make(map[uint64]bool)
+	var swap, sleep int64
+	for _, dir := range vmDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			stat, ok := info.Sys().(*syscall.Stat_t)
+			if !ok || seen[stat.Ino] {
+				continue
+			}
+			seen[stat.Ino] = true
+			if entry.Name() == "sleepimage" {
+				sleep += stat.Blocks * 512
+			} else {
+				swap += stat.Blocks * 512
+			}
+		}
+	}
+
+	var areas []SystemArea
+	if swap > 0 {
+		areas = append(areas, SystemArea{
+			Name:   "Swap files",
+			Size:   swap,
+			Detail: "Virtual memory on the VM volume; shrinks after a restart",
+		})
+	}
+	if sleep > 0 {
+		areas = append(areas, SystemArea{
+			Name:   "Sleep image",
+			Path:   "/private/var/vm/sleepimage",
+			Size:   sleep,
+			Detail: "Memory contents saved for hibernation (pmset hibernatemode)",
+		})
+	}
+	return areas
+}
@@ -0,0 +1,61 @@
+//go:build !windows && !darwin
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// getSystemAreas finds deleted files still held open by running processes.
+// Their space stays in use until the process exits but no path leads to them.
+// Only processes the current user may inspect are counted.
+func getSystemAreas(root string) []SystemArea {
+	var rootStat syscall.Stat_t
+	if err := syscall.Stat(root, &rootStat); err != nil {
+		return nil
+	}
+
+	fdDirs, err := filepath.Glob("/proc/[0-9]*/fd")
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[uint64]bool)
+	var size, count int64
+	for _, dir := range fdDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			fd := filepath.Join(dir, entry.Name())
+			target, err := os.Readlink(fd)
+			if err != nil || !strings.HasSuffix(target, " (deleted)") {
+				continue
+			}
+			var stat syscall.Stat_t
+			if err := syscall.Stat(fd, &stat); err != nil || stat.Dev != rootStat.Dev {
+				continue
+			}
+			if stat.Mode&syscall.S_IFMT != syscall.S_IFREG || seen[stat.Ino] {
+				continue
+			}
+			seen[stat.Ino] = true
+			size += stat.Blocks * 512
+			count++
+		}
+	}
+
+	if size == 0 {
+		return nil
+	}
+	return []SystemArea{{
+		Name:   "Deleted files held open",
+		Size:   size,
+		Detail: fmt.Sprintf("%d file(s) removed while still open; the space is freed when the programs using them exit (lsof +L1)", count),
+	}}
+}
@@ -0,0 +1,147 @@
+//go:build windows
+
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const fsctlGetNTFSVolumeData = 0x00090064
+
+// ntfsVolumeData mirrors NTFS_VOLUME_DATA_BUFFER
+type ntfsVolumeData struct {
+	VolumeSerialNumber           int64
+	NumberSectors                int64
+	TotalClusters                int64
+	FreeClusters                 int64
+	TotalReserved                int64
+	BytesPerSector               uint32
+	BytesPerCluster              uint32
+	BytesPerFileRecordSegment    uint32
+	ClustersPerFileRecordSegment uint32
+	MftValidDataLength           int64
+	MftStartLcn                  int64
+	Mft2StartLcn                 int64
+	MftZoneStart                 int64
+	MftZoneEnd                   int64
+}
+
+// systemFiles are large files Windows keeps at the root of a drive
+var systemFiles = []struct {
+	name   string
+	label  string
+	detail string
+}{
+	{"pagefile.sys", "Page file", "Virtual memory; size is managed in System > Advanced > Performance"},
+	{"hiberfil.sys", "Hibernation file", "Memory contents saved for hibernate and fast startup (powercfg /h off removes it)"},
+	{"swapfile.sys", "Swap file", "Used to suspend Store apps"},
+}
+
+// shadowStorageScript lists shadow copy storage with the drive letter of the
+// volume holding it. Reading Win32_ShadowStorage needs administrator rights.
+const shadowStorageScript = `ConvertTo-Json -InputObject @(Get-CimInstance Win32_ShadowStorage | ForEach-Object {
+	$id = $_.DiffVolume.DeviceID
+	$vol = Get-CimInstance Win32_Volume | Where-Object { $_.DeviceID -eq $id }
+	[pscustomobject]@{ Drive = $vol.DriveLetter; Used = $_.UsedSpace; Allocated = $_.AllocatedSpace; Max = $_.MaxSpace }
+})`
+
+// shadowStorage is one row of shadowStorageScript output
+type shadowStorage struct {
+	Drive     string
+	Used      int64
+	Allocated int64
+	Max       int64
+}
+
+// getSystemAreas measures the page, hibernation and swap files, shadow copy
+// storage and the NTFS master file table when root is a drive root
+func getSystemAreas(root string) []SystemArea {
+	volume := filepath.VolumeName(root)
+	if volume == "" || filepath.Clean(root) != volume+`\` {
+		return nil
+	}
+
+	var areas []SystemArea
+	for _, f := range systemFiles {
+		path := filepath.Join(root, f.name)
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			continue
+		}
+		areas = append(areas, SystemArea{Name: f.label, Path: path, Size: info.Size(), Detail: f.detail})
+	}
+
+	if used := shadowStorageUsed(volume); used > 0 {
+		areas = append(areas, SystemArea{
+			Name: "Shadow copies",
+			Size: used,
+			Detail: fmt.Sprintf("Restore points and previous file versions. Limit them with "+
+				"vssadmin resize shadowstorage /For=%s /On=%s /MaxSize=10%%, or in System Protection settings", volume, volume),
+		})
+	}
+
+	if mft := mftSize(volume); mft > 0 {
+		areas = append(areas, SystemArea{
+			Name:   "NTFS master file table",
+			Size:   mft,
+			Detail: "Filesystem metadata ($MFT) describing every file; grows with the number of files",
+		})
+	}
+	return areas
+}
+
+// mftSize returns the size of the master file table. Opening the volume
+// requires administrator rights, so this returns 0 otherwise.
+func mftSize(volume string) int64 {
+	path, err := windows.UTF16PtrFromString(`\\.\` + volume)
+	if err != nil {
+		return 0
+	}
+	handle, err := windows.CreateFile(path, 0,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		return 0
+	}
+	defer windows.CloseHandle(handle)
+
+	var data ntfsVolumeData
+	var returned uint32
+	err = windows.DeviceIoControl(handle, fsctlGetNTFSVolumeData, nil, 0,
+		(*byte)(unsafe.Pointer(&data)), uint32(unsafe.Sizeof(data)), &returned, nil)
+	if err != nil {
+		return 0
+	}
+	return data.MftValidDataLength
+}
+
+// shadowStorageUsed returns the bytes of shadow copy storage kept on volume,
+// or 0 if it cannot be queried
+func shadowStorageUsed(volume string) int64 {
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", shadowStorageScript)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return 0
+	}
+
+	var rows []shadowStorage
+	if err := json.Unmarshal(out, &rows); err != nil {
+		return 0
+	}
+	var used int64
+	for _, r := range rows {
+		if strings.EqualFold(r.Drive, volume) {
+			used += r.Used
+		}
+	}
+	return used
+}
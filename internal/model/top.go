@@ -0,0 +1,55 @@
+package model
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// LargestFiles returns the limit largest files under n, biggest first.
+// Deleted files, estimated system space and grouped small files are left out.
+func (n *Node) LargestFiles(limit int) []*Node {
+	if n == nil || limit <= 0 {
+		return nil
+	}
+
+	h := &fileHeap{}
+	for node := range n.Files() {
+		if node.Attrs.Has(AttrGrouped) {
+			continue
+		}
+		if h.Len() < limit {
+			heap.Push(h, node)
+		} else if fileLess((*h)[0], node) {
+			(*h)[0] = node
+			heap.Fix(h, 0)
+		}
+	}
+
+	result := []*Node(*h)
+	sort.Slice(result, func(i, j int) bool {
+		return fileLess(result[j], result[i])
+	})
+	return result
+}
+
+// fileLess orders files by size, breaking ties by path so results are stable
+func fileLess(a, b *Node) bool {
+	if a.Size != b.Size {
+		return a.Size < b.Size
+	}
+	return a.Path() > b.Path()
+}
+
+// fileHeap is a min-heap of files, keeping the smallest of the current top N at the root
+type fileHeap []*Node
+
+func (h fileHeap) Len() int           { return len(h) }
+func (h fileHeap) Less(i, j int) bool { return fileLess(h[i], h[j]) }
+func (h fileHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *fileHeap) Push(x any)        { *h = append(*h, x.(*Node)) }
+func (h *fileHeap) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
@@ -0,0 +1,41 @@
+package model
+
+import "testing"
+
+func TestLargestFiles(t *testing.T) {
+	file := func(name string, size int64) *Node {
+		return &Node{Name: name, Size: size}
+	}
+	sub := &Node{Name: "sub", IsDir: true, Children: []*Node{
+		file("sub/big.iso", 900),
+		file("sub/small.txt", 5),
+	}}
+	deleted := file("gone.bin", 1000)
+	deleted.IsDeleted = true
+	root := &Node{Name: "r", IsDir: true, Children: []*Node{
+		file("a.mp4", 500),
+		file("b.mp4", 500),
+		file("c.log", 20),
+		deleted,
+		{Name: "[System]", IsDir: true, Attrs: AttrVirtual, Children: []*Node{file("swap", 5000)}},
+		sub,
+	}}
+
+	top := root.LargestFiles(3)
+	want := []string{"sub/big.iso", "a.mp4", "b.mp4"}
+	if len(top) != len(want) {
+		t.Fatalf("expected %d files, got %d", len(want), len(top))
+	}
+	for i, name := range want {
+		if top[i].Name != name {
+			t.Errorf("position %d: expected %s, got %s", i, name, top[i].Name)
+		}
+	}
+
+	if all := root.LargestFiles(100); len(all) != 5 {
+		t.Errorf("expected all 5 files, got %d", len(all))
+	}
+	if none := root.LargestFiles(0); none != nil {
+		t.Errorf("expected nil for limit 0, got %v", none)
+	}
+}
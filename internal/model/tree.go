@@ -1,15 +1,175 @@
 package model
 
-import "sort"
+import (
+	"container/heap"
+	"sort"
+)
+
+// pagedSortMin is the number of nodes above which SortedPage selects the
+// requested page instead of sorting everything
+const pagedSortMin = 4096
 
 // SortBySize sorts nodes by total size descending, then by name ascending
 func SortBySize(nodes []*Node) {
 	sort.Slice(nodes, func(i, j int) bool {
-		si, sj := nodes[i].TotalSize(), nodes[j].TotalSize()
-		if si != sj {
-			return si > sj
-		}
-		return nodes[i].Name < nodes[j].Name
+		return SizeBefore(nodes[i], nodes[j])
+	})
+}
+
+// SizeBefore reports whether a sorts before b in SortBySize order
+func SizeBefore(a, b *Node) bool {
+	if a.TotalSize() != b.TotalSize() {
+		return a.TotalSize() > b.TotalSize()
+	}
+	return a.Name < b.Name
+}
+
+// SortByCount sorts nodes by the number of files they hold descending, then
+// in SortBySize order
+func SortByCount(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return CountBefore(nodes[i], nodes[j])
+	})
+}
+
+// CountBefore reports whether a sorts before b in SortByCount order
+func CountBefore(a, b *Node) bool {
+	if a.FileCount() != b.FileCount() {
+		return a.FileCount() > b.FileCount()
+	}
+	return SizeBefore(a, b)
+}
+
+// SortByAge sorts nodes by modification time, oldest first, then in
+// SortBySize order. Nodes with an unknown time go last.
+func SortByAge(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return AgeBefore(nodes[i], nodes[j])
 	})
 }
 
+// AgeBefore reports whether a sorts before b in SortByAge order
+func AgeBefore(a, b *Node) bool {
+	if a.ModTime != b.ModTime {
+		switch {
+		case a.ModTime == 0:
+			return false
+		case b.ModTime == 0:
+			return true
+		}
+		return a.ModTime < b.ModTime
+	}
+	return SizeBefore(a, b)
+}
+
+// SortOrder selects one of the orders above for listing a folder
+type SortOrder int
+
+const (
+	OrderSize  SortOrder = iota // SortBySize
+	OrderCount                  // SortByCount
+	OrderAge                    // SortByAge
+	sortOrders
+)
+
+// Next returns the order after o, wrapping around to OrderSize
+func (o SortOrder) Next() SortOrder {
+	return (o + 1) % sortOrders
+}
+
+// String names the order for display
+func (o SortOrder) String() string {
+	switch o {
+	case OrderCount:
+		return "file count"
+	case OrderAge:
+		return "age"
+	}
+	return "size"
+}
+
+// Sort sorts nodes in o's order
+func (o SortOrder) Sort(nodes []*Node) {
+	switch o {
+	case OrderCount:
+		SortByCount(nodes)
+	case OrderAge:
+		SortByAge(nodes)
+	default:
+		SortBySize(nodes)
+	}
+}
+
+// Before reports whether a sorts before b in o's order
+func (o SortOrder) Before(a, b *Node) bool {
+	switch o {
+	case OrderCount:
+		return CountBefore(a, b)
+	case OrderAge:
+		return AgeBefore(a, b)
+	}
+	return SizeBefore(a, b)
+}
+
+// SortedPage returns up to limit of nodes starting at offset, in SortBySize
+// order, leaving nodes untouched. For long lists only the nodes up to the end
+// of the page are ordered, so the first screens of a folder with hundreds of
+// thousands of entries stay cheap to show.
+func SortedPage(nodes []*Node, offset, limit int) []*Node {
+	return OrderSize.Page(nodes, offset, limit)
+}
+
+// Page is SortedPage in o's order
+func (o SortOrder) Page(nodes []*Node, offset, limit int) []*Node {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || offset >= len(nodes) {
+		return nil
+	}
+	end := offset + limit
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	if len(nodes) <= pagedSortMin || end > len(nodes)/2 {
+		sorted := append([]*Node(nil), nodes...)
+		o.Sort(sorted)
+		return sorted[offset:end]
+	}
+
+	// Keep the first end nodes in a heap whose root is the one sorting last
+	h := &pageHeap{nodes: make([]*Node, 0, end), order: o}
+	for _, node := range nodes {
+		if len(h.nodes) < end {
+			heap.Push(h, node)
+		} else if o.Before(node, h.nodes[0]) {
+			h.nodes[0] = node
+			heap.Fix(h, 0)
+		}
+	}
+	page := h.nodes
+	o.Sort(page)
+	return page[offset:]
+}
+
+// ChildPage returns up to limit children starting at offset, in SortBySize order
+func (n *Node) ChildPage(offset, limit int) []*Node {
+	return SortedPage(n.Children, offset, limit)
+}
+
+// pageHeap is a heap of nodes with the one sorting last in order at the root
+type pageHeap struct {
+	nodes []*Node
+	order SortOrder
+}
+
+func (h *pageHeap) Len() int           { return len(h.nodes) }
+func (h *pageHeap) Less(i, j int) bool { return h.order.Before(h.nodes[j], h.nodes[i]) }
+func (h *pageHeap) Swap(i, j int)      { h.nodes[i], h.nodes[j] = h.nodes[j], h.nodes[i] }
+func (h *pageHeap) Push(x any)         { h.nodes = append(h.nodes, x.(*Node)) }
+func (h *pageHeap) Pop() any {
+	n := h.nodes[len(h.nodes)-1]
+	h.nodes = h.nodes[:len(h.nodes)-1]
+	return n
+}
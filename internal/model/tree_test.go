@@ -0,0 +1,115 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSortedPage(t *testing.T) {
+	dir := &Node{Name: "maildir", IsDir: true}
+	for i := 0; i < pagedSortMin*3; i++ {
+		// Sizes repeat so that names break ties
+		dir.AddChild(&Node{Name: fmt.Sprintf("msg%05d", i), Size: int64(i % 1000)})
+	}
+
+	all := append([]*Node(nil), dir.Children...)
+	SortBySize(all)
+
+	for _, page := range []struct{ offset, limit int }{{0, 50}, {50, 50}, {990, 40}, {len(all) - 10, 50}} {
+		got := dir.ChildPage(page.offset, page.limit)
+		end := page.offset + page.limit
+		if end > len(all) {
+			end = len(all)
+		}
+		want := all[page.offset:end]
+		if len(got) != len(want) {
+			t.Fatalf("page %+v: expected %d nodes, got %d", page, len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("page %+v: node %d is %s, want %s", page, i, got[i].Name, want[i].Name)
+			}
+		}
+	}
+
+	if dir.ChildPage(len(all), 10) != nil {
+		t.Error("expected no nodes past the end")
+	}
+	if dir.Children[0].Name != "msg00000" {
+		t.Error("paging must not reorder the children")
+	}
+}
+
+func TestSortByCount(t *testing.T) {
+	root := &Node{Name: "root", IsDir: true}
+	many := &Node{Name: "many", IsDir: true}
+	few := &Node{Name: "few", IsDir: true}
+	root.AddChild(many)
+	root.AddChild(few)
+	for i := range 3 {
+		many.AddChild(&Node{Name: fmt.Sprintf("m%d", i), Size: 10})
+	}
+	few.AddChild(&Node{Name: "big", Size: 1000})
+	root.AddChild(&Node{Name: "file", Size: 5})
+
+	if got := root.FileCount(); got != 5 {
+		t.Errorf("expected 5 files after AddChild, got %d", got)
+	}
+	root.ComputeSizes()
+	if got := root.FileCount(); got != 5 {
+		t.Errorf("expected 5 files after ComputeSizes, got %d", got)
+	}
+
+	nodes := append([]*Node(nil), root.Children...)
+	SortByCount(nodes)
+	// few and file both hold one file, so size breaks the tie
+	for i, want := range []string{"many", "few", "file"} {
+		if nodes[i].Name != want {
+			t.Errorf("position %d: expected %s, got %s", i, want, nodes[i].Name)
+		}
+	}
+
+	many.RemoveChild(many.Children[0])
+	if got := root.FileCount(); got != 4 {
+		t.Errorf("expected 4 files after RemoveChild, got %d", got)
+	}
+}
+
+func TestSortByAge(t *testing.T) {
+	nodes := []*Node{
+		{Name: "unknown", Size: 900},
+		{Name: "new", ModTime: 3000},
+		{Name: "old", ModTime: 1000},
+		{Name: "old-big", ModTime: 1000, Size: 50},
+	}
+
+	SortByAge(nodes)
+
+	for i, want := range []string{"old-big", "old", "new", "unknown"} {
+		if nodes[i].Name != want {
+			t.Errorf("position %d: expected %s, got %s", i, want, nodes[i].Name)
+		}
+	}
+}
+
+func TestSortOrderPage(t *testing.T) {
+	dir := &Node{Name: "dir", IsDir: true}
+	for i := range pagedSortMin * 2 {
+		dir.AddChild(&Node{Name: fmt.Sprintf("f%05d", i), Size: int64(i), ModTime: int64(pagedSortMin*2 - i)})
+	}
+	dir.ComputeSizes()
+
+	for _, order := range []SortOrder{OrderSize, OrderCount, OrderAge} {
+		all := append([]*Node(nil), dir.Children...)
+		order.Sort(all)
+		page := order.Page(dir.Children, 10, 20)
+		for i, node := range page {
+			if node != all[10+i] {
+				t.Fatalf("%s: page entry %d is %s, expected %s", order, i, node.Name, all[10+i].Name)
+			}
+		}
+	}
+	if OrderAge.Next() != OrderSize {
+		t.Error("expected the orders to wrap around to size")
+	}
+}
@@ -0,0 +1,86 @@
+package model
+
+import "iter"
+
+// WalkOptions controls the order and extent of Walk
+type WalkOptions struct {
+	// BySize visits children largest first, in SortBySize order
+	BySize bool
+
+	// Skip leaves out the nodes it returns true for, along with everything below them
+	Skip func(*Node) bool
+}
+
+// Walk returns an iterator over root and its descendants in pre-order, each
+// directory before its contents. Breaking out of the loop ends the walk.
+func Walk(root *Node, opts WalkOptions) iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		if root != nil {
+			walkNode(root, opts, yield)
+		}
+	}
+}
+
+// walkNode visits n and its subtree, returning false once yield asks to stop
+func walkNode(n *Node, opts WalkOptions, yield func(*Node) bool) bool {
+	if opts.Skip != nil && opts.Skip(n) {
+		return true
+	}
+	if !yield(n) {
+		return false
+	}
+	children := n.Children
+	if opts.BySize && len(children) > 1 {
+		children = append([]*Node(nil), children...)
+		SortBySize(children)
+	}
+	for _, child := range children {
+		if !walkNode(child, opts, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Files returns an iterator over the files under n that take space on disk,
+// leaving out deleted files and estimated system space
+func (n *Node) Files() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for node := range Walk(n, WalkOptions{Skip: NotOnDisk}) {
+			if !node.IsDir && !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// NotOnDisk reports whether n is deleted or estimated space, which
+// traversals over real files leave out
+func NotOnDisk(n *Node) bool {
+	return n.IsDeleted || n.Attrs.Has(AttrVirtual)
+}
+
+// Flatten returns what lies depth levels below n as one list, largest first:
+// the nodes at that depth plus files and empty folders ending above it, so
+// together they account for all of n. Deleted nodes are left out.
+func (n *Node) Flatten(depth int) []*Node {
+	var result []*Node
+	var flatten func(node *Node, level int)
+	flatten = func(node *Node, level int) {
+		if node.IsDeleted {
+			return
+		}
+		if level == depth || len(node.Children) == 0 {
+			result = append(result, node)
+			return
+		}
+		for _, child := range node.Children {
+			flatten(child, level+1)
+		}
+	}
+	for _, child := range n.Children {
+		flatten(child, 1)
+	}
+	SortBySize(result)
+	return result
+}
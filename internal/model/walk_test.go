@@ -0,0 +1,86 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func walkTree() *Node {
+	root := &Node{Name: "root", IsDir: true, Children: []*Node{
+		{Name: "a", IsDir: true, Children: []*Node{
+			{Name: "a1", Size: 10},
+			{Name: "a2", Size: 300},
+		}},
+		{Name: "b", Size: 200},
+		{Name: "sys", Size: 50, Attrs: AttrVirtual},
+	}}
+	root.RebuildParentLinks()
+	root.ComputeSizes()
+	return root
+}
+
+func names(seq func(func(*Node) bool)) []string {
+	var out []string
+	for n := range seq {
+		out = append(out, n.Name)
+	}
+	return out
+}
+
+func TestWalk(t *testing.T) {
+	root := walkTree()
+
+	if got, want := names(Walk(root, WalkOptions{})), []string{"root", "a", "a1", "a2", "b", "sys"}; !slices.Equal(got, want) {
+		t.Errorf("pre-order: got %v, want %v", got, want)
+	}
+	if got, want := names(Walk(root, WalkOptions{BySize: true})), []string{"root", "a", "a2", "a1", "b", "sys"}; !slices.Equal(got, want) {
+		t.Errorf("by size: got %v, want %v", got, want)
+	}
+
+	skipA := WalkOptions{Skip: func(n *Node) bool { return n.Name == "a" }}
+	if got, want := names(Walk(root, skipA)), []string{"root", "b", "sys"}; !slices.Equal(got, want) {
+		t.Errorf("skip: got %v, want %v", got, want)
+	}
+
+	var first []string
+	for n := range Walk(root, WalkOptions{}) {
+		first = append(first, n.Name)
+		if len(first) == 3 {
+			break
+		}
+	}
+	if len(first) != 3 {
+		t.Errorf("expected the walk to stop after 3 nodes, got %v", first)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	root := walkTree()
+	root.Children[0].Children[0].MarkDeleted()
+
+	if got, want := names(root.Files()), []string{"a2", "b"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	root := walkTree()
+	root.Children[0].Children = append(root.Children[0].Children, &Node{Name: "a3", IsDir: true, Children: []*Node{{Name: "deep", Size: 40}}})
+	root.RebuildParentLinks()
+	root.ComputeSizes()
+
+	if got, want := names(slices.Values(root.Flatten(2))), []string{"a2", "b", "sys", "a3", "a1"}; !slices.Equal(got, want) {
+		t.Errorf("depth 2: got %v, want %v", got, want)
+	}
+	if got, want := names(slices.Values(root.Flatten(1))), []string{"a", "b", "sys"}; !slices.Equal(got, want) {
+		t.Errorf("depth 1: got %v, want %v", got, want)
+	}
+
+	var sum int64
+	for _, n := range root.Flatten(3) {
+		sum += n.TotalSize()
+	}
+	if sum != root.TotalSize() {
+		t.Errorf("flattened sizes sum to %d, want %d", sum, root.TotalSize())
+	}
+}
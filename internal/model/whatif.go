@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// Projection is the tree as it would look after deleting some of its nodes.
+// The zero value deletes nothing.
+type Projection struct {
+	gone    map[*Node]bool  // Nodes that would be deleted
+	removed map[*Node]int64 // Bytes leaving each ancestor of a deleted node
+	freed   int64
+}
+
+// NewProjection projects deleting nodes, none of which may be an ancestor
+// of another. Nodes already deleted or estimated free nothing.
+func NewProjection(nodes []*Node) Projection {
+	p := Projection{
+		gone:    make(map[*Node]bool, len(nodes)),
+		removed: make(map[*Node]int64),
+	}
+	for _, n := range nodes {
+		if NotOnDisk(n) {
+			continue
+		}
+		size := n.TotalSize()
+		p.gone[n] = true
+		p.freed += size
+		for a := n.Parent; a != nil; a = a.Parent {
+			p.removed[a] += size
+		}
+	}
+	return p
+}
+
+// Size returns how much n would take after the deletion
+func (p Projection) Size(n *Node) int64 {
+	if len(p.gone) == 0 {
+		return n.TotalSize()
+	}
+	for a := n; a != nil; a = a.Parent {
+		if p.gone[a] {
+			return 0
+		}
+	}
+	return max(n.TotalSize()-p.removed[n], 0)
+}
+
+// Freed returns the bytes the deletion would free
+func (p Projection) Freed() int64 {
+	return p.freed
+}
+
+// Count returns how many nodes the projection deletes
+func (p Projection) Count() int {
+	return len(p.gone)
+}
+
+// GrowthPerDay returns how many bytes a day usage grew from before to after
+// over elapsed. Shrinking usage counts as no growth.
+func GrowthPerDay(before, after int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 || after <= before {
+		return 0
+	}
+	return float64(after-before) / elapsed.Hours() * 24
+}
+
+// DaysUntilFull returns how many days free bytes last when usage grows by
+// perDay bytes a day, or false if usage is not growing
+func DaysUntilFull(free int64, perDay float64) (float64, bool) {
+	if perDay <= 0 {
+		return 0, false
+	}
+	return float64(max(free, 0)) / perDay, true
+}
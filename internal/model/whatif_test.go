@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProjection(t *testing.T) {
+	root := walkTree()
+	a := root.Children[0]
+	a2 := a.Children[1]
+	b := root.Children[1]
+
+	p := NewProjection([]*Node{a2, b})
+	if p.Freed() != 500 || p.Count() != 2 {
+		t.Errorf("Freed() = %d, Count() = %d, want 500, 2", p.Freed(), p.Count())
+	}
+	if got := p.Size(root); got != root.TotalSize()-500 {
+		t.Errorf("Size(root) = %d, want %d", got, root.TotalSize()-500)
+	}
+	if got := p.Size(a); got != 10 {
+		t.Errorf("Size(a) = %d, want 10", got)
+	}
+	if got := p.Size(b); got != 0 {
+		t.Errorf("Size(b) = %d, want 0", got)
+	}
+
+	// Descendants of a deleted folder go with it
+	p = NewProjection([]*Node{a})
+	if got := p.Size(a.Children[0]); got != 0 {
+		t.Errorf("Size(a1) = %d, want 0", got)
+	}
+
+	var none Projection
+	if none.Size(root) != root.TotalSize() || none.Freed() != 0 {
+		t.Error("zero Projection should leave sizes unchanged")
+	}
+}
+
+func TestProjectionSkipsDeleted(t *testing.T) {
+	root := walkTree()
+	b := root.Children[1]
+	b.MarkDeleted()
+
+	p := NewProjection([]*Node{b, root.Children[2]})
+	if p.Freed() != 0 || p.Count() != 0 {
+		t.Errorf("Freed() = %d, Count() = %d, want nothing for deleted and estimated nodes", p.Freed(), p.Count())
+	}
+}
+
+func TestDaysUntilFull(t *testing.T) {
+	perDay := GrowthPerDay(1000, 1700, 7*24*time.Hour)
+	if perDay != 100 {
+		t.Fatalf("GrowthPerDay() = %v, want 100", perDay)
+	}
+	if days, ok := DaysUntilFull(3000, perDay); !ok || days != 30 {
+		t.Errorf("DaysUntilFull() = %v, %v, want 30, true", days, ok)
+	}
+
+	if GrowthPerDay(1700, 1000, time.Hour) != 0 {
+		t.Error("shrinking usage should count as no growth")
+	}
+	if _, ok := DaysUntilFull(3000, 0); ok {
+		t.Error("DaysUntilFull() should report false without growth")
+	}
+}
@@ -0,0 +1,12 @@
+package scanner
+
+import "syscall"
+
+// ufCompressed is UF_COMPRESSED from <sys/stat.h>, set on files APFS and
+// HFS+ store compressed
+const ufCompressed = 0x00000020
+
+// isCompressed reports whether the file is stored compressed
+func isCompressed(path string, stat *syscall.Stat_t) bool {
+	return stat.Flags&ufCompressed != 0
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"os"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// Linux constants from <linux/fs.h>, <linux/fiemap.h> and <linux/magic.h>
+const (
+	fsIocFiemap         = 0xC020660B // FS_IOC_FIEMAP
+	fiemapExtentLast    = 0x00000001 // FIEMAP_EXTENT_LAST
+	fiemapExtentEncoded = 0x00000008 // FIEMAP_EXTENT_ENCODED
+	btrfsSuperMagic     = 0x9123683E // BTRFS_SUPER_MAGIC
+)
+
+// fiemapExtents is how many extents one FIEMAP call asks for
+const fiemapExtents = 32
+
+// fiemapExtent is struct fiemap_extent
+type fiemapExtent struct {
+	Logical  uint64
+	Physical uint64
+	Length   uint64
+	_        [2]uint64
+	Flags    uint32
+	_        [3]uint32
+}
+
+// fiemap is struct fiemap followed by room for its extents
+type fiemap struct {
+	Start         uint64
+	Length        uint64
+	Flags         uint32
+	MappedExtents uint32
+	ExtentCount   uint32
+	_             uint32
+	Extents       [fiemapExtents]fiemapExtent
+}
+
+// btrfsDevices remembers, per device, whether it holds a btrfs filesystem
+var btrfsDevices sync.Map
+
+// isCompressed reports whether the file is stored compressed. Only btrfs
+// compresses transparently here, and stat does not say so, so the file's
+// extents are asked for and any of them marked encoded counts. Files of a
+// block or less cannot save space and are not looked at.
+func isCompressed(path string, stat *syscall.Stat_t) bool {
+	if stat.Size <= int64(stat.Blksize) || !onBtrfs(path, uint64(stat.Dev)) {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	var m fiemap
+	for {
+		m.Length = ^uint64(0) - m.Start
+		m.ExtentCount = fiemapExtents
+		m.MappedExtents = 0
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), fsIocFiemap, uintptr(unsafe.Pointer(&m)))
+		if errno != 0 || m.MappedExtents == 0 {
+			return false
+		}
+		for _, e := range m.Extents[:m.MappedExtents] {
+			if e.Flags&fiemapExtentEncoded != 0 {
+				return true
+			}
+			if e.Flags&fiemapExtentLast != 0 {
+				return false
+			}
+		}
+		last := m.Extents[m.MappedExtents-1]
+		if last.Logical+last.Length <= m.Start {
+			return false
+		}
+		m.Start = last.Logical + last.Length
+	}
+}
+
+// onBtrfs reports whether path, on device dev, lies on a btrfs filesystem,
+// asking the filesystem once per device
+func onBtrfs(path string, dev uint64) bool {
+	if known, ok := btrfsDevices.Load(dev); ok {
+		return known.(bool)
+	}
+	var sfs syscall.Statfs_t
+	if err := syscall.Statfs(path, &sfs); err != nil {
+		return false
+	}
+	is := uint32(sfs.Type) == btrfsSuperMagic
+	btrfsDevices.Store(dev, is)
+	return is
+}
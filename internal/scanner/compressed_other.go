@@ -0,0 +1,11 @@
+//go:build !windows && !darwin && !linux
+
+package scanner
+
+import "syscall"
+
+// isCompressed reports whether the file is stored compressed, which stat
+// does not say here
+func isCompressed(path string, stat *syscall.Stat_t) bool {
+	return false
+}
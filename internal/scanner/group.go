@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// fileGroup collects the small files of one folder into a single entry
+type fileGroup struct {
+	entry nodeEntry
+	count int
+}
+
+// groupSmallFiles folds the smallest files of each folder into one entry per
+// folder until the entries and the root fit in budget nodes. Sizes are kept,
+// and the folded files still count in extensions. A folder with a single
+// small file keeps it, since folding it would save nothing.
+func groupSmallFiles(entries []nodeEntry, budget int, extensions model.ExtHistogram) []nodeEntry {
+	excess := len(entries) + 1 - budget
+	if excess <= 0 {
+		return entries
+	}
+
+	files := make([]int, 0, len(entries))
+	for i := range entries {
+		if !entries[i].isDir {
+			files = append(files, i)
+		}
+	}
+	slices.SortFunc(files, func(a, b int) int {
+		return cmp.Compare(entries[a].size, entries[b].size)
+	})
+
+	// Take the smallest files until folding them saves enough nodes. The
+	// first file taken in a folder saves nothing, as its group needs a node.
+	perDir := make(map[string]int)
+	taken, saved := 0, 0
+	for _, i := range files {
+		if saved >= excess {
+			break
+		}
+		dir := filepath.Dir(entries[i].path)
+		perDir[dir]++
+		if perDir[dir] > 1 {
+			saved++
+		}
+		taken++
+	}
+
+	groups := make(map[string]*fileGroup)
+	folded := make([]bool, len(entries))
+	for _, i := range files[:taken] {
+		e := &entries[i]
+		dir := filepath.Dir(e.path)
+		if perDir[dir] < 2 {
+			continue
+		}
+		g := groups[dir]
+		if g == nil {
+			g = &fileGroup{entry: nodeEntry{uid: e.uid, gid: e.gid, attrs: model.AttrGrouped}}
+			groups[dir] = g
+		}
+		g.count++
+		g.entry.size += e.size
+		g.entry.logical += e.logical
+		g.entry.modTime = max(g.entry.modTime, e.modTime)
+		if g.entry.uid != e.uid {
+			g.entry.uid = model.NoOwner
+		}
+		if g.entry.gid != e.gid {
+			g.entry.gid = model.NoOwner
+		}
+		extensions.Add(e.name, e.size)
+		folded[i] = true
+	}
+
+	kept := entries[:0]
+	for i := range entries {
+		if !folded[i] {
+			kept = append(kept, entries[i])
+		}
+	}
+	for dir, g := range groups {
+		g.entry.name = fmt.Sprintf("<%d small files>", g.count)
+		g.entry.path = filepath.Join(dir, g.entry.name)
+		kept = append(kept, g.entry)
+	}
+	return kept
+}
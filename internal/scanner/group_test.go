@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestGroupSmallFiles(t *testing.T) {
+	root := filepath.FromSlash("/scan")
+	a := filepath.Join(root, "a")
+	entries := []nodeEntry{
+		{path: a, name: "a", isDir: true},
+		{path: filepath.Join(a, "big.mp4"), name: "big.mp4", size: 1000},
+		{path: filepath.Join(a, "1.txt"), name: "1.txt", size: 1, uid: 7},
+		{path: filepath.Join(a, "2.txt"), name: "2.txt", size: 2, uid: 7},
+		{path: filepath.Join(a, "3.txt"), name: "3.txt", size: 3, uid: 7},
+		{path: filepath.Join(root, "lone.txt"), name: "lone.txt", size: 1},
+	}
+
+	ext := make(model.ExtHistogram)
+	got := groupSmallFiles(entries, 5, ext)
+
+	// Root plus 6 entries is 7 nodes; folding the three .txt files in a saves 2
+	if len(got) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(got))
+	}
+	var group *nodeEntry
+	for i := range got {
+		if got[i].attrs.Has(model.AttrGrouped) {
+			group = &got[i]
+		}
+	}
+	if group == nil {
+		t.Fatal("expected a grouped entry")
+	}
+	if group.name != "<3 small files>" || filepath.Dir(group.path) != a {
+		t.Errorf("unexpected group %q at %s", group.name, group.path)
+	}
+	if group.size != 6 || group.uid != 7 {
+		t.Errorf("expected size 6 owned by 7, got %d owned by %d", group.size, group.uid)
+	}
+	if ext[".txt"].Count != 3 || ext[".txt"].Bytes != 6 {
+		t.Errorf("folded files should still count by extension, got %+v", ext[".txt"])
+	}
+	for _, e := range got {
+		if strings.HasSuffix(e.name, ".txt") && filepath.Dir(e.path) == a {
+			t.Errorf("%s should have been folded", e.name)
+		}
+	}
+}
+
+func TestGroupSmallFilesUnderBudget(t *testing.T) {
+	entries := []nodeEntry{{path: "/scan/a.txt", name: "a.txt", size: 1}}
+	if got := groupSmallFiles(entries, 10, make(model.ExtHistogram)); len(got) != 1 {
+		t.Errorf("entries under budget should be left alone, got %d", len(got))
+	}
+}
@@ -0,0 +1,39 @@
+package scanner
+
+import "time"
+
+// Interference detection settings
+const (
+	interferenceMinPeak = 500.0           // files/s a scan must reach before a drop means anything
+	interferenceDrop    = 0.1             // throughput below this share of the peak counts as collapsed
+	interferenceMaxCPU  = 0.25            // share of one core below which the process counts as idle
+	interferenceHold    = 3 * time.Second // how long the collapse must last
+)
+
+// interferenceDetector notices throughput collapsing while workers wait on
+// the filesystem and the CPU sits idle. The scan is then held up by
+// something outside it, typically antivirus or an indexer inspecting each
+// file the walker opens.
+type interferenceDetector struct {
+	peak  float64   // best files/s seen
+	since time.Time // start of the current slow spell, zero if none
+}
+
+// sample feeds one progress sample and reports whether interference is suspected
+func (d *interferenceDetector) sample(now time.Time, filesPerSec, cpu, busy float64) bool {
+	if filesPerSec > d.peak {
+		d.peak = filesPerSec
+	}
+	slow := d.peak >= interferenceMinPeak &&
+		filesPerSec < d.peak*interferenceDrop &&
+		cpu < interferenceMaxCPU &&
+		busy >= 1
+	if !slow {
+		d.since = time.Time{}
+		return false
+	}
+	if d.since.IsZero() {
+		d.since = now
+	}
+	return now.Sub(d.since) >= interferenceHold
+}
@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterferenceDetector(t *testing.T) {
+	var d interferenceDetector
+	start := time.Now()
+	at := func(s float64) time.Time { return start.Add(time.Duration(s * float64(time.Second))) }
+
+	if d.sample(at(0), 5000, 1.5, 4) {
+		t.Error("fast scan flagged")
+	}
+	// Collapsed, idle and waiting, but not for long enough yet
+	if d.sample(at(1), 50, 0.05, 6) {
+		t.Error("flagged before the hold time")
+	}
+	if !d.sample(at(4.5), 40, 0.05, 6) {
+		t.Error("expected interference after a sustained collapse")
+	}
+	// Busy CPU means the scan itself is the bottleneck
+	if d.sample(at(5), 40, 0.9, 6) {
+		t.Error("flagged while the CPU was busy")
+	}
+	// Recovery resets the spell
+	if d.sample(at(6), 40, 0.05, 6) {
+		t.Error("hold time should restart after recovering")
+	}
+
+	var slowDisk interferenceDetector
+	for i := 0; i < 10; i++ {
+		if slowDisk.sample(at(float64(i)), 100, 0.05, 6) {
+			t.Fatal("a scan that was never fast should not be flagged")
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import "fmt"
+
+// ScanPolicy controls how the walker treats links and filesystem boundaries.
+// The zero value skips other filesystems, does not follow links and counts
+// hard-linked files once.
+type ScanPolicy struct {
+	// CrossMounts descends into directories on other filesystems
+	CrossMounts bool `json:"crossMounts"`
+
+	// FollowSymlinks descends into symlinked directories outside the scan root
+	// that live on the same device as the root
+	FollowSymlinks bool `json:"followSymlinks"`
+
+	// FollowJunctions descends into NTFS junctions (Windows only)
+	FollowJunctions bool `json:"followJunctions"`
+
+	// CountHardLinks counts every hard link to a file instead of only the first
+	CountHardLinks bool `json:"countHardLinks"`
+
+	// NodeBudget caps the nodes in the tree; past it the smallest files of
+	// each folder are grouped into one node. 0 keeps every file.
+	NodeBudget int `json:"nodeBudget"`
+}
+
+// Options returns the config names of the options that are switched on
+func (p ScanPolicy) Options() []string {
+	var opts []string
+	if p.CrossMounts {
+		opts = append(opts, "crossMounts")
+	}
+	if p.FollowSymlinks {
+		opts = append(opts, "followSymlinks")
+	}
+	if p.FollowJunctions {
+		opts = append(opts, "followJunctions")
+	}
+	if p.CountHardLinks {
+		opts = append(opts, "countHardLinks")
+	}
+	if p.NodeBudget > 0 {
+		opts = append(opts, fmt.Sprintf("nodeBudget=%d", p.NodeBudget))
+	}
+	return opts
+}
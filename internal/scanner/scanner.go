@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"time"
 
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
@@ -11,7 +12,27 @@ type Progress struct {
 	FilesScanned int64
 	DirsScanned  int64
 	BytesFound   int64
-	CurrentPath  string
+	CurrentPath  string  // Directory most recently entered
+	FilesPerSec  float64 // Throughput over the last second
+	BytesPerSec  float64
+
+	// Disk activity, to tell a disk-bound scan from one stuck on a directory
+	Busy    float64       // Workers waiting on the filesystem, averaged over the last second
+	Workers int           // Parallel workers the walker runs
+	Stalled time.Duration // Time since the last file or directory was counted
+
+	// CPU is the share of one core the process used over the last second
+	CPU float64
+	// Interference is set when throughput collapsed while workers waited on
+	// the filesystem and the CPU idled, as when antivirus inspects each file
+	Interference bool
+}
+
+// SkipStats summarizes what a scan could not or chose not to count
+type SkipStats struct {
+	Inaccessible      int64    // Directories that could not be read
+	InaccessiblePaths []string // The first few unreadable directories
+	Mounts            int64    // Directories on other filesystems that were skipped
 }
 
 // Scanner defines the interface for filesystem scanning
@@ -21,4 +42,13 @@ type Scanner interface {
 
 	// Progress returns a channel that receives progress updates
 	Progress() <-chan Progress
+
+	// Extensions returns the per-extension histogram gathered by the last scan
+	Extensions() model.ExtHistogram
+
+	// Skipped returns what the last scan could not or chose not to count
+	Skipped() SkipStats
+
+	// SlowPaths returns the directories the last scan spent longest on
+	SlowPaths() []SlowPath
 }
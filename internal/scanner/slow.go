@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"cmp"
+	"hash/maphash"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSlowPaths is how many of the slowest directories a scan remembers
+const maxSlowPaths = 10
+
+// timerShards is how many parts a dirTimer is split into, so workers timing
+// different directories seldom wait on each other
+const timerShards = 64
+
+// SlowPath is a directory that took long to enumerate. Slow directories
+// point at dying disks, cloud placeholders being fetched or antivirus
+// software inspecting every file.
+type SlowPath struct {
+	Path     string
+	Duration time.Duration // From the first entry read to the last one handled
+	Entries  int
+}
+
+// dirTiming spans the handling of one directory's entries
+type dirTiming struct {
+	first   time.Time
+	last    time.Time
+	entries int
+}
+
+// dirTimer records how long each directory takes to enumerate. A directory
+// is read by a single worker, so the span of its entries covers reading it
+// and stat-ing its contents, though not opening it. Directories are spread
+// over shards by path, each with its own lock, and each shard picks its
+// slowest before they are merged.
+type dirTimer struct {
+	seed   maphash.Seed
+	once   sync.Once
+	shards [timerShards]timerShard
+}
+
+// timerShard holds the timings of the directories whose paths hash to it
+type timerShard struct {
+	mu   sync.Mutex
+	dirs map[string]dirTiming
+}
+
+// record notes that an entry of dir was handled between began and ended
+func (t *dirTimer) record(dir string, began, ended time.Time) {
+	t.once.Do(func() { t.seed = maphash.MakeSeed() })
+	s := &t.shards[maphash.String(t.seed, dir)%timerShards]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirs == nil {
+		s.dirs = make(map[string]dirTiming)
+	}
+	d, ok := s.dirs[dir]
+	if !ok {
+		s.dirs[dir] = dirTiming{first: began, last: ended, entries: 1}
+		return
+	}
+	if began.Before(d.first) {
+		d.first = began
+	}
+	if ended.After(d.last) {
+		d.last = ended
+	}
+	d.entries++
+	s.dirs[dir] = d
+}
+
+// slowest returns up to n directories that took longest, slowest first,
+// and releases the timings
+func (t *dirTimer) slowest(n int) []SlowPath {
+	var paths []SlowPath
+	for i := range t.shards {
+		paths = append(paths, t.shards[i].slowest(n)...)
+	}
+	slices.SortFunc(paths, compareSlow)
+	if len(paths) > n {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+// slowest returns up to n directories of the shard that took longest,
+// slowest first, and releases the timings
+func (s *timerShard) slowest(n int) []SlowPath {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]SlowPath, 0, len(s.dirs))
+	for dir, d := range s.dirs {
+		paths = append(paths, SlowPath{Path: dir, Duration: d.last.Sub(d.first), Entries: d.entries})
+	}
+	s.dirs = nil
+
+	slices.SortFunc(paths, compareSlow)
+	if len(paths) > n {
+		paths = paths[:n]
+	}
+	return paths
+}
+
+// compareSlow orders slow paths slowest first, then by path
+func compareSlow(a, b SlowPath) int {
+	if a.Duration != b.Duration {
+		return cmp.Compare(b.Duration, a.Duration)
+	}
+	return strings.Compare(a.Path, b.Path)
+}
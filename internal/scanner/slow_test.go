@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirTimerSlowest(t *testing.T) {
+	var timer dirTimer
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	timer.record("/fast", at(0), at(1))
+	timer.record("/slow", at(5), at(6))
+	timer.record("/slow", at(0), at(2))
+	timer.record("/slow", at(200), at(300))
+	timer.record("/mid", at(10), at(60))
+
+	got := timer.slowest(2)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(got))
+	}
+	if got[0].Path != "/slow" || got[0].Duration != 300*time.Millisecond || got[0].Entries != 3 {
+		t.Errorf("unexpected slowest %+v", got[0])
+	}
+	if got[1].Path != "/mid" {
+		t.Errorf("expected /mid second, got %+v", got[1])
+	}
+	for i := range timer.shards {
+		if timer.shards[i].dirs != nil {
+			t.Error("expected timings to be released")
+		}
+	}
+}
+
+func TestWalkerSlowPaths(t *testing.T) {
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, "sub"), 0755)
+	os.WriteFile(filepath.Join(tmp, "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(tmp, "sub", "b.txt"), []byte("b"), 0644)
+	os.WriteFile(filepath.Join(tmp, "sub", "c.txt"), []byte("c"), 0644)
+
+	w := NewWalker(2, ScanPolicy{})
+	if _, err := w.Scan(context.Background(), tmp); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	entries := make(map[string]int)
+	for _, p := range w.SlowPaths() {
+		entries[p.Path] = p.Entries
+	}
+	if entries[filepath.Join(tmp, "sub")] != 2 {
+		t.Errorf("expected sub timed over 2 entries, got %v", entries)
+	}
+}
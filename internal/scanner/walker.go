@@ -3,7 +3,9 @@ package scanner
 import (
 	"context"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -15,18 +17,54 @@ import (
 // Walker implements parallel filesystem scanning
 type Walker struct {
 	workers    int
+	policy     ScanPolicy
 	progressCh chan Progress
 	progress   Progress
+	current    atomic.Pointer[string] // directory most recently entered
+	busy       atomic.Int64           // filesystem calls in flight
+	extensions model.ExtHistogram
+	skipped    SkipStats
+	timer      dirTimer
+	slow       []SlowPath
 	mu         sync.Mutex
 }
 
+// maxInaccessiblePaths limits how many unreadable directories are remembered
+const maxInaccessiblePaths = 20
+
+// skipReason explains why a directory is left out of a scan
+type skipReason int
+
+const (
+	skipNone      skipReason = iota
+	skipMount                // On another filesystem
+	skipDuplicate            // Already reached through another path (firmlinks)
+)
+
+// Progress reporting settings
+const (
+	progressInterval = 200 * time.Millisecond
+	rateWindow       = 5 // samples averaged for throughput (5 x 200ms = 1s)
+	busyInterval     = 10 * time.Millisecond
+)
+
+// progressSample is a snapshot of the counters used to compute throughput
+type progressSample struct {
+	at    time.Time
+	files int64
+	bytes int64
+	busy  float64       // average filesystem calls in flight since the previous sample
+	cpu   time.Duration // process CPU time
+}
+
 // NewWalker creates a new parallel filesystem walker
-func NewWalker(workers int) *Walker {
+func NewWalker(workers int, policy ScanPolicy) *Walker {
 	if workers < 1 {
 		workers = 8
 	}
 	return &Walker{
 		workers:    workers,
+		policy:     policy,
 		progressCh: make(chan Progress, 100),
 	}
 }
@@ -36,12 +74,44 @@ func (w *Walker) Progress() <-chan Progress {
 	return w.progressCh
 }
 
+// Extensions returns the per-extension histogram built during the last scan
+func (w *Walker) Extensions() model.ExtHistogram {
+	return w.extensions
+}
+
+// Skipped returns what the last scan could not or chose not to count
+func (w *Walker) Skipped() SkipStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.skipped
+}
+
+// SlowPaths returns the directories the last scan took longest to enumerate
+func (w *Walker) SlowPaths() []SlowPath {
+	return w.slow
+}
+
+// recordInaccessible notes a directory whose contents could not be read
+func (w *Walker) recordInaccessible(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.skipped.Inaccessible++
+	if len(w.skipped.InaccessiblePaths) < maxInaccessiblePaths {
+		w.skipped.InaccessiblePaths = append(w.skipped.InaccessiblePaths, path)
+	}
+}
+
 // nodeEntry is a temporary structure for building the tree
 type nodeEntry struct {
-	path  string
-	name  string
-	size  int64
-	isDir bool
+	path    string
+	name    string
+	size    int64
+	logical int64
+	modTime int64
+	uid     model.OwnerID
+	gid     model.OwnerID
+	isDir   bool
+	attrs   model.NodeAttr
 }
 
 // Scan scans the filesystem starting at root using fastwalk
@@ -62,95 +132,141 @@ func (w *Walker) Scan(ctx context.Context, root string) (*model.Node, error) {
 	// Track seen paths/inodes for deduplication
 	var seenItems sync.Map
 
+	// Link targets are resolved against the real root so targets inside it are not counted twice
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		realRoot = absRoot
+	}
+
+	// Linked directories to descend into once the main walk finishes
+	var links []string
+
 	// Configure fastwalk
 	conf := &fastwalk.Config{
-		Follow:     false, // Don't follow symlinks
+		Follow:     false, // Links are followed per policy via separate walks
 		NumWorkers: w.workers,
 	}
 
 	// Start progress reporter goroutine
 	done := make(chan struct{})
-	go func() {
-		ticker := time.NewTicker(200 * time.Millisecond)
-		defer ticker.Stop()
-		for {
+	go w.reportProgress(done)
+
+	walkFn := func(walkRoot string) fs.WalkDirFunc {
+		return func(path string, d fs.DirEntry, err error) error {
+			// Check context cancellation
 			select {
-			case <-done:
-				return
-			case <-ticker.C:
-				// Send current progress (non-blocking)
-				select {
-				case w.progressCh <- Progress{
-					FilesScanned: atomic.LoadInt64(&w.progress.FilesScanned),
-					DirsScanned:  atomic.LoadInt64(&w.progress.DirsScanned),
-					BytesFound:   atomic.LoadInt64(&w.progress.BytesFound),
-				}:
-				default:
-				}
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
 			}
-		}
-	}()
 
-	// Walk filesystem with fastwalk
-	walkErr := fastwalk.Walk(conf, absRoot, func(path string, d fs.DirEntry, err error) error {
-		// Check context cancellation
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+			if err != nil {
+				if d != nil && d.IsDir() {
+					w.recordInaccessible(path)
+				}
+				return nil // Skip entries with errors
+			}
 
-		if err != nil {
-			return nil // Skip entries with errors
-		}
+			// Skip the root itself (already recorded for linked directories)
+			if path == walkRoot {
+				return nil
+			}
 
-		// Skip the root itself
-		if path == absRoot {
-			return nil
-		}
+			// Time the entry against its directory for the slow paths report
+			began := time.Now()
+			defer func() { w.timer.record(filepath.Dir(path), began, time.Now()) }()
+
+			isDir := d.IsDir()
+			attrs := entryAttrs(path, d)
+			uid, gid := model.NoOwner, model.NoOwner
+			appendEntry := func(size, logical, modTime int64, isDir bool) {
+				entriesMu.Lock()
+				entries = append(entries, nodeEntry{
+					path:    path,
+					name:    d.Name(),
+					size:    size,
+					logical: logical,
+					modTime: modTime,
+					uid:     uid,
+					gid:     gid,
+					isDir:   isDir,
+					attrs:   attrs,
+				})
+				entriesMu.Unlock()
+			}
 
-		// Platform-specific directory checks (mount points, firmlinks)
-		if d.IsDir() {
-			if shouldSkipDir(path, d, rootInfo, &seenItems) {
+			// Links reported as directories are recorded without descending
+			// unless followed, so they cannot loop or double-count
+			if linkedDir(isDir, attrs) && !w.followLink(path, attrs, realRoot, rootInfo, &seenItems) {
+				appendEntry(0, 0, 0, false)
 				return fs.SkipDir
 			}
-		}
 
-		var size int64
-		if !d.IsDir() {
-			info, err := d.Info()
-			if err != nil {
-				return nil
+			// Platform-specific directory checks (mount points, firmlinks)
+			if isDir {
+				switch shouldSkipDir(path, d, rootInfo, &seenItems, w.policy) {
+				case skipMount:
+					w.mu.Lock()
+					w.skipped.Mounts++
+					w.mu.Unlock()
+					return fs.SkipDir
+				case skipDuplicate:
+					return fs.SkipDir
+				}
+				w.current.Store(&path)
 			}
 
-			// Get file size (platform-specific for accurate disk usage)
-			size = getFileSize(info, &seenItems)
-			if size < 0 {
-				// Negative means skip (e.g., already counted hard link)
-				return nil
+			if !isDir && attrs.IsLink() && w.followLink(path, attrs, realRoot, rootInfo, &seenItems) {
+				// Record the link as a directory and walk its target afterwards
+				isDir = true
+				entriesMu.Lock()
+				links = append(links, path)
+				entriesMu.Unlock()
 			}
 
-			atomic.AddInt64(&w.progress.FilesScanned, 1)
-			atomic.AddInt64(&w.progress.BytesFound, size)
-		} else {
-			atomic.AddInt64(&w.progress.DirsScanned, 1)
-		}
+			var size, logical, modTime int64
+			if !isDir {
+				info, err := w.stat(d)
+				if err != nil {
+					return nil
+				}
+				modTime = info.ModTime().Unix()
+				uid, gid = model.OwnerOf(info)
+
+				// Get file size (platform-specific for accurate disk usage)
+				size, logical = getFileSize(path, info, &seenItems, w.policy)
+				if size < 0 {
+					// Negative means skip (e.g., already counted hard link)
+					return nil
+				}
+				attrs |= storageAttrs(path, info, size, logical)
+
+				atomic.AddInt64(&w.progress.FilesScanned, 1)
+				atomic.AddInt64(&w.progress.BytesFound, size)
+			} else {
+				if info, err := w.stat(d); err == nil {
+					modTime = info.ModTime().Unix()
+					uid, gid = model.OwnerOf(info)
+				}
+				atomic.AddInt64(&w.progress.DirsScanned, 1)
+			}
 
-		// Append to entries slice
-		entriesMu.Lock()
-		entries = append(entries, nodeEntry{
-			path:  path,
-			name:  d.Name(),
-			size:  size,
-			isDir: d.IsDir(),
-		})
-		entriesMu.Unlock()
+			appendEntry(size, logical, modTime, isDir)
+			return nil
+		}
+	}
 
-		return nil
-	})
+	// Walk filesystem with fastwalk, then any linked directories found along the way
+	walkErr := fastwalk.Walk(conf, absRoot, walkFn(absRoot))
+	for len(links) > 0 && walkErr == nil {
+		link := links[0]
+		links = links[1:]
+		walkErr = fastwalk.Walk(conf, link, walkFn(link))
+	}
 
 	// Stop progress reporter
 	close(done)
+	w.slow = w.timer.slowest(maxSlowPaths)
 
 	if walkErr != nil && walkErr != ctx.Err() {
 		close(w.progressCh)
@@ -164,8 +280,135 @@ func (w *Walker) Scan(ctx context.Context, root string) (*model.Node, error) {
 	return rootNode, nil
 }
 
+// stat reads an entry's metadata, counting the call as disk activity
+func (w *Walker) stat(d fs.DirEntry) (fs.FileInfo, error) {
+	w.busy.Add(1)
+	defer w.busy.Add(-1)
+	return d.Info()
+}
+
+// reportProgress periodically sends progress with throughput until done is closed
+func (w *Walker) reportProgress(done <-chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	busyTicker := time.NewTicker(busyInterval)
+	defer busyTicker.Stop()
+
+	samples := make([]progressSample, 0, rateWindow+1)
+	var busySum, busyCount int64
+	var lastCount int64
+	lastChange := time.Now()
+	var detector interferenceDetector
+	for {
+		select {
+		case <-done:
+			return
+		case <-busyTicker.C:
+			busySum += w.busy.Load()
+			busyCount++
+		case now := <-ticker.C:
+			p := Progress{
+				FilesScanned: atomic.LoadInt64(&w.progress.FilesScanned),
+				DirsScanned:  atomic.LoadInt64(&w.progress.DirsScanned),
+				BytesFound:   atomic.LoadInt64(&w.progress.BytesFound),
+				Workers:      w.workers,
+			}
+			if current := w.current.Load(); current != nil {
+				p.CurrentPath = *current
+			}
+
+			// Time since the counters last moved
+			if count := p.FilesScanned + p.DirsScanned; count != lastCount {
+				lastCount = count
+				lastChange = now
+			}
+			p.Stalled = now.Sub(lastChange)
+
+			// Throughput and disk activity over the sliding window
+			sample := progressSample{at: now, files: p.FilesScanned, bytes: p.BytesFound, cpu: processCPUTime()}
+			if busyCount > 0 {
+				sample.busy = float64(busySum) / float64(busyCount)
+			}
+			busySum, busyCount = 0, 0
+			samples = append(samples, sample)
+			if len(samples) > rateWindow+1 {
+				samples = samples[1:]
+			}
+			if oldest := samples[0]; len(samples) > 1 {
+				secs := now.Sub(oldest.at).Seconds()
+				p.FilesPerSec = float64(p.FilesScanned-oldest.files) / secs
+				p.BytesPerSec = float64(p.BytesFound-oldest.bytes) / secs
+				for _, s := range samples[1:] {
+					p.Busy += s.busy
+				}
+				p.Busy /= float64(len(samples) - 1)
+				p.CPU = float64(sample.cpu-oldest.cpu) / float64(now.Sub(oldest.at))
+				p.Interference = detector.sample(now, p.FilesPerSec, p.CPU, p.Busy)
+			}
+
+			// Send current progress (non-blocking)
+			select {
+			case w.progressCh <- p:
+			default:
+			}
+		}
+	}
+}
+
+// followLink reports whether a symlink or junction should be descended into
+// under the walker's policy. Targets inside the scan root, on other devices
+// (unless crossing mounts) or already followed are skipped, so links pointing
+// back up the tree can neither loop nor double-count.
+func (w *Walker) followLink(path string, attrs model.NodeAttr, realRoot string, rootInfo platformRootInfo, seenItems *sync.Map) bool {
+	switch {
+	case attrs.Has(model.AttrJunction):
+		if !w.policy.FollowJunctions {
+			return false
+		}
+	case attrs.Has(model.AttrSymlink):
+		if !w.policy.FollowSymlinks {
+			return false
+		}
+	default:
+		return false
+	}
+
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() {
+		return false
+	}
+	if isWithin(target, realRoot) {
+		return false // Counted where it lives
+	}
+	if !w.policy.CrossMounts && !sameDevice(target, info, rootInfo) {
+		return false
+	}
+	_, seen := seenItems.LoadOrStore("link:"+target, true)
+	return !seen
+}
+
+// isWithin reports whether path equals dir or lies inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
 // buildTree constructs the tree structure from flat entries
 func (w *Walker) buildTree(rootPath string, entries []nodeEntry) *model.Node {
+	w.extensions = make(model.ExtHistogram)
+
+	// Past the node budget, small files are folded into one node per folder
+	if w.policy.NodeBudget > 0 {
+		entries = groupSmallFiles(entries, w.policy.NodeBudget, w.extensions)
+	}
+
 	// Map to hold all nodes
 	nodes := make(map[string]*model.Node, len(entries)+1)
 	// Map to count children per directory (for pre-allocation)
@@ -173,10 +416,12 @@ func (w *Walker) buildTree(rootPath string, entries []nodeEntry) *model.Node {
 
 	// Create root node
 	rootNode := &model.Node{
-		Path:  rootPath,
 		Name:  filepath.Base(rootPath),
 		IsDir: true,
+		UID:   model.NoOwner,
+		GID:   model.NoOwner,
 	}
+	rootNode.SetPath(rootPath)
 	nodes[rootPath] = rootNode
 
 	// First pass: count children per parent and create nodes
@@ -187,12 +432,23 @@ func (w *Walker) buildTree(rootPath string, entries []nodeEntry) *model.Node {
 		parentPath := filepath.Dir(e.path)
 		childCounts[parentPath]++
 
+		var category model.Category
+		if !e.isDir && !e.attrs.Has(model.AttrGrouped) {
+			w.extensions.Add(e.name, e.size)
+			category = model.CategoryOf(e.name)
+		}
+
 		// Create node
 		nodes[e.path] = &model.Node{
-			Path:  e.path,
-			Name:  e.name,
-			Size:  e.size,
-			IsDir: e.isDir,
+			Name:     e.name,
+			Size:     e.size,
+			Logical:  e.logical,
+			ModTime:  e.modTime,
+			UID:      e.uid,
+			GID:      e.gid,
+			IsDir:    e.isDir,
+			Attrs:    e.attrs,
+			Category: category,
 		}
 	}
 
@@ -217,5 +473,19 @@ func (w *Walker) buildTree(rootPath string, entries []nodeEntry) *model.Node {
 	return rootNode
 }
 
+// EntryAttrs returns the attributes a scan gives the entry at path, for
+// entries found after the scan
+func EntryAttrs(path string, d fs.DirEntry) model.NodeAttr {
+	return entryAttrs(path, d)
+}
+
+// FileSize returns the space the file at path takes on disk and its logical
+// size as a scan under policy counts them, for files that changed after the
+// scan. A file with other hard links counts in full, as the links the scan
+// saw are not known here.
+func FileSize(path string, info fs.FileInfo, policy ScanPolicy) (disk, logical int64) {
+	return getFileSize(path, info, &sync.Map{}, policy)
+}
+
 // Ensure Walker implements Scanner
 var _ Scanner = (*Walker)(nil)
@@ -4,7 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
 func TestWalkerScan(t *testing.T) {
@@ -17,7 +21,7 @@ func TestWalkerScan(t *testing.T) {
 	os.WriteFile(filepath.Join(tmp, "subdir", "file2.txt"), []byte("world!"), 0644)
 
 	// Scan
-	w := NewWalker(4)
+	w := NewWalker(4, ScanPolicy{})
 	root, err := w.Scan(context.Background(), tmp)
 	if err != nil {
 		t.Fatalf("scan failed: %v", err)
@@ -45,3 +49,147 @@ func TestWalkerScan(t *testing.T) {
 		t.Errorf("expected 2 children, got %d", len(root.Children))
 	}
 }
+
+func TestWalkerExtensions(t *testing.T) {
+	tmp := t.TempDir()
+	os.WriteFile(filepath.Join(tmp, "a.txt"), []byte("hello"), 0644)
+	os.WriteFile(filepath.Join(tmp, "b.TXT"), []byte("world"), 0644)
+	os.WriteFile(filepath.Join(tmp, "c.go"), []byte("package c"), 0644)
+
+	w := NewWalker(4, ScanPolicy{})
+	if _, err := w.Scan(context.Background(), tmp); err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+
+	hist := w.Extensions()
+	if hist[".txt"] == nil || hist[".txt"].Count != 2 {
+		t.Errorf("expected 2 .txt files, got %+v", hist[".txt"])
+	}
+	if hist[".go"] == nil || hist[".go"].Count != 1 {
+		t.Errorf("expected 1 .go file, got %+v", hist[".go"])
+	}
+}
+
+func TestWalkerPolicyFollowSymlinks(t *testing.T) {
+	tmp := t.TempDir()
+	outside := filepath.Join(tmp, "outside")
+	root := filepath.Join(tmp, "root")
+	os.MkdirAll(outside, 0755)
+	os.MkdirAll(filepath.Join(root, "inner"), 0755)
+	os.WriteFile(filepath.Join(outside, "data.bin"), []byte("outside"), 0644)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	// Links back into the root are never followed
+	os.Symlink(filepath.Join(root, "inner"), filepath.Join(root, "loop"))
+
+	find := func(node *model.Node, name string) *model.Node {
+		for _, c := range node.Children {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+
+	// Default policy records the link without descending
+	rootNode, err := NewWalker(4, ScanPolicy{}).Scan(context.Background(), root)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if link := find(rootNode, "link"); link == nil || link.IsDir || !link.Attrs.Has(model.AttrSymlink) {
+		t.Errorf("expected link to be recorded as a tagged file, got %+v", link)
+	}
+
+	rootNode, err = NewWalker(4, ScanPolicy{FollowSymlinks: true}).Scan(context.Background(), root)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	link := find(rootNode, "link")
+	if link == nil || !link.IsDir || find(link, "data.bin") == nil {
+		t.Errorf("expected link to be followed, got %+v", link)
+	}
+	if loop := find(rootNode, "loop"); loop == nil || loop.IsDir {
+		t.Errorf("expected link into root not to be followed, got %+v", loop)
+	}
+}
+
+func TestWalkerHidden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("dotfiles are not hidden on Windows")
+	}
+	tmp := t.TempDir()
+	os.MkdirAll(filepath.Join(tmp, ".config"), 0755)
+	os.WriteFile(filepath.Join(tmp, ".config", "settings"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(tmp, "visible.txt"), []byte("x"), 0644)
+
+	root, err := NewWalker(4, ScanPolicy{}).Scan(context.Background(), tmp)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	for _, child := range root.Children {
+		if hidden := child.Attrs.Has(model.AttrHidden); hidden != (child.Name == ".config") {
+			t.Errorf("%s: hidden = %v", child.Name, hidden)
+		}
+	}
+}
+
+func TestWalkerModTime(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "old.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWalker(4, ScanPolicy{})
+	root, err := w.Scan(context.Background(), tmp)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(root.Children))
+	}
+	if got := root.Children[0].Modified(); !got.Equal(old) {
+		t.Errorf("expected mtime %v, got %v", old, got)
+	}
+}
+
+func TestWalkerSparseFile(t *testing.T) {
+	tmp := t.TempDir()
+	path := filepath.Join(tmp, "disk.img")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Extending without writing leaves a hole on filesystems that support them
+	f.Truncate(64 << 20)
+	f.Close()
+	os.WriteFile(filepath.Join(tmp, "plain.txt"), []byte("hello"), 0644)
+
+	root, err := NewWalker(2, ScanPolicy{}).Scan(context.Background(), tmp)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	var img, plain *model.Node
+	for _, child := range root.Children {
+		switch child.Name {
+		case "disk.img":
+			img = child
+		case "plain.txt":
+			plain = child
+		}
+	}
+	if plain == nil || plain.Attrs.Has(model.AttrSparse) || plain.Attrs.Has(model.AttrCompressed) {
+		t.Errorf("expected a plain file without storage flags, got %+v", plain)
+	}
+	if img == nil || img.Size >= img.Logical {
+		t.Skip("filesystem allocated the whole file")
+	}
+	if !img.Attrs.Has(model.AttrSparse) && !img.Attrs.Has(model.AttrCompressed) {
+		t.Errorf("expected %d bytes on disk for %d logical to be flagged", img.Size, img.Logical)
+	}
+}
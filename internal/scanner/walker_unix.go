@@ -4,8 +4,12 @@ package scanner
 
 import (
 	"io/fs"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
 // platformRootInfo holds platform-specific root information
@@ -22,48 +26,107 @@ func getPlatformRootInfo(path string) platformRootInfo {
 	return platformRootInfo{dev: uint64(stat.Dev)}
 }
 
-// shouldSkipDir returns true if the directory should be skipped
-func shouldSkipDir(path string, d fs.DirEntry, rootInfo platformRootInfo, seenItems *sync.Map) bool {
+// shouldSkipDir returns why the directory should be skipped, if at all
+func shouldSkipDir(path string, d fs.DirEntry, rootInfo platformRootInfo, seenItems *sync.Map, policy ScanPolicy) skipReason {
 	info, err := d.Info()
 	if err != nil {
-		return false
+		return skipNone
 	}
 
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
-		return false
+		return skipNone
 	}
 
 	// Skip if different filesystem (mount point)
-	if uint64(stat.Dev) != rootInfo.dev {
-		return true
+	if !policy.CrossMounts && uint64(stat.Dev) != rootInfo.dev {
+		return skipMount
 	}
 
 	// Skip if already seen this inode (firmlinks on macOS)
 	inode := stat.Ino
 	if _, exists := seenItems.LoadOrStore(inode, true); exists {
-		return true
+		return skipDuplicate
 	}
 
+	return skipNone
+}
+
+// entryAttrs returns special attributes of an entry: AttrSymlink for
+// symlinks and AttrHidden for dotfiles
+func entryAttrs(path string, d fs.DirEntry) model.NodeAttr {
+	var attrs model.NodeAttr
+	if strings.HasPrefix(d.Name(), ".") {
+		attrs |= model.AttrHidden
+	}
+	if d.Type()&fs.ModeSymlink != 0 {
+		attrs |= model.AttrSymlink
+	}
+	return attrs
+}
+
+// linkedDir reports whether a directory entry is a link; never on Unix, where
+// the walk reports links as links and junctions do not exist
+func linkedDir(isDir bool, attrs model.NodeAttr) bool {
 	return false
 }
 
-// getFileSize returns the file size, or -1 if the file should be skipped
-func getFileSize(info fs.FileInfo, seenItems *sync.Map) int64 {
+// sameDevice reports whether a resolved link target is on the root's filesystem
+func sameDevice(target string, info fs.FileInfo, rootInfo platformRootInfo) bool {
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
-		return info.Size()
+		return true
+	}
+	return uint64(stat.Dev) == rootInfo.dev
+}
+
+// getFileSize returns the space a file takes on disk and its logical size,
+// or a negative disk size if the file should be skipped.
+// On ZFS and APFS the allocated blocks reflect compression; btrfs reports
+// blocks before compression, so there the two sizes only differ for sparse
+// files, and compressed ones are only flagged, by storageAttrs.
+func getFileSize(path string, info fs.FileInfo, seenItems *sync.Map, policy ScanPolicy) (disk, logical int64) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size(), info.Size()
 	}
 
 	// Check for hard links (nlink > 1)
-	if stat.Nlink > 1 {
+	if stat.Nlink > 1 && !policy.CountHardLinks {
 		inode := stat.Ino
 		if _, exists := seenItems.LoadOrStore(inode, true); exists {
-			return -1 // Already counted
+			return -1, 0 // Already counted
 		}
 	}
 
-	// Use actual blocks allocated (handles sparse files)
+	// Use actual blocks allocated (handles sparse and compressed files)
 	// Blocks is in 512-byte units
-	return stat.Blocks * 512
+	return stat.Blocks * 512, info.Size()
+}
+
+// storageAttrs flags files that take less space than they hold. stat cannot
+// tell holes from compression, so apart from files the platform marks as
+// compressed, a shortfall of at least a block counts as sparse; on ZFS and
+// other compressing filesystems that may be compression too.
+func storageAttrs(path string, info fs.FileInfo, disk, logical int64) model.NodeAttr {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0
+	}
+	if isCompressed(path, stat) {
+		return model.AttrCompressed
+	}
+	if logical-disk >= int64(stat.Blksize) && stat.Blksize > 0 {
+		return model.AttrSparse
+	}
+	return 0
+}
+
+// processCPUTime returns the CPU time the process has used so far
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
 }
@@ -4,26 +4,196 @@ package scanner
 
 import (
 	"io/fs"
+	"path/filepath"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+var (
+	kernel32               = syscall.NewLazyDLL("kernel32.dll")
+	getCompressedFileSizeW = kernel32.NewProc("GetCompressedFileSizeW")
+)
+
+// File attributes and reparse tags not defined in syscall
+const (
+	fileAttributeSparseFile         = 0x00000200
+	fileAttributeCompressed         = 0x00000800
+	fileAttributeOffline            = 0x00001000
+	fileAttributeRecallOnOpen       = 0x00040000
+	fileAttributeRecallOnDataAccess = 0x00400000
+
+	ioReparseTagMountPoint = 0xA0000003
+	ioReparseTagSymlink    = 0xA000000C
+	ioReparseTagCloud      = 0x9000001A // Low bits of the tag vary between cloud providers
+	ioReparseTagCloudMask  = 0xFFFF0FFF
+
+	invalidFileSize = 0xFFFFFFFF
 )
 
 // platformRootInfo holds platform-specific root information
 type platformRootInfo struct {
 	// Windows doesn't need mount point detection - drives are separate
+	volume string // Volume name used to keep followed links on the same drive
 }
 
 // getPlatformRootInfo returns platform-specific info about the root path
 func getPlatformRootInfo(path string) platformRootInfo {
-	return platformRootInfo{}
+	return platformRootInfo{volume: filepath.VolumeName(path)}
 }
 
-// shouldSkipDir returns true if the directory should be skipped
+// shouldSkipDir returns why the directory should be skipped, if at all
 // On Windows, we don't need to check for mount points since drives are separate
-func shouldSkipDir(path string, d fs.DirEntry, rootInfo platformRootInfo, seenItems *sync.Map) bool {
-	return false
+func shouldSkipDir(path string, d fs.DirEntry, rootInfo platformRootInfo, seenItems *sync.Map, policy ScanPolicy) skipReason {
+	return skipNone
+}
+
+// entryAttrs returns special attributes of an entry: symlinks, junctions
+// (which Go reports as plain directories), cloud placeholders such as
+// OneDrive files and folders, other reparse points, and entries flagged
+// hidden or system
+func entryAttrs(path string, d fs.DirEntry) model.NodeAttr {
+	fileAttrs := entryFileAttributes(d)
+	var attrs model.NodeAttr
+	if fileAttrs&(syscall.FILE_ATTRIBUTE_HIDDEN|syscall.FILE_ATTRIBUTE_SYSTEM) != 0 {
+		attrs |= model.AttrHidden
+	}
+	if d.Type()&fs.ModeSymlink != 0 {
+		return attrs | model.AttrSymlink
+	}
+
+	if fileAttrs&(fileAttributeOffline|fileAttributeRecallOnOpen|fileAttributeRecallOnDataAccess) != 0 {
+		attrs |= model.AttrCloud
+	}
+	if fileAttrs&syscall.FILE_ATTRIBUTE_REPARSE_POINT == 0 {
+		return attrs
+	}
+
+	switch tag := reparseTag(path); {
+	case tag == ioReparseTagMountPoint:
+		attrs |= model.AttrJunction
+	case tag == ioReparseTagSymlink:
+		attrs |= model.AttrSymlink
+	case tag&ioReparseTagCloudMask == ioReparseTagCloud:
+		attrs |= model.AttrCloud
+	default:
+		attrs |= model.AttrReparse
+	}
+	return attrs
+}
+
+// linkedDir reports whether a directory entry is a junction or a directory
+// symlink, which Go reports as plain directories
+func linkedDir(isDir bool, attrs model.NodeAttr) bool {
+	return isDir && attrs.IsLink()
+}
+
+// entryFileAttributes returns the Win32 attributes of an entry
+func entryFileAttributes(d fs.DirEntry) uint32 {
+	info, err := d.Info()
+	if err != nil {
+		return 0
+	}
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return 0
+	}
+	return data.FileAttributes
+}
+
+// reparseTag returns the reparse tag of a reparse point, or 0 if unavailable
+func reparseTag(path string) uint32 {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0
+	}
+	var data syscall.Win32finddata
+	handle, err := syscall.FindFirstFile(pathPtr, &data)
+	if err != nil {
+		return 0
+	}
+	syscall.FindClose(handle)
+	return data.Reserved0
+}
+
+// sameDevice reports whether a resolved link target is on the root's drive
+func sameDevice(target string, info fs.FileInfo, rootInfo platformRootInfo) bool {
+	return strings.EqualFold(filepath.VolumeName(target), rootInfo.volume)
+}
+
+// getFileSize returns the space a file takes on disk and its logical size,
+// or a negative disk size if the file should be skipped
+func getFileSize(path string, info fs.FileInfo, seenItems *sync.Map, policy ScanPolicy) (disk, logical int64) {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return info.Size(), info.Size()
+	}
+
+	// Online-only cloud files take no space on disk
+	if data.FileAttributes&(fileAttributeOffline|fileAttributeRecallOnDataAccess) != 0 {
+		return 0, info.Size()
+	}
+
+	// NTFS-compressed and sparse files are smaller on disk than they read
+	if data.FileAttributes&(fileAttributeCompressed|fileAttributeSparseFile) != 0 {
+		if size, ok := compressedFileSize(path); ok {
+			return size, info.Size()
+		}
+	}
+	return info.Size(), info.Size()
+}
+
+// storageAttrs flags NTFS-compressed and sparse files
+func storageAttrs(path string, info fs.FileInfo, disk, logical int64) model.NodeAttr {
+	data, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return 0
+	}
+	var attrs model.NodeAttr
+	if data.FileAttributes&fileAttributeCompressed != 0 {
+		attrs |= model.AttrCompressed
+	}
+	if data.FileAttributes&fileAttributeSparseFile != 0 {
+		attrs |= model.AttrSparse
+	}
+	return attrs
+}
+
+// compressedFileSize returns the bytes a compressed or sparse file occupies
+func compressedFileSize(path string) (int64, bool) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, false
+	}
+
+	var high uint32
+	low, _, callErr := getCompressedFileSizeW.Call(
+		uintptr(unsafe.Pointer(pathPtr)),
+		uintptr(unsafe.Pointer(&high)),
+	)
+	if uint32(low) == invalidFileSize && callErr != syscall.Errno(0) {
+		return 0, false
+	}
+	return int64(high)<<32 | int64(uint32(low)), true
 }
 
-// getFileSize returns the file size, or -1 if the file should be skipped
-func getFileSize(info fs.FileInfo, seenItems *sync.Map) int64 {
-	return info.Size()
+// processCPUTime returns the CPU time the process has used so far
+func processCPUTime() time.Duration {
+	handle, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0
+	}
+	var creation, exit, kernel, user syscall.Filetime
+	if err := syscall.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0
+	}
+	// Filetime counts 100ns intervals
+	ticks := func(ft syscall.Filetime) int64 {
+		return int64(ft.HighDateTime)<<32 | int64(ft.LowDateTime)
+	}
+	return time.Duration((ticks(kernel) + ticks(user)) * 100)
 }
@@ -0,0 +1,20 @@
+// Package shell runs the command lines of quick actions and cleanup rules
+// with the platform's shell: sh, or cmd.exe on Windows.
+package shell
+
+import "strings"
+
+// Expand replaces each placeholder in line with its value, given as
+// placeholder and value pairs, so the shell sees the value as one word.
+// It returns the line along with variables to add to the command's
+// environment for values the shell could not be given literally.
+func Expand(line string, pairs ...string) (string, []string) {
+	var env []string
+	replace := make([]string, 0, len(pairs))
+	for i := 0; i+1 < len(pairs); i += 2 {
+		word, vars := quoteArg(pairs[i], pairs[i+1])
+		replace = append(replace, pairs[i], word)
+		env = append(env, vars...)
+	}
+	return strings.NewReplacer(replace...).Replace(line), env
+}
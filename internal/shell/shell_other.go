@@ -0,0 +1,24 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// Command runs a command line with sh
+func Command(line string) *exec.Cmd {
+	return exec.Command("sh", "-c", line)
+}
+
+// Quote quotes a value as a single sh word
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteArg returns value as a single sh word for Expand; sh reads any
+// value quoted, so no variables are needed
+func quoteArg(placeholder, value string) (string, []string) {
+	return Quote(value), nil
+}
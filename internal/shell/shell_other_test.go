@@ -0,0 +1,52 @@
+//go:build !windows
+
+package shell
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":   "'plain'",
+		"a b":     "'a b'",
+		"it's":    `'it'\''s'`,
+		`"$HOME"`: `'"$HOME"'`,
+		"":        "''",
+	}
+	for in, want := range tests {
+		if got := Quote(in); got != want {
+			t.Errorf("Quote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"plain", "/home/me/file.txt"},
+		{"spaces", "/home/me/My Documents/a  b.txt"},
+		{"single quotes", "/home/me/it's here'"},
+		{"double quotes", `/home/me/"quoted"`},
+		{"dollar", "/home/me/$HOME/$(whoami)/`id`"},
+		{"percent", "/home/me/100%/%PATH%"},
+		{"placeholder", "/home/me/{name}/{dir}"},
+		{"glob", "/home/me/*?[a]"},
+		{"newline", "/home/me/a\nb;echo no"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, env := Expand("printf '%s|%s' {path} {name}", "{path}", tt.value, "{name}", "x y")
+			if env != nil {
+				t.Errorf("expected no variables, got %v", env)
+			}
+			out, err := Command(line).Output()
+			if err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			if want := tt.value + "|x y"; string(out) != want {
+				t.Errorf("%s printed %q, want %q", line, out, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package shell
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// Command runs a command line with cmd.exe. The line is passed through
+// untouched so quoting in the action or rule is kept as written.
+func Command(line string) *exec.Cmd {
+	cmd := exec.Command("cmd")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine:    `cmd /d /s /c "` + line + `"`,
+		HideWindow: true,
+	}
+	return cmd
+}
+
+// Quote quotes a value for cmd.exe; paths cannot contain double quotes.
+// cmd.exe still expands %VAR% inside quotes, so Expand passes values
+// holding % through the environment instead.
+func Quote(s string) string {
+	return `"` + s + `"`
+}
+
+// quoteArg returns value as a single cmd.exe word for Expand. A value with
+// a % goes in an environment variable named after the placeholder, as
+// "{path}" becomes %DISKDIVE_PATH%; cmd.exe expands variables only once,
+// so what the value holds is not expanded again.
+func quoteArg(placeholder, value string) (string, []string) {
+	if !strings.Contains(value, "%") {
+		return Quote(value), nil
+	}
+	name := "DISKDIVE_" + strings.ToUpper(strings.Trim(placeholder, "{}"))
+	return Quote("%" + name + "%"), []string{name + "=" + value}
+}
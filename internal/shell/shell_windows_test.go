@@ -0,0 +1,29 @@
+package shell
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		line  string
+		env   []string
+	}{
+		{"plain", `C:\Users\me\file.txt`, `type "C:\Users\me\file.txt"`, nil},
+		{"spaces", `C:\My Documents\a  b.txt`, `type "C:\My Documents\a  b.txt"`, nil},
+		{"dollar", `C:\$Recycle.Bin`, `type "C:\$Recycle.Bin"`, nil},
+		{"placeholder", `C:\{name}`, `type "C:\{name}"`, nil},
+		{"percent", `C:\100%\%PATH%`, `type "%DISKDIVE_PATH%"`, []string{`DISKDIVE_PATH=C:\100%\%PATH%`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, env := Expand("type {path}", "{path}", tt.value)
+			if line != tt.line || !slices.Equal(env, tt.env) {
+				t.Errorf("got %s with %v, want %s with %v", line, env, tt.line, tt.env)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package stats
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Formats Export writes
+const (
+	ExportCSV  = "csv"
+	ExportJSON = "json"
+)
+
+// exportStats is the JSON export: the stats store without the settings
+// kept in it
+type exportStats struct {
+	FreedLifetime   int64         `json:"freedLifetime"`
+	DeletedLifetime int64         `json:"deletedLifetime"` // Deletions too small to count as freed included
+	Freed           []exportFreed `json:"freed"`
+	Drives          []exportDrive `json:"drives"`
+}
+
+type exportFreed struct {
+	At    time.Time `json:"at"`
+	Bytes int64     `json:"bytes"`
+	Drive string    `json:"drive,omitempty"`
+}
+
+type exportDrive struct {
+	Path  string       `json:"path"`
+	Freed int64        `json:"freed"`
+	Scans int          `json:"scans"`
+	Usage []exportScan `json:"usage"`
+}
+
+type exportScan struct {
+	At      time.Time `json:"at"`
+	Used    int64     `json:"used,omitempty"`
+	Seconds float64   `json:"seconds,omitempty"` // How long the scan took
+}
+
+// Export writes the freed history and the per-drive scan history to w as
+// CSV or JSON, for dashboards. CSV has one row per freed event or scan,
+// oldest first, after a row of column names; JSON is one object.
+func (m *Manager) Export(w io.Writer, format string) error {
+	if format != ExportCSV && format != ExportJSON {
+		return fmt.Errorf("unknown export format %q (want %s or %s)", format, ExportCSV, ExportJSON)
+	}
+
+	m.mu.RLock()
+	out := exportStats{FreedLifetime: m.stats.FreedLifetime, DeletedLifetime: m.stats.DeletedLifetime, Freed: []exportFreed{}, Drives: []exportDrive{}}
+	for _, e := range m.stats.Freed {
+		out.Freed = append(out.Freed, exportFreed(e))
+	}
+	for path, d := range m.stats.Drives {
+		drive := exportDrive{Path: path, Freed: d.Freed, Scans: d.Scans, Usage: []exportScan{}}
+		for _, s := range d.Usage {
+			drive.Usage = append(drive.Usage, exportScan{At: s.At, Used: s.Used, Seconds: s.Took.Seconds()})
+		}
+		out.Drives = append(out.Drives, drive)
+	}
+	m.mu.RUnlock()
+	slices.SortFunc(out.Drives, func(a, b exportDrive) int { return cmp.Compare(a.Path, b.Path) })
+
+	bw := bufio.NewWriter(w)
+	var err error
+	if format == ExportCSV {
+		err = exportCSV(bw, out)
+	} else {
+		enc := json.NewEncoder(bw)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(out)
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// exportCSV writes freed events and scans as rows of one table, told apart
+// by the kind column
+func exportCSV(w io.Writer, out exportStats) error {
+	type row struct {
+		at     time.Time
+		fields []string
+	}
+	var rows []row
+	for _, e := range out.Freed {
+		rows = append(rows, row{e.At, []string{"freed", e.Drive, e.At.Format(time.RFC3339), strconv.FormatInt(e.Bytes, 10), ""}})
+	}
+	for _, d := range out.Drives {
+		for _, s := range d.Usage {
+			used, seconds := "", ""
+			if s.Used > 0 {
+				used = strconv.FormatInt(s.Used, 10)
+			}
+			if s.Seconds > 0 {
+				seconds = strconv.FormatFloat(s.Seconds, 'f', 1, 64)
+			}
+			rows = append(rows, row{s.At, []string{"scan", d.Path, s.At.Format(time.RFC3339), used, seconds}})
+		}
+	}
+	slices.SortStableFunc(rows, func(a, b row) int { return a.at.Compare(b.at) })
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"kind", "drive", "at", "bytes", "seconds"}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := cw.Write(r.fields); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+)
+
+func TestExport(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+	defer m.Close()
+
+	m.RecordScan("/", 5000, 90*time.Second)
+	clk.Advance(time.Hour)
+	m.AddFreed("/", 300)
+	clk.Advance(time.Hour)
+	m.RecordScan("/mnt/usb", 0, 2*time.Second)
+
+	var out bytes.Buffer
+	if err := m.Export(&out, ExportCSV); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"kind", "drive", "at", "bytes", "seconds"},
+		{"scan", "/", "2024-01-01T12:00:00Z", "5000", "90.0"},
+		{"freed", "/", "2024-01-01T13:00:00Z", "300", ""},
+		{"scan", "/mnt/usb", "2024-01-01T14:00:00Z", "", "2.0"},
+	}
+	if !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("expected rows %v, got %v", want, rows)
+	}
+
+	out.Reset()
+	if err := m.Export(&out, ExportJSON); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	var got exportStats
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.FreedLifetime != 300 || len(got.Freed) != 1 || len(got.Drives) != 2 {
+		t.Fatalf("unexpected export: %+v", got)
+	}
+	if root := got.Drives[0]; root.Path != "/" || root.Freed != 300 || root.Scans != 1 || root.Usage[0].Used != 5000 || root.Usage[0].Seconds != 90 {
+		t.Errorf("unexpected drive: %+v", root)
+	}
+
+	if err := m.Export(&out, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
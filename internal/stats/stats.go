@@ -2,34 +2,130 @@ package stats
 
 import (
 	"encoding/json"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+)
+
+const (
+	defaultSaveDelay = 2 * time.Second      // Debounce saves
+	freedKeep        = 366 * 24 * time.Hour // Freed events older than this are forgotten
+	usageKeep        = 366 * 24 * time.Hour // Usage samples older than this are forgotten
+	trendWindow      = 30 * 24 * time.Hour  // How far back a trend looks for an earlier sample
+	trendMinSpan     = time.Hour            // Shortest gap between samples that gives a trend
+	deletionsKeep    = 100                  // Deletions logged before the oldest are forgotten
+	minDeletion      = 1 << 20              // Smallest deletion logged
+	deletionMerge    = time.Minute          // Deletions inside a folder this recent are part of its deletion
 )
 
+// FreedEvent is space recovered at one time
+type FreedEvent struct {
+	At    time.Time `json:"at"`
+	Bytes int64     `json:"bytes"`
+	Drive string    `json:"drive,omitempty"` // Path of the drive it was freed on
+}
+
+// Deletion is an item seen deleted
+type Deletion struct {
+	At    time.Time `json:"at"`
+	Path  string    `json:"path"`
+	Bytes int64     `json:"bytes"`
+}
+
+// UsageSample is how many bytes of a drive were in use at one scan, and how
+// long the scan took
+type UsageSample struct {
+	At   time.Time     `json:"at"`
+	Used int64         `json:"used"`           // 0 if unknown
+	Took time.Duration `json:"took,omitempty"` // 0 if unknown
+}
+
+// DriveStats holds the statistics of one drive
+type DriveStats struct {
+	Freed    int64         `json:"freed"`
+	Scans    int           `json:"scans"`
+	LastScan time.Time     `json:"last_scan,omitzero"`
+	PrevScan time.Time     `json:"prev_scan,omitzero"` // The scan before LastScan
+	Usage    []UsageSample `json:"usage,omitempty"`    // Oldest first, for the last year
+}
+
+// Trend returns how many bytes a day the drive's usage changed, negative
+// when shrinking, from the latest sample and the earliest within
+// trendWindow before it. Looking back over several scans evens out a
+// one-off download or cleanup between the last two. It is false without a
+// sample at least trendMinSpan earlier.
+func (d DriveStats) Trend() (float64, bool) {
+	if len(d.Usage) < 2 {
+		return 0, false
+	}
+	last := d.Usage[len(d.Usage)-1]
+	if last.Used == 0 {
+		return 0, false
+	}
+	for _, s := range d.Usage[:len(d.Usage)-1] {
+		if s.Used == 0 {
+			continue
+		}
+		if since := last.At.Sub(s.At); since <= trendWindow && since >= trendMinSpan {
+			return float64(last.Used-s.Used) / since.Hours() * 24, true
+		}
+	}
+	return 0, false
+}
+
 // Stats holds persistent statistics
 type Stats struct {
-	FreedLifetime int64  `json:"freed_lifetime"`
-	DefaultDrive  string `json:"default_drive,omitempty"` // Path of default drive to scan on startup
+	FreedLifetime   int64        `json:"freed_lifetime"`
+	DeletedLifetime int64        `json:"deleted_lifetime"`        // Like FreedLifetime, deletions too small to count as freed included
+	Freed           []FreedEvent `json:"freed,omitempty"`         // Oldest first, for the last year
+	DefaultDrive    string       `json:"default_drive,omitempty"` // Path of default drive to scan on startup
+
+	// Per drive, by the drive's path
+	Drives map[string]DriveStats `json:"drives,omitempty"`
+
+	// The latest deletions of at least minDeletion, oldest first
+	Deletions []Deletion `json:"deletions,omitempty"`
+
+	// Paths the cleanup wizard was told to keep, by the ID of the suggestion
+	// they came up in (its title, for those kept before suggestions had IDs)
+	Dismissed map[string][]string `json:"dismissed,omitempty"`
 }
 
 // Manager handles loading and saving stats
 type Manager struct {
-	path         string
-	stats        Stats
-	mu           sync.RWMutex
-	dirty        bool
-	saveTimer    *time.Timer
-	saveDuration time.Duration
+	path  string
+	stats Stats
+	mu    sync.RWMutex
+	dirty bool
+	saver *clock.Debouncer
+	clock clock.Clock // Time source for freed events
 }
 
 // NewManager creates a new stats manager
 func NewManager() *Manager {
-	return &Manager{
-		path:         defaultPath(),
-		saveDuration: 2 * time.Second, // Debounce saves
-	}
+	m := &Manager{path: defaultPath(), clock: clock.Real()}
+	m.saver = clock.NewDebouncer(clock.Real(), defaultSaveDelay, m.saveDirty)
+	return m
+}
+
+// SetSaveDelay sets how long changes are held before being written to disk
+func (m *Manager) SetSaveDelay(d time.Duration) {
+	m.saver.SetDelay(d)
+}
+
+// SetClock replaces the time source used for debounced saves and freed
+// events (for tests)
+func (m *Manager) SetClock(c clock.Clock) {
+	m.mu.Lock()
+	m.clock = c
+	m.mu.Unlock()
+	m.saver.SetClock(c)
 }
 
 // defaultPath returns the default stats file path
@@ -84,6 +180,15 @@ func (m *Manager) saveLocked() error {
 	return os.WriteFile(m.path, data, 0644)
 }
 
+// saveDirty writes pending changes (called by the debounced saver)
+func (m *Manager) saveDirty() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dirty {
+		_ = m.saveLocked() // Ignore errors for background save
+	}
+}
+
 // FreedLifetime returns the lifetime freed bytes
 func (m *Manager) FreedLifetime() int64 {
 	m.mu.RLock()
@@ -91,6 +196,109 @@ func (m *Manager) FreedLifetime() int64 {
 	return m.stats.FreedLifetime
 }
 
+// DeletedLifetime returns the lifetime bytes of every deletion, those too
+// small to count as freed included
+func (m *Manager) DeletedLifetime() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats.DeletedLifetime
+}
+
+// AddDeleted adds to the lifetime bytes of every deletion and schedules a
+// debounced save
+func (m *Manager) AddDeleted(bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.DeletedLifetime += bytes
+	m.dirty = true
+	m.saver.Trigger()
+}
+
+// RecordScan counts a completed scan of drive that took took, with used
+// bytes in use on it, and schedules a debounced save. Either may be zero
+// when unknown.
+func (m *Manager) RecordScan(drive string, used int64, took time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.updateDrive(drive, func(d *DriveStats) {
+		d.Scans++
+		d.PrevScan, d.LastScan = d.LastScan, now
+		if used <= 0 && took <= 0 {
+			return
+		}
+		d.Usage = append(d.Usage, UsageSample{At: now, Used: max(used, 0), Took: took})
+		cutoff := now.Add(-usageKeep)
+		i := 0
+		for i < len(d.Usage) && d.Usage[i].At.Before(cutoff) {
+			i++
+		}
+		d.Usage = d.Usage[i:]
+	})
+	m.dirty = true
+	m.saver.Trigger()
+}
+
+// Drive returns the statistics of the drive at path
+func (m *Manager) Drive(path string) DriveStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats.Drives[path]
+}
+
+// Drives returns the statistics of every drive, by the drive's path
+func (m *Manager) Drives() map[string]DriveStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.stats.Drives)
+}
+
+// updateDrive applies update to the statistics of drive, unless drive is
+// empty (caller must hold lock)
+func (m *Manager) updateDrive(drive string, update func(*DriveStats)) {
+	if drive == "" {
+		return
+	}
+	if m.stats.Drives == nil {
+		m.stats.Drives = make(map[string]DriveStats)
+	}
+	d := m.stats.Drives[drive]
+	update(&d)
+	m.stats.Drives[drive] = d
+}
+
+// FreedHistory returns when space was freed over the last year, oldest first
+func (m *Manager) FreedHistory() []FreedEvent {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.stats.Freed)
+}
+
+// FreedPerPeriod sums events into n periods of days calendar days each,
+// oldest first, the last ending with the day of now
+func FreedPerPeriod(events []FreedEvent, now time.Time, days, n int) []int64 {
+	sums := make([]int64, n)
+	y, mo, d := now.Date()
+	end := time.Date(y, mo, d+1, 0, 0, 0, 0, now.Location())
+	starts := make([]time.Time, n)
+	for i := range starts {
+		starts[i] = end.AddDate(0, 0, -(n-i)*days)
+	}
+	for _, e := range events {
+		if !e.At.Before(end) {
+			continue
+		}
+		for i := n - 1; i >= 0; i-- {
+			if !e.At.Before(starts[i]) {
+				sums[i] += e.Bytes
+				break
+			}
+		}
+	}
+	return sums
+}
+
 // DefaultDrive returns the default drive path
 func (m *Manager) DefaultDrive() string {
 	m.mu.RLock()
@@ -110,53 +318,153 @@ func (m *Manager) SetDefaultDrive(path string) {
 	m.stats.DefaultDrive = path
 	m.dirty = true
 
-	// Cancel any pending save timer
-	if m.saveTimer != nil {
-		m.saveTimer.Stop()
+	// Schedule a debounced save
+	m.saver.Trigger()
+}
+
+// AddFreed adds to the lifetime freed counters, overall and of drive,
+// records when the space was freed and schedules a debounced save
+func (m *Manager) AddFreed(drive string, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	m.stats.FreedLifetime += bytes
+	m.updateDrive(drive, func(d *DriveStats) { d.Freed += bytes })
+	m.stats.Freed = append(m.stats.Freed, FreedEvent{At: now, Bytes: bytes, Drive: drive})
+	cutoff := now.Add(-freedKeep)
+	keep := 0
+	for keep < len(m.stats.Freed) && m.stats.Freed[keep].At.Before(cutoff) {
+		keep++
 	}
+	m.stats.Freed = m.stats.Freed[keep:]
+	m.dirty = true
 
 	// Schedule a debounced save
-	m.saveTimer = time.AfterFunc(m.saveDuration, func() {
-		m.mu.Lock()
-		defer m.mu.Unlock()
-		if m.dirty {
-			_ = m.saveLocked()
-		}
-	})
+	m.saver.Trigger()
 }
 
-// AddFreed adds to the lifetime freed counter and schedules a debounced save
-func (m *Manager) AddFreed(bytes int64) {
+// ResetFreed forgets all space freed: the lifetime totals, overall and per
+// drive, the history and the deletions logged. It schedules a debounced save.
+func (m *Manager) ResetFreed() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.stats.FreedLifetime += bytes
+	m.stats.FreedLifetime = 0
+	m.stats.DeletedLifetime = 0
+	m.stats.Freed = nil
+	m.stats.Deletions = nil
+	for path, d := range m.stats.Drives {
+		d.Freed = 0
+		m.stats.Drives[path] = d
+	}
+	m.dirty = true
+	m.saver.Trigger()
+}
+
+// CorrectFreed takes bytes counted as freed by mistake off the lifetime
+// freed totals, overall and of drive, without going below zero, and
+// schedules a debounced save. The history of when space was freed stays as
+// it is.
+func (m *Manager) CorrectFreed(drive string, bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stats.FreedLifetime = max(m.stats.FreedLifetime-bytes, 0)
+	if _, ok := m.stats.Drives[drive]; ok {
+		m.updateDrive(drive, func(d *DriveStats) { d.Freed = max(d.Freed-bytes, 0) })
+	}
 	m.dirty = true
+	m.saver.Trigger()
+}
 
-	// Cancel any pending save timer
-	if m.saveTimer != nil {
-		m.saveTimer.Stop()
+// LogDeletion remembers that path, holding bytes, was deleted, if it held
+// enough to be worth recalling, and schedules a debounced save. A folder's
+// contents go before it, so its deletion replaces those logged inside it
+// within deletionMerge, and deletions inside a folder logged that recently
+// are left out.
+func (m *Manager) LogDeletion(path string, bytes int64) {
+	if bytes < minDeletion {
+		return
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	// Schedule a debounced save
-	m.saveTimer = time.AfterFunc(m.saveDuration, func() {
-		m.mu.Lock()
-		defer m.mu.Unlock()
-		if m.dirty {
-			_ = m.saveLocked() // Ignore errors for background save
+	now := m.clock.Now()
+	recent := func(d Deletion) bool { return now.Sub(d.At) <= deletionMerge }
+	for _, d := range m.stats.Deletions {
+		if recent(d) && within(path, d.Path) {
+			return
 		}
+	}
+	m.stats.Deletions = slices.DeleteFunc(m.stats.Deletions, func(d Deletion) bool {
+		return recent(d) && within(d.Path, path)
 	})
+	m.stats.Deletions = append(m.stats.Deletions, Deletion{At: now, Path: path, Bytes: bytes})
+	if extra := len(m.stats.Deletions) - deletionsKeep; extra > 0 {
+		m.stats.Deletions = slices.Delete(m.stats.Deletions, 0, extra)
+	}
+	m.dirty = true
+	m.saver.Trigger()
 }
 
-// Close ensures any pending saves are written
-func (m *Manager) Close() error {
+// Deletions returns the latest deletions logged, oldest first
+func (m *Manager) Deletions() []Deletion {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.stats.Deletions)
+}
+
+// within reports whether path equals dir or lies inside it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// Dismissed returns the paths not to suggest again under the suggestion with ID rule
+func (m *Manager) Dismissed(rule string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stats.Dismissed[rule]
+}
+
+// Dismiss records paths not to suggest again under rule and schedules a
+// debounced save
+func (m *Manager) Dismiss(rule string, paths []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stats.Dismissed == nil {
+		m.stats.Dismissed = make(map[string][]string)
+	}
+	for _, p := range paths {
+		if !slices.Contains(m.stats.Dismissed[rule], p) {
+			m.stats.Dismissed[rule] = append(m.stats.Dismissed[rule], p)
+		}
+	}
+	m.dirty = true
+	m.saver.Trigger()
+}
+
+// ResetDismissed forgets every dismissed suggestion and schedules a
+// debounced save
+func (m *Manager) ResetDismissed() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if m.saveTimer != nil {
-		m.saveTimer.Stop()
-		m.saveTimer = nil
+	if len(m.stats.Dismissed) == 0 {
+		return
 	}
+	m.stats.Dismissed = nil
+	m.dirty = true
+	m.saver.Trigger()
+}
+
+// Close ensures any pending saves are written
+func (m *Manager) Close() error {
+	m.saver.Stop()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	if m.dirty {
 		return m.saveLocked()
@@ -0,0 +1,265 @@
+package stats
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/clock"
+)
+
+func TestAddFreedDebouncesSave(t *testing.T) {
+	clk := clock.NewFake(time.Unix(0, 0))
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+	m.SetSaveDelay(2 * time.Second)
+
+	m.AddFreed("/", 100)
+	clk.Advance(time.Second)
+	m.AddFreed("/", 50)
+	clk.Advance(time.Second)
+	if _, err := os.Stat(m.path); !os.IsNotExist(err) {
+		t.Fatal("expected no save while changes keep arriving")
+	}
+
+	clk.Advance(time.Second)
+	loaded := NewManager()
+	loaded.path = m.path
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := loaded.FreedLifetime(); got != 150 {
+		t.Errorf("expected 150 freed, got %d", got)
+	}
+}
+
+func TestDismissed(t *testing.T) {
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+
+	m.Dismiss("Clear caches", []string{"/home/me/.cache/keep"})
+	m.Dismiss("Clear caches", []string{"/home/me/.cache/keep", "/home/me/.cache/also"})
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	loaded := NewManager()
+	loaded.path = m.path
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if got := loaded.Dismissed("Clear caches"); len(got) != 2 {
+		t.Errorf("expected 2 dismissed paths, got %v", got)
+	}
+	if got := loaded.Dismissed("Old downloads"); len(got) != 0 {
+		t.Errorf("expected nothing dismissed for another rule, got %v", got)
+	}
+
+	loaded.ResetDismissed()
+	if got := loaded.Dismissed("Clear caches"); len(got) != 0 {
+		t.Errorf("expected dismissals forgotten, got %v", got)
+	}
+}
+
+func TestFreedHistory(t *testing.T) {
+	start := time.Date(2025, 3, 10, 12, 0, 0, 0, time.Local)
+	clk := clock.NewFake(start)
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+
+	m.AddFreed("/", 100)
+	clk.Advance(400 * 24 * time.Hour)
+	m.AddFreed("/", 50)
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	loaded := NewManager()
+	loaded.path = m.path
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	history := loaded.FreedHistory()
+	if len(history) != 1 || history[0].Bytes != 50 {
+		t.Errorf("expected only the event from the last year, got %v", history)
+	}
+	if got := loaded.FreedLifetime(); got != 150 {
+		t.Errorf("expected the lifetime counter to keep old events, got %d", got)
+	}
+}
+
+func TestFreedPerPeriod(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	day := func(d, hour int) time.Time { return time.Date(2025, 3, d, hour, 0, 0, 0, time.Local) }
+	events := []FreedEvent{
+		{At: day(1, 12), Bytes: 1},  // Before the periods shown
+		{At: day(8, 0), Bytes: 10},  // First day shown
+		{At: day(9, 23), Bytes: 20}, // Yesterday
+		{At: day(10, 1), Bytes: 30}, // Today
+		{At: day(10, 8), Bytes: 5},
+	}
+
+	days := FreedPerPeriod(events, now, 1, 3)
+	if want := []int64{10, 20, 35}; !slices.Equal(days, want) {
+		t.Errorf("per day: expected %v, got %v", want, days)
+	}
+	weeks := FreedPerPeriod(events, now, 7, 2)
+	if want := []int64{1, 65}; !slices.Equal(weeks, want) {
+		t.Errorf("per week: expected %v, got %v", want, weeks)
+	}
+}
+
+func TestDriveStats(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+
+	m.RecordScan("/", 0, 0)
+	clk.Advance(time.Hour)
+	m.RecordScan("/", 0, 0)
+	m.RecordScan("/mnt/usb", 0, 0)
+	m.AddFreed("/", 100)
+	m.AddFreed("/mnt/usb", 30)
+	if err := m.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+
+	loaded := NewManager()
+	loaded.path = m.path
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	root := loaded.Drive("/")
+	if root.Scans != 2 || root.Freed != 100 {
+		t.Errorf("expected 2 scans and 100 freed on /, got %+v", root)
+	}
+	if !root.LastScan.Equal(time.Unix(1000, 0).Add(time.Hour)) || !root.PrevScan.Equal(time.Unix(1000, 0)) {
+		t.Errorf("expected the last two scan times, got %v and %v", root.LastScan, root.PrevScan)
+	}
+	if usb := loaded.Drive("/mnt/usb"); usb.Scans != 1 || usb.Freed != 30 || !usb.PrevScan.IsZero() {
+		t.Errorf("expected one scan and 30 freed on the other drive, got %+v", usb)
+	}
+	if got := loaded.FreedLifetime(); got != 130 {
+		t.Errorf("expected 130 freed overall, got %d", got)
+	}
+}
+
+func TestDriveTrend(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+	defer m.Close()
+
+	m.RecordScan("/", 1000, 0)
+	if _, ok := m.Drive("/").Trend(); ok {
+		t.Error("expected no trend from a single scan")
+	}
+	clk.Advance(30 * time.Minute)
+	m.RecordScan("/", 2000, 0)
+	if _, ok := m.Drive("/").Trend(); ok {
+		t.Error("expected no trend from scans too close together")
+	}
+
+	// Measured from the earliest scan within the window
+	clk.Advance(47*time.Hour + 30*time.Minute)
+	m.RecordScan("/", 5000, 0)
+	if perDay, ok := m.Drive("/").Trend(); !ok || perDay != 2000 {
+		t.Errorf("expected 2000 bytes a day, got %v (%v)", perDay, ok)
+	}
+
+	// Shrinking is negative; unknown usage adds no sample
+	clk.Advance(24 * time.Hour)
+	m.RecordScan("/", 0, 0)
+	clk.Advance(24 * time.Hour)
+	m.RecordScan("/", 1000, 0)
+	d := m.Drive("/")
+	if len(d.Usage) != 4 {
+		t.Errorf("expected 4 usage samples, got %d", len(d.Usage))
+	}
+	if perDay, ok := d.Trend(); !ok || perDay != 0 {
+		t.Errorf("expected no change over four days, got %v (%v)", perDay, ok)
+	}
+	clk.Advance(31 * 24 * time.Hour)
+	m.RecordScan("/", 4100, 0)
+	clk.Advance(24 * time.Hour)
+	m.RecordScan("/", 4000, 0)
+	if perDay, ok := m.Drive("/").Trend(); !ok || perDay != -100 {
+		t.Errorf("expected -100 bytes a day once older samples fall out of the window, got %v (%v)", perDay, ok)
+	}
+	if got := m.Drives(); len(got) != 1 || len(got["/"].Usage) != 6 {
+		t.Errorf("expected one drive with 6 samples, got %+v", got)
+	}
+}
+
+func TestLogDeletion(t *testing.T) {
+	clk := clock.NewFake(time.Unix(1000, 0))
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	m.SetClock(clk)
+	defer m.Close()
+
+	dir := filepath.Join(string(filepath.Separator), "data", "old")
+	m.LogDeletion(filepath.Join(dir, "small.txt"), 10) // Too small to log
+	m.LogDeletion(filepath.Join(dir, "a.iso"), 2<<20)
+	m.LogDeletion(filepath.Join(dir, "b.iso"), 3<<20)
+	m.LogDeletion(dir, 5<<20)
+	m.LogDeletion(filepath.Join(dir, "late.iso"), 1<<20)
+	got := m.Deletions()
+	if len(got) != 1 || got[0].Path != dir || got[0].Bytes != 5<<20 {
+		t.Fatalf("expected the folder to stand for its contents, got %+v", got)
+	}
+
+	// Much later, the same path is a deletion of its own
+	clk.Advance(time.Hour)
+	m.LogDeletion(filepath.Join(dir, "a.iso"), 2<<20)
+	if got := m.Deletions(); len(got) != 2 {
+		t.Errorf("expected 2 deletions, got %+v", got)
+	}
+
+	for range deletionsKeep {
+		clk.Advance(time.Hour)
+		m.LogDeletion(filepath.Join(string(filepath.Separator), "tmp", clk.Now().Format("150405")), 1<<20)
+	}
+	got = m.Deletions()
+	if len(got) != deletionsKeep || got[0].Path == dir {
+		t.Errorf("expected the oldest deletions to be forgotten, got %d starting with %+v", len(got), got[0])
+	}
+}
+
+func TestCorrectAndResetFreed(t *testing.T) {
+	m := NewManager()
+	m.path = filepath.Join(t.TempDir(), "stats.json")
+	defer m.Close()
+
+	m.AddFreed("/", 300)
+	m.AddFreed("/mnt/usb", 100)
+	m.AddDeleted(450)
+	m.LogDeletion("/big.iso", 2<<20)
+
+	m.CorrectFreed("/", 200)
+	if got := m.FreedLifetime(); got != 200 {
+		t.Errorf("expected 200 freed after the correction, got %d", got)
+	}
+	if got := m.Drive("/").Freed; got != 100 {
+		t.Errorf("expected 100 freed on / after the correction, got %d", got)
+	}
+	m.CorrectFreed("/mnt/usb", 500)
+	if got, usb := m.FreedLifetime(), m.Drive("/mnt/usb").Freed; got != 0 || usb != 0 {
+		t.Errorf("expected corrections to stop at zero, got %d overall and %d on the drive", got, usb)
+	}
+	m.CorrectFreed("/nowhere", 1)
+	if _, ok := m.Drives()["/nowhere"]; ok {
+		t.Error("expected no stats for a drive never seen")
+	}
+
+	m.ResetFreed()
+	if m.DeletedLifetime() != 0 || len(m.FreedHistory()) != 0 || len(m.Deletions()) != 0 || m.Drive("/").Freed != 0 {
+		t.Errorf("expected everything freed to be forgotten, got %+v", m.stats)
+	}
+}
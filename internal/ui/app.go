@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/config"
 	"github.com/lumipallolabs/diskdive/internal/logging"
 	"github.com/lumipallolabs/diskdive/internal/model"
 	"github.com/lumipallolabs/diskdive/internal/scanner"
@@ -170,7 +171,7 @@ func NewApp(version string, scanPath string) App {
 			help:          NewHelpOverlay(),
 			driveSelector: NewDriveSelector(drives),
 			keys:          DefaultKeyMap(),
-			scanner:       scanner.NewWalker(8),
+			scanner:       scanner.NewWalker(8, scanner.ScanPolicy{}),
 			statsManager:  statsMgr,
 			freedLifetime: statsMgr.FreedLifetime(),
 			drives:        drives,
@@ -206,7 +207,7 @@ func NewApp(version string, scanPath string) App {
 		help:          NewHelpOverlay(),
 		driveSelector: NewDriveSelector(drives),
 		keys:    DefaultKeyMap(),
-		scanner: scanner.NewWalker(8),
+		scanner: scanner.NewWalker(8, scanner.ScanPolicy{}),
 		statsManager:  statsMgr,
 		freedLifetime: statsMgr.FreedLifetime(),
 		drives:      drives,
@@ -361,7 +362,7 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// Create and start new watcher
-		w, err := watcher.New()
+		w, err := watcher.New(config.Default().Watch.Queue)
 		if err != nil {
 			logging.Debug.Printf("Failed to create watcher: %v", err)
 			return a, nil
@@ -631,7 +632,7 @@ func (a *App) selectDrive(idx int) tea.Cmd {
 	a.treemap.SetRoot(nil)
 
 	// Create a new scanner for each scan
-	a.scanner = scanner.NewWalker(8)
+	a.scanner = scanner.NewWalker(8, scanner.ScanPolicy{})
 
 	// Start scan, spinner, and progress listener
 	spinnerCmd := tea.Tick(spinnerTickInterval, func(t time.Time) tea.Msg {
@@ -734,7 +735,7 @@ func (a *App) handleDeletion(path string) {
 
 		// Update stats manager (will debounce saves)
 		if a.statsManager != nil {
-			a.statsManager.AddFreed(size)
+			a.statsManager.AddFreed("", size)
 		}
 
 		// Update header display
@@ -746,7 +747,7 @@ func (a *App) handleDeletion(path string) {
 
 // findNodeByPath searches for a node by its path
 func (a *App) findNodeByPath(node *model.Node, path string) *model.Node {
-	if node.Path == path {
+	if node.Path() == path {
 		return node
 	}
 
@@ -767,9 +768,9 @@ func (a *App) openInExplorer() tea.Cmd {
 		return nil
 	}
 
-	logging.Debug.Printf("openInExplorer: revealing %s", node.Path)
+	logging.Debug.Printf("openInExplorer: revealing %s", node.Path())
 	// Open in file manager (platform-specific implementation reveals item in parent)
-	if err := openInFileManager(node.Path); err != nil {
+	if err := openInFileManager(node.Path()); err != nil {
 		logging.Debug.Printf("openInExplorer: error: %v", err)
 	}
 	return nil
@@ -942,7 +943,7 @@ func (a App) infoBar() string {
 
 	// Get file info
 	var modTimeStr, createTimeStr string
-	if info, err := os.Stat(node.Path); err == nil {
+	if info, err := os.Stat(node.Path()); err == nil {
 		now := time.Now()
 
 		// Modification time
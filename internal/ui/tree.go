@@ -38,7 +38,7 @@ func (t *TreePanel) SetRoot(root *model.Node) {
 	t.offset = 0
 	t.expanded = make(map[string]bool)
 	if root != nil {
-		t.expanded[root.Path] = true
+		t.expanded[root.Path()] = true
 	}
 	t.updateVisible()
 }
@@ -120,7 +120,7 @@ func (t *TreePanel) PageDown() {
 // Collapse collapses current folder
 func (t *TreePanel) Collapse() {
 	if node := t.Selected(); node != nil && node.IsDir {
-		delete(t.expanded, node.Path)
+		delete(t.expanded, node.Path())
 		t.updateVisible()
 	}
 }
@@ -128,7 +128,7 @@ func (t *TreePanel) Collapse() {
 // Expand expands current folder
 func (t *TreePanel) Expand() {
 	if node := t.Selected(); node != nil && node.IsDir {
-		t.expanded[node.Path] = true
+		t.expanded[node.Path()] = true
 		t.updateVisible()
 	}
 }
@@ -136,10 +136,10 @@ func (t *TreePanel) Expand() {
 // Toggle toggles expand/collapse of current folder
 func (t *TreePanel) Toggle() {
 	if node := t.Selected(); node != nil && node.IsDir {
-		if t.expanded[node.Path] {
-			delete(t.expanded, node.Path)
+		if t.expanded[node.Path()] {
+			delete(t.expanded, node.Path())
 		} else {
-			t.expanded[node.Path] = true
+			t.expanded[node.Path()] = true
 		}
 		t.updateVisible()
 	}
@@ -172,7 +172,7 @@ func (t *TreePanel) ExpandTo(node *model.Node) {
 	// Expand each ancestor
 	for _, n := range path {
 		if n.IsDir {
-			t.expanded[n.Path] = true
+			t.expanded[n.Path()] = true
 		}
 	}
 
@@ -213,7 +213,7 @@ func (t *TreePanel) updateVisible() {
 func (t *TreePanel) collectVisible(node *model.Node, depth int) {
 	t.visible = append(t.visible, node)
 
-	if node.IsDir && t.expanded[node.Path] {
+	if node.IsDir && t.expanded[node.Path()] {
 		// Sort children by size
 		children := make([]*model.Node, len(node.Children))
 		copy(children, node.Children)
@@ -269,7 +269,7 @@ func (t TreePanel) buildLineContent(node *model.Node) lineContent {
 
 	prefix := strings.Repeat("  ", depth)
 	if node.IsDir {
-		if t.expanded[node.Path] {
+		if t.expanded[node.Path()] {
 			prefix += "\u25bc " // down triangle
 		} else {
 			prefix += "\u25b6 " // right triangle
@@ -3,7 +3,7 @@ package tui
 import (
 	"context"
 	"fmt"
-	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,7 +11,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gabriel-vasile/mimetype"
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/config"
 	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/fileops"
+	"github.com/lumipallolabs/diskdive/internal/locale"
 	"github.com/lumipallolabs/diskdive/internal/logging"
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
@@ -29,12 +33,65 @@ type (
 	scanStartMsg         struct{}
 	deletionDetectedMsg  struct{ event core.DeletionDetectedEvent }
 	creationDetectedMsg  struct{ event core.CreationDetectedEvent }
+	moveDetectedMsg      struct{ event core.MoveDetectedEvent }
+	sizeChangedMsg       struct{ event core.SizeChangedEvent }
+	watchOverflowMsg     struct{ event core.WatchOverflowEvent }
+	resyncedMsg          struct{ event core.ResyncedEvent }
+	watchPollingMsg      struct{ event core.WatchPollingEvent }
 	focusDebounceMsg     struct {
 		version int
 		node    *model.Node
 	}
 	spinnerTickMsg       struct{}
 	scanCompleteDelayMsg struct{ root *model.Node }
+	moveEventMsg         struct{ event core.Event }
+	toastClearMsg        struct{ version int }
+	refreshMsg           struct{}
+	rescanDoneMsg        struct {
+		old  *model.Node
+		node *model.Node
+		err  error
+	}
+	quickActionDoneMsg struct{ result core.QuickActionResult }
+	subtreeSavedMsg    struct {
+		path string
+		err  error
+	}
+	historyLoadedMsg struct{ history *cache.History }
+)
+
+// promptAction identifies what a submitted prompt value is used for
+type promptAction int
+
+const (
+	promptNone promptAction = iota
+	promptNewFolder
+	promptMove
+	promptCompress
+	promptSubtreeLabel
+	promptSnapshotLabel
+)
+
+// numberAction identifies what a submitted number is used for
+type numberAction int
+
+const (
+	numberNone numberAction = iota
+	numberFlattenDepth
+	numberNodeBudget
+	numberFreedCorrection
+)
+
+// confirmAction identifies what a confirmed dialog goes on to do
+type confirmAction int
+
+const (
+	confirmNone confirmAction = iota
+	confirmMove
+	confirmReuse
+	confirmResetFreed
+	confirmTrash
+	confirmCompressDelete
 )
 
 // Spinner frames - modern braille dots spinner
@@ -46,9 +103,21 @@ var spinnerFrames = []string{
 const (
 	spinnerTickInterval  = 80 * time.Millisecond
 	borderRotationSpeed  = 33  // milliseconds per frame
-	focusDebounceTimeout = 300 * time.Millisecond
+	toastDuration        = 4 * time.Second
+	minRefreshInterval   = 250 * time.Millisecond // coalesces watcher-driven redraws
+	scanStallThreshold   = 3 * time.Second        // no new entries before the scan counts as stuck
+)
+
+// Thresholds for pointing out unaccounted space and slow folders after a scan
+const (
+	integrityToastMinBytes   = 1 << 30 // 1GB
+	integrityToastMinPercent = 5.0
+	slowPathToastMin         = 5 * time.Second // Folder read time worth a mention
 )
 
+// maxNodeBudget is the largest node budget that can be typed in
+const maxNodeBudget = 100_000_000
+
 // App is the main TUI application model
 type App struct {
 	// Core controller (business logic)
@@ -60,17 +129,80 @@ type App struct {
 	treemap       TreemapPanel
 	help          HelpOverlay
 	driveSelector DriveSelector
+	prompt        Prompt
+	confirm       ConfirmDialog
+	integrity     IntegrityOverlay
+	slowPaths     SlowPathsOverlay
+	watchStats    WatchStatsOverlay
+	owners        OwnersOverlay
+	growth        GrowthChart
+	freed         FreedChart
+	quick         QuickActionsOverlay
+	topFiles      TopFilesOverlay
+	flatten       FlattenOverlay
+	explain       ExplainOverlay
+	snapshots     SnapshotPicker
+	number        NumberInput
+	flasher       *Flasher
+	macros        *MacroRecorder
 	keys          KeyMap
 	version       string
 
 	// UI state (TUI-specific)
 	activePanel  Panel
+	maximized    bool            // The active panel fills the screen
+	filter       int             // Index into categoryFilters
+	noHidden     bool            // Hidden items left out of the tree and treemap
+	order        model.SortOrder // How the tree lists each folder's children
 	err          error
 	focusVersion int // for debouncing
 
+	// Projected deletion of the marked items, nil outside what-if mode
+	whatIf *core.WhatIfState
+
+	// Pending prompt or confirmation and the nodes it applies to
+	promptAction       promptAction
+	numberAction       numberAction
+	confirmAction      confirmAction
+	pendingNodes       []*model.Node
+	pendingDest        string // Destination awaiting confirmation
+	deleteNeedsConfirm bool   // Deleting after compressing takes an explicit y, per confirm.delete
+
+	// Snapshot offered in place of scanning the custom path
+	reuseOffer core.SnapshotOffer
+
+	// Saved scan whose label is being asked for
+	labelAt time.Time
+
+	// Size history of the scanned path, for the sparkline in the info bar
+	history *cache.History
+
+	// What the file details panel read from disk about the selected file, and
+	// what the info bar walked the selected folder for
+	details *fileDetails
+	folder  *folderInfo
+
+	// Status toast shown in place of the help bar
+	toast        string
+	toastVersion int
+
+	// Terminal window title last set, when the config asks for live titles
+	windowTitle string
+
+	// Whether the flash animation tick is running
+	flashing bool
+
+	// Whether a folder rescan is in progress
+	rescanning bool
+
+	// Coalescing of watcher-driven tree/treemap refreshes
+	lastRefresh      time.Time
+	refreshScheduled bool
+
 	// Event channels (for continuing to listen after each event)
 	scanEventCh    <-chan core.Event
 	watcherEventCh <-chan core.Event
+	moveEventCh    <-chan core.Event
 
 	// Dimensions
 	width           int
@@ -81,6 +213,8 @@ type App struct {
 // NewApp creates a new application instance
 func NewApp(version string, scanPath string) App {
 	ctrl := core.NewController(scanPath)
+	ctrl.SetVersion(version)
+	appLocale = locale.New(ctrl.Config().Locale)
 	drives := ctrl.Drives()
 
 	app := App{
@@ -90,12 +224,33 @@ func NewApp(version string, scanPath string) App {
 		treemap:       NewTreemapPanel(),
 		help:          NewHelpOverlay(version),
 		driveSelector: NewDriveSelector(drives),
+		prompt:        NewPrompt(),
+		confirm:       NewConfirmDialog(),
+		integrity:     NewIntegrityOverlay(),
+		slowPaths:     NewSlowPathsOverlay(),
+		watchStats:    NewWatchStatsOverlay(),
+		owners:        NewOwnersOverlay(),
+		growth:        NewGrowthChart(),
+		freed:         NewFreedChart(),
+		quick:         NewQuickActionsOverlay(),
+		topFiles:      NewTopFilesOverlay(),
+		flatten:       NewFlattenOverlay(),
+		explain:       NewExplainOverlay(),
+		snapshots:     NewSnapshotPicker(),
+		number:        NewNumberInput(),
+		flasher:       NewFlasher(),
+		macros:        NewMacroRecorder(),
+		details:       &fileDetails{},
+		folder:        &folderInfo{},
 		keys:          DefaultKeyMap(),
 		version:       version,
 		activePanel:   PanelTree,
 	}
 
 	app.tree.SetFocused(true)
+	app.tree.SetMarkChecker(ctrl.IsMarked)
+	app.tree.SetFlashChecker(app.flasher.Color)
+	app.treemap.SetFlashChecker(app.flasher.Color)
 	app.treemap.SetFocused(false)
 
 	// Set up initial state
@@ -108,12 +263,13 @@ func NewApp(version string, scanPath string) App {
 		app.header.SetScanning(true, "")
 	} else if len(drives) > 0 {
 		// No default - show drive selector
+		app.driveSelector.SetStats(ctrl.AllDriveStats())
 		app.driveSelector.SetVisible(true)
 	}
 
 	// Update header with loaded stats
 	freed := ctrl.FreedState()
-	app.header.SetFreedStats(freed.Session, freed.Lifetime)
+	app.header.SetFreedStats(freed.Session, freed.Drive)
 
 	return app
 }
@@ -131,6 +287,45 @@ func (a App) Init() tea.Cmd {
 
 // Update implements tea.Model
 func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := a.update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		// Keys are what expand, collapse and zoom into folders
+		switch m := model.(type) {
+		case App:
+			m.syncFollowed()
+		case *App:
+			m.syncFollowed()
+		}
+	}
+	if !a.ctrl.Config().WindowTitle {
+		return model, cmd
+	}
+	// Some handlers return a pointer to the app
+	switch m := model.(type) {
+	case App:
+		titleCmd := m.syncTitle()
+		return m, tea.Batch(cmd, titleCmd)
+	case *App:
+		return m, tea.Batch(cmd, m.syncTitle())
+	}
+	return model, cmd
+}
+
+// syncFollowed tells a scoped watcher which folders are open in the tree
+// or zoomed into, so changes show up in what is being looked at
+func (a *App) syncFollowed() {
+	if !a.ctrl.Scoped() {
+		return
+	}
+	dirs := a.tree.ExpandedPaths()
+	if focus := a.treemap.Focus(); focus != nil && focus.IsDir {
+		dirs = append(dirs, focus.Path())
+	}
+	a.ctrl.FollowFolders(dirs)
+}
+
+// update handles a message, leaving the window title to Update
+func (a App) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		a.width = msg.Width
@@ -139,9 +334,12 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case tea.KeyMsg:
-		return a.handleKey(msg)
+		return a.handleMacroKey(msg)
 
 	case scanStartMsg:
+		if offer, ok := a.ctrl.CoveringSnapshot(); ok {
+			return a.offerReuse(offer)
+		}
 		return a.startScan()
 
 	case scanEventMsg:
@@ -152,24 +350,99 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.finalizeScan(msg.root)
 
 	case deletionDetectedMsg:
-		a.header.SetFreedStats(msg.event.SessionFreed, msg.event.TotalFreed)
+		a.header.SetFreedStats(msg.event.SessionFreed, msg.event.DriveFreed)
 		if msg.event.DiskFree > 0 {
 			a.header.UpdateDiskFree(msg.event.DiskFree)
 		}
-		a.tree.RefreshVisible()
-		a.treemap.InvalidateCache()
-		return a, a.listenForWatcherEvents()
+		if msg.event.Noise {
+			// A metadata file left quietly; only the sizes change
+			return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh())
+		}
+		a.flasher.Flash(msg.event.Node)
+		return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh(), a.startFlash())
 
 	case creationDetectedMsg:
 		logging.Debug.Printf("[TUI] creationDetectedMsg received for path: %s", msg.event.Path)
 		if msg.event.DiskFree > 0 {
 			a.header.UpdateDiskFree(msg.event.DiskFree)
 		}
-		logging.Debug.Printf("[TUI] calling tree.RefreshVisible()")
+		for _, node := range msg.event.Nodes {
+			a.flasher.Flash(node)
+		}
+		logging.Debug.Printf("[TUI] creationDetectedMsg processing complete")
+		return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh(), a.startFlash())
+
+	case moveDetectedMsg:
+		a.tree.Rename(msg.event.OldPath, msg.event.Path)
+		a.flasher.Flash(msg.event.Node)
+		return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh(), a.startFlash())
+
+	case sizeChangedMsg:
+		if msg.event.DiskFree > 0 {
+			a.header.UpdateDiskFree(msg.event.DiskFree)
+		}
+		for _, node := range msg.event.Nodes {
+			a.flasher.Flash(node)
+		}
+		return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh(), a.startFlash())
+
+	case watchPollingMsg:
+		return a, tea.Batch(a.listenForWatcherEvents(), a.showToast(fmt.Sprintf("Checking open folders for changes every %s (%s)", msg.event.Interval, msg.event.Reason)))
+
+	case watchOverflowMsg:
+		if msg.event.Rescan {
+			// The folder shows as stale until its rescan arrives
+			return a, tea.Batch(a.listenForWatcherEvents(), a.requestRefresh())
+		}
+		return a, tea.Batch(a.listenForWatcherEvents(), a.showToast("Too many changes to follow below "+msg.event.Path+" - press r to rescan"))
+
+	case resyncedMsg:
 		a.tree.RefreshVisible()
+		a.treemap.Relayout()
+		a.updateLayout()
+		a.flasher.Flash(msg.event.Node)
+		return a, tea.Batch(a.listenForWatcherEvents(), a.startFlash(), a.showToast("Missed changes in "+msg.event.Node.Name+"; rescanned it"))
+
+	case rescanDoneMsg:
+		return a.handleRescanDone(msg)
+
+	case quickActionDoneMsg:
+		a.toast = ""
+		a.quick.ShowResult(msg.result)
+		return a, nil
+
+	case historyLoadedMsg:
+		a.history = msg.history
+		return a, nil
+
+	case subtreeSavedMsg:
+		if msg.err != nil {
+			return a, a.showToast("Cannot save snapshot: " + msg.err.Error())
+		}
+		return a, a.showToast("Saved a snapshot of " + msg.path + "; opening the folder offers it")
+
+	case refreshMsg:
+		a.refreshScheduled = false
+		a.refreshNow()
+		return a, nil
+
+	case flashTickMsg:
+		a.flasher.Tick()
 		a.treemap.InvalidateCache()
-		logging.Debug.Printf("[TUI] creationDetectedMsg processing complete")
-		return a, a.listenForWatcherEvents()
+		if !a.flasher.Active() {
+			a.flashing = false
+			return a, nil
+		}
+		return a, tickFlash()
+
+	case moveEventMsg:
+		return a.handleMoveEvent(msg.event)
+
+	case toastClearMsg:
+		if msg.version == a.toastVersion {
+			a.toast = ""
+		}
+		return a, nil
 
 	case focusDebounceMsg:
 		if msg.version == a.focusVersion && msg.node != nil {
@@ -187,6 +460,18 @@ func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Keep the prompt's cursor blinking
+	if a.prompt.IsVisible() {
+		var cmd tea.Cmd
+		a.prompt, cmd = a.prompt.Update(msg)
+		return a, cmd
+	}
+	if a.number.IsVisible() {
+		var cmd tea.Cmd
+		a.number, cmd = a.number.Update(msg)
+		return a, cmd
+	}
+
 	return a, nil
 }
 
@@ -195,8 +480,8 @@ func (a App) handleScanEvent(event core.Event) (tea.Model, tea.Cmd) {
 	switch e := event.(type) {
 	case core.ScanProgressEvent:
 		state := a.ctrl.ScanState()
-		progress := fmt.Sprintf("%d files, %s, %s",
-			state.FilesScanned,
+		progress := fmt.Sprintf("%s files, %s, %s",
+			FormatCount(state.FilesScanned),
 			FormatSize(state.BytesFound),
 			state.Elapsed())
 		a.header.SetScanning(true, progress)
@@ -212,10 +497,17 @@ func (a App) handleScanEvent(event core.Event) (tea.Model, tea.Cmd) {
 			a.header.SetScanning(false, "")
 			return a, nil
 		}
-		// Show "Complete" briefly before showing data
-		return a, tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
+		// Show "Complete" briefly before showing data, and keep listening
+		// for the snapshot being saved
+		return a, tea.Batch(tea.Tick(500*time.Millisecond, func(t time.Time) tea.Msg {
 			return scanCompleteDelayMsg{root: e.Root}
-		})
+		}), a.listenForScanEvents())
+
+	case core.SnapshotSavedEvent:
+		if e.Err != nil {
+			return a, a.showToast("Saving the snapshot failed: " + e.Err.Error())
+		}
+		return a, tea.Batch(a.showToast("Snapshot saved to "+e.File), a.loadHistory())
 
 	default:
 		// Unknown event (like ScanStartedEvent) - just continue listening
@@ -227,6 +519,34 @@ func (a App) handleScanEvent(event core.Event) (tea.Model, tea.Cmd) {
 func (a App) startScan() (tea.Model, tea.Cmd) {
 	ctx := context.Background()
 	eventCh, err := a.ctrl.StartScan(ctx)
+	a.header.SetReusedFrom(time.Time{})
+	return a.followScan(eventCh, err)
+}
+
+// offerReuse asks whether to show the custom path from a recent snapshot of
+// it or its drive instead of scanning it
+func (a App) offerReuse(offer core.SnapshotOffer) (tea.Model, tea.Cmd) {
+	a.reuseOffer = offer
+	a.confirmAction = confirmReuse
+	title, age := "Show this folder from the scan of "+offer.From+"?", "Drive scanned "
+	if offer.Own {
+		title, age = "Show this folder from its saved snapshot?", "Saved "
+	}
+	a.confirm.Open(title,
+		a.ctrl.CustomPath(),
+		age+FormatAge(time.Since(offer.ScannedAt))+" ago ("+FormatTime(offer.ScannedAt)+")",
+		"n scans the folder afresh")
+	return a, nil
+}
+
+// reuseSnapshot shows the custom path from the offered drive snapshot
+func (a App) reuseSnapshot() (tea.Model, tea.Cmd) {
+	eventCh, err := a.ctrl.ReuseSnapshot(a.reuseOffer)
+	return a.followScan(eventCh, err)
+}
+
+// followScan listens to the events of a scan that has just started
+func (a App) followScan(eventCh <-chan core.Event, err error) (tea.Model, tea.Cmd) {
 	if err != nil {
 		a.err = err
 		return a, nil
@@ -238,6 +558,12 @@ func (a App) startScan() (tea.Model, tea.Cmd) {
 	// Store channel for continued listening
 	a.scanEventCh = eventCh
 
+	// A new scan drops the marks a projection was made of, and the history
+	// of the path scanned before
+	a.whatIf = nil
+	a.treemap.SetProjector(nil)
+	a.history = nil
+
 	// Start listening for events and ticking spinner
 	return a, tea.Batch(
 		a.listenForScanEvents(),
@@ -270,14 +596,57 @@ func (a App) listenForScanEvents() tea.Cmd {
 // finalizeScan completes the scan and shows data
 func (a App) finalizeScan(root *model.Node) (tea.Model, tea.Cmd) {
 	a.ctrl.FinalizeScan()
+	a.flasher.Clear()
 	a.tree.SetRoot(root)
 	a.treemap.SetRoot(root)
+	a.treemap.SetEmptyHints(emptyHints(root, a.ctrl.Skipped()))
 	a.header.SetScanning(false, "")
+	a.header.SetReusedFrom(a.ctrl.ScanState().ReusedFrom)
+	a.header.SetComparison(core.Comparison{})
+	freed := a.ctrl.FreedState()
+	a.header.SetFreedStats(freed.Session, freed.Drive)
+	a.header.SetDriveStats(a.ctrl.DriveStats())
+	a.tree.SetComparing(false)
 	a.err = nil
 	a.updateLayout()
 
-	// Start filesystem watcher
-	return a, a.startWatcher()
+	// Start filesystem watcher; a gap in used space outranks slow folders
+	report := a.reportIntegrity()
+	if report == nil {
+		report = a.reportSlowPaths()
+	}
+	return a, tea.Batch(a.startWatcher(), report, a.loadHistory())
+}
+
+// loadHistory reads the size history of the scanned path in the background
+func (a *App) loadHistory() tea.Cmd {
+	ctrl := a.ctrl
+	return func() tea.Msg {
+		history, err := ctrl.SizeHistory()
+		if err != nil {
+			logging.Debug.Printf("[TUI] No size history: %v", err)
+		}
+		return historyLoadedMsg{history: history}
+	}
+}
+
+// reportIntegrity points out a large gap between the scan and the drive's used space
+func (a *App) reportIntegrity() tea.Cmd {
+	report := a.ctrl.Integrity()
+	missing := report.Missing()
+	if !report.Applicable || missing < integrityToastMinBytes || percentOf(missing, report.Used) < integrityToastMinPercent {
+		return nil
+	}
+	return a.showToast(fmt.Sprintf("%s of used space not found by scan - press i for details", FormatSize(missing)))
+}
+
+// reportSlowPaths points out a folder that took unusually long to read
+func (a *App) reportSlowPaths() tea.Cmd {
+	paths := a.ctrl.SlowPaths()
+	if len(paths) == 0 || paths[0].Duration < slowPathToastMin {
+		return nil
+	}
+	return a.showToast(fmt.Sprintf("%s took %s to read - press L for slow folders", filepath.Base(paths[0].Path), formatScanTime(paths[0].Duration)))
 }
 
 // startWatcher starts watching for deletions
@@ -287,9 +656,71 @@ func (a *App) startWatcher() tea.Cmd {
 		return nil
 	}
 	a.watcherEventCh = eventCh
+	a.syncFollowed()
 	return a.listenForWatcherEvents()
 }
 
+// requestRefresh refreshes the tree and treemap, at most once per
+// minRefreshInterval; requests in between are folded into one deferred refresh
+func (a *App) requestRefresh() tea.Cmd {
+	if a.refreshScheduled {
+		return nil
+	}
+	wait := minRefreshInterval - time.Since(a.lastRefresh)
+	if wait <= 0 {
+		a.refreshNow()
+		return nil
+	}
+	a.refreshScheduled = true
+	return tea.Tick(wait, func(t time.Time) tea.Msg {
+		return refreshMsg{}
+	})
+}
+
+// refreshNow rebuilds the visible tree rows and invalidates the treemap render
+func (a *App) refreshNow() {
+	a.lastRefresh = time.Now()
+	a.tree.RefreshVisible()
+	a.treemap.InvalidateCache()
+	a.updateWhatIf()
+}
+
+// toggleWhatIf enters or leaves what-if mode, where the treemap is drawn as
+// if the marked items were deleted and the help bar shows what that buys
+func (a *App) toggleWhatIf() tea.Cmd {
+	if a.whatIf != nil {
+		a.whatIf = nil
+		a.treemap.SetProjector(nil)
+		return a.showToast("Left what-if mode")
+	}
+
+	state := a.ctrl.WhatIf()
+	a.whatIf = &state
+	ctrl := a.ctrl
+	a.treemap.SetProjector(func() model.Projection {
+		return model.NewProjection(ctrl.Marked())
+	})
+	return nil
+}
+
+// updateWhatIf projects the marked items again after they or the tree changed
+func (a *App) updateWhatIf() {
+	if a.whatIf == nil {
+		return
+	}
+	state := a.ctrl.WhatIf()
+	a.whatIf = &state
+}
+
+// startFlash starts the flash animation unless it is already running
+func (a *App) startFlash() tea.Cmd {
+	if a.flashing || !a.flasher.Active() {
+		return nil
+	}
+	a.flashing = true
+	return tickFlash()
+}
+
 // listenForWatcherEvents creates a command that listens for watcher events
 func (a App) listenForWatcherEvents() tea.Cmd {
 	if a.watcherEventCh == nil {
@@ -306,11 +737,76 @@ func (a App) listenForWatcherEvents() tea.Cmd {
 			return deletionDetectedMsg{event: e}
 		case core.CreationDetectedEvent:
 			return creationDetectedMsg{event: e}
+		case core.MoveDetectedEvent:
+			return moveDetectedMsg{event: e}
+		case core.SizeChangedEvent:
+			return sizeChangedMsg{event: e}
+		case core.WatchOverflowEvent:
+			return watchOverflowMsg{event: e}
+		case core.ResyncedEvent:
+			return resyncedMsg{event: e}
+		case core.WatchPollingEvent:
+			return watchPollingMsg{event: e}
 		}
 		return nil
 	}
 }
 
+// handleMacroKey records and replays key macros, passing other keys to handleKey.
+// Keys meant for an overlay, prompt or dialog are never taken as macro commands.
+func (a App) handleMacroKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.overlayOpen() {
+		a.macros.Record(msg)
+		return a.handleKey(msg)
+	}
+
+	result := a.macros.HandleKey(msg)
+	switch {
+	case result.invalid != "":
+		return a, a.showToast(result.invalid)
+	case result.started != 0:
+		a.setToast(fmt.Sprintf("Recording @%c... press Q to stop", result.started))
+		return a, nil
+	case result.stopped != 0:
+		return a, a.showToast(fmt.Sprintf("Recorded @%c", result.stopped))
+	case len(result.replay) > 0:
+		return a.replayMacro(result.replay)
+	case result.consumed:
+		return a, nil
+	}
+	return a.handleKey(msg)
+}
+
+// overlayOpen reports whether an overlay, prompt or dialog takes the keys
+func (a App) overlayOpen() bool {
+	return a.help.IsVisible() || a.driveSelector.IsVisible() || a.prompt.IsVisible() ||
+		a.number.IsVisible() || a.confirm.IsVisible() || a.integrity.IsVisible() ||
+		a.slowPaths.IsVisible() || a.watchStats.IsVisible() || a.owners.IsVisible() ||
+		a.growth.IsVisible() || a.freed.IsVisible() || a.quick.IsVisible() ||
+		a.topFiles.IsVisible() || a.flatten.IsVisible() || a.explain.IsVisible() ||
+		a.snapshots.IsVisible()
+}
+
+// replayMacro feeds recorded keys through the key handler in order
+func (a App) replayMacro(keys []tea.KeyMsg) (tea.Model, tea.Cmd) {
+	a.macros.SetReplaying(true)
+	defer a.macros.SetReplaying(false)
+
+	var cmds []tea.Cmd
+	for _, k := range keys {
+		model, cmd := a.handleKey(k)
+		cmds = append(cmds, cmd)
+		// Some handlers return a pointer to the app
+		switch m := model.(type) {
+		case App:
+			a = m
+		case *App:
+			a = *m
+		}
+	}
+	return a, tea.Batch(cmds...)
+}
+
 // handleKey handles keyboard input
 func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Help overlay - any key closes it
@@ -319,6 +815,137 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Integrity overlay - any key closes it
+	if a.integrity.IsVisible() {
+		a.integrity.SetVisible(false)
+		return a, nil
+	}
+
+	// Slow paths overlay - any key closes it
+	if a.slowPaths.IsVisible() {
+		a.slowPaths.SetVisible(false)
+		return a, nil
+	}
+
+	// Watcher activity overlay - any key closes it
+	if a.watchStats.IsVisible() {
+		a.watchStats.SetVisible(false)
+		return a, nil
+	}
+
+	// Owners overlay - any key closes it
+	if a.owners.IsVisible() {
+		a.owners.SetVisible(false)
+		return a, nil
+	}
+
+	// Growth chart - any key closes it
+	if a.growth.IsVisible() {
+		a.growth.SetVisible(false)
+		return a, nil
+	}
+
+	// Freed space chart - Tab switches views, - and r correct the totals,
+	// any other key closes it
+	if a.freed.IsVisible() {
+		switch msg.String() {
+		case "tab":
+			a.freed.NextView()
+			return a, nil
+		case "-":
+			a.freed.SetVisible(false)
+			a.numberAction = numberFreedCorrection
+			lifetime := a.ctrl.FreedState().Lifetime
+			return a, a.number.Open("Space counted as freed by mistake", UnitBytes, 0, 0, lifetime)
+		case "r":
+			a.freed.SetVisible(false)
+			a.confirmAction = confirmResetFreed
+			a.confirm.Open("Reset the space freed?",
+				"Forgets "+FormatSize(a.ctrl.FreedState().Lifetime)+" freed all time,",
+				"its history and the deletions logged")
+			return a, nil
+		}
+		a.freed.SetVisible(false)
+		return a, nil
+	}
+
+	// Quick actions palette, or the output of an action - any key closes it
+	if a.quick.IsVisible() {
+		return a.handleQuickKey(msg)
+	}
+
+	// Largest files list
+	if a.topFiles.IsVisible() {
+		switch {
+		case key.Matches(msg, a.keys.Up):
+			a.topFiles.MoveUp()
+		case key.Matches(msg, a.keys.Down):
+			a.topFiles.MoveDown()
+		case key.Matches(msg, a.keys.Enter):
+			a.topFiles.SetVisible(false)
+			return a.revealNode(a.topFiles.Selected())
+		default:
+			a.topFiles.SetVisible(false)
+		}
+		return a, nil
+	}
+
+	// Folder flattened a few levels deep
+	if a.flatten.IsVisible() {
+		switch {
+		case key.Matches(msg, a.keys.Up):
+			a.flatten.MoveUp()
+		case key.Matches(msg, a.keys.Down):
+			a.flatten.MoveDown()
+		case key.Matches(msg, a.keys.Enter):
+			a.flatten.SetVisible(false)
+			return a.revealNode(a.flatten.Selected())
+		case msg.String() == "d":
+			a.flatten.SetVisible(false)
+			a.numberAction = numberFlattenDepth
+			return a, a.number.Open("Levels to flatten", UnitCount, int64(a.flatten.Depth()), 1, flattenMaxDepth)
+		default:
+			a.flatten.SetVisible(false)
+		}
+		return a, nil
+	}
+
+	// Breakdown of why a folder is as big as it is
+	if a.explain.IsVisible() {
+		switch {
+		case key.Matches(msg, a.keys.Up):
+			a.explain.MoveUp()
+		case key.Matches(msg, a.keys.Down):
+			a.explain.MoveDown()
+		case key.Matches(msg, a.keys.Enter):
+			a.explain.SetVisible(false)
+			return a.revealNode(a.explain.Selected())
+		default:
+			a.explain.SetVisible(false)
+		}
+		return a, nil
+	}
+
+	// Saved scans to compare
+	if a.snapshots.IsVisible() {
+		switch {
+		case key.Matches(msg, a.keys.Up):
+			a.snapshots.MoveUp()
+		case key.Matches(msg, a.keys.Down):
+			a.snapshots.MoveDown()
+		case key.Matches(msg, a.keys.Enter):
+			if from, to, ok := a.snapshots.Pick(); ok {
+				a.snapshots.SetVisible(false)
+				return a.compareScans(from, to)
+			}
+		case msg.String() == "l":
+			return a, a.askSnapshotLabel()
+		default:
+			a.snapshots.SetVisible(false)
+		}
+		return a, nil
+	}
+
 	// Drive selector overlay
 	if a.driveSelector.IsVisible() {
 		switch {
@@ -338,6 +965,19 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 	}
 
+	// Text prompt overlay
+	if a.prompt.IsVisible() {
+		return a.handlePromptKey(msg)
+	}
+	if a.number.IsVisible() {
+		return a.handleNumberKey(msg)
+	}
+
+	// Confirmation dialog - y or Enter proceeds, anything else cancels
+	if a.confirm.IsVisible() {
+		return a.handleConfirmKey(msg)
+	}
+
 	switch {
 	case key.Matches(msg, a.keys.Quit):
 		a.ctrl.Stop()
@@ -349,6 +989,7 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, a.keys.SelectDrive):
 		if len(a.ctrl.Drives()) > 0 {
+			a.driveSelector.SetStats(a.ctrl.AllDriveStats())
 			a.driveSelector.SetVisible(true)
 		}
 		return a, nil
@@ -363,10 +1004,44 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.activePanel = PanelTree
 			a.tree.SetFocused(true)
 			a.treemap.SetFocused(false)
+			a.updateLayout()
 			return a, a.syncSelection()
 		}
+		a.updateLayout()
 		return a, nil
 
+	case key.Matches(msg, a.keys.Maximize):
+		a.maximized = !a.maximized
+		a.updateLayout()
+		return a, nil
+
+	case key.Matches(msg, a.keys.Category):
+		a.filter = (a.filter + 1) % len(categoryFilters)
+		filter := categoryFilters[a.filter]
+		a.tree.SetFilter(filter.set)
+		a.updateLayout()
+		text := "Showing everything"
+		if filter.set != 0 {
+			text = "Showing only " + filter.label
+		}
+		return a, tea.Batch(a.showToast(text), a.syncSelection())
+
+	case key.Matches(msg, a.keys.Hidden):
+		a.noHidden = !a.noHidden
+		a.tree.SetHideHidden(a.noHidden)
+		a.treemap.SetHideHidden(a.noHidden)
+		a.updateLayout()
+		text := "Showing hidden items"
+		if a.noHidden {
+			text = "Hiding hidden items"
+		}
+		return a, tea.Batch(a.showToast(text), a.syncSelection())
+
+	case key.Matches(msg, a.keys.Sort):
+		a.order = a.order.Next()
+		a.tree.SetOrder(a.order)
+		return a, tea.Batch(a.showToast("Sorting by "+a.order.String()), a.syncSelection())
+
 	case key.Matches(msg, a.keys.Up):
 		if a.activePanel == PanelTree {
 			a.tree.MoveUp()
@@ -450,6 +1125,10 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a, nil
 
 	case key.Matches(msg, a.keys.Back):
+		if a.tree.Visual() {
+			a.tree.EndVisual() // Cancelled, nothing marked
+			return a, nil
+		}
 		if a.activePanel == PanelTreemap {
 			a.treemap.ZoomOut()
 		} else {
@@ -461,43 +1140,923 @@ func (a App) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, a.keys.Rescan):
 		state := a.ctrl.ScanState()
 		if !state.IsScanning() {
+			if a.ctrl.CustomPath() != "" {
+				return a.rescanPath()
+			}
 			if a.ctrl.SelectedDrive() != nil {
 				return a.selectDrive(a.ctrl.SelectedDriveIndex())
 			}
 		}
 		return a, nil
 
-	case key.Matches(msg, a.keys.OpenExplorer):
-		return a, a.openInExplorer()
+	case key.Matches(msg, a.keys.RescanDir):
+		if a.comparing() {
+			return a, a.showToast("Press r to go back to the live scan first")
+		}
+		return a, a.rescanSelected()
 
-	case key.Matches(msg, a.keys.Preview):
-		return a, a.previewFile()
-	}
+	case key.Matches(msg, a.keys.Compare):
+		root := a.ctrl.Root()
+		if root == nil || a.ctrl.ScanState().IsScanning() {
+			return a, nil
+		}
+		snapshots := a.ctrl.Snapshots()
+		if len(snapshots) == 0 {
+			return a, a.showToast("No saved scans of this path to compare with")
+		}
+		a.snapshots.Show(core.SnapshotInfo{Files: root.FileCount(), Bytes: root.TotalSize() - root.DeletedSize}, snapshots)
+		return a, nil
 
-	return a, nil
-}
+	case key.Matches(msg, a.keys.Reroot):
+		if a.ctrl.ScanState().IsScanning() {
+			return a, nil
+		}
+		if a.comparing() {
+			return a, a.showToast("Press r to go back to the live scan first")
+		}
+		return a.reroot()
 
-// selectDrive selects a drive and starts scanning
-func (a *App) selectDrive(idx int) (tea.Model, tea.Cmd) {
-	if err := a.ctrl.SelectDrive(idx); err != nil {
-		a.err = err
+	case key.Matches(msg, a.keys.SaveSubtree):
+		if a.ctrl.ScanState().IsScanning() {
+			return a, nil
+		}
+		if a.comparing() {
+			return a, a.showToast("Press r to go back to the live scan first")
+		}
+		return a, a.askSubtreeLabel()
+
+	case key.Matches(msg, a.keys.Prune):
+		return a, a.pruneDeleted()
+
+	case key.Matches(msg, a.keys.Integrity):
+		if a.ctrl.Root() != nil {
+			a.integrity.Show(a.ctrl.Integrity())
+		}
 		return a, nil
-	}
 
-	freed := a.ctrl.FreedState()
-	a.header.SetFreedStats(freed.Session, freed.Lifetime)
-	a.header.SetSelected(idx)
-	a.header.SetScanning(true, "")
-	a.tree.SetRoot(nil)
-	a.treemap.SetRoot(nil)
+	case key.Matches(msg, a.keys.SlowPaths):
+		a.slowPaths.Show(a.ctrl.SlowPaths())
+		return a, nil
 
-	return a.startScan()
-}
+	case key.Matches(msg, a.keys.WatchStats):
+		a.watchStats.Show(a.ctrl.WatchStats())
+		return a, nil
 
-// syncSelection syncs tree selection to treemap
-func (a *App) syncSelection() tea.Cmd {
-	node := a.tree.Selected()
-	if node == nil {
+	case key.Matches(msg, a.keys.Owners):
+		node := a.tree.Selected()
+		if node != nil && !node.IsDir {
+			node = node.Parent
+		}
+		if node != nil {
+			a.owners.Show(node)
+		}
+		return a, nil
+
+	case key.Matches(msg, a.keys.Growth):
+		node := a.tree.Selected()
+		if node != nil && !node.IsDir {
+			node = node.Parent
+		}
+		if node == nil {
+			return a, nil
+		}
+		history, err := a.ctrl.SizeHistory()
+		if err != nil {
+			return a, a.showToast("Reading the size history failed: " + err.Error())
+		}
+		a.growth.Show(node.Path(), node.Name, history)
+		return a, nil
+
+	case key.Matches(msg, a.keys.Freed):
+		a.showFreed()
+		return a, nil
+
+	case key.Matches(msg, a.keys.TopFiles):
+		node := a.tree.Selected()
+		if node != nil && !node.IsDir {
+			node = node.Parent
+		}
+		if node != nil {
+			a.topFiles.Show(node)
+		}
+		return a, nil
+
+	case key.Matches(msg, a.keys.Flatten):
+		node := a.tree.Selected()
+		if node != nil && !node.IsDir {
+			node = node.Parent
+		}
+		if node != nil {
+			a.flatten.Show(node)
+		}
+		return a, nil
+
+	case key.Matches(msg, a.keys.NodeBudget):
+		a.numberAction = numberNodeBudget
+		return a, a.number.Open("Node budget for scans (0 for none)", UnitCount, int64(a.ctrl.ScanPolicy().NodeBudget), 0, maxNodeBudget)
+
+	case key.Matches(msg, a.keys.Explain):
+		node := a.tree.Selected()
+		if node != nil && !node.IsDir {
+			node = node.Parent
+		}
+		if node != nil {
+			a.explain.Show(node)
+		}
+		return a, nil
+
+	case key.Matches(msg, a.keys.ExportTreemap):
+		return a, a.exportTreemap()
+
+	case key.Matches(msg, a.keys.QuickActions):
+		return a, a.openQuickActions()
+
+	case key.Matches(msg, a.keys.OpenExplorer):
+		return a, a.openInExplorer()
+
+	case key.Matches(msg, a.keys.Preview):
+		return a, a.previewFile()
+
+	case key.Matches(msg, a.keys.Mark):
+		if node := a.tree.Selected(); node != nil && node.Parent != nil {
+			a.ctrl.ToggleMark(node)
+			a.markedChanged()
+			if a.activePanel == PanelTree {
+				a.tree.MoveDown()
+				return a, a.syncSelection()
+			}
+		}
+		return a, nil
+
+	case key.Matches(msg, a.keys.Visual):
+		if a.activePanel != PanelTree {
+			return a, nil
+		}
+		if !a.tree.Visual() {
+			a.tree.StartVisual()
+			return a, nil
+		}
+		added := a.ctrl.MarkNodes(a.tree.EndVisual())
+		a.markedChanged()
+		return a, a.showToast(fmt.Sprintf("Marked %s more item(s)", FormatCount(int64(added))))
+
+	case key.Matches(msg, a.keys.UnmarkAll):
+		a.ctrl.ClearMarks()
+		a.markedChanged()
+		return a, nil
+
+	case key.Matches(msg, a.keys.ExportMarked):
+		return a, a.exportMarked()
+
+	case key.Matches(msg, a.keys.WhatIf):
+		return a, a.toggleWhatIf()
+
+	case key.Matches(msg, a.keys.Move):
+		return a, a.openMovePrompt()
+
+	case key.Matches(msg, a.keys.Trash):
+		return a.requestTrash()
+
+	case key.Matches(msg, a.keys.Compress):
+		return a, a.openCompressPrompt()
+
+	case key.Matches(msg, a.keys.NewFolder):
+		return a, a.openNewFolderPrompt()
+	}
+
+	return a, nil
+}
+
+// exportTreemap saves the treemap as currently drawn, without colors, to a
+// text file and the clipboard
+func (a *App) exportTreemap() tea.Cmd {
+	focus := a.treemap.Focus()
+	if focus == nil {
+		return nil
+	}
+
+	now := time.Now()
+	text := fmt.Sprintf("%s  %s  (diskdive, %s)\n", focus.Path(), FormatSize(focus.TotalSize()), FormatTime(now)) +
+		plainText(a.treemap.View())
+	path, copied, err := exportText(exportDir(), "treemap", text, now)
+	if err != nil {
+		return a.showToast("Export failed: " + err.Error())
+	}
+	if copied {
+		return a.showToast("Treemap copied to clipboard and saved to " + path)
+	}
+	return a.showToast("Treemap saved to " + path)
+}
+
+// revealNode selects a node in the tree, expanding its ancestors, and
+// switches to the tree panel
+func (a App) revealNode(node *model.Node) (tea.Model, tea.Cmd) {
+	if node == nil {
+		return a, nil
+	}
+	a.activePanel = PanelTree
+	a.tree.SetFocused(true)
+	a.treemap.SetFocused(false)
+	a.tree.ExpandTo(node)
+	a.updateLayout()
+	return a, a.syncSelection()
+}
+
+// handleQuickKey handles keyboard input while the quick actions overlay is open
+func (a App) handleQuickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.quick.ShowingResult() {
+		a.quick.SetVisible(false)
+		return a, nil
+	}
+
+	switch {
+	case key.Matches(msg, a.keys.Back):
+		a.quick.SetVisible(false)
+	case msg.Type == tea.KeyUp:
+		a.quick.MoveUp()
+	case msg.Type == tea.KeyDown:
+		a.quick.MoveDown()
+	case msg.Type == tea.KeyEnter:
+		if action, ok := a.quick.Selected(); ok {
+			return a, a.runQuickAction(action)
+		}
+	default:
+		if action, ok := a.quick.ByKey(msg.String()); ok {
+			return a, a.runQuickAction(action)
+		}
+	}
+	return a, nil
+}
+
+// openQuickActions shows the configured quick actions for the selected item
+func (a *App) openQuickActions() tea.Cmd {
+	node := a.tree.Selected()
+	if node == nil || node.Attrs.IsSynthetic() {
+		return nil
+	}
+	actions := a.ctrl.QuickActions()
+	if len(actions) == 0 {
+		return a.showToast("No quick actions configured; add them under \"actions\" in ~/.diskdive/config.json")
+	}
+	a.pendingNodes = []*model.Node{node}
+	a.quick.Open(actions, node.Name)
+	return nil
+}
+
+// runQuickAction closes the palette and runs an action in the background
+func (a *App) runQuickAction(action config.QuickAction) tea.Cmd {
+	a.quick.SetVisible(false)
+	if len(a.pendingNodes) == 0 {
+		return nil
+	}
+	node := a.pendingNodes[0]
+	a.pendingNodes = nil
+
+	a.setToast(fmt.Sprintf("Running %s on %s...", action.Name, node.Name))
+	ctrl := a.ctrl
+	return func() tea.Msg {
+		return quickActionDoneMsg{result: ctrl.RunQuickAction(action, node)}
+	}
+}
+
+// handlePromptKey handles keyboard input while the text prompt is open
+func (a App) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		if a.promptAction == promptSnapshotLabel {
+			a.snapshots.SetVisible(true)
+		}
+		a.prompt.Close()
+		a.promptAction = promptNone
+		a.pendingNodes = nil
+		return a, nil
+
+	case tea.KeyEnter:
+		value := a.prompt.Value()
+		action := a.promptAction
+		a.prompt.Close()
+		a.promptAction = promptNone
+
+		// Labels may be left empty
+		switch action {
+		case promptSubtreeLabel:
+			return a, a.saveSubtree(strings.TrimSpace(value))
+		case promptSnapshotLabel:
+			return a, a.labelSnapshot(strings.TrimSpace(value))
+		}
+		if value == "" {
+			a.pendingNodes = nil
+			return a, nil
+		}
+		switch action {
+		case promptNewFolder:
+			return a.createFolder(value)
+		case promptMove:
+			return a.requestMove(value)
+		case promptCompress:
+			return a.requestCompress(value)
+		}
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.prompt, cmd = a.prompt.Update(msg)
+	return a, cmd
+}
+
+// handleNumberKey handles keyboard input while the number input is open
+func (a App) handleNumberKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		a.number.Close()
+		switch a.numberAction {
+		case numberFlattenDepth:
+			a.flatten.SetVisible(true)
+		case numberFreedCorrection:
+			a.showFreed()
+		}
+		a.numberAction = numberNone
+		return a, nil
+
+	case tea.KeyEnter:
+		value, ok := a.number.Submit()
+		if !ok {
+			return a, nil
+		}
+		action := a.numberAction
+		a.number.Close()
+		a.numberAction = numberNone
+		switch action {
+		case numberFlattenDepth:
+			a.flatten.SetDepth(int(value))
+		case numberNodeBudget:
+			policy := a.ctrl.ScanPolicy()
+			policy.NodeBudget = int(value)
+			a.ctrl.SetScanPolicy(policy)
+			if value == 0 {
+				return a, a.showToast("No node budget from the next scan")
+			}
+			return a, a.showToast(fmt.Sprintf("Node budget of %s from the next scan", FormatCount(value)))
+		case numberFreedCorrection:
+			if value == 0 {
+				a.showFreed()
+				return a, nil
+			}
+			a.ctrl.CorrectFreed(value)
+			a.refreshFreed()
+			a.showFreed()
+			return a, a.showToast("Took " + FormatSize(value) + " off the space freed")
+		}
+		return a, nil
+	}
+
+	var cmd tea.Cmd
+	a.number, cmd = a.number.Update(msg)
+	return a, cmd
+}
+
+// showFreed opens the freed space chart with the latest totals
+func (a *App) showFreed() {
+	a.freed.Show(a.ctrl.FreedHistory(), a.ctrl.RecentDeletions(), a.ctrl.FreedState(), time.Now())
+}
+
+// refreshFreed shows corrected freed totals in the header
+func (a *App) refreshFreed() {
+	freed := a.ctrl.FreedState()
+	a.header.SetFreedStats(freed.Session, freed.Drive)
+}
+
+// handleConfirmKey handles keyboard input while the confirmation dialog is open
+func (a App) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := a.confirmAction
+	a.confirm.Close()
+	a.confirmAction = confirmNone
+
+	// Enter answers yes, except where the answer deletes or loses something
+	// for good, which takes an explicit y. Deleting a compressed folder takes
+	// one only if confirm.delete asks for it.
+	yes := msg.String() == "y" || msg.String() == "Y"
+	if msg.Type == tea.KeyEnter && action != confirmResetFreed &&
+		(action != confirmCompressDelete || !a.deleteNeedsConfirm) {
+		yes = true
+	}
+	if !yes {
+		switch action {
+		case confirmReuse:
+			return a.startScan()
+		case confirmResetFreed:
+			a.showFreed()
+			return a, nil
+		case confirmCompressDelete:
+			if msg.String() == "n" || msg.String() == "N" {
+				return a.compressItem(false)
+			}
+		}
+		a.pendingNodes = nil
+		a.pendingDest = ""
+		return a, a.showToast("Cancelled")
+	}
+
+	switch action {
+	case confirmMove:
+		dest := a.pendingDest
+		a.pendingDest = ""
+		return a.moveItems(dest)
+	case confirmReuse:
+		return a.reuseSnapshot()
+	case confirmTrash:
+		return a.trashItems()
+	case confirmCompressDelete:
+		return a.compressItem(true)
+	case confirmResetFreed:
+		a.ctrl.ResetFreed()
+		a.refreshFreed()
+		a.showFreed()
+		return a, a.showToast("Space freed reset")
+	}
+	return a, nil
+}
+
+// pendingSummary returns the total size of the pending nodes and whether any is a directory
+func (a App) pendingSummary() (total int64, hasDir bool) {
+	for _, node := range a.pendingNodes {
+		total += node.TotalSize()
+		hasDir = hasDir || node.IsDir
+	}
+	return total, hasDir
+}
+
+// openNewFolderPrompt asks for the name of a folder to create next to the selection
+func (a *App) openNewFolderPrompt() tea.Cmd {
+	parent := a.tree.Selected()
+	if parent != nil && !parent.IsDir {
+		parent = parent.Parent
+	}
+	if parent == nil || parent.IsDeleted || parent.Attrs.Has(model.AttrVirtual) {
+		return nil
+	}
+
+	a.pendingNodes = []*model.Node{parent}
+	a.promptAction = promptNewFolder
+	return a.prompt.Open("New folder in "+parent.Path(), "")
+}
+
+// actionNodes returns the marked items, or the selection if nothing is marked
+func (a App) actionNodes() []*model.Node {
+	nodes := a.ctrl.Marked()
+	if len(nodes) == 0 {
+		if node := a.tree.Selected(); node != nil && node.Parent != nil && !node.IsDeleted && !node.Attrs.IsSynthetic() {
+			nodes = []*model.Node{node}
+		}
+	}
+	return nodes
+}
+
+// openMovePrompt asks for the destination of the marked items (or the selection)
+func (a *App) openMovePrompt() tea.Cmd {
+	nodes := a.actionNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var total int64
+	for _, node := range nodes {
+		total += node.TotalSize()
+	}
+
+	a.pendingNodes = nodes
+	a.promptAction = promptMove
+	title := fmt.Sprintf("Move %d item(s), %s, to:", len(nodes), FormatSize(total))
+	return a.prompt.Open(title, filepath.Dir(nodes[0].Path())+string(filepath.Separator))
+}
+
+// createFolder creates the folder named in the prompt
+func (a *App) createFolder(name string) (tea.Model, tea.Cmd) {
+	parent := a.pendingNodes[0]
+	a.pendingNodes = nil
+
+	node, err := a.ctrl.CreateDirectory(parent, name)
+	if err != nil {
+		return a, a.showToast("Create folder failed: " + err.Error())
+	}
+
+	a.tree.ExpandTo(node)
+	a.treemap.Relayout()
+	a.updateLayout()
+	return a, tea.Batch(a.syncSelection(), a.showToast("Created "+node.Path()))
+}
+
+// requestMove moves the pending nodes to dest, first asking for confirmation
+// if the move policy in the config calls for it
+func (a *App) requestMove(dest string) (tea.Model, tea.Cmd) {
+	total, hasDir := a.pendingSummary()
+	if !a.ctrl.Config().Confirm.Move.Needs(total, hasDir) {
+		return a.moveItems(dest)
+	}
+
+	a.confirmAction = confirmMove
+	a.pendingDest = dest
+	a.confirm.Open(fmt.Sprintf("Move %d item(s), %s?", len(a.pendingNodes), FormatSize(total)),
+		"To: "+expandHome(dest))
+	return a, nil
+}
+
+// moveItems starts moving the pending nodes to the destination from the prompt
+func (a *App) moveItems(dest string) (tea.Model, tea.Cmd) {
+	nodes := a.pendingNodes
+	a.pendingNodes = nil
+
+	eventCh, err := a.ctrl.MoveItems(nodes, expandHome(dest))
+	if err != nil {
+		return a, a.showToast("Move failed: " + err.Error())
+	}
+
+	a.moveEventCh = eventCh
+	a.setToast(fmt.Sprintf("Moving %d item(s)...", len(nodes)))
+	return a, a.listenForMoveEvents()
+}
+
+// requestTrash moves the marked items (or the selection) to the trash,
+// first asking for confirmation if the trash policy in the config calls for it
+func (a *App) requestTrash() (tea.Model, tea.Cmd) {
+	a.pendingNodes = a.actionNodes()
+	if len(a.pendingNodes) == 0 {
+		return a, nil
+	}
+	total, hasDir := a.pendingSummary()
+	if !a.ctrl.Config().Confirm.Trash.Needs(total, hasDir) {
+		return a.trashItems()
+	}
+
+	a.confirmAction = confirmTrash
+	lines := []string{truncateLeft(a.pendingNodes[0].Path(), 50)}
+	if more := len(a.pendingNodes) - 1; more > 0 {
+		lines = append(lines, fmt.Sprintf("and %d more", more))
+	}
+	a.confirm.Open(fmt.Sprintf("Move %d item(s), %s, to the trash?", len(a.pendingNodes), FormatSize(total)), lines...)
+	return a, nil
+}
+
+// trashItems starts moving the pending nodes to the trash
+func (a *App) trashItems() (tea.Model, tea.Cmd) {
+	nodes := a.pendingNodes
+	a.pendingNodes = nil
+
+	eventCh, err := a.ctrl.TrashItems(nodes)
+	if err != nil {
+		return a, a.showToast("Move to trash failed: " + err.Error())
+	}
+
+	a.moveEventCh = eventCh
+	a.setToast(fmt.Sprintf("Moving %d item(s) to the trash...", len(nodes)))
+	return a, a.listenForMoveEvents()
+}
+
+// openCompressPrompt asks where to write the archive of the selected folder
+func (a *App) openCompressPrompt() tea.Cmd {
+	node := a.tree.Selected()
+	if node == nil || !node.IsDir || node.Parent == nil || node.IsDeleted || node.Attrs.IsSynthetic() {
+		return a.showToast("Select a folder to compress")
+	}
+
+	a.pendingNodes = []*model.Node{node}
+	a.promptAction = promptCompress
+	title := fmt.Sprintf("Compress %s, %s, to (%s):", node.Name, FormatSize(node.TotalSize()), strings.Join(fileops.ArchiveExts, " "))
+	return a.prompt.Open(title, node.Path()+fileops.ArchiveExts[0])
+}
+
+// requestCompress asks whether to delete the pending folder once it has
+// been compressed to dest
+func (a *App) requestCompress(dest string) (tea.Model, tea.Cmd) {
+	node := a.pendingNodes[0]
+	a.confirmAction = confirmCompressDelete
+	a.pendingDest = dest
+	a.deleteNeedsConfirm = a.ctrl.Config().Confirm.Delete.Needs(node.TotalSize(), true)
+	a.confirm.Open("Delete "+node.Name+" once it is compressed?", node.Path(), "To: "+expandHome(dest))
+	if a.deleteNeedsConfirm {
+		a.confirm.SetHint("y delete it  n keep it  Esc cancel")
+	} else {
+		a.confirm.SetHint("y/Enter delete it  n keep it  Esc cancel")
+	}
+	return a, nil
+}
+
+// compressItem starts compressing the pending folder to the destination
+// from the prompt
+func (a *App) compressItem(deleteAfter bool) (tea.Model, tea.Cmd) {
+	node, dest := a.pendingNodes[0], a.pendingDest
+	a.pendingNodes = nil
+	a.pendingDest = ""
+
+	eventCh, err := a.ctrl.CompressItem(node, expandHome(dest), deleteAfter)
+	if err != nil {
+		return a, a.showToast("Compress failed: " + err.Error())
+	}
+
+	a.moveEventCh = eventCh
+	a.setToast("Compressing " + node.Name + "...")
+	return a, a.listenForMoveEvents()
+}
+
+// handleMoveEvent processes move events and continues listening
+func (a App) handleMoveEvent(event core.Event) (tea.Model, tea.Cmd) {
+	switch e := event.(type) {
+	case core.MoveProgressEvent:
+		pct := 0
+		if e.BytesTotal > 0 {
+			pct = int(e.BytesDone * 100 / e.BytesTotal)
+		}
+		a.setToast(fmt.Sprintf("Moving %s  %s / %s (%d%%)",
+			filepath.Base(e.Path), FormatSize(e.BytesDone), FormatSize(e.BytesTotal), pct))
+		return a, a.listenForMoveEvents()
+
+	case core.MoveCompletedEvent:
+		a.moveEventCh = nil
+		freed := a.ctrl.FreedState()
+		a.header.SetFreedStats(freed.Session, freed.Drive)
+		if e.DiskFree > 0 {
+			a.header.UpdateDiskFree(e.DiskFree)
+		}
+		a.tree.RefreshVisible()
+		a.treemap.Relayout()
+		a.updateLayout()
+		a.updateWhatIf()
+
+		text := fmt.Sprintf("Moved %d item(s)", e.Moved)
+		if e.Err != nil {
+			text += fmt.Sprintf(", error: %v", e.Err)
+		}
+		return a, a.showToast(text)
+
+	case core.CompressProgressEvent:
+		pct := 0
+		if e.BytesTotal > 0 {
+			pct = int(min(e.BytesDone*100/e.BytesTotal, 100))
+		}
+		a.setToast(fmt.Sprintf("Compressing %s  %s / %s (%d%%)",
+			filepath.Base(e.Path), FormatSize(e.BytesDone), FormatSize(e.BytesTotal), pct))
+		return a, a.listenForMoveEvents()
+
+	case core.CompressCompletedEvent:
+		a.moveEventCh = nil
+		a.refreshFreed()
+		if e.DiskFree > 0 {
+			a.header.UpdateDiskFree(e.DiskFree)
+		}
+		a.tree.RefreshVisible()
+		a.treemap.Relayout()
+		a.updateLayout()
+		a.updateWhatIf()
+
+		if e.Archive == "" {
+			return a, a.showToast(fmt.Sprintf("Compress failed: %v", e.Err))
+		}
+		text := fmt.Sprintf("Compressed to %s, %s", e.Archive, FormatSize(e.Size))
+		switch {
+		case e.Err != nil:
+			text += fmt.Sprintf(", error: %v", e.Err)
+		case e.Deleted:
+			text += fmt.Sprintf(", folder deleted, %s freed", FormatSize(e.Freed))
+		}
+		return a, a.showToast(text)
+
+	case core.TrashCompletedEvent:
+		a.moveEventCh = nil
+		a.refreshFreed()
+		if e.DiskFree > 0 {
+			a.header.UpdateDiskFree(e.DiskFree)
+		}
+		a.tree.RefreshVisible()
+		a.treemap.Relayout()
+		a.updateLayout()
+		a.updateWhatIf()
+
+		text := fmt.Sprintf("Moved %d item(s), %s, to the trash", e.Trashed, FormatSize(e.Freed))
+		if e.Err != nil {
+			text += fmt.Sprintf(", error: %v", e.Err)
+		}
+		return a, a.showToast(text)
+	}
+	return a, a.listenForMoveEvents()
+}
+
+// listenForMoveEvents creates a command that listens for move events
+func (a App) listenForMoveEvents() tea.Cmd {
+	if a.moveEventCh == nil {
+		return nil
+	}
+	eventCh := a.moveEventCh
+	return func() tea.Msg {
+		event, ok := <-eventCh
+		if !ok {
+			return nil // Channel closed
+		}
+		return moveEventMsg{event: event}
+	}
+}
+
+// rescanSelected rescans the selected folder (or the selected file's folder) in the background
+func (a *App) rescanSelected() tea.Cmd {
+	node := a.tree.Selected()
+	if node != nil && !node.IsDir {
+		node = node.Parent
+	}
+	if node == nil || a.rescanning || a.ctrl.ScanState().IsScanning() {
+		return nil
+	}
+
+	a.rescanning = true
+	a.setToast(fmt.Sprintf("Rescanning %s...", node.Name))
+	ctrl := a.ctrl
+	return func() tea.Msg {
+		fresh, err := ctrl.RescanSubtree(node)
+		return rescanDoneMsg{old: node, node: fresh, err: err}
+	}
+}
+
+// pruneDeleted drops deleted items from the tree and refreshes the panels
+func (a *App) pruneDeleted() tea.Cmd {
+	root := a.ctrl.Root()
+	if root == nil {
+		return nil
+	}
+	count, bytes := a.ctrl.PruneDeleted()
+	if count == 0 {
+		return a.showToast("No deleted items to prune")
+	}
+
+	// A treemap focused inside a pruned folder goes back to the root
+	if focus := a.treemap.Focus(); focus != nil && !isAttached(focus, root) {
+		a.treemap.SetFocus(root)
+	}
+	a.tree.RefreshVisible()
+	a.treemap.Relayout()
+	a.updateLayout()
+	a.updateWhatIf()
+	return tea.Batch(a.showToast(fmt.Sprintf("Pruned %s deleted items (%s)", FormatCount(int64(count)), FormatSize(bytes))), a.syncSelection())
+}
+
+// isAttached reports whether node is still part of the tree under root
+func isAttached(node, root *model.Node) bool {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node == root
+}
+
+// handleRescanDone refreshes the panels after a folder rescan
+func (a App) handleRescanDone(msg rescanDoneMsg) (tea.Model, tea.Cmd) {
+	a.rescanning = false
+	if msg.err != nil {
+		return a, a.showToast("Rescan failed: " + msg.err.Error())
+	}
+
+	a.tree.RefreshVisible()
+	a.treemap.Relayout()
+	a.updateLayout()
+	a.flasher.Flash(msg.node)
+
+	text := fmt.Sprintf("Rescanned %s: %s", msg.node.Name, FormatSize(msg.node.TotalSize()))
+	if delta := msg.node.TotalSize() - msg.old.TotalSize(); delta != 0 {
+		sign := "+"
+		if delta < 0 {
+			sign = ""
+		}
+		text += fmt.Sprintf(" (%s%s)", sign, FormatSize(delta))
+	}
+	return a, tea.Batch(a.showToast(text), a.startFlash())
+}
+
+// setToast shows a status message until it is replaced or cleared
+func (a *App) setToast(text string) {
+	a.toast = text
+	a.toastVersion++
+}
+
+// showToast shows a status message that clears itself after toastDuration
+func (a *App) showToast(text string) tea.Cmd {
+	a.setToast(text)
+	version := a.toastVersion
+	return tea.Tick(toastDuration, func(t time.Time) tea.Msg {
+		return toastClearMsg{version: version}
+	})
+}
+
+// selectDrive selects a drive and starts scanning
+func (a *App) selectDrive(idx int) (tea.Model, tea.Cmd) {
+	if err := a.ctrl.SelectDrive(idx); err != nil {
+		a.err = err
+		return a, nil
+	}
+
+	freed := a.ctrl.FreedState()
+	a.header.SetFreedStats(freed.Session, freed.Drive)
+	a.header.SetDriveStats(a.ctrl.DriveStats())
+	a.header.SetSelected(idx)
+	a.header.SetScanning(true, "")
+	a.tree.SetRoot(nil)
+	a.treemap.SetRoot(nil)
+
+	return a.startScan()
+}
+
+// rescanPath scans the folder diskdive was opened on again
+func (a *App) rescanPath() (tea.Model, tea.Cmd) {
+	a.header.SetScanning(true, "")
+	a.tree.SetRoot(nil)
+	a.treemap.SetRoot(nil)
+	return a.startScan()
+}
+
+// reroot makes the selected folder the root of the view
+func (a *App) reroot() (tea.Model, tea.Cmd) {
+	root, err := a.ctrl.Reroot(a.tree.Selected())
+	if err != nil {
+		return a, a.showToast("Cannot open as root: " + err.Error())
+	}
+	a.whatIf = nil
+	a.treemap.SetProjector(nil)
+	return a.finalizeScan(root)
+}
+
+// askSubtreeLabel asks for an optional label before saving the selected
+// folder as a snapshot of its own
+func (a *App) askSubtreeLabel() tea.Cmd {
+	node := a.tree.Selected()
+	if node == nil || !node.IsDir || node.Attrs.IsSynthetic() {
+		return a.showToast("Select a folder to save a snapshot of")
+	}
+	a.pendingNodes = []*model.Node{node}
+	a.promptAction = promptSubtreeLabel
+	return a.prompt.Open("Label for the snapshot of "+node.Name+" (optional)", "")
+}
+
+// saveSubtree stores the folder awaiting its label as a snapshot of its own
+// in the background
+func (a *App) saveSubtree(label string) tea.Cmd {
+	if len(a.pendingNodes) == 0 {
+		return nil
+	}
+	node := a.pendingNodes[0]
+	a.pendingNodes = nil
+
+	a.setToast("Saving a snapshot of " + node.Name + "...")
+	ctrl := a.ctrl
+	return func() tea.Msg {
+		_, err := ctrl.SaveSubtree(node, label)
+		return subtreeSavedMsg{path: node.Path(), err: err}
+	}
+}
+
+// askSnapshotLabel asks for the label of the saved scan under the cursor of
+// the snapshot picker, which returns once it is set
+func (a *App) askSnapshotLabel() tea.Cmd {
+	s, ok := a.snapshots.Cursor()
+	if !ok {
+		return a.showToast("Only saved scans can be labeled")
+	}
+	a.snapshots.SetVisible(false)
+	a.labelAt = s.ScannedAt
+	a.promptAction = promptSnapshotLabel
+	return a.prompt.Open("Label for the scan of "+FormatTime(s.ScannedAt), s.Label)
+}
+
+// labelSnapshot sets the label asked for and lists the snapshots again
+func (a *App) labelSnapshot(label string) tea.Cmd {
+	err := a.ctrl.LabelSnapshot(a.labelAt, label)
+	a.snapshots.Relist(a.ctrl.Snapshots())
+	if err != nil {
+		return a.showToast("Cannot label the scan: " + err.Error())
+	}
+	return nil
+}
+
+// compareScans shows what changed between two scans of the current path
+func (a *App) compareScans(from, to time.Time) (tea.Model, tea.Cmd) {
+	root, err := a.ctrl.Compare(from, to)
+	if err != nil {
+		return a, a.showToast("Cannot compare: " + err.Error())
+	}
+	a.whatIf = nil
+	a.treemap.SetProjector(nil)
+	a.flasher.Clear()
+	a.tree.SetRoot(root)
+	a.tree.SetComparing(true)
+	a.treemap.SetRoot(root)
+	a.header.SetComparison(a.ctrl.ScanState().Comparison)
+	a.updateLayout()
+	return a, nil
+}
+
+// comparing reports whether the tree shows the changes between two scans
+func (a *App) comparing() bool {
+	return !a.ctrl.ScanState().Comparison.From.IsZero()
+}
+
+// syncSelection syncs tree selection to treemap
+func (a *App) syncSelection() tea.Cmd {
+	node := a.tree.Selected()
+	if node == nil {
 		return nil
 	}
 	a.treemap.SetSelected(node)
@@ -521,7 +2080,7 @@ func (a *App) syncSelection() tea.Cmd {
 
 	a.focusVersion++
 	version := a.focusVersion
-	return tea.Tick(focusDebounceTimeout, func(t time.Time) tea.Msg {
+	return tea.Tick(a.ctrl.Config().Debounce.Focus.Std(), func(t time.Time) tea.Msg {
 		return focusDebounceMsg{version: version, node: focusTarget}
 	})
 }
@@ -531,14 +2090,24 @@ func (a *App) syncSelectionFromTreemap() {
 	// Don't expand tree to match - could be jarring
 }
 
-// openInExplorer opens the selected item in file manager
+// openInExplorer opens the marked items, or else the selected one, in the file manager
 func (a *App) openInExplorer() tea.Cmd {
+	if count, _ := a.ctrl.MarkedTotal(); count > 0 {
+		opened, err := a.ctrl.OpenMarked(openInFileManager)
+		if err != nil {
+			return a.showToast("Open failed: " + err.Error())
+		}
+		if opened < count {
+			return a.showToast(fmt.Sprintf("Opened the %d largest of %d marked items", opened, count))
+		}
+		return nil
+	}
 	node := a.tree.Selected()
-	if node == nil {
+	if node == nil || node.Attrs.IsSynthetic() {
 		return nil
 	}
-	logging.Debug.Printf("openInExplorer: revealing %s", node.Path)
-	if err := openInFileManager(node.Path); err != nil {
+	logging.Debug.Printf("openInExplorer: revealing %s", node.Path())
+	if err := openInFileManager(node.Path()); err != nil {
 		logging.Debug.Printf("openInExplorer: error: %v", err)
 	}
 	return nil
@@ -547,11 +2116,11 @@ func (a *App) openInExplorer() tea.Cmd {
 // previewFile opens Quick Look preview
 func (a *App) previewFile() tea.Cmd {
 	node := a.tree.Selected()
-	if node == nil {
+	if node == nil || node.Attrs.IsSynthetic() {
 		return nil
 	}
-	logging.Debug.Printf("previewFile: previewing %s", node.Path)
-	if err := previewInQuickLook(node.Path); err != nil {
+	logging.Debug.Printf("previewFile: previewing %s", node.Path())
+	if err := previewInQuickLook(node.Path()); err != nil {
 		logging.Debug.Printf("previewFile: error: %v", err)
 	}
 	return nil
@@ -577,12 +2146,35 @@ func (a *App) updateLayout() {
 		treeWidth = 20
 	}
 
+	// A maximized panel takes the full width and the other one is not drawn
+	if a.maximized {
+		if a.activePanel == PanelTree {
+			treeWidth = a.width - 2 // The border is drawn outside the panel width
+		} else {
+			treeWidth = 0
+		}
+	}
+
 	a.header.SetWidth(a.width)
 	a.tree.SetSize(treeWidth, panelHeight)
 	a.rightPanelWidth = a.width - treeWidth
 	a.treemap.SetSize(a.rightPanelWidth, panelHeight-infoBarHeight)
 	a.help.SetSize(a.width, a.height)
+	a.integrity.SetSize(a.width, a.height)
+	a.slowPaths.SetSize(a.width, a.height)
+	a.watchStats.SetSize(a.width, a.height)
+	a.owners.SetSize(a.width, a.height)
+	a.growth.SetSize(a.width, a.height)
+	a.freed.SetSize(a.width, a.height)
+	a.quick.SetSize(a.width, a.height)
+	a.topFiles.SetSize(a.width, a.height)
+	a.flatten.SetSize(a.width, a.height)
+	a.explain.SetSize(a.width, a.height)
+	a.snapshots.SetSize(a.width, a.height)
+	a.number.SetSize(a.width, a.height)
 	a.driveSelector.SetSize(a.width, a.height)
+	a.prompt.SetSize(a.width, a.height)
+	a.confirm.SetSize(a.width, a.height)
 }
 
 // View implements tea.Model
@@ -613,7 +2205,15 @@ func (a App) View() string {
 		sections = append(sections, a.renderMainPanels())
 	}
 
-	sections = append(sections, HelpBar(a.width))
+	if a.toast != "" {
+		sections = append(sections, ToastStyle.Width(a.width).MaxHeight(1).Render(a.toast))
+	} else if a.whatIf != nil && root != nil {
+		sections = append(sections, whatIfBar(*a.whatIf, a.width))
+	} else if count, size := a.ctrl.MarkedTotal(); (count > 0 || a.tree.Visual()) && root != nil {
+		sections = append(sections, markedBar(count, size, a.tree.Visual(), a.width))
+	} else {
+		sections = append(sections, HelpBar(a.width))
+	}
 	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 
 	// Overlays
@@ -623,6 +2223,48 @@ func (a App) View() string {
 	if a.driveSelector.IsVisible() {
 		return a.renderOverlay(a.driveSelector.View())
 	}
+	if a.prompt.IsVisible() {
+		return a.renderOverlay(a.prompt.View())
+	}
+	if a.number.IsVisible() {
+		return a.renderOverlay(a.number.View())
+	}
+	if a.confirm.IsVisible() {
+		return a.renderOverlay(a.confirm.View())
+	}
+	if a.integrity.IsVisible() {
+		return a.renderOverlay(a.integrity.View())
+	}
+	if a.slowPaths.IsVisible() {
+		return a.renderOverlay(a.slowPaths.View())
+	}
+	if a.watchStats.IsVisible() {
+		return a.renderOverlay(a.watchStats.View())
+	}
+	if a.owners.IsVisible() {
+		return a.renderOverlay(a.owners.View())
+	}
+	if a.growth.IsVisible() {
+		return a.renderOverlay(a.growth.View())
+	}
+	if a.freed.IsVisible() {
+		return a.renderOverlay(a.freed.View())
+	}
+	if a.quick.IsVisible() {
+		return a.renderOverlay(a.quick.View())
+	}
+	if a.topFiles.IsVisible() {
+		return a.renderOverlay(a.topFiles.View())
+	}
+	if a.flatten.IsVisible() {
+		return a.renderOverlay(a.flatten.View())
+	}
+	if a.explain.IsVisible() {
+		return a.renderOverlay(a.explain.View())
+	}
+	if a.snapshots.IsVisible() {
+		return a.renderOverlay(a.snapshots.View())
+	}
 
 	return content
 }
@@ -652,23 +2294,27 @@ func (a App) renderScanningPanel(state core.ScanState) string {
 	spinnerIdx := int(time.Now().UnixMilli()/int64(spinnerTickInterval.Milliseconds())) % len(spinnerFrames)
 	spinner := spinnerFrames[spinnerIdx]
 
-	// Progress bar
+	// Progress bar: the previous snapshot gives a real denominator, otherwise
+	// compare bytes found with the drive's used space
 	var progressBar string
-	if a.ctrl.CustomPath() == "" {
+	progress := state.Fraction()
+	if progress < 0 && a.ctrl.CustomPath() == "" {
 		if drive := a.ctrl.SelectedDrive(); drive != nil && drive.UsedBytes() > 0 {
-			progress := float64(state.BytesFound) / float64(drive.UsedBytes())
+			progress = float64(state.BytesFound) / float64(drive.UsedBytes())
 			if progress > 1.0 {
 				progress = 1.0
 			}
-			maxDots := 20
-			numDots := int(progress * float64(maxDots))
-			emptyDots := maxDots - numDots
-			dotStyle := lipgloss.NewStyle().Foreground(ColorCyan)
-			emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#3F3F46"))
-			bracketStyle := lipgloss.NewStyle().Foreground(ColorCyan)
-			progressBar = " " + bracketStyle.Render("[") + dotStyle.Render(strings.Repeat("·", numDots)) + emptyStyle.Render(strings.Repeat("·", emptyDots)) + bracketStyle.Render("]")
 		}
 	}
+	if progress >= 0 {
+		maxDots := 20
+		numDots := int(progress * float64(maxDots))
+		emptyDots := maxDots - numDots
+		dotStyle := lipgloss.NewStyle().Foreground(ColorCyan)
+		emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#3F3F46"))
+		bracketStyle := lipgloss.NewStyle().Foreground(ColorCyan)
+		progressBar = " " + bracketStyle.Render("[") + dotStyle.Render(strings.Repeat("·", numDots)) + emptyStyle.Render(strings.Repeat("·", emptyDots)) + bracketStyle.Render("]")
+	}
 
 	// Phase display
 	phases := []struct {
@@ -704,11 +2350,41 @@ func (a App) renderScanningPanel(state core.ScanState) string {
 		fileStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00FFFF")).Bold(true)
 		dataStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#C084FC")).Bold(true)
 		timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FBBF24")).Bold(true)
+		rateStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#34D399")).Bold(true)
+		pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF"))
 
 		logLines = append(logLines, "")
-		logLines = append(logLines, fmt.Sprintf("    %s %s", labelStyle.Render("FILES"), fileStyle.Render(fmt.Sprintf("%d files", state.FilesScanned))))
+		logLines = append(logLines, fmt.Sprintf("    %s %s", labelStyle.Render("FILES"), fileStyle.Render(FormatCount(state.FilesScanned)+" files")))
 		logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("DATA"), dataStyle.Render(FormatSize(state.BytesFound))))
-		logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("TIME"), timeStyle.Render(state.Elapsed().String())))
+		timeText := state.Elapsed().String()
+		if pct := state.Fraction(); pct >= 0 {
+			timeText += fmt.Sprintf(" · %d%%", int(pct*100))
+			if eta := state.ETA(); eta > 0 {
+				timeText += fmt.Sprintf(" · ~%s left", eta)
+			}
+		}
+		logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("TIME"), timeStyle.Render(timeText)))
+		logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("RATE"), rateStyle.Render(
+			fmt.Sprintf("%.0f files/s · %s/s", state.FilesPerSec, FormatSize(int64(state.BytesPerSec))))))
+		if state.Workers > 0 {
+			diskText := rateStyle.Render(fmt.Sprintf("%.1f/%d busy", state.Busy, state.Workers))
+			if state.Stalled >= scanStallThreshold {
+				stallStyle := lipgloss.NewStyle().Foreground(ColorDanger).Bold(true)
+				diskText += stallStyle.Render(fmt.Sprintf(" · stuck %s", state.Stalled.Truncate(time.Second)))
+			}
+			logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("DISK"), diskText))
+		}
+		logLines = append(logLines, fmt.Sprintf("    %s  %s", labelStyle.Render("PATH"), pathStyle.Render(truncateLeft(state.CurrentPath, 32))))
+		if state.Following != 0 {
+			hintStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(40).MarginLeft(4)
+			logLines = append(logLines, "", hintStyle.Render(fmt.Sprintf("Another diskdive (pid %d) is already scanning this path; following its progress", state.Following)))
+		}
+		if state.Interference {
+			if hint := interferenceHint(state.Path, truncateLeft(state.CurrentPath, 60)); hint != "" {
+				hintStyle := lipgloss.NewStyle().Foreground(ColorMarked).Width(40).MarginLeft(4)
+				logLines = append(logLines, "", hintStyle.Render(hint))
+			}
+		}
 	}
 
 	logContent := strings.Join(logLines, "\n")
@@ -717,7 +2393,8 @@ func (a App) renderScanningPanel(state core.ScanState) string {
 		Width(48).
 		Render(logContent)
 
-	boxHeight := 9
+	// Hints below the stats make the box taller
+	boxHeight := max(11, lipgloss.Height(innerContent)+2)
 	scanningBox := renderSpinningBorder(
 		lipgloss.Place(48, boxHeight-2, lipgloss.Left, lipgloss.Center, innerContent),
 		50, boxHeight, time.Now())
@@ -727,6 +2404,10 @@ func (a App) renderScanningPanel(state core.ScanState) string {
 
 // renderMainPanels renders the tree and treemap panels
 func (a App) renderMainPanels() string {
+	if a.maximized && a.activePanel == PanelTree {
+		return a.tree.View()
+	}
+
 	treeView := a.tree.View()
 	infoBar := a.infoBar()
 
@@ -739,6 +2420,9 @@ func (a App) renderMainPanels() string {
 	}
 
 	rightPanel := lipgloss.JoinVertical(lipgloss.Left, infoBar, rightContent)
+	if a.maximized {
+		return rightPanel
+	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, treeView, rightPanel)
 }
 
@@ -782,22 +2466,54 @@ func (a App) buildNodeInfo(node *model.Node) string {
 	var parts []string
 	parts = append(parts, icon, " ", name)
 
+	if label := attrLabel(node.Attrs); label != "" {
+		parts = append(parts, sep, dimStyle.Render(label))
+	}
+
+	if owner := model.OwnerName(node.UID); owner != "" {
+		parts = append(parts, sep, dimStyle.Render(owner+":"+model.GroupName(node.GID)))
+	}
+
+	if filter := categoryFilters[a.filter]; filter.set != 0 {
+		parts = append(parts, sep, dimStyle.Render("only "+filter.label))
+	}
+
 	if node.IsDir {
-		count := countFiles(node)
-		parts = append(parts, sep, dimStyle.Render(fmt.Sprintf("%d files", count)))
+		var noise func(*model.Node) bool
+		if a.ctrl.Config().Noise.Uncounted {
+			noise = a.ctrl.Noise().Is
+		}
+		info := a.folder.of(node, time.Now(), noise)
+		parts = append(parts, sep, dimStyle.Render(FormatCount(info.files)+" files"))
 
-		if info, err := os.Stat(node.Path); err == nil {
-			createTime := getCreationTime(info)
-			modTime := info.ModTime()
+		// What the folder keeps out of view while hidden items are left out
+		if hidden, bytes := node.HiddenChildren(); a.noHidden && hidden > 0 {
+			parts = append(parts, sep, dimStyle.Render(fmt.Sprintf("%s hidden, %s", FormatCount(int64(hidden)), FormatSize(bytes))))
+		}
 
-			if createTimeStr := FormatTime(createTime); createTimeStr != "" {
-				parts = append(parts, sep, dimStyle.Render("C: "+createTimeStr))
-			}
+		// What kinds of files take up the folder
+		if bar := categoryBar(node.CategorySizes(), categoryBarWidth); bar != "" {
+			parts = append(parts, sep, bar)
+		}
 
-			modTimeStr := FormatTime(modTime)
-			if modTimeStr != FormatTime(createTime) {
-				parts = append(parts, sep, dimStyle.Render("M: "+modTimeStr))
-			}
+		// How the folder's size went across saved scans
+		if spark := sparkline(a.history.Samples(node.Path()), sparkWidth); spark != "" {
+			parts = append(parts, sep, spark)
+		}
+
+		// How much of the folder has sat untouched for the longest bucket
+		if age, bytes, ok := info.age.Oldest(); ok {
+			parts = append(parts, sep, dimStyle.Render(fmt.Sprintf("%s untouched %s+", FormatSize(bytes), age.Label)))
+		}
+
+		// Modification time comes from the scan and creation time is read with
+		// the folder info, so rendering never touches the disk
+		createTimeStr := FormatTime(info.created)
+		if createTimeStr != "" {
+			parts = append(parts, sep, dimStyle.Render("C: "+createTimeStr))
+		}
+		if modTimeStr := FormatTime(node.Modified()); modTimeStr != "" && modTimeStr != createTimeStr {
+			parts = append(parts, sep, dimStyle.Render("M: "+modTimeStr))
 		}
 	}
 
@@ -822,13 +2538,35 @@ func (a App) fileDetailsPanel() string {
 
 	var contentLines []string
 
-	if fileType := getFileType(node.Path); fileType != "" {
+	details := a.details.of(node)
+	fileType, mimeCategory := details.fileType, details.category
+	if fileType != "" {
 		contentLines = append(contentLines, labelStyle.Render("Type: ")+valueStyle.Render(fileType))
 	}
 
+	// The extension decides the category; content only helps when it says nothing
+	category := node.Category
+	if category == model.CategoryOther {
+		category = mimeCategory
+	}
+	categoryStyle := lipgloss.NewStyle().Foreground(categoryColor(category))
+	contentLines = append(contentLines, labelStyle.Render("Category: ")+categoryStyle.Render(category.String()))
+
 	contentLines = append(contentLines, labelStyle.Render("Size: ")+valueStyle.Render(FormatSize(node.TotalSize())))
 
-	if info, err := os.Stat(node.Path); err == nil {
+	// Compressed and sparse files hold more data than they take on disk
+	if node.StoredSmaller() {
+		contentLines = append(contentLines, labelStyle.Render("Logical: ")+valueStyle.Render(FormatSize(node.Logical)))
+		contentLines = append(contentLines, labelStyle.Render("Ratio: ")+valueStyle.Render(fmt.Sprintf("%.2fx", node.CompressionRatio())))
+	}
+	switch {
+	case node.Attrs.Has(model.AttrCompressed):
+		contentLines = append(contentLines, labelStyle.Render("Storage: ")+valueStyle.Render("compressed by the filesystem"))
+	case node.Attrs.Has(model.AttrSparse):
+		contentLines = append(contentLines, labelStyle.Render("Storage: ")+valueStyle.Render("sparse, holes take no space"))
+	}
+
+	if info := details.info; info != nil {
 		if timeStr := FormatTime(getCreationTime(info)); timeStr != "" {
 			contentLines = append(contentLines, labelStyle.Render("Created: ")+valueStyle.Render(timeStr))
 		}
@@ -837,8 +2575,16 @@ func (a App) fileDetailsPanel() string {
 	}
 
 	contentLines = append(contentLines, "")
-	contentLines = append(contentLines, labelStyle.Render("Path:"))
-	contentLines = append(contentLines, pathStyle.Render(node.Path))
+	if node.Attrs.Has(model.AttrVirtual) {
+		contentLines = append(contentLines, labelStyle.Render("Estimated space with no file behind it."))
+		contentLines = append(contentLines, labelStyle.Render("Press i for details."))
+	} else if node.Attrs.Has(model.AttrGrouped) {
+		contentLines = append(contentLines, labelStyle.Render("Small files grouped to keep a huge tree in memory."))
+		contentLines = append(contentLines, labelStyle.Render("Raise scan.nodeBudget in the config to list them."))
+	} else {
+		contentLines = append(contentLines, labelStyle.Render("Path:"))
+		contentLines = append(contentLines, pathStyle.Render(node.Path()))
+	}
 
 	borderColor := lipgloss.Color("#2D6A6A")
 	if a.activePanel == PanelTreemap {
@@ -870,28 +2616,17 @@ func (a App) fileDetailsPanel() string {
 }
 
 // getFileType detects file type using magic numbers
-func getFileType(path string) string {
+func getFileType(path string) (string, model.Category) {
 	mtype, err := mimetype.DetectFile(path)
 	if err != nil {
-		return ""
+		return "", model.CategoryOther
 	}
+	category := model.CategoryFromMIME(mtype.String())
 	ext := mtype.Extension()
 	if ext != "" {
-		return strings.ToUpper(strings.TrimPrefix(ext, "."))
-	}
-	return ""
-}
-
-// countFiles counts all files in a node tree
-func countFiles(node *model.Node) int {
-	if !node.IsDir {
-		return 1
-	}
-	count := 0
-	for _, child := range node.Children {
-		count += countFiles(child)
+		return strings.ToUpper(strings.TrimPrefix(ext, ".")), category
 	}
-	return count
+	return "", category
 }
 
 // renderSpinningBorder draws a box with spinning gradient border
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const categoryBarWidth = 12 // Cells in the info bar's category breakdown
+
+// categoryFilter limits the tree to folders and files holding some categories
+type categoryFilter struct {
+	label string
+	set   model.CategorySet // zero shows everything
+}
+
+// categoryFilters lists the filters the category key cycles through
+var categoryFilters = func() []categoryFilter {
+	filters := []categoryFilter{{}, {label: "media", set: model.MediaCategories}}
+	for _, c := range model.Categories {
+		filters = append(filters, categoryFilter{label: c.String(), set: model.NewCategorySet(c)})
+	}
+	return filters
+}()
+
+// categoryBar renders a stacked bar of the bytes per category, in display order
+func categoryBar(sizes model.CategorySizes, width int) string {
+	total := sizes.Total()
+	if total <= 0 || width <= 0 {
+		return ""
+	}
+
+	// Largest remainder: floor every share, then hand the leftover cells to
+	// the categories that lost the most in rounding
+	cells := make([]int, len(model.Categories))
+	rests := make([]int64, len(model.Categories))
+	used := 0
+	for i, c := range model.Categories {
+		scaled := sizes[c] * int64(width)
+		cells[i] = int(scaled / total)
+		rests[i] = scaled % total
+		used += cells[i]
+	}
+	for ; used < width; used++ {
+		best := 0
+		for i := range rests {
+			if rests[i] > rests[best] {
+				best = i
+			}
+		}
+		cells[best]++
+		rests[best] = -1
+	}
+
+	var b strings.Builder
+	for i, c := range model.Categories {
+		if cells[i] > 0 {
+			style := lipgloss.NewStyle().Foreground(categoryColor(c))
+			b.WriteString(style.Render(strings.Repeat("█", cells[i])))
+		}
+	}
+	return b.String()
+}
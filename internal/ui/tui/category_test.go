@@ -0,0 +1,24 @@
+package tui
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestCategoryBar(t *testing.T) {
+	var sizes model.CategorySizes
+	sizes[model.CategoryVideo] = 700
+	sizes[model.CategoryCode] = 200
+	sizes[model.CategoryOther] = 100
+
+	bar := ansi.Strip(categoryBar(sizes, 12))
+	if n := utf8.RuneCountInString(bar); n != 12 {
+		t.Errorf("expected 12 cells, got %d: %q", n, bar)
+	}
+	if categoryBar(model.CategorySizes{}, 12) != "" {
+		t.Error("expected no bar for an empty breakdown")
+	}
+}
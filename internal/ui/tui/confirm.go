@@ -0,0 +1,92 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ConfirmDialog asks a yes/no question before an action runs
+type ConfirmDialog struct {
+	title   string
+	lines   []string
+	hint    string // Keys line, when not the usual one
+	visible bool
+	width   int
+	height  int
+}
+
+// NewConfirmDialog creates a new confirmation dialog component
+func NewConfirmDialog() ConfirmDialog {
+	return ConfirmDialog{}
+}
+
+// Open shows the dialog with a question and lines of detail
+func (c *ConfirmDialog) Open(title string, lines ...string) {
+	c.title = title
+	c.lines = lines
+	c.hint = ""
+	c.visible = true
+}
+
+// SetHint replaces the keys line of the open dialog, for questions where
+// no is not the same as cancel
+func (c *ConfirmDialog) SetHint(hint string) {
+	c.hint = hint
+}
+
+// Close hides the dialog
+func (c *ConfirmDialog) Close() {
+	c.visible = false
+}
+
+// IsVisible returns whether the dialog is visible
+func (c ConfirmDialog) IsVisible() bool {
+	return c.visible
+}
+
+// SetSize sets the dimensions for centering
+func (c *ConfirmDialog) SetSize(w, h int) {
+	c.width = w
+	c.height = h
+}
+
+// View renders the confirmation dialog
+func (c ConfirmDialog) View() string {
+	if !c.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorMarked).
+		Padding(1, 2).
+		Background(ColorBackground)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorMarked).
+		Bold(true).
+		MarginBottom(1)
+
+	lineStyle := lipgloss.NewStyle().Foreground(ColorText)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		MarginTop(1)
+
+	var body []string
+	for _, line := range c.lines {
+		body = append(body, lineStyle.Render(truncateLeft(line, promptInputWidth)))
+	}
+
+	hint := c.hint
+	if hint == "" {
+		hint = "y confirm  n cancel"
+	}
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(c.title),
+		strings.Join(body, "\n"),
+		hintStyle.Render(hint),
+	)
+
+	return lipgloss.Place(c.width, c.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(content))
+}
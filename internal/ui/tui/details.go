@@ -0,0 +1,75 @@
+package tui
+
+import (
+	"os"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+// fileDetails is what the file details panel reads from disk about a file:
+// its type from its contents and its stat. It is kept until the selection
+// moves or the watcher sees the file change, so redrawing stays off the disk.
+type fileDetails struct {
+	node     *model.Node
+	size     int64
+	modTime  int64
+	fileType string
+	category model.Category
+	info     os.FileInfo // nil if the file could not be read
+}
+
+// of returns the details of node, reading them again if they were read for
+// another node or the node has changed since
+func (d *fileDetails) of(node *model.Node) *fileDetails {
+	if d.node == node && d.size == node.Size && d.modTime == node.ModTime {
+		return d
+	}
+	*d = fileDetails{node: node, size: node.Size, modTime: node.ModTime}
+	d.fileType, d.category = getFileType(node.Path())
+	if info, err := os.Stat(node.Path()); err == nil {
+		d.info = info
+	}
+	return d
+}
+
+// folderInfo is what the info bar walks a folder for: its file count less
+// OS metadata files, and how much of it sat untouched, along with its
+// creation time, which the scan does not record. It is kept until the
+// selection moves, the folder's totals change or a day passes, so redrawing
+// does not walk the subtree again.
+type folderInfo struct {
+	node    *model.Node
+	size    int64
+	deleted int64
+	count   int64
+	day     int64
+	files   int64 // Files below, less noise files if those are left uncounted
+	age     model.AgeUsage
+	created time.Time // Zero where the platform keeps no creation time
+}
+
+// of returns the info of node as of now, walking it again if it was walked
+// for another node, the node has changed since or it was another day. noise
+// reports the files to leave out of the count, nil to count them all.
+func (f *folderInfo) of(node *model.Node, now time.Time, noise func(*model.Node) bool) *folderInfo {
+	day := now.Unix() / int64(24*time.Hour/time.Second)
+	if f.node == node && f.size == node.Size && f.deleted == node.DeletedSize && f.count == node.FileCount() && f.day == day {
+		return f
+	}
+	*f = folderInfo{node: node, size: node.Size, deleted: node.DeletedSize, count: node.FileCount(), day: day}
+	f.files = node.FileCount()
+	if noise != nil {
+		f.files = 0
+		for n := range model.Walk(node, model.WalkOptions{}) {
+			if !n.IsDir && !noise(n) {
+				f.files++
+			}
+		}
+	}
+	f.age = node.AgeUsage(now)
+	if info, err := os.Stat(node.Path()); err == nil {
+		f.created = getCreationTime(info)
+	}
+	return f
+}
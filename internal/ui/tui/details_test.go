@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestFileDetailsReadOncePerSelection(t *testing.T) {
+	dir := t.TempDir()
+	root := &model.Node{Name: "root", IsDir: true}
+	root.SetPath(dir)
+	a := &model.Node{Name: "a.txt", Size: 5}
+	b := &model.Node{Name: "b.txt", Size: 5}
+	root.AddChild(a)
+	root.AddChild(b)
+	for _, n := range []*model.Node{a, b} {
+		if err := os.WriteFile(filepath.Join(dir, n.Name), []byte("hello"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var d fileDetails
+	if d.of(a).info == nil {
+		t.Fatal("expected the file to be read")
+	}
+
+	// Redrawing the same selection does not go back to the disk
+	os.Remove(filepath.Join(dir, "a.txt"))
+	if d.of(a).info == nil {
+		t.Error("expected the details to be kept while a stays selected")
+	}
+
+	// A new selection, or a change the watcher saw, reads them again
+	if got := d.of(b); got.node != b || got.info == nil {
+		t.Errorf("expected the details of b, got %+v", got)
+	}
+	if d.of(a).info != nil {
+		t.Error("expected a to be read again once selected again")
+	}
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello again"), 0644)
+	a.Size = 11
+	if d.of(a).info == nil {
+		t.Error("expected a changed file to be read again")
+	}
+}
+
+func TestFolderInfoWalkedOncePerChange(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	root := &model.Node{Name: "root", IsDir: true}
+	old := &model.Node{Name: "old.bin", Size: 100, ModTime: now.AddDate(-2, 0, 0).Unix()}
+	swap := &model.Node{Name: ".old.bin.swp", Size: 1, ModTime: now.Unix()}
+	root.AddChild(old)
+	root.AddChild(swap)
+	noise := func(n *model.Node) bool { return n == swap }
+
+	var f folderInfo
+	if got := f.of(root, now, noise); got.files != 1 || got.age.Stale[len(model.AgeThresholds)-1] != 100 {
+		t.Fatalf("expected 1 file with 100 bytes past a year, got %d and %+v", got.files, got.age)
+	}
+
+	// Redrawing the same folder does not walk it again
+	old.ModTime = now.Unix()
+	if f.of(root, now, noise).age.Stale[0] != 100 {
+		t.Error("expected the info to be kept while the folder is unchanged")
+	}
+
+	// A change to the folder's totals, or a new day, walks it again
+	root.AddChild(&model.Node{Name: "new.txt", Size: 5, ModTime: now.Unix()})
+	if got := f.of(root, now, noise); got.files != 2 || got.age.Stale[0] != 0 {
+		t.Errorf("expected the changed folder walked again, got %d files and %+v", got.files, got.age)
+	}
+	old.ModTime = now.AddDate(0, -2, 0).Unix()
+	if got := f.of(root, now.Add(24*time.Hour), nil); got.files != 3 || got.age.Stale[0] != 100 {
+		t.Errorf("expected the folder walked again the next day, got %d files and %+v", got.files, got.age)
+	}
+}
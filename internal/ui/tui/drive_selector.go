@@ -6,11 +6,13 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/stats"
 )
 
 // DriveSelector displays a list of available drives for selection
 type DriveSelector struct {
 	drives   []model.Drive
+	stats    map[string]stats.DriveStats // By drive path, for usage trends
 	selected int
 	visible  bool
 	width    int
@@ -34,6 +36,11 @@ func (d *DriveSelector) SetDrives(drives []model.Drive) {
 	}
 }
 
+// SetStats sets the statistics of the drives, by drive path
+func (d *DriveSelector) SetStats(s map[string]stats.DriveStats) {
+	d.stats = s
+}
+
 // SetSelected sets the currently highlighted drive
 func (d *DriveSelector) SetSelected(idx int) {
 	if idx >= 0 && idx < len(d.drives) {
@@ -118,6 +125,10 @@ func (d DriveSelector) View() string {
 		PaddingLeft(1).
 		PaddingRight(1)
 
+	detailStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		PaddingLeft(3)
+
 	hintStyle := lipgloss.NewStyle().
 		Foreground(ColorMuted).
 		MarginTop(1)
@@ -141,6 +152,17 @@ func (d DriveSelector) View() string {
 			content.WriteString(normalStyle.Render(line))
 		}
 		content.WriteString("\n")
+		details := driveDetails(drive)
+		if trend := formatTrend(d.stats[drive.Path], drive.FreeBytes); trend != "" {
+			if details != "" {
+				details += " · "
+			}
+			details += trend
+		}
+		if details != "" {
+			content.WriteString(detailStyle.Render(details))
+			content.WriteString("\n")
+		}
 	}
 
 	content.WriteString(hintStyle.Render("↑/↓ select  Enter confirm  Esc cancel"))
@@ -149,3 +171,24 @@ func (d DriveSelector) View() string {
 
 	return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, box)
 }
+
+// driveDetails describes the filesystem behind a drive, e.g.
+// "ext4 on /dev/sda2 · removable · read-only"
+func driveDetails(drive model.Drive) string {
+	var parts []string
+	switch {
+	case drive.FSType != "" && drive.Device != "":
+		parts = append(parts, drive.FSType+" on "+drive.Device)
+	case drive.FSType != "":
+		parts = append(parts, drive.FSType)
+	case drive.Device != "":
+		parts = append(parts, drive.Device)
+	}
+	if drive.Kind != model.DriveFixed {
+		parts = append(parts, drive.Kind.String())
+	}
+	if drive.ReadOnly {
+		parts = append(parts, "read-only")
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+// emptyHints suggests why a scan of root found no bytes, from what the scan
+// had to skip. Hidden files are always scanned, so they are ruled out
+// rather than suggested.
+func emptyHints(root *model.Node, skipped scanner.SkipStats) []string {
+	var hints []string
+	if skipped.Inaccessible > 0 {
+		hints = append(hints, fmt.Sprintf("%s folder(s) could not be read; run diskdive with more privileges to include them", FormatCount(skipped.Inaccessible)))
+	} else if len(root.Children) == 0 {
+		hints = append(hints, "If you expected files here, check that you can list the folder: its contents may need more privileges")
+	}
+	if skipped.Mounts > 0 {
+		hints = append(hints, fmt.Sprintf("%s mount point(s) on other filesystems were not scanned; scan them by their own path", FormatCount(skipped.Mounts)))
+	}
+	hints = append(hints, "Hidden files and folders are included, so nothing is hiding here")
+	hints = append(hints, "Press e to pick another drive or r to scan again")
+	return hints
+}
+
+// emptySummary describes what a scan that found no bytes did find
+func emptySummary(root *model.Node) string {
+	files := root.FileCount()
+	switch {
+	case len(root.Children) == 0:
+		return root.Name + " is empty"
+	case files == 0:
+		return root.Name + " holds only empty folders"
+	default:
+		return fmt.Sprintf("%s holds %s file(s), all of them empty", root.Name, FormatCount(files))
+	}
+}
+
+// renderEmptyState draws the panel shown in place of the treemap when the
+// scan found no bytes, filling width x height
+func renderEmptyState(root *model.Node, hints []string, width, height int) string {
+	boxStyle := TreemapPanelStyle.
+		Width(max(width-2, 1)).
+		Height(max(height-2, 1))
+	titleStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	hintStyle := dimStyle.Width(max(width-10, 10))
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Nothing to show"))
+	content.WriteString("\n\n")
+	content.WriteString(textStyle.Render(emptySummary(root)))
+	content.WriteString("\n")
+	for _, h := range hints {
+		content.WriteString("\n")
+		content.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, dimStyle.Render("• "), hintStyle.Render(h)))
+	}
+	// Hints past the bottom of a short panel are cut rather than pushing it taller
+	body := lipgloss.NewStyle().MaxHeight(max(height-2, 1)).Render(content.String())
+	return boxStyle.Render(body)
+}
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+func TestEmptySummary(t *testing.T) {
+	empty := &model.Node{Name: "empty", IsDir: true}
+
+	folders := &model.Node{Name: "folders", IsDir: true}
+	folders.AddChild(&model.Node{Name: "sub", IsDir: true})
+
+	zeros := &model.Node{Name: "zeros", IsDir: true}
+	zeros.AddChild(&model.Node{Name: "a"})
+	zeros.AddChild(&model.Node{Name: "b"})
+
+	for root, want := range map[*model.Node]string{
+		empty:   "empty is empty",
+		folders: "folders holds only empty folders",
+		zeros:   "zeros holds 2 file(s), all of them empty",
+	} {
+		root.ComputeSizes()
+		if got := emptySummary(root); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestEmptyHints(t *testing.T) {
+	root := &model.Node{Name: "locked", IsDir: true}
+
+	hints := strings.Join(emptyHints(root, scanner.SkipStats{Inaccessible: 3, Mounts: 1}), "\n")
+	for _, want := range []string{"3 folder(s) could not be read", "1 mount point(s)", "Hidden files"} {
+		if !strings.Contains(hints, want) {
+			t.Errorf("expected a hint with %q, got:\n%s", want, hints)
+		}
+	}
+
+	hints = strings.Join(emptyHints(root, scanner.SkipStats{}), "\n")
+	if !strings.Contains(hints, "check that you can list the folder") || strings.Contains(hints, "mount point") {
+		t.Errorf("unexpected hints for an empty folder:\n%s", hints)
+	}
+}
+
+func TestTreemapEmptyState(t *testing.T) {
+	root := &model.Node{Name: "zeros", IsDir: true}
+	root.AddChild(&model.Node{Name: "a"})
+	root.AddChild(&model.Node{Name: "sub", IsDir: true})
+	root.ComputeSizes()
+
+	panel := NewTreemapPanel()
+	panel.SetSize(60, 16)
+	panel.SetRoot(root)
+	panel.SetEmptyHints(emptyHints(root, scanner.SkipStats{}))
+
+	view := ansi.Strip(panel.View())
+	if !strings.Contains(view, "Nothing to show") || !strings.Contains(view, "Hidden files") {
+		t.Errorf("expected the empty state, got:\n%s", view)
+	}
+	lines := strings.Split(view, "\n")
+	if len(lines) != 16 {
+		t.Errorf("expected the empty state to fill 16 rows, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if w := ansi.StringWidth(line); w > 58 {
+			t.Errorf("line %d is %d wide, past the panel", i, w)
+		}
+	}
+
+	// A short panel cuts the hints instead of growing
+	panel.SetSize(60, 6)
+	if lines := strings.Split(ansi.Strip(panel.View()), "\n"); len(lines) != 6 {
+		t.Errorf("expected the empty state to keep to 6 rows, got %d", len(lines))
+	}
+
+	// Once something takes space the blocks are back
+	root.Children[0].Size = 100
+	root.ComputeSizes()
+	panel.SetRoot(root)
+	if view := ansi.Strip(panel.View()); strings.Contains(view, "Nothing to show") {
+		t.Errorf("expected blocks once the tree holds bytes, got:\n%s", view)
+	}
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const explainTopLimit = 5 // Largest children listed in the explain overlay
+
+// ExplainOverlay answers "why is this folder so big" on one screen: its
+// largest children, how much sits in files versus subfolders, and how much
+// appeared since the scan
+type ExplainOverlay struct {
+	node    *model.Node
+	explain model.Explanation
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// NewExplainOverlay creates a new explain overlay component
+func NewExplainOverlay() ExplainOverlay {
+	return ExplainOverlay{}
+}
+
+// Show breaks node's size down and displays it
+func (o *ExplainOverlay) Show(node *model.Node) {
+	o.node = node
+	o.explain = node.Explain(explainTopLimit)
+	o.cursor = 0
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *ExplainOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o ExplainOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *ExplainOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// MoveUp moves the cursor up
+func (o *ExplainOverlay) MoveUp() {
+	if o.cursor > 0 {
+		o.cursor--
+	}
+}
+
+// MoveDown moves the cursor down
+func (o *ExplainOverlay) MoveDown() {
+	if o.cursor < len(o.explain.Top)-1 {
+		o.cursor++
+	}
+}
+
+// Selected returns the child under the cursor
+func (o ExplainOverlay) Selected() *model.Node {
+	if o.cursor < 0 || o.cursor >= len(o.explain.Top) {
+		return nil
+	}
+	return o.explain.Top[o.cursor]
+}
+
+// View renders the explain overlay
+func (o ExplainOverlay) View() string {
+	if !o.visible || o.node == nil {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	sectionStyle := lipgloss.NewStyle().Foreground(ColorMuted).Bold(true)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(10).Align(lipgloss.Right)
+	shareStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(5).Align(lipgloss.Right)
+	nameStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dirStyle := lipgloss.NewStyle().Foreground(ColorDir)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	e := o.explain
+	row := func(size int64, label string, style lipgloss.Style) string {
+		return sizeStyle.Render(FormatSize(size)) +
+			shareStyle.Render(explainShare(size, e.Total)) + "  " +
+			style.Render(label) + "\n"
+	}
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(fmt.Sprintf("Why %s is %s", o.node.Name, FormatSize(e.Total))))
+	content.WriteString("\n")
+
+	content.WriteString(sectionStyle.Render("Largest items"))
+	content.WriteString("\n")
+	if len(e.Top) == 0 {
+		content.WriteString(dimStyle.Render("Nothing here"))
+		content.WriteString("\n")
+	}
+	for i, n := range e.Top {
+		style := nameStyle
+		name := n.Name
+		if n.IsDir {
+			style = dirStyle
+			name += "/"
+		}
+		if i == o.cursor {
+			style = selectedStyle
+		}
+		content.WriteString(row(n.TotalSize()-n.DeletedSize, truncateLeft(name, topFilesPathWidth), style))
+	}
+
+	content.WriteString("\n")
+	content.WriteString(sectionStyle.Render("Where it sits"))
+	content.WriteString("\n")
+	content.WriteString(row(e.FileBytes, "in files directly inside", nameStyle))
+	content.WriteString(row(e.DirBytes, "in subfolders", nameStyle))
+	content.WriteString(row(e.NewBytes, "new since the scan", nameStyle))
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Enter jumps to the item  Esc closes"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// explainShare formats part as a whole percentage of total
+func explainShare(part, total int64) string {
+	if total <= 0 {
+		return ""
+	}
+	return FormatShare(float64(part) / float64(total))
+}
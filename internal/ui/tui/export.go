@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// exportTimeFormat is the timestamp embedded in export filenames
+const exportTimeFormat = "2006-01-02_150405"
+
+// copyToClipboard puts text on the system clipboard (replaced in tests)
+var copyToClipboard = clipboard.WriteAll
+
+// exportDir returns the directory exported files are written to
+func exportDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".diskdive-exports"
+	}
+	return filepath.Join(home, ".diskdive", "exports")
+}
+
+// plainText strips colors and styling from rendered output and trims the
+// padding at the end of each line
+func plainText(view string) string {
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// exportText writes text to a timestamped file named after kind in dir and
+// copies it to the clipboard. The clipboard is best effort; copied reports
+// whether it worked.
+func exportText(dir, kind, text string, now time.Time) (path string, copied bool, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", false, fmt.Errorf("create export dir: %w", err)
+	}
+	path = filepath.Join(dir, fmt.Sprintf("%s_%s.txt", kind, now.Format(exportTimeFormat)))
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return "", false, err
+	}
+	copied = copyToClipboard(text) == nil
+	return path, copied, nil
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPlainText(t *testing.T) {
+	styled := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("big.iso") + "   \n" +
+		lipgloss.NewStyle().Bold(true).Render("1.0GB") + "\n\n"
+
+	if got := plainText(styled); got != "big.iso\n1.0GB\n" {
+		t.Errorf("unexpected plain text %q", got)
+	}
+}
+
+func TestExportText(t *testing.T) {
+	var clip string
+	copyToClipboard = func(text string) error {
+		clip = text
+		return nil
+	}
+	defer func() { copyToClipboard = clipboard.WriteAll }()
+
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.Local)
+
+	path, copied, err := exportText(dir, "treemap", "layout\n", now)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if !copied || clip != "layout\n" {
+		t.Errorf("expected text on the clipboard, got %q", clip)
+	}
+	if !strings.HasSuffix(path, "treemap_2024-05-06_070809.txt") {
+		t.Errorf("unexpected path %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "layout\n" {
+		t.Errorf("unexpected file content %q (%v)", data, err)
+	}
+}
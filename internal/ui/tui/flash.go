@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const flashTickInterval = 150 * time.Millisecond
+
+// flashColors fade from bright to dim; a new flash starts at the last entry
+var flashColors = []lipgloss.Color{
+	"#A16207",
+	"#CA8A04",
+	"#EAB308",
+	"#FACC15",
+	"#FDE047",
+	"#FEF08A",
+}
+
+// flashTickMsg advances the flash animation
+type flashTickMsg struct{}
+
+// Flasher tracks nodes whose size recently changed and fades their highlight
+type Flasher struct {
+	remaining map[*model.Node]int // ticks left per node
+}
+
+// NewFlasher creates an empty flasher
+func NewFlasher() *Flasher {
+	return &Flasher{remaining: make(map[*model.Node]int)}
+}
+
+// Flash highlights a node and its ancestors, whose sizes changed along with it
+func (f *Flasher) Flash(node *model.Node) {
+	for n := node; n != nil; n = n.Parent {
+		f.remaining[n] = len(flashColors)
+	}
+}
+
+// Active reports whether any highlight is still fading
+func (f *Flasher) Active() bool {
+	return len(f.remaining) > 0
+}
+
+// Tick advances the fade by one step, dropping finished highlights
+func (f *Flasher) Tick() {
+	for node, left := range f.remaining {
+		if left <= 1 {
+			delete(f.remaining, node)
+		} else {
+			f.remaining[node] = left - 1
+		}
+	}
+}
+
+// Clear removes all highlights
+func (f *Flasher) Clear() {
+	f.remaining = make(map[*model.Node]int)
+}
+
+// Color returns the current highlight color for a node, if it is flashing
+func (f *Flasher) Color(node *model.Node) (lipgloss.Color, bool) {
+	left, ok := f.remaining[node]
+	if !ok {
+		return "", false
+	}
+	return flashColors[left-1], true
+}
+
+// tickFlash schedules the next animation step
+func tickFlash() tea.Cmd {
+	return tea.Tick(flashTickInterval, func(t time.Time) tea.Msg {
+		return flashTickMsg{}
+	})
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestFlasherFades(t *testing.T) {
+	root := &model.Node{Name: "root", IsDir: true}
+	dir := &model.Node{Name: "dir", IsDir: true}
+	file := &model.Node{Name: "file", Size: 10}
+	root.AddChild(dir)
+	dir.AddChild(file)
+
+	f := NewFlasher()
+	f.Flash(file)
+
+	// The changed node and its ancestors start at full brightness
+	for _, n := range []*model.Node{file, dir, root} {
+		color, ok := f.Color(n)
+		if !ok || color != flashColors[len(flashColors)-1] {
+			t.Errorf("%s: expected brightest flash, got %q (ok=%v)", n.Name, color, ok)
+		}
+	}
+
+	f.Tick()
+	if color, _ := f.Color(file); color != flashColors[len(flashColors)-2] {
+		t.Errorf("expected flash to dim after a tick, got %q", color)
+	}
+
+	for i := 1; i < len(flashColors); i++ {
+		f.Tick()
+	}
+	if f.Active() {
+		t.Error("expected flash to finish")
+	}
+	if _, ok := f.Color(file); ok {
+		t.Error("expected no highlight after fade")
+	}
+}
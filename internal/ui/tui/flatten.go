@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const (
+	flattenDepth    = 2  // Levels below the folder that are ranked together by default
+	flattenMaxDepth = 8  // Deepest level that can be asked for
+	flattenLimit    = 20 // Entries listed in the overlay
+)
+
+// FlattenOverlay ranks everything a few levels below a folder in one list,
+// so hotspots like Users/alice/Library show without expanding each level
+type FlattenOverlay struct {
+	node    *model.Node
+	depth   int
+	entries []*model.Node
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// NewFlattenOverlay creates a new flatten overlay component
+func NewFlattenOverlay() FlattenOverlay {
+	return FlattenOverlay{depth: flattenDepth}
+}
+
+// Show flattens node and displays its largest entries
+func (o *FlattenOverlay) Show(node *model.Node) {
+	o.node = node
+	o.entries = node.Flatten(o.depth)
+	if len(o.entries) > flattenLimit {
+		o.entries = o.entries[:flattenLimit]
+	}
+	o.cursor = 0
+	o.visible = true
+}
+
+// Depth returns how many levels below the folder are ranked together
+func (o FlattenOverlay) Depth() int {
+	return o.depth
+}
+
+// SetDepth changes how many levels are ranked together and shows the
+// current folder again at that depth
+func (o *FlattenOverlay) SetDepth(depth int) {
+	o.depth = depth
+	if o.node != nil {
+		o.Show(o.node)
+	}
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *FlattenOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o FlattenOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *FlattenOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// MoveUp moves the cursor up
+func (o *FlattenOverlay) MoveUp() {
+	if o.cursor > 0 {
+		o.cursor--
+	}
+}
+
+// MoveDown moves the cursor down
+func (o *FlattenOverlay) MoveDown() {
+	if o.cursor < len(o.entries)-1 {
+		o.cursor++
+	}
+}
+
+// Selected returns the entry under the cursor
+func (o FlattenOverlay) Selected() *model.Node {
+	if o.cursor < 0 || o.cursor >= len(o.entries) {
+		return nil
+	}
+	return o.entries[o.cursor]
+}
+
+// View renders the flatten overlay
+func (o FlattenOverlay) View() string {
+	if !o.visible || o.node == nil {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(10).Align(lipgloss.Right)
+	shareStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(5).Align(lipgloss.Right)
+	pathStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dirStyle := lipgloss.NewStyle().Foreground(ColorDir)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(fmt.Sprintf("%s, %d levels deep", o.node.Name, o.depth)))
+	content.WriteString("\n")
+
+	if len(o.entries) == 0 {
+		content.WriteString(dimStyle.Render("Nothing here"))
+		content.WriteString("\n")
+	}
+	total := o.node.TotalSize()
+	for i, n := range o.entries {
+		style := pathStyle
+		if n.IsDir {
+			style = dirStyle
+		}
+		if i == o.cursor {
+			style = selectedStyle
+		}
+		rel, err := filepath.Rel(o.node.Path(), n.Path())
+		if err != nil {
+			rel = n.Path()
+		}
+		if n.IsDir {
+			rel += string(filepath.Separator)
+		}
+		share := ""
+		if total > 0 {
+			share = fmt.Sprintf("%d%%", n.TotalSize()*100/total)
+		}
+		content.WriteString(sizeStyle.Render(FormatSize(n.TotalSize())))
+		content.WriteString(shareStyle.Render(share))
+		content.WriteString("  ")
+		content.WriteString(style.Render(truncateLeft(rel, topFilesPathWidth)))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Enter jumps to the entry  d depth  Esc closes"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/stats"
+)
+
+const (
+	freedDays  = 14 // Days charted per day
+	freedWeeks = 12 // Weeks charted per week
+	freedBar   = 32 // Width of the longest bar
+	freedPaths = 14 // Deletions listed
+)
+
+// freedView is what the freed space chart shows
+type freedView int
+
+const (
+	freedPerDay freedView = iota
+	freedPerWeek
+	freedDeletions // The latest sizable deletions
+)
+
+// FreedChart shows how much space was recovered per day or per week, from
+// the freed events kept in the stats store, and what was deleted lately
+type FreedChart struct {
+	events    []stats.FreedEvent
+	deletions []stats.Deletion
+	freed     core.FreedState
+	now       time.Time
+	view      freedView
+	visible   bool
+	width     int
+	height    int
+}
+
+// NewFreedChart creates a new freed space chart component
+func NewFreedChart() FreedChart {
+	return FreedChart{}
+}
+
+// Show charts events, the space freed over the last year, and lists
+// deletions, oldest first, as of now, with the totals in freed
+func (f *FreedChart) Show(events []stats.FreedEvent, deletions []stats.Deletion, freed core.FreedState, now time.Time) {
+	f.events = events
+	f.deletions = deletions
+	f.freed = freed
+	f.now = now
+	f.visible = true
+}
+
+// NextView switches from space freed per day to per week to the latest
+// deletions, and back
+func (f *FreedChart) NextView() {
+	f.view = (f.view + 1) % (freedDeletions + 1)
+}
+
+// SetVisible sets the visibility of the chart
+func (f *FreedChart) SetVisible(visible bool) {
+	f.visible = visible
+}
+
+// IsVisible returns whether the chart is visible
+func (f FreedChart) IsVisible() bool {
+	return f.visible
+}
+
+// SetSize sets the dimensions for centering
+func (f *FreedChart) SetSize(w, h int) {
+	f.width = w
+	f.height = h
+}
+
+// View renders the freed space chart
+func (f FreedChart) View() string {
+	if !f.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	title, body := "Space freed per day", f.chart()
+	switch f.view {
+	case freedPerWeek:
+		title = "Space freed per week"
+	case freedDeletions:
+		title, body = "Recently freed", f.list()
+	}
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(body)
+	content.WriteString("\n")
+	total := "All time: " + FormatSize(f.freed.Lifetime)
+	if f.freed.DeletedLifetime > f.freed.Lifetime {
+		total += " · all deletions " + FormatSize(f.freed.DeletedLifetime)
+	}
+	content.WriteString(dimStyle.Render(total))
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render("Tab switches days, weeks and deletions · any other key closes"))
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("- takes space counted by mistake off · r resets"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(f.width, f.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// chart draws one bar per period, oldest first, scaled to the largest
+func (f FreedChart) chart() string {
+	days, n := 1, freedDays
+	if f.view == freedPerWeek {
+		days, n = 7, freedWeeks
+	}
+	sums := stats.FreedPerPeriod(f.events, f.now, days, n)
+	peak := slices.Max(sums)
+
+	labelStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(14) // Fits "Dec 30, 2024"
+	barStyle := lipgloss.NewStyle().Foreground(ColorShrunk)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorText).Width(10).Align(lipgloss.Right)
+
+	y, m, d := f.now.Date()
+	end := time.Date(y, m, d+1, 0, 0, 0, 0, f.now.Location())
+	var b strings.Builder
+	for i, sum := range sums {
+		start := end.AddDate(0, 0, -(n-i)*days)
+		label := appLocale.Date(start, start.Year() != f.now.Year())
+		if f.view == freedPerDay && i == n-1 {
+			label = "today"
+		}
+		cells := 0
+		if peak > 0 {
+			cells = int((sum*freedBar + peak - 1) / peak)
+		}
+		b.WriteString(labelStyle.Render(label))
+		b.WriteString(barStyle.Render(strings.Repeat("█", cells)))
+		b.WriteString(strings.Repeat(" ", freedBar-cells))
+		if sum > 0 {
+			b.WriteString(sizeStyle.Render(FormatSize(sum)))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// list shows the latest deletions, newest first, each with when it
+// happened and how much it freed
+func (f FreedChart) list() string {
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	if len(f.deletions) == 0 {
+		return dimStyle.Render("No deletions of 1MB or more seen yet.") + "\n"
+	}
+	// As wide as the chart: 14 + freedBar + 10
+	labelStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(20) // Fits "Dec 30, 2024 23:59"
+	pathStyle := lipgloss.NewStyle().Foreground(ColorText).Width(freedBar - 6)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorShrunk).Width(10).Align(lipgloss.Right)
+
+	var b strings.Builder
+	for i := len(f.deletions) - 1; i >= 0 && i >= len(f.deletions)-freedPaths; i-- {
+		d := f.deletions[i]
+		b.WriteString(labelStyle.Render(appLocale.DateTime(d.At, d.At.Year() != f.now.Year())))
+		b.WriteString(pathStyle.Render(truncateLeft(d.Path, freedBar-7)))
+		b.WriteString(sizeStyle.Render(FormatSize(d.Bytes)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/stats"
+)
+
+func TestFreedChartBars(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	f := NewFreedChart()
+	f.Show([]stats.FreedEvent{
+		{At: now.Add(-time.Hour), Bytes: 4 << 20},
+		{At: now.AddDate(0, 0, -1), Bytes: 1 << 20},
+	}, nil, core.FreedState{Lifetime: 5 << 20}, now)
+
+	lines := strings.Split(strings.TrimRight(f.chart(), "\n"), "\n")
+	if len(lines) != freedDays {
+		t.Fatalf("expected %d days, got %d lines", freedDays, len(lines))
+	}
+	today, yesterday := lines[len(lines)-1], lines[len(lines)-2]
+	if !strings.Contains(today, "today") || strings.Count(today, "█") != freedBar {
+		t.Errorf("expected today's bar at full width, got %q", today)
+	}
+	if got := strings.Count(yesterday, "█"); got != freedBar/4 {
+		t.Errorf("expected yesterday's bar a quarter as wide, got %d cells", got)
+	}
+	if strings.Contains(lines[0], "█") {
+		t.Errorf("expected no bar for a day without deletions, got %q", lines[0])
+	}
+
+	f.NextView()
+	lines = strings.Split(strings.TrimRight(f.chart(), "\n"), "\n")
+	if len(lines) != freedWeeks || strings.Count(lines[len(lines)-1], "█") != freedBar {
+		t.Errorf("expected %d weeks with both days in the last, got %q", freedWeeks, lines[len(lines)-1])
+	}
+}
+
+func TestFreedChartDeletions(t *testing.T) {
+	now := time.Date(2025, 3, 10, 9, 0, 0, 0, time.Local)
+	f := NewFreedChart()
+	f.Show(nil, []stats.Deletion{
+		{At: now.Add(-48 * time.Hour), Path: "/data/old.iso", Bytes: 2 << 20},
+		{At: now.Add(-time.Hour), Path: "/data/new.iso", Bytes: 3 << 20},
+	}, core.FreedState{Lifetime: 5 << 20}, now)
+	f.NextView()
+	f.NextView()
+
+	view := f.View()
+	if !strings.Contains(view, "Recently freed") {
+		t.Fatalf("expected the deletions after days and weeks, got:\n%s", view)
+	}
+	lines := strings.Split(strings.TrimRight(f.list(), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "/data/new.iso") || !strings.Contains(lines[0], "3.0MB") {
+		t.Errorf("expected the newest deletion first, got %q", lines)
+	}
+
+	f.NextView()
+	if f.view != freedPerDay {
+		t.Errorf("expected Tab to come back to days, got view %d", f.view)
+	}
+}
@@ -0,0 +1,288 @@
+package tui
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/cache"
+)
+
+const (
+	growthTop    = 5  // Folders charted on their own; the rest are summed up as other
+	growthHeight = 12 // Rows of the chart when the terminal is tall enough
+	growthAxis   = 9  // Width of the size labels left of the chart
+	sparkWidth   = 16 // Scans drawn by the sparkline in the info bar
+)
+
+// sparkBlocks are the cells a sparkline is drawn with, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// growthColors tell the charted folders apart, largest first
+var growthColors = [growthTop]lipgloss.Color{
+	lipgloss.Color("#C084FC"), // violet
+	lipgloss.Color("#00FFFF"), // cyan
+	lipgloss.Color("#FB923C"), // orange
+	lipgloss.Color("#A3E635"), // lime
+	lipgloss.Color("#F472B6"), // pink
+}
+
+// growthOtherColor is for the band summing up the rest
+const growthOtherColor = lipgloss.Color("#6B7280")
+
+// growthSeries is one band of the chart: a folder, or the rest
+type growthSeries struct {
+	name  string
+	color lipgloss.Color
+	bytes []int64 // One per scan, oldest first
+}
+
+// GrowthChart shows how the largest folders below a folder changed size
+// across saved scans, stacked, so which one grew or was cleaned up when
+// reads at a glance. It draws from the size history, so folders deleted
+// since still show.
+type GrowthChart struct {
+	name    string
+	times   []time.Time
+	series  []growthSeries // Largest first, then other if there is any
+	visible bool
+	width   int
+	height  int
+}
+
+// NewGrowthChart creates a new growth chart component
+func NewGrowthChart() GrowthChart {
+	return GrowthChart{}
+}
+
+// Show charts the folders below path from the size history h
+func (g *GrowthChart) Show(path, name string, h *cache.History) {
+	g.name = name
+	g.times = nil
+	g.series = nil
+	g.visible = true
+
+	total := h.Samples(path)
+	if len(total) == 0 {
+		return
+	}
+	for _, s := range total {
+		g.times = append(g.times, s.At)
+	}
+
+	// Rank folders by the most they ever held, so one emptied since still
+	// gets its own band
+	var folders []growthSeries
+	for _, child := range h.Children(path) {
+		samples := h.Samples(child)
+		f := growthSeries{name: filepath.Base(child)}
+		for _, s := range samples {
+			f.bytes = append(f.bytes, s.Bytes)
+		}
+		folders = append(folders, f)
+	}
+	slices.SortStableFunc(folders, func(a, b growthSeries) int {
+		return cmp.Compare(slices.Max(b.bytes), slices.Max(a.bytes))
+	})
+	if len(folders) > growthTop {
+		folders = folders[:growthTop]
+	}
+	for i := range folders {
+		folders[i].color = growthColors[i]
+	}
+
+	// Files directly in the folder and smaller folders, if they ever added up
+	other := growthSeries{name: "other", color: growthOtherColor, bytes: make([]int64, len(total))}
+	for i, s := range total {
+		other.bytes[i] = s.Bytes
+		for _, f := range folders {
+			other.bytes[i] -= f.bytes[i]
+		}
+		other.bytes[i] = max(other.bytes[i], 0)
+	}
+	g.series = folders
+	if slices.Max(other.bytes) > 0 {
+		g.series = append(g.series, other)
+	}
+}
+
+// SetVisible sets the visibility of the chart
+func (g *GrowthChart) SetVisible(visible bool) {
+	g.visible = visible
+}
+
+// IsVisible returns whether the chart is visible
+func (g GrowthChart) IsVisible() bool {
+	return g.visible
+}
+
+// SetSize sets the dimensions for centering
+func (g *GrowthChart) SetSize(w, h int) {
+	g.width = w
+	g.height = h
+}
+
+// View renders the growth chart
+func (g GrowthChart) View() string {
+	if !g.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Growth of " + g.name))
+	content.WriteString("\n")
+	if len(g.times) == 0 {
+		content.WriteString(dimStyle.Render("No size history for this folder yet. Each scan adds to it;\nfolders under 1MB are not followed."))
+	} else {
+		// The frame, axis and hints take 12 rows, the legend one per band
+		content.WriteString(g.chart(max(g.width-12-growthAxis, 10), min(max(g.height-12-len(g.series), 3), growthHeight)))
+	}
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(g.width, g.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// chart draws the stacked bars, at most width cells wide and height rows
+// high, with the newest scans kept when not all of them fit, then the legend
+func (g GrowthChart) chart(width, height int) string {
+	// Wide bars while they fit, one cell per scan otherwise
+	bar, gap := 2, 1
+	if len(g.times)*(bar+gap) > width {
+		bar, gap = 1, 0
+	}
+	cols := min(len(g.times), width/(bar+gap))
+	first := len(g.times) - cols
+
+	var peak int64
+	for i := first; i < len(g.times); i++ {
+		var sum int64
+		for _, s := range g.series {
+			sum += s.bytes[i]
+		}
+		peak = max(peak, sum)
+	}
+
+	// tops[c][k] is the row below which band k of column c ends
+	tops := make([][]int, cols)
+	for c := range cols {
+		var sum int64
+		for _, s := range g.series {
+			sum += s.bytes[first+c]
+			top := 0
+			if peak > 0 {
+				top = int((sum*int64(height) + peak/2) / peak)
+			}
+			tops[c] = append(tops[c], top)
+		}
+	}
+
+	axisStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	labelStyle := axisStyle.Width(growthAxis - 1).Align(lipgloss.Right)
+	var b strings.Builder
+	for row := height - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case height - 1:
+			label = FormatSize(peak)
+		case 0:
+			label = "0"
+		}
+		b.WriteString(labelStyle.Render(label))
+		b.WriteString(axisStyle.Render("│"))
+		for c := range cols {
+			cell := strings.Repeat(" ", bar)
+			for k, top := range tops[c] {
+				if row < top {
+					cell = lipgloss.NewStyle().Foreground(g.series[k].color).Render(strings.Repeat("█", bar))
+					break
+				}
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", gap))
+		}
+		b.WriteString("\n")
+	}
+	span := cols*(bar+gap) - gap
+	b.WriteString(strings.Repeat(" ", growthAxis-1))
+	b.WriteString(axisStyle.Render("└" + strings.Repeat("─", max(span, 1))))
+	b.WriteString("\n")
+	from, to := FormatTime(g.times[first]), FormatTime(g.times[len(g.times)-1])
+	dates := from
+	if cols > 1 {
+		dates += strings.Repeat(" ", max(span-len(from)-len(to), 1)) + to
+	}
+	b.WriteString(strings.Repeat(" ", growthAxis))
+	b.WriteString(axisStyle.Render(dates))
+	b.WriteString("\n\n")
+
+	// Legend, with how much each band changed over the scans shown
+	nameStyle := lipgloss.NewStyle().Foreground(ColorText).Width(20)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(10).Align(lipgloss.Right)
+	for k, s := range g.series {
+		last := s.bytes[len(s.bytes)-1]
+		change := last - s.bytes[first]
+		b.WriteString(lipgloss.NewStyle().Foreground(s.color).Render("██ "))
+		b.WriteString(nameStyle.Render(truncateLeft(s.name, 19)))
+		b.WriteString(sizeStyle.Render(FormatSize(last)))
+		switch {
+		case change > 0:
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorGrew).Render("  +" + FormatSize(change)))
+		case change < 0:
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorShrunk).Render("  -" + FormatSize(-change)))
+		}
+		if k < len(g.series)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// sparkline draws the size of a folder across the newest width scans, one
+// cell each, scaled between the smallest and largest size among them, and
+// adds how much it changed over those scans. Fewer than two scans draw
+// nothing.
+func sparkline(samples []cache.Sample, width int) string {
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	if len(samples) < 2 {
+		return ""
+	}
+	lo, hi := samples[0].Bytes, samples[0].Bytes
+	for _, s := range samples {
+		lo, hi = min(lo, s.Bytes), max(hi, s.Bytes)
+	}
+
+	var cells strings.Builder
+	for _, s := range samples {
+		level := 0
+		if hi > lo {
+			level = int((s.Bytes - lo) * int64(len(sparkBlocks)-1) / (hi - lo))
+		}
+		cells.WriteRune(sparkBlocks[level])
+	}
+	line := lipgloss.NewStyle().Foreground(ColorDir).Render(cells.String())
+
+	switch change := samples[len(samples)-1].Bytes - samples[0].Bytes; {
+	case change > 0:
+		line += lipgloss.NewStyle().Foreground(ColorGrew).Render(" +" + FormatSize(change))
+	case change < 0:
+		line += lipgloss.NewStyle().Foreground(ColorShrunk).Render(" -" + FormatSize(-change))
+	}
+	return line
+}
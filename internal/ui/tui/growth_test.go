@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/clock"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestGrowthChart(t *testing.T) {
+	c := cache.New(t.TempDir())
+	clk := clock.NewFake(time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local))
+	c.SetClock(clk)
+
+	base := filepath.Join(string(filepath.Separator), "home")
+	save := func(sizes map[string]int64) {
+		t.Helper()
+		root := &model.Node{Name: "home", IsDir: true}
+		root.SetPath(base)
+		for name, size := range sizes {
+			dir := &model.Node{Name: name, IsDir: true}
+			dir.AddChild(&model.Node{Name: "f", Size: size})
+			root.AddChild(dir)
+		}
+		root.ComputeSizes()
+		if err := c.Save(base, root); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		clk.Advance(24 * time.Hour)
+	}
+
+	// Downloads grew, was cleaned up, then VMs grew
+	save(map[string]int64{"Downloads": 2 << 20, "Photos": 3 << 20})
+	save(map[string]int64{"Downloads": 9 << 20, "Photos": 3 << 20})
+	save(map[string]int64{"Photos": 3 << 20, "VMs": 12 << 20})
+
+	h, err := c.History(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGrowthChart()
+	g.SetSize(100, 40)
+	g.Show(base, "home", h)
+
+	var names []string
+	for _, s := range g.series {
+		names = append(names, s.name)
+	}
+	if strings.Join(names, ",") != "VMs,Downloads,Photos" {
+		t.Errorf("expected folders by peak size with no other band, got %v", names)
+	}
+	if d := g.series[1].bytes; d[1] != 9<<20 || d[2] != 0 {
+		t.Errorf("expected Downloads at 9MB then gone, got %v", d)
+	}
+
+	view := g.View()
+	for _, want := range []string{"Growth of home", "VMs", "+12.0MB", "Downloads"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the chart:\n%s", want, view)
+		}
+	}
+
+	// A folder the history does not follow has nothing to chart
+	g.Show(filepath.Join(base, "Photos", "f"), "f", h)
+	if !strings.Contains(g.View(), "No size history") {
+		t.Error("expected the empty message for a path without history")
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	samples := func(sizes ...int64) []cache.Sample {
+		var s []cache.Sample
+		for _, size := range sizes {
+			s = append(s, cache.Sample{Bytes: size})
+		}
+		return s
+	}
+
+	if got := sparkline(samples(5<<20), sparkWidth); got != "" {
+		t.Errorf("expected nothing for a single scan, got %q", got)
+	}
+	if got := sparkline(samples(2<<20, 9<<20, 2<<20, 16<<20), sparkWidth); got != "▁▄▁█ +14.0MB" {
+		t.Errorf("expected scaled cells and growth, got %q", got)
+	}
+	if got := sparkline(samples(3<<20, 3<<20), sparkWidth); got != "▁▁" {
+		t.Errorf("expected a flat line without change, got %q", got)
+	}
+
+	// Only the newest scans are drawn, and the change is over those
+	got := sparkline(samples(100<<20, 8<<20, 4<<20, 6<<20), 3)
+	if got != "█▁▄ -2.0MB" {
+		t.Errorf("expected the newest three scans, got %q", got)
+	}
+}
@@ -3,10 +3,13 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
 	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/stats"
 )
 
 const headerProgressBarWidth = 20 // Width of disk usage progress bar
@@ -19,8 +22,13 @@ type Header struct {
 	scanning     bool
 	scanProgress string
 	freedSession int64
-	freedTotal   int64
+	freedDrive   int64
+	driveStats   stats.DriveStats // Of the drive being viewed
 	version      string
+	reusedFrom   time.Time // When the snapshot on show was taken, zero after a scan
+
+	// Scans being compared, zero when showing a single scan
+	comparison core.Comparison
 }
 
 // NewHeader creates a new header component
@@ -64,10 +72,16 @@ func (h *Header) SetScanning(scanning bool, progress string) {
 	h.scanProgress = progress
 }
 
-// SetFreedStats sets the freed space statistics
-func (h *Header) SetFreedStats(session, total int64) {
+// SetFreedStats sets the space freed this session and all time on the
+// drive being viewed
+func (h *Header) SetFreedStats(session, drive int64) {
 	h.freedSession = session
-	h.freedTotal = total
+	h.freedDrive = drive
+}
+
+// SetDriveStats sets the scan statistics of the drive being viewed
+func (h *Header) SetDriveStats(s stats.DriveStats) {
+	h.driveStats = s
 }
 
 // UpdateDiskFree updates the free disk space for the selected drive
@@ -77,6 +91,18 @@ func (h *Header) UpdateDiskFree(freeBytes int64) {
 	}
 }
 
+// SetReusedFrom marks the tree as loaded from a snapshot taken at t;
+// a zero time clears the mark
+func (h *Header) SetReusedFrom(t time.Time) {
+	h.reusedFrom = t
+}
+
+// SetComparison marks the tree as the differences between two scans; a
+// zero comparison clears the mark
+func (h *Header) SetComparison(comparison core.Comparison) {
+	h.comparison = comparison
+}
+
 // ScanProgress returns the current scan progress text
 func (h Header) ScanProgress() string {
 	return h.scanProgress
@@ -92,9 +118,23 @@ func (h Header) Update(msg tea.Msg) (Header, tea.Cmd) {
 	return h, nil
 }
 
+// FormatAge renders how long ago something happened, in its largest unit
+func FormatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 // View renders the header (2 lines + separator)
 // Line 1: DiskDive 0.1.4                     Used: X / Y [bar] XX%
-// Line 2: Drive: Name [space]               Freed: X session | Y total
+// Line 2: Drive: Name [space]      Scan #N · previous Xd ago  Recovered: X session | Y this drive
 // Line 3: ─────────────────────────────────────────────────────────
 func (h Header) View() string {
 	// Styles
@@ -140,6 +180,16 @@ func (h Header) View() string {
 		}
 	}
 
+	// Where usage is heading, when there is room for it
+	if drive := h.Selected(); drive != nil {
+		if trend := formatTrend(h.driveStats, drive.FreeBytes); trend != "" {
+			trend = dimStyle.Render(trend + "  ")
+			if h.width-lipgloss.Width(appName)-lipgloss.Width(trend+freeStats) >= 4 {
+				freeStats = trend + freeStats
+			}
+		}
+	}
+
 	// Build line 1
 	line1Left := appName
 	line1Right := freeStats
@@ -151,12 +201,27 @@ func (h Header) View() string {
 
 	// === LINE 2: Drive info (left) | Freed stats (right) ===
 	var freedStats string
-	if h.freedSession > 0 || h.freedTotal > 0 {
+	if h.freedSession > 0 || h.freedDrive > 0 {
 		freedLabel := labelStyle.Render("Recovered: ")
 		freedSession := lipgloss.NewStyle().Foreground(lipgloss.Color("#34D399")).Render(FormatSize(h.freedSession) + " session")
 		freedSep := dimStyle.Render(" | ")
-		freedTotal := dimStyle.Render(FormatSize(h.freedTotal) + " total")
-		freedStats = freedLabel + freedSession + freedSep + freedTotal
+		freedDrive := dimStyle.Render(FormatSize(h.freedDrive) + " this drive")
+		freedStats = freedLabel + freedSession + freedSep + freedDrive
+	}
+
+	// How often this drive was scanned, when there is room for it
+	if s := h.driveStats; s.Scans > 0 {
+		scanStats := "scan #" + appLocale.Count(int64(s.Scans))
+		if !s.PrevScan.IsZero() {
+			scanStats += " · previous " + FormatAge(time.Since(s.PrevScan)) + " ago"
+		}
+		scanStats = dimStyle.Render(scanStats)
+		if freedStats != "" {
+			scanStats += dimStyle.Render("  ")
+		}
+		if h.width-lipgloss.Width(scanStats+freedStats) >= 40 {
+			freedStats = scanStats + freedStats
+		}
 	}
 
 	var driveName string
@@ -177,6 +242,27 @@ func (h Header) View() string {
 		}
 	}
 
+	// Loaded from a drive snapshot, so it may be out of date
+	if !h.reusedFrom.IsZero() {
+		age := lipgloss.NewStyle().Foreground(ColorMarked).Render("snapshot " + FormatAge(time.Since(h.reusedFrom)) + " old")
+		if driveName != "" {
+			driveName += dimStyle.Render(" · ")
+		}
+		driveName += age + dimStyle.Render("  ") + KeyHint.Render("r") + dimStyle.Render(" rescan")
+	}
+
+	// Showing the differences between two scans
+	if c := h.comparison; !c.From.IsZero() {
+		to := "now"
+		if !c.To.IsZero() {
+			to = scanName(c.To, c.ToLabel)
+		}
+		if driveName != "" {
+			driveName += dimStyle.Render(" · ")
+		}
+		driveName += GrewStyle.Render("changes "+scanName(c.From, c.FromLabel)+" → "+to) + dimStyle.Render("  ") + KeyHint.Render("r") + dimStyle.Render(" back to live")
+	}
+
 	// Build line 2
 	line2Left := driveName
 	line2Right := freedStats
@@ -90,6 +90,9 @@ func (h HelpOverlay) View() string {
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "PgUp/PgDn", "Scroll faster", true))
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "g / G", "Top / Bottom", true))
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "Tab", "Switch panel", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "z", "Maximize panel", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "c", "Filter by category", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, ".", "Show/hide hidden items", true))
 
 	// Actions section
 	content.WriteString(sectionStyle.Render("Actions"))
@@ -98,8 +101,40 @@ func (h HelpOverlay) View() string {
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "e", "Change drive", true))
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "o", "Open in Finder", true))
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "r", "Rescan", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "R", "Rescan selected folder", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "O", "Open selected folder as root", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "S", "Save snapshot of selected folder", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "D", "Compare two saved scans", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "H", "Growth of subfolders across scans", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "f", "Space freed per day or week, recent deletions", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "P", "Prune deleted items", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "i", "Explain missing space", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "L", "Slowest folders to scan", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "w", "Watcher activity", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "u", "Usage by owner", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "t", "Largest files", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "F", "Largest few levels down", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "x", "Why is this folder so big", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "B", "Set node budget for scans", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "y", "Copy treemap as text", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "!", "Quick actions", true))
 	content.WriteString(formatHelpLine(keyStyle, descStyle, "q", "Quit", true))
 
+	// File management section
+	content.WriteString(sectionStyle.Render("File management"))
+	content.WriteString("\n")
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "m", "Mark / unmark item", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "v", "Mark a range (v again marks it)", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "U", "Unmark all", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "X", "Export the marked list", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "W", "What if marked were deleted", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "M", "Move marked items", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "d/Del", "Move marked items to the trash", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "Z", "Compress folder to an archive", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "n", "New folder", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "Q a-z", "Record macro (Q stops)", true))
+	content.WriteString(formatHelpLine(keyStyle, descStyle, "@ a-z", "Replay macro (@@ repeats)", true))
+
 	// Footer
 	content.WriteString("\n")
 	content.WriteString(dimStyle.Render("Press any key to close"))
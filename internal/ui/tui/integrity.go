@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+const (
+	integrityWidth      = 64 // Width of the wrapped detail text
+	integrityLabelWidth = 28 // Width of the label column
+	integrityMaxPaths   = 5  // Inaccessible folders listed in the overlay
+)
+
+// IntegrityOverlay explains the difference between the scan and the drive's used space
+type IntegrityOverlay struct {
+	report  core.IntegrityReport
+	visible bool
+	width   int
+	height  int
+}
+
+// NewIntegrityOverlay creates a new integrity overlay component
+func NewIntegrityOverlay() IntegrityOverlay {
+	return IntegrityOverlay{}
+}
+
+// Show displays the overlay with a fresh report
+func (o *IntegrityOverlay) Show(report core.IntegrityReport) {
+	o.report = report
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *IntegrityOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o IntegrityOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *IntegrityOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// View renders the integrity overlay
+func (o IntegrityOverlay) View() string {
+	if !o.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	sectionStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		MarginTop(1)
+
+	labelStyle := lipgloss.NewStyle().Foreground(ColorText).Width(integrityLabelWidth)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true)
+	detailStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(integrityWidth).PaddingLeft(2)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	r := o.report
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Scan integrity"))
+	content.WriteString("\n")
+
+	row := func(label, value string) {
+		content.WriteString(labelStyle.Render(label) + valueStyle.Render(value) + "\n")
+	}
+
+	row("Found by scan", FormatSize(r.Scanned))
+	if !r.Applicable {
+		content.WriteString(detailStyle.Render("Scan a whole drive to compare against the filesystem's used space."))
+		content.WriteString("\n")
+	} else {
+		row("Used according to drive", FormatSize(r.Used))
+		missing := r.Missing()
+		if missing >= 0 {
+			row("Not found by scan", fmt.Sprintf("%s (%.1f%%)", FormatSize(missing), percentOf(missing, r.Used)))
+		} else {
+			row("Found beyond used space", FormatSize(-missing))
+			content.WriteString(detailStyle.Render("Hard links, compression or clones can make files appear larger than the space they use."))
+			content.WriteString("\n")
+		}
+
+		if len(r.Sources) > 0 {
+			content.WriteString(sectionStyle.Render("Where the difference is"))
+			content.WriteString("\n")
+			for _, s := range r.Sources {
+				value := "unknown size"
+				if s.Bytes > 0 {
+					value = FormatSize(s.Bytes)
+				}
+				row(s.Label, value)
+				content.WriteString(detailStyle.Render(s.Detail))
+				content.WriteString("\n")
+			}
+		}
+	}
+
+	if paths := r.Skipped.InaccessiblePaths; len(paths) > 0 {
+		content.WriteString(sectionStyle.Render("Unreadable folders"))
+		content.WriteString("\n")
+		for i, p := range paths {
+			if i == integrityMaxPaths {
+				content.WriteString(dimStyle.Render(fmt.Sprintf("  … and %d more", r.Skipped.Inaccessible-int64(i))))
+				content.WriteString("\n")
+				break
+			}
+			content.WriteString(dimStyle.Render("  " + truncateLeft(p, integrityWidth-2)))
+			content.WriteString("\n")
+		}
+	}
+
+	if r.Skipped.Mounts > 0 {
+		content.WriteString(dimStyle.Render(fmt.Sprintf("%d mount point(s) on other filesystems were not scanned", r.Skipped.Mounts)))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// percentOf returns part as a percentage of whole
+func percentOf(part, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) / float64(whole) * 100
+}
@@ -0,0 +1,9 @@
+//go:build darwin
+
+package tui
+
+// interferenceHint explains a scan slowed down by something inspecting files
+func interferenceHint(root, current string) string {
+	return "Scanning slowed while the CPU is idle. Spotlight or antivirus software is likely reading files in " +
+		current + ". Adding " + root + " to Spotlight's Privacy list or your antivirus exclusions speeds up the scan."
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !darwin
+
+package tui
+
+// interferenceHint returns no hint; other platforms have no common real-time scanner to name
+func interferenceHint(root, current string) string {
+	return ""
+}
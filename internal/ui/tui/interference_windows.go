@@ -0,0 +1,9 @@
+//go:build windows
+
+package tui
+
+// interferenceHint explains a scan slowed down by something inspecting files
+func interferenceHint(root, current string) string {
+	return "Scanning slowed while the CPU is idle. Microsoft Defender is likely checking each file in " +
+		current + ". Adding " + root + " to Defender's exclusions (or pausing real-time protection) speeds up the scan."
+}
@@ -4,23 +4,53 @@ import "github.com/charmbracelet/bubbles/key"
 
 // KeyMap defines all keyboard shortcuts
 type KeyMap struct {
-	Up           key.Binding
-	Down         key.Binding
-	Left         key.Binding
-	Right        key.Binding
-	PageUp       key.Binding
-	PageDown     key.Binding
-	Top          key.Binding
-	Bottom       key.Binding
-	Tab          key.Binding
-	Enter        key.Binding
-	Back         key.Binding
-	Rescan       key.Binding
-	Help         key.Binding
-	Quit         key.Binding
-	SelectDrive  key.Binding
-	OpenExplorer key.Binding
-	Preview      key.Binding
+	Up            key.Binding
+	Down          key.Binding
+	Left          key.Binding
+	Right         key.Binding
+	PageUp        key.Binding
+	PageDown      key.Binding
+	Top           key.Binding
+	Bottom        key.Binding
+	Tab           key.Binding
+	Enter         key.Binding
+	Back          key.Binding
+	Rescan        key.Binding
+	RescanDir     key.Binding
+	Help          key.Binding
+	Quit          key.Binding
+	SelectDrive   key.Binding
+	OpenExplorer  key.Binding
+	Preview       key.Binding
+	Mark          key.Binding
+	Visual        key.Binding
+	UnmarkAll     key.Binding
+	ExportMarked  key.Binding
+	Move          key.Binding
+	Trash         key.Binding
+	Compress      key.Binding
+	NewFolder     key.Binding
+	Integrity     key.Binding
+	Owners        key.Binding
+	QuickActions  key.Binding
+	TopFiles      key.Binding
+	ExportTreemap key.Binding
+	Maximize      key.Binding
+	Prune         key.Binding
+	Category      key.Binding
+	Hidden        key.Binding
+	Sort          key.Binding
+	SlowPaths     key.Binding
+	WatchStats    key.Binding
+	WhatIf        key.Binding
+	Flatten       key.Binding
+	Explain       key.Binding
+	NodeBudget    key.Binding
+	Reroot        key.Binding
+	SaveSubtree   key.Binding
+	Compare       key.Binding
+	Growth        key.Binding
+	Freed         key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -74,6 +104,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "rescan"),
 		),
+		RescanDir: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "rescan folder"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -94,6 +128,122 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys(" "),
 			key.WithHelp("Space", "preview"),
 		),
+		Mark: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "mark"),
+		),
+		Visual: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "mark range"),
+		),
+		UnmarkAll: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "unmark all"),
+		),
+		ExportMarked: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "export marked"),
+		),
+		Move: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "move marked"),
+		),
+		Trash: key.NewBinding(
+			key.WithKeys("d", "delete"),
+			key.WithHelp("d/del", "move to trash"),
+		),
+		Compress: key.NewBinding(
+			key.WithKeys("Z"),
+			key.WithHelp("Z", "compress folder"),
+		),
+		NewFolder: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new folder"),
+		),
+		Integrity: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "scan integrity"),
+		),
+		Owners: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "usage by owner"),
+		),
+		QuickActions: key.NewBinding(
+			key.WithKeys("!"),
+			key.WithHelp("!", "quick actions"),
+		),
+		TopFiles: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "largest files"),
+		),
+		ExportTreemap: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy treemap as text"),
+		),
+		Maximize: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "maximize panel"),
+		),
+		Prune: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "prune deleted items"),
+		),
+		Category: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "filter by category"),
+		),
+		Hidden: key.NewBinding(
+			key.WithKeys("."),
+			key.WithHelp(".", "show/hide hidden items"),
+		),
+		SlowPaths: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "slowest folders"),
+		),
+		WatchStats: key.NewBinding(
+			key.WithKeys("w"),
+			key.WithHelp("w", "watcher activity"),
+		),
+		WhatIf: key.NewBinding(
+			key.WithKeys("W"),
+			key.WithHelp("W", "what-if deletion"),
+		),
+		Flatten: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "flatten levels"),
+		),
+		Explain: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "explain folder size"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort by size/files/age"),
+		),
+		NodeBudget: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "node budget"),
+		),
+		Reroot: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "open as root"),
+		),
+		SaveSubtree: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "save folder snapshot"),
+		),
+		Compare: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "compare scans"),
+		),
+		Growth: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "growth chart"),
+		),
+		Freed: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "space freed over time"),
+		),
 	}
 }
 
@@ -106,9 +256,10 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.Top, k.Bottom, k.Tab},
-		{k.Enter, k.Back},
-		{k.Rescan},
+		{k.Top, k.Bottom, k.Tab, k.Maximize},
+		{k.Enter, k.Back, k.Category, k.Hidden, k.Sort},
+		{k.Rescan, k.RescanDir, k.Prune, k.Integrity, k.SlowPaths, k.WatchStats, k.Owners, k.TopFiles, k.Flatten, k.Explain, k.NodeBudget, k.Reroot, k.SaveSubtree, k.Compare, k.Growth, k.Freed},
+		{k.Mark, k.Visual, k.UnmarkAll, k.ExportMarked, k.WhatIf, k.Move, k.Trash, k.Compress, k.NewFolder, k.QuickActions, k.ExportTreemap},
 		{k.Help, k.Quit},
 	}
 }
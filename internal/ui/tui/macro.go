@@ -0,0 +1,128 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+const (
+	macroRecordKey = "Q" // Q{a-z} starts recording into a register, Q stops
+	macroReplayKey = "@" // @{a-z} replays a register, @@ the last one replayed
+	maxMacroKeys   = 200 // Guards against forgetting to stop recording
+)
+
+// macroWait is what the recorder expects from the next key
+type macroWait int
+
+const (
+	macroWaitNone   macroWait = iota
+	macroWaitRecord           // register name to record into
+	macroWaitReplay           // register name to replay
+)
+
+// MacroRecorder records key sequences into named registers and replays them
+type MacroRecorder struct {
+	registers map[rune][]tea.KeyMsg
+	recording rune // register being recorded, 0 if none
+	wait      macroWait
+	last      rune // last register replayed, for @@
+	replaying bool
+}
+
+// NewMacroRecorder creates a recorder with empty registers
+func NewMacroRecorder() *MacroRecorder {
+	return &MacroRecorder{registers: make(map[rune][]tea.KeyMsg)}
+}
+
+// Recording returns the register being recorded, or 0
+func (m *MacroRecorder) Recording() rune {
+	return m.recording
+}
+
+// macroResult tells the app what to do with a key after the recorder saw it
+type macroResult struct {
+	consumed bool         // the key was a macro command and must not be handled further
+	started  rune         // recording started into this register
+	stopped  rune         // recording into this register finished
+	replay   []tea.KeyMsg // keys to replay
+	invalid  string       // unknown register or empty macro, for a status message
+}
+
+// HandleKey processes a key press. Macro commands are consumed; other keys
+// are appended to the register being recorded.
+func (m *MacroRecorder) HandleKey(msg tea.KeyMsg) macroResult {
+	if m.replaying {
+		return macroResult{}
+	}
+
+	switch m.wait {
+	case macroWaitRecord:
+		m.wait = macroWaitNone
+		reg, ok := registerName(msg)
+		if !ok {
+			return macroResult{consumed: true, invalid: "Registers are a-z"}
+		}
+		m.recording = reg
+		m.registers[reg] = nil
+		return macroResult{consumed: true, started: reg}
+
+	case macroWaitReplay:
+		m.wait = macroWaitNone
+		reg := m.last
+		if msg.String() != macroReplayKey {
+			var ok bool
+			if reg, ok = registerName(msg); !ok {
+				return macroResult{consumed: true, invalid: "Registers are a-z"}
+			}
+		}
+		keys := m.registers[reg]
+		if len(keys) == 0 {
+			return macroResult{consumed: true, invalid: "Nothing recorded in @" + string(reg)}
+		}
+		m.last = reg
+		return macroResult{consumed: true, replay: keys}
+	}
+
+	switch msg.String() {
+	case macroRecordKey:
+		if m.recording != 0 {
+			reg := m.recording
+			m.recording = 0
+			return macroResult{consumed: true, stopped: reg}
+		}
+		m.wait = macroWaitRecord
+		return macroResult{consumed: true}
+
+	case macroReplayKey:
+		if m.recording == 0 {
+			m.wait = macroWaitReplay
+			return macroResult{consumed: true}
+		}
+	}
+
+	m.Record(msg)
+	return macroResult{}
+}
+
+// Record appends a key to the register being recorded, if any, without
+// taking it as a macro command
+func (m *MacroRecorder) Record(msg tea.KeyMsg) {
+	if m.replaying || m.recording == 0 || len(m.registers[m.recording]) >= maxMacroKeys {
+		return
+	}
+	m.registers[m.recording] = append(m.registers[m.recording], msg)
+}
+
+// SetReplaying marks a replay in progress so replayed keys are not recorded again
+func (m *MacroRecorder) SetReplaying(replaying bool) {
+	m.replaying = replaying
+}
+
+// registerName returns the register a key names, if it is a lowercase letter
+func registerName(msg tea.KeyMsg) (rune, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return 0, false
+	}
+	r := msg.Runes[0]
+	if r < 'a' || r > 'z' {
+		return 0, false
+	}
+	return r, true
+}
@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func runeKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func TestMacroRecordAndReplay(t *testing.T) {
+	m := NewMacroRecorder()
+
+	if res := m.HandleKey(runeKey('Q')); !res.consumed {
+		t.Fatal("Q should be consumed")
+	}
+	if res := m.HandleKey(runeKey('a')); res.started != 'a' {
+		t.Fatalf("expected recording into a, got %+v", res)
+	}
+	for _, k := range []tea.KeyMsg{runeKey('m'), {Type: tea.KeyDown}} {
+		if res := m.HandleKey(k); res.consumed {
+			t.Errorf("recorded key %s should pass through", k)
+		}
+	}
+	if res := m.HandleKey(runeKey('Q')); res.stopped != 'a' {
+		t.Fatalf("expected recording to stop, got %+v", res)
+	}
+
+	m.HandleKey(runeKey('@'))
+	res := m.HandleKey(runeKey('a'))
+	if len(res.replay) != 2 || res.replay[0].String() != "m" || res.replay[1].Type != tea.KeyDown {
+		t.Fatalf("unexpected replay %v", res.replay)
+	}
+
+	// @@ repeats the last register
+	m.HandleKey(runeKey('@'))
+	if res := m.HandleKey(runeKey('@')); len(res.replay) != 2 {
+		t.Errorf("expected @@ to replay a, got %+v", res)
+	}
+}
+
+func TestMacroReplayEmptyRegister(t *testing.T) {
+	m := NewMacroRecorder()
+	m.HandleKey(runeKey('@'))
+	if res := m.HandleKey(runeKey('z')); res.invalid == "" || len(res.replay) != 0 {
+		t.Errorf("expected error for empty register, got %+v", res)
+	}
+}
+
+func TestMacroIgnoresKeysWhileReplaying(t *testing.T) {
+	m := NewMacroRecorder()
+	m.SetReplaying(true)
+	if res := m.HandleKey(runeKey('Q')); res.consumed {
+		t.Error("keys during replay should not be taken as macro commands")
+	}
+}
+
+func TestMacroRecordTakesNoCommands(t *testing.T) {
+	m := NewMacroRecorder()
+	m.HandleKey(runeKey('Q'))
+	m.HandleKey(runeKey('a'))
+	// Keys typed into an overlay are recorded as they are, Q and @ included
+	for _, r := range "Q@x" {
+		m.Record(runeKey(r))
+	}
+	if m.Recording() != 'a' {
+		t.Fatal("Record must not stop the recording")
+	}
+	m.HandleKey(runeKey('Q'))
+	m.HandleKey(runeKey('@'))
+	if res := m.HandleKey(runeKey('a')); len(res.replay) != 3 || res.replay[0].String() != "Q" {
+		t.Errorf("expected the three recorded keys, got %+v", res)
+	}
+}
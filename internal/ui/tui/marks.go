@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// markedBar replaces the help bar while items are marked or a range is being
+// selected, with how much is marked and what can be done with it
+func markedBar(count int, size int64, visual bool, width int) string {
+	labelStyle := lipgloss.NewStyle().Foreground(ColorMarked).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	sep := dimStyle.Render(" │ ")
+
+	parts := []string{labelStyle.Render("MARKED")}
+	if count > 0 {
+		parts[0] += fmt.Sprintf(" %s item(s), %s", FormatCount(int64(count)), FormatSize(size))
+	}
+	if visual {
+		parts = append(parts, labelStyle.Render("RANGE")+dimStyle.Render(" move to extend, v marks it, Esc cancels"))
+	} else {
+		var hints []string
+		for _, h := range [][2]string{{"d", "trash"}, {"M", "move"}, {"o", "open"}, {"X", "export list"}, {"W", "what-if"}, {"U", "unmark all"}} {
+			hints = append(hints, HelpKey.Render(h[0])+" "+dimStyle.Render(h[1]))
+		}
+		parts = append(parts, strings.Join(hints, " "))
+	}
+	return HelpStyle.Width(width).MaxHeight(1).Render(strings.Join(parts, sep))
+}
+
+// markedChanged updates what depends on the marks after they changed
+func (a *App) markedChanged() {
+	if a.whatIf != nil {
+		a.updateWhatIf()
+		a.treemap.Relayout()
+	}
+}
+
+// exportMarked saves the list of marked items to the exports folder and
+// copies it to the clipboard
+func (a *App) exportMarked() tea.Cmd {
+	count, _ := a.ctrl.MarkedTotal()
+	if count == 0 {
+		return a.showToast("Nothing marked; mark items with m or v first")
+	}
+
+	var text strings.Builder
+	if err := a.ctrl.WriteMarked(&text); err != nil {
+		return a.showToast("Export failed: " + err.Error())
+	}
+	path, copied, err := exportText(exportDir(), "marked", text.String(), time.Now())
+	if err != nil {
+		return a.showToast("Export failed: " + err.Error())
+	}
+	if copied {
+		return a.showToast(fmt.Sprintf("%d marked item(s) copied to clipboard and saved to %s", count, path))
+	}
+	return a.showToast(fmt.Sprintf("%d marked item(s) saved to %s", count, path))
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/config"
+)
+
+const numberInputWidth = 16 // Width of the text field inside the number box
+
+// NumberUnit is what the value of a NumberInput counts
+type NumberUnit int
+
+const (
+	UnitCount NumberUnit = iota // Plain number; "k" and "m" stand for thousands and millions
+	UnitBytes                   // Size such as "250M" or "1.5G"
+)
+
+// NumberInput asks for one number in a centered overlay, so thresholds,
+// depths and budgets can be typed rather than cycled through presets. The
+// value is checked on submit and the box stays open until it is valid.
+type NumberInput struct {
+	input    textinput.Model
+	title    string
+	unit     NumberUnit
+	min, max int64
+	err      string // Why the last submitted value was refused
+	visible  bool
+	width    int
+	height   int
+}
+
+// NewNumberInput creates a new number input component
+func NewNumberInput() NumberInput {
+	input := textinput.New()
+	input.Prompt = "› "
+	input.Width = numberInputWidth
+	input.PromptStyle = lipgloss.NewStyle().Foreground(ColorCyan)
+	input.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+	return NumberInput{input: input}
+}
+
+// Open shows the input with a title and the current value, accepting values
+// from min to max
+func (n *NumberInput) Open(title string, unit NumberUnit, value, min, max int64) tea.Cmd {
+	n.title = title
+	n.unit = unit
+	n.min, n.max = min, max
+	n.err = ""
+	n.visible = true
+	n.input.SetValue(formatNumber(value, unit))
+	n.input.CursorEnd()
+	return n.input.Focus()
+}
+
+// Close hides the input
+func (n *NumberInput) Close() {
+	n.visible = false
+	n.input.Blur()
+}
+
+// IsVisible returns whether the input is visible
+func (n NumberInput) IsVisible() bool {
+	return n.visible
+}
+
+// SetSize sets the dimensions for centering
+func (n *NumberInput) SetSize(w, h int) {
+	n.width = w
+	n.height = h
+}
+
+// Submit parses and range-checks the entered value. When it is invalid the
+// reason is shown under the field and ok is false.
+func (n *NumberInput) Submit() (value int64, ok bool) {
+	value, err := ParseNumber(n.input.Value(), n.unit)
+	if err == nil && (value < n.min || value > n.max) {
+		err = fmt.Errorf("enter a value from %s to %s", formatNumber(n.min, n.unit), formatNumber(n.max, n.unit))
+	}
+	if err != nil {
+		n.err = err.Error()
+		return 0, false
+	}
+	return value, true
+}
+
+// Update forwards input messages to the text field, clearing the error once
+// the value is edited
+func (n NumberInput) Update(msg tea.Msg) (NumberInput, tea.Cmd) {
+	if _, ok := msg.(tea.KeyMsg); ok {
+		n.err = ""
+	}
+	var cmd tea.Cmd
+	n.input, cmd = n.input.Update(msg)
+	return n, cmd
+}
+
+// View renders the number input overlay
+func (n NumberInput) View() string {
+	if !n.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Background(ColorBackground)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	errStyle := lipgloss.NewStyle().Foreground(ColorDanger)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		MarginTop(1)
+
+	hint := "e.g. 1500 or 20k"
+	if n.unit == UnitBytes {
+		hint = "e.g. 250M or 1.5G"
+	}
+	lines := []string{titleStyle.Render(n.title), n.input.View()}
+	if n.err != "" {
+		lines = append(lines, errStyle.Render(n.err))
+	}
+	lines = append(lines, hintStyle.Render(hint+"  Enter confirm  Esc cancel"))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+	return lipgloss.Place(n.width, n.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(content))
+}
+
+// ParseNumber parses text typed into a NumberInput: a size such as "250M"
+// for UnitBytes, or a count such as "1500" or "1.5k" for UnitCount
+func ParseNumber(text string, unit NumberUnit) (int64, error) {
+	if unit == UnitBytes {
+		size, err := config.ParseSize(text)
+		return int64(size), err
+	}
+
+	s := strings.ToLower(strings.TrimSpace(text))
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		scale, s = 1e3, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		scale, s = 1e6, strings.TrimSuffix(s, "m")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid number %q", strings.TrimSpace(text))
+	}
+	return int64(v * scale), nil
+}
+
+// formatNumber writes value in a form ParseNumber reads back
+func formatNumber(value int64, unit NumberUnit) string {
+	if unit == UnitBytes {
+		return config.Size(value).String()
+	}
+	return strconv.FormatInt(value, 10)
+}
@@ -0,0 +1,49 @@
+package tui
+
+import "testing"
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		text string
+		unit NumberUnit
+		want int64
+	}{
+		{"1500", UnitCount, 1500},
+		{"20k", UnitCount, 20000},
+		{"1.5M", UnitCount, 1500000},
+		{" 3 ", UnitCount, 3},
+		{"250M", UnitBytes, 250 << 20},
+		{"1.5G", UnitBytes, 3 << 29},
+		{"4096", UnitBytes, 4096},
+	}
+	for _, tt := range tests {
+		got, err := ParseNumber(tt.text, tt.unit)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseNumber(%q) = %d, %v; want %d", tt.text, got, err, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "lots", "-5", "2x"} {
+		if _, err := ParseNumber(text, UnitCount); err == nil {
+			t.Errorf("expected %q to be refused", text)
+		}
+	}
+}
+
+func TestNumberInputSubmit(t *testing.T) {
+	n := NewNumberInput()
+	n.Open("Depth", UnitCount, 2, 1, 8)
+	if v, ok := n.Submit(); !ok || v != 2 {
+		t.Errorf("expected the initial value to submit, got %d %v", v, ok)
+	}
+
+	n.input.SetValue("12")
+	if _, ok := n.Submit(); ok || n.err == "" {
+		t.Error("expected a value above the maximum to be refused with a reason")
+	}
+
+	n.Open("Minimum size", UnitBytes, 10<<20, 0, 1<<40)
+	if got := n.input.Value(); got != "10MB" {
+		t.Errorf("expected the size to be written as 10MB, got %q", got)
+	}
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const (
+	ownersMaxRows   = 12 // Owners listed before the rest are summed up
+	ownersNameWidth = 20 // Width of the user name column
+)
+
+// OwnersOverlay shows how much of a folder each user's files take up
+type OwnersOverlay struct {
+	node    *model.Node
+	usage   []model.OwnerUsage
+	visible bool
+	width   int
+	height  int
+}
+
+// NewOwnersOverlay creates a new owners overlay component
+func NewOwnersOverlay() OwnersOverlay {
+	return OwnersOverlay{}
+}
+
+// Show computes the per-owner breakdown of node and displays it
+func (o *OwnersOverlay) Show(node *model.Node) {
+	o.node = node
+	o.usage = node.OwnerUsage()
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *OwnersOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o OwnersOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *OwnersOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// View renders the owners overlay
+func (o OwnersOverlay) View() string {
+	if !o.visible || o.node == nil {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	nameStyle := lipgloss.NewStyle().Foreground(ColorText).Width(ownersNameWidth)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(10).Align(lipgloss.Right)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Owners of " + o.node.Name))
+	content.WriteString("\n")
+
+	var total int64
+	for _, u := range o.usage {
+		total += u.Bytes
+	}
+
+	if len(o.usage) == 0 {
+		content.WriteString(dimStyle.Render("File ownership is not available here"))
+		content.WriteString("\n")
+	}
+	for i, u := range o.usage {
+		if i == ownersMaxRows {
+			var rest int64
+			for _, r := range o.usage[i:] {
+				rest += r.Bytes
+			}
+			content.WriteString(dimStyle.Render(fmt.Sprintf("… %d more owner(s), %s", len(o.usage)-i, FormatSize(rest))))
+			content.WriteString("\n")
+			break
+		}
+		content.WriteString(nameStyle.Render(truncateLeft(model.OwnerName(u.UID), ownersNameWidth-1)))
+		content.WriteString(sizeStyle.Render(FormatSize(u.Bytes)))
+		content.WriteString(dimStyle.Render(fmt.Sprintf("  %5.1f%%  %s files", percentOf(u.Bytes, total), FormatCount(u.Files))))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const promptInputWidth = 50 // Width of the text field inside the prompt box
+
+// Prompt displays a single-line text input in a centered overlay
+type Prompt struct {
+	input   textinput.Model
+	title   string
+	visible bool
+	width   int
+	height  int
+}
+
+// NewPrompt creates a new prompt component
+func NewPrompt() Prompt {
+	input := textinput.New()
+	input.Prompt = "› "
+	input.Width = promptInputWidth
+	input.PromptStyle = lipgloss.NewStyle().Foreground(ColorCyan)
+	input.TextStyle = lipgloss.NewStyle().Foreground(ColorText)
+	return Prompt{input: input}
+}
+
+// Open shows the prompt with a title and an initial value
+func (p *Prompt) Open(title, value string) tea.Cmd {
+	p.title = title
+	p.visible = true
+	p.input.SetValue(value)
+	p.input.CursorEnd()
+	return p.input.Focus()
+}
+
+// Close hides the prompt
+func (p *Prompt) Close() {
+	p.visible = false
+	p.input.Blur()
+}
+
+// IsVisible returns whether the prompt is visible
+func (p Prompt) IsVisible() bool {
+	return p.visible
+}
+
+// Value returns the entered text with surrounding whitespace removed
+func (p Prompt) Value() string {
+	return strings.TrimSpace(p.input.Value())
+}
+
+// SetSize sets the dimensions for centering
+func (p *Prompt) SetSize(w, h int) {
+	p.width = w
+	p.height = h
+}
+
+// Update forwards input messages to the text field
+func (p Prompt) Update(msg tea.Msg) (Prompt, tea.Cmd) {
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return p, cmd
+}
+
+// View renders the prompt overlay
+func (p Prompt) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Background(ColorBackground)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		MarginTop(1)
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(p.title),
+		p.input.View(),
+		hintStyle.Render("Enter confirm  Esc cancel"),
+	)
+
+	return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Center, boxStyle.Render(content))
+}
+
+// expandHome expands a leading ~ to the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~"+string(filepath.Separator)) && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,215 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/config"
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+const (
+	quickWidth       = 72 // Width of the command and output text
+	quickOutputLines = 16 // Output lines shown, from the end
+)
+
+// QuickActionsOverlay lists the configured quick actions and, once one has
+// run, shows its output
+type QuickActionsOverlay struct {
+	actions []config.QuickAction
+	cursor  int
+	target  string // Name of the item the actions run on
+	result  *core.QuickActionResult
+	visible bool
+	width   int
+	height  int
+}
+
+// NewQuickActionsOverlay creates a new quick actions overlay component
+func NewQuickActionsOverlay() QuickActionsOverlay {
+	return QuickActionsOverlay{}
+}
+
+// Open shows the action palette for an item
+func (o *QuickActionsOverlay) Open(actions []config.QuickAction, target string) {
+	o.actions = actions
+	o.target = target
+	o.cursor = 0
+	o.result = nil
+	o.visible = true
+}
+
+// ShowResult switches the overlay to the output of a finished action
+func (o *QuickActionsOverlay) ShowResult(result core.QuickActionResult) {
+	o.result = &result
+	o.visible = true
+}
+
+// ShowingResult reports whether the overlay shows an action's output
+func (o QuickActionsOverlay) ShowingResult() bool {
+	return o.result != nil
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *QuickActionsOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o QuickActionsOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *QuickActionsOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// MoveUp moves the palette cursor up
+func (o *QuickActionsOverlay) MoveUp() {
+	if o.cursor > 0 {
+		o.cursor--
+	}
+}
+
+// MoveDown moves the palette cursor down
+func (o *QuickActionsOverlay) MoveDown() {
+	if o.cursor < len(o.actions)-1 {
+		o.cursor++
+	}
+}
+
+// Selected returns the action under the cursor
+func (o QuickActionsOverlay) Selected() (config.QuickAction, bool) {
+	if o.cursor < 0 || o.cursor >= len(o.actions) {
+		return config.QuickAction{}, false
+	}
+	return o.actions[o.cursor], true
+}
+
+// ByKey returns the action bound to a key in the palette
+func (o QuickActionsOverlay) ByKey(k string) (config.QuickAction, bool) {
+	for i, a := range o.actions {
+		if actionKey(o.actions, i) == k {
+			return a, true
+		}
+	}
+	return config.QuickAction{}, false
+}
+
+// actionKey returns the palette key of the i'th action: its configured key,
+// or else its position 1-9 when no other action claims that digit
+func actionKey(actions []config.QuickAction, i int) string {
+	if actions[i].Key != "" {
+		return actions[i].Key
+	}
+	if i >= 9 {
+		return ""
+	}
+	digit := strconv.Itoa(i + 1)
+	for _, a := range actions {
+		if a.Key == digit {
+			return ""
+		}
+	}
+	return digit
+}
+
+// View renders the palette or the output of the last action
+func (o QuickActionsOverlay) View() string {
+	if !o.visible {
+		return ""
+	}
+	if o.result != nil {
+		return o.resultView()
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	keyStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true).Width(4)
+	nameStyle := lipgloss.NewStyle().Foreground(ColorText)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Quick actions on " + o.target))
+	content.WriteString("\n")
+
+	for i, a := range o.actions {
+		style := nameStyle
+		if i == o.cursor {
+			style = selectedStyle
+		}
+		content.WriteString(keyStyle.Render(actionKey(o.actions, i)))
+		content.WriteString(style.Render(a.Name))
+		content.WriteString("\n")
+		content.WriteString(dimStyle.Render("    " + truncateLeft(a.Command, quickWidth-4)))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Enter or key runs  Esc closes"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// resultView renders the command's exit status and the tail of its output
+func (o QuickActionsOverlay) resultView() string {
+	r := o.result
+	color := ColorSuccess
+	status := fmt.Sprintf("finished in %s", r.Duration.Round(time.Millisecond))
+	if r.Err != nil {
+		color = ColorDanger
+		status = r.Err.Error()
+	} else if r.ExitCode != 0 {
+		color = ColorDanger
+		status = fmt.Sprintf("exit status %d after %s", r.ExitCode, r.Duration.Round(time.Millisecond))
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().Foreground(color).Bold(true)
+	outputStyle := lipgloss.NewStyle().Foreground(ColorText).Width(quickWidth)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(r.Action.Name + ": " + status))
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("$ " + truncateLeft(r.Command, quickWidth-2)))
+	content.WriteString("\n\n")
+
+	output := strings.TrimRight(r.Output, "\n")
+	if output == "" {
+		content.WriteString(dimStyle.Render("(no output)"))
+	} else {
+		lines := strings.Split(output, "\n")
+		if len(lines) > quickOutputLines {
+			content.WriteString(dimStyle.Render(fmt.Sprintf("… %d earlier line(s)", len(lines)-quickOutputLines)))
+			content.WriteString("\n")
+			lines = lines[len(lines)-quickOutputLines:]
+		}
+		content.WriteString(outputStyle.Render(strings.Join(lines, "\n")))
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
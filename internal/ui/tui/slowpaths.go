@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+const slowPathsWidth = 56 // Width of the path column
+
+// SlowPathsOverlay lists the directories the last scan took longest to read
+type SlowPathsOverlay struct {
+	paths   []scanner.SlowPath
+	visible bool
+	width   int
+	height  int
+}
+
+// NewSlowPathsOverlay creates a new slow paths overlay component
+func NewSlowPathsOverlay() SlowPathsOverlay {
+	return SlowPathsOverlay{}
+}
+
+// Show displays the overlay with the given directories, slowest first
+func (o *SlowPathsOverlay) Show(paths []scanner.SlowPath) {
+	o.paths = paths
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *SlowPathsOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o SlowPathsOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *SlowPathsOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// View renders the slow paths overlay
+func (o SlowPathsOverlay) View() string {
+	if !o.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	pathStyle := lipgloss.NewStyle().Foreground(ColorText).Width(slowPathsWidth)
+	timeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(8).Align(lipgloss.Right)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Slowest folders to scan"))
+	content.WriteString("\n")
+
+	if len(o.paths) == 0 {
+		content.WriteString(dimStyle.Render("No timings yet - run a scan first."))
+		content.WriteString("\n")
+	}
+	for _, p := range o.paths {
+		content.WriteString(pathStyle.Render(truncateLeft(p.Path, slowPathsWidth-1)))
+		content.WriteString(timeStyle.Render(formatScanTime(p.Duration)))
+		content.WriteString(dimStyle.Render(fmt.Sprintf("  %s entries", FormatCount(int64(p.Entries)))))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Slow folders can mean a failing disk, cloud files being\ndownloaded or antivirus software checking each file."))
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// formatScanTime renders a directory's read time with a precision that suits it
+func formatScanTime(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(100 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Millisecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
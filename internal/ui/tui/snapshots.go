@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+// SnapshotPicker lists the saved scans of the current path so two can be
+// picked for comparison. The first row stands for the current scan.
+type SnapshotPicker struct {
+	snapshots []core.SnapshotInfo
+	cursor    int
+	picked    int // Row picked first, -1 until then
+	visible   bool
+	width     int
+	height    int
+}
+
+// scanName names a saved scan by its time and, if it has one, its label
+func scanName(t time.Time, label string) string {
+	if label == "" {
+		return FormatTime(t)
+	}
+	return FormatTime(t) + ` "` + label + `"`
+}
+
+// NewSnapshotPicker creates a new snapshot picker component
+func NewSnapshotPicker() SnapshotPicker {
+	return SnapshotPicker{picked: -1}
+}
+
+// Show lists the current scan and the given snapshots, newest first
+func (p *SnapshotPicker) Show(current core.SnapshotInfo, snapshots []core.SnapshotInfo) {
+	p.snapshots = append([]core.SnapshotInfo{current}, snapshots...)
+	p.cursor = 0
+	p.picked = -1
+	p.visible = true
+}
+
+// Relist replaces the snapshots listed, as after one was labeled, keeping
+// the cursor and the row picked first
+func (p *SnapshotPicker) Relist(snapshots []core.SnapshotInfo) {
+	p.snapshots = append(p.snapshots[:1], snapshots...)
+	p.cursor = min(p.cursor, len(p.snapshots)-1)
+	if p.picked >= len(p.snapshots) {
+		p.picked = -1
+	}
+	p.visible = true
+}
+
+// Cursor returns the saved scan under the cursor, or false on the row of
+// the current scan
+func (p SnapshotPicker) Cursor() (core.SnapshotInfo, bool) {
+	if p.cursor <= 0 || p.cursor >= len(p.snapshots) {
+		return core.SnapshotInfo{}, false
+	}
+	return p.snapshots[p.cursor], true
+}
+
+// SetVisible sets the visibility of the picker
+func (p *SnapshotPicker) SetVisible(visible bool) {
+	p.visible = visible
+}
+
+// IsVisible returns whether the picker is visible
+func (p SnapshotPicker) IsVisible() bool {
+	return p.visible
+}
+
+// SetSize sets the dimensions for centering
+func (p *SnapshotPicker) SetSize(w, h int) {
+	p.width = w
+	p.height = h
+}
+
+// MoveUp moves the cursor up
+func (p *SnapshotPicker) MoveUp() {
+	if p.cursor > 0 {
+		p.cursor--
+	}
+}
+
+// MoveDown moves the cursor down
+func (p *SnapshotPicker) MoveDown() {
+	if p.cursor < len(p.snapshots)-1 {
+		p.cursor++
+	}
+}
+
+// Pick picks the row under the cursor. Once two different rows are
+// picked it returns their scan times, zero for the current scan, and true.
+func (p *SnapshotPicker) Pick() (from, to time.Time, ok bool) {
+	if p.cursor < 0 || p.cursor >= len(p.snapshots) {
+		return time.Time{}, time.Time{}, false
+	}
+	if p.picked < 0 || p.picked == p.cursor {
+		p.picked = p.cursor
+		return time.Time{}, time.Time{}, false
+	}
+	return p.timeOf(p.picked), p.timeOf(p.cursor), true
+}
+
+// timeOf returns the scan time of a row, zero for the current scan
+func (p SnapshotPicker) timeOf(row int) time.Time {
+	if row == 0 {
+		return time.Time{}
+	}
+	return p.snapshots[row].ScannedAt
+}
+
+// View renders the snapshot picker
+func (p SnapshotPicker) View() string {
+	if !p.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	timeStyle := lipgloss.NewStyle().Foreground(ColorText).Width(20)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Width(10).Align(lipgloss.Right)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Width(20)
+	pickedStyle := MarkedStyle.Width(20)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	filesStyle := dimStyle.Width(16)
+	labelStyle := lipgloss.NewStyle().Foreground(ColorText)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Compare scans"))
+	content.WriteString("\n")
+
+	for i, s := range p.snapshots {
+		label := "Current scan"
+		if i > 0 {
+			label = FormatTime(s.ScannedAt)
+		}
+		marker, style := "  ", timeStyle
+		if i == p.cursor {
+			marker, style = "> ", selectedStyle
+		}
+		if i == p.picked {
+			marker, style = "◆ ", pickedStyle
+		}
+		label = marker + label
+		content.WriteString(style.Render(label))
+		content.WriteString(sizeStyle.Render(FormatSize(s.Bytes)))
+		content.WriteString(filesStyle.Render("  " + FormatCount(s.Files) + " files"))
+		content.WriteString(labelStyle.Render(s.Label))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	if p.picked < 0 {
+		content.WriteString(dimStyle.Render("Enter picks the first scan  l labels it  Esc closes"))
+	} else {
+		content.WriteString(dimStyle.Render("Enter picks the scan to compare with  l labels it  Esc closes"))
+	}
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(p.width, p.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -1,10 +1,11 @@
 package tui
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/locale"
+	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
 // Colors - cyberpunk/neon palette
@@ -22,8 +23,35 @@ var (
 
 	// Deletion indicator
 	ColorShrunk = lipgloss.Color("#5EEAD4") // teal - freed space
+
+	// Growth since an earlier scan
+	ColorGrew = lipgloss.Color("#FB923C") // orange
+
+	// Marked items
+	ColorMarked = lipgloss.Color("#FBBF24") // amber
+
+	// Estimated system space (no files behind it)
+	ColorVirtual = lipgloss.Color("#F472B6") // pink
 )
 
+// categoryColors tint files by kind; uncategorized files use ColorFile
+var categoryColors = map[model.Category]lipgloss.Color{
+	model.CategoryVideo:     lipgloss.Color("#F87171"), // red
+	model.CategoryImage:     lipgloss.Color("#E879F9"), // fuchsia
+	model.CategoryAudio:     lipgloss.Color("#A3E635"), // lime
+	model.CategoryCode:      lipgloss.Color("#60A5FA"), // blue
+	model.CategoryArchive:   lipgloss.Color("#C4B5FD"), // lavender
+	model.CategoryInstaller: lipgloss.Color("#FB923C"), // orange
+}
+
+// categoryColor returns the color used for files of a category
+func categoryColor(c model.Category) lipgloss.Color {
+	if color, ok := categoryColors[c]; ok {
+		return color
+	}
+	return ColorFile
+}
+
 // Styles
 var (
 	// Header
@@ -104,12 +132,28 @@ var (
 	ShrunkStyle = lipgloss.NewStyle().
 			Foreground(ColorShrunk)
 
+	// Growth indicators when comparing scans
+	GrewStyle = lipgloss.NewStyle().
+			Foreground(ColorGrew)
+
+	// Status toast (replaces the help bar while shown)
+	ToastStyle = lipgloss.NewStyle().
+			Foreground(ColorText).
+			Background(lipgloss.Color("#1E3A4C")).
+			Padding(0, 1)
+
+	MarkedStyle = lipgloss.NewStyle().
+			Foreground(ColorMarked)
+
 	DeletedBadge = lipgloss.NewStyle().
 			Background(lipgloss.Color("#374151")). // dark gray
 			Foreground(lipgloss.Color("#9CA3AF")). // light gray
 			Padding(0, 1)
 )
 
+// appLocale formats numbers and dates; NewApp replaces it with the configured locale
+var appLocale = locale.New("en-US")
+
 // FormatSize formats bytes to human readable string
 func FormatSize(bytes int64) string {
 	const (
@@ -128,15 +172,15 @@ func FormatSize(bytes int64) string {
 	var result string
 	switch {
 	case bytes >= TB:
-		result = fmt.Sprintf("%.1fTB", float64(bytes)/TB)
+		result = appLocale.Decimal(float64(bytes)/TB, 1) + "TB"
 	case bytes >= GB:
-		result = fmt.Sprintf("%.1fGB", float64(bytes)/GB)
+		result = appLocale.Decimal(float64(bytes)/GB, 1) + "GB"
 	case bytes >= MB:
-		result = fmt.Sprintf("%.1fMB", float64(bytes)/MB)
+		result = appLocale.Decimal(float64(bytes)/MB, 1) + "MB"
 	case bytes >= KB:
-		result = fmt.Sprintf("%.1fKB", float64(bytes)/KB)
+		result = appLocale.Decimal(float64(bytes)/KB, 1) + "KB"
 	default:
-		result = fmt.Sprintf("%dB", bytes)
+		result = appLocale.Count(bytes) + "B"
 	}
 
 	if negative {
@@ -145,13 +189,35 @@ func FormatSize(bytes int64) string {
 	return result
 }
 
+// FormatCount formats a count with the locale's digit grouping
+func FormatCount(n int64) string {
+	return appLocale.Count(n)
+}
+
+// FormatShare formats a 0..1 fraction as a whole percentage, "<1%" for slivers
+func FormatShare(share float64) string {
+	switch {
+	case share <= 0:
+		return "0%"
+	case share < 0.01:
+		return "<1%"
+	}
+	return appLocale.Count(int64(share*100+0.5)) + "%"
+}
+
 // FormatTime formats a time for display, using shorter format for current year
 func FormatTime(t time.Time) string {
 	if t.IsZero() {
 		return ""
 	}
-	if t.Year() == time.Now().Year() {
-		return t.Format("Jan 2 15:04")
+	return appLocale.DateTime(t, t.Year() != time.Now().Year())
+}
+
+// truncateLeft shortens s to at most max runes, keeping the end and marking the cut with "…"
+func truncateLeft(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max || max < 1 {
+		return s
 	}
-	return t.Format("Jan 2, 2006 15:04")
+	return "…" + string(runes[len(runes)-max+1:])
 }
@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+// SetMinFreed overrides freed.minSize, the smallest deletion that counts as
+// space freed
+func (a App) SetMinFreed(size int64) {
+	a.ctrl.SetMinFreed(size)
+}
+
+// Summary sums up the session, for printing once the interface is gone
+func (a App) Summary() core.SessionSummary {
+	return a.ctrl.Summary()
+}
+
+// FormatSummary renders a session summary as plain lines in the style of
+// diskdive status, or "" if nothing was scanned or freed
+func FormatSummary(s core.SessionSummary) string {
+	if s.Path == "" && s.Freed == 0 {
+		return ""
+	}
+	var b strings.Builder
+	if s.Path != "" {
+		fmt.Fprintln(&b, s.Path)
+	}
+	if s.ScanTook > 0 {
+		fmt.Fprintf(&b, "  scan took  %s\n", formatScanTime(s.ScanTook))
+	}
+	fmt.Fprintf(&b, "  freed      %s", FormatSize(s.Freed))
+	if s.Deleted > s.Freed {
+		fmt.Fprintf(&b, " (all deletions %s)", FormatSize(s.Deleted))
+	}
+	fmt.Fprintln(&b)
+	for i, d := range s.Deletions {
+		label := ""
+		if i == 0 {
+			label = "deleted"
+		}
+		fmt.Fprintf(&b, "  %-9s  %7s  %s\n", label, FormatSize(d.Size), d.Path)
+	}
+	if s.Grew != "" {
+		fmt.Fprintf(&b, "  grew most  %s, +%s since the previous scan\n", s.Grew, FormatSize(s.GrewBy))
+	}
+	return b.String()
+}
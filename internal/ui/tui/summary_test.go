@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+func TestFormatSummary(t *testing.T) {
+	if got := FormatSummary(core.SessionSummary{}); got != "" {
+		t.Errorf("expected nothing for an empty session, got %q", got)
+	}
+
+	got := FormatSummary(core.SessionSummary{
+		Path:     "/data",
+		ScanTook: 12340 * time.Millisecond,
+		Freed:    3 << 20,
+		Deleted:  4 << 20,
+		Deletions: []core.Deletion{
+			{Path: "/data/a.iso", Size: 2 << 20},
+			{Path: "/data/b", Size: 1 << 20},
+		},
+		Grew:   "/data/VMs",
+		GrewBy: 2 << 30,
+	})
+	for _, want := range []string{
+		"/data\n",
+		"  scan took  12.3s\n",
+		"  freed      3.0MB (all deletions 4.0MB)\n",
+		"  deleted      2.0MB  /data/a.iso\n",
+		"               1.0MB  /data/b\n",
+		"  grew most  /data/VMs, +2.0GB since the previous scan\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in summary:\n%s", want, got)
+		}
+	}
+}
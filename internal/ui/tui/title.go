@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// syncTitle returns a command setting the terminal title when the status it
+// shows has changed since it was last set
+func (a *App) syncTitle() tea.Cmd {
+	title := a.statusTitle()
+	if title == a.windowTitle {
+		return nil
+	}
+	a.windowTitle = title
+	return tea.SetWindowTitle(title)
+}
+
+// statusTitle describes what diskdive is doing in a line short enough for a
+// tab, e.g. "DISKDIVE – scanning 43% – C:"
+func (a App) statusTitle() string {
+	parts := []string{"DISKDIVE"}
+
+	if state := a.ctrl.ScanState(); state.IsScanning() {
+		if f := state.Fraction(); f >= 0 {
+			parts = append(parts, fmt.Sprintf("scanning %d%%", int(f*100)))
+		} else {
+			parts = append(parts, "scanning")
+		}
+	} else if freed := a.ctrl.FreedState().Session; freed > 0 {
+		parts = append(parts, "freed "+FormatSize(freed))
+	}
+
+	if target := a.titleTarget(); target != "" {
+		parts = append(parts, target)
+	}
+	return strings.Join(parts, " – ")
+}
+
+// titleTarget names the scanned path or drive, "C:" for a drive letter
+func (a App) titleTarget() string {
+	if path := a.ctrl.CustomPath(); path != "" {
+		return path
+	}
+	drive := a.ctrl.SelectedDrive()
+	if drive == nil {
+		return ""
+	}
+	if len(drive.Letter) == 1 && drive.Letter != "/" {
+		return drive.Letter + ":"
+	}
+	return drive.Letter
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+const (
+	topFilesLimit     = 20 // Files listed in the overlay
+	topFilesPathWidth = 60 // Width of the path column
+)
+
+// TopFilesOverlay lists the largest individual files under a folder
+type TopFilesOverlay struct {
+	node    *model.Node
+	files   []*model.Node
+	cursor  int
+	visible bool
+	width   int
+	height  int
+}
+
+// NewTopFilesOverlay creates a new top files overlay component
+func NewTopFilesOverlay() TopFilesOverlay {
+	return TopFilesOverlay{}
+}
+
+// Show finds the largest files under node and displays them
+func (o *TopFilesOverlay) Show(node *model.Node) {
+	o.node = node
+	o.files = node.LargestFiles(topFilesLimit)
+	o.cursor = 0
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *TopFilesOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o TopFilesOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *TopFilesOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// MoveUp moves the cursor up
+func (o *TopFilesOverlay) MoveUp() {
+	if o.cursor > 0 {
+		o.cursor--
+	}
+}
+
+// MoveDown moves the cursor down
+func (o *TopFilesOverlay) MoveDown() {
+	if o.cursor < len(o.files)-1 {
+		o.cursor++
+	}
+}
+
+// Selected returns the file under the cursor
+func (o TopFilesOverlay) Selected() *model.Node {
+	if o.cursor < 0 || o.cursor >= len(o.files) {
+		return nil
+	}
+	return o.files[o.cursor]
+}
+
+// View renders the top files overlay
+func (o TopFilesOverlay) View() string {
+	if !o.visible || o.node == nil {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Bold(true).Width(10).Align(lipgloss.Right)
+	pathStyle := lipgloss.NewStyle().Foreground(ColorText)
+	selectedStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(fmt.Sprintf("Largest files in %s", o.node.Name)))
+	content.WriteString("\n")
+
+	if len(o.files) == 0 {
+		content.WriteString(dimStyle.Render("No files here"))
+		content.WriteString("\n")
+	}
+	for i, f := range o.files {
+		style := pathStyle
+		if i == o.cursor {
+			style = selectedStyle
+		}
+		rel, err := filepath.Rel(o.node.Path(), f.Path())
+		if err != nil {
+			rel = f.Path()
+		}
+		content.WriteString(sizeStyle.Render(FormatSize(f.Size)))
+		content.WriteString("  ")
+		content.WriteString(style.Render(truncateLeft(rel, topFilesPathWidth)))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(dimStyle.Render("Enter jumps to the file  Esc closes"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
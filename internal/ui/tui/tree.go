@@ -2,6 +2,8 @@ package tui
 
 import (
 	"fmt"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,7 +12,10 @@ import (
 	"github.com/lumipallolabs/diskdive/internal/model"
 )
 
-const treeSizeBarWidth = 4 // Width of size proportion bar [████]
+const (
+	treeSizeBarWidth = 4    // Width of size proportion bar [████]
+	treePageSize     = 1000 // Children listed at a time in very large folders
+)
 
 // TreePanel displays the folder tree
 type TreePanel struct {
@@ -21,28 +26,81 @@ type TreePanel struct {
 	width    int
 	height   int
 	focused  bool
-	offset   int // scroll offset
+	offset   int                         // scroll offset
+	filter   model.CategorySet           // shows only items holding these categories, zero for all
+	noHidden bool                        // leaves out hidden items
+	order    model.SortOrder             // how each folder's children are listed
+	shown    map[string]int              // children listed in folders too large to list at once
+	more     map[*model.Node]*model.Node // "N more" rows, mapped to their folder
+
+	isMarked   func(*model.Node) bool                  // reports batch-operation marks
+	flashColor func(*model.Node) (lipgloss.Color, bool) // reports live-update highlights
+
+	comparing bool // Show each item's change since an earlier scan
+
+	visualFrom *model.Node // Row a range selection started on, nil outside one
 }
 
 // NewTreePanel creates a new tree panel
 func NewTreePanel() TreePanel {
 	return TreePanel{
 		expanded: make(map[string]bool),
+		shown:    make(map[string]int),
 	}
 }
 
+// SetComparing shows each item's change since an earlier scan, for trees
+// built by comparing two scans
+func (t *TreePanel) SetComparing(comparing bool) {
+	t.comparing = comparing
+}
+
 // SetRoot sets the root node
 func (t *TreePanel) SetRoot(root *model.Node) {
 	t.root = root
 	t.cursor = 0
 	t.offset = 0
 	t.expanded = make(map[string]bool)
+	t.shown = make(map[string]int)
+	t.visualFrom = nil
 	if root != nil {
-		t.expanded[root.Path] = true
+		t.expanded[root.Path()] = true
 	}
 	t.updateVisible()
 }
 
+// ExpandedPaths returns the paths of the expanded folders
+func (t *TreePanel) ExpandedPaths() []string {
+	paths := make([]string, 0, len(t.expanded))
+	for path := range t.expanded {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Rename carries what is expanded at oldPath and below over to path, after
+// the folder was renamed or moved on disk
+func (t *TreePanel) Rename(oldPath, path string) {
+	renameKeys(t.expanded, oldPath, path)
+	renameKeys(t.shown, oldPath, path)
+	t.updateVisible()
+}
+
+// renameKeys moves the entries of m for oldPath and the paths below it to
+// the same places under path
+func renameKeys[V any](m map[string]V, oldPath, path string) {
+	moved := make(map[string]V)
+	for p, v := range m {
+		if p == oldPath || strings.HasPrefix(p, oldPath+string(filepath.Separator)) {
+			moved[path+p[len(oldPath):]] = v
+			delete(m, p)
+		}
+	}
+	for p, v := range moved {
+		m[p] = v
+	}
+}
+
 // SetSize sets the panel dimensions
 func (t *TreePanel) SetSize(w, h int) {
 	t.width = w
@@ -54,6 +112,68 @@ func (t *TreePanel) SetFocused(focused bool) {
 	t.focused = focused
 }
 
+// SetMarkChecker sets the function used to show marked items
+func (t *TreePanel) SetMarkChecker(isMarked func(*model.Node) bool) {
+	t.isMarked = isMarked
+}
+
+// SetFlashChecker sets the function used to highlight recently changed items
+func (t *TreePanel) SetFlashChecker(flashColor func(*model.Node) (lipgloss.Color, bool)) {
+	t.flashColor = flashColor
+}
+
+// flash returns the highlight color for a recently changed node
+func (t TreePanel) flash(node *model.Node) (lipgloss.Color, bool) {
+	if t.flashColor == nil {
+		return "", false
+	}
+	return t.flashColor(node)
+}
+
+// marked reports whether a node is marked for a batch operation
+func (t TreePanel) marked(node *model.Node) bool {
+	return t.isMarked != nil && t.isMarked(node)
+}
+
+// StartVisual starts selecting the rows from the cursor to wherever it moves
+func (t *TreePanel) StartVisual() {
+	t.visualFrom = t.Selected()
+}
+
+// Visual reports whether a range is being selected
+func (t TreePanel) Visual() bool {
+	return t.visualFrom != nil
+}
+
+// EndVisual stops selecting a range and returns the rows in it, top first
+func (t *TreePanel) EndVisual() []*model.Node {
+	lo, hi, ok := t.visualRange()
+	t.visualFrom = nil
+	if !ok {
+		return nil
+	}
+	var nodes []*model.Node
+	for _, node := range t.visible[lo : hi+1] {
+		if t.more[node] == nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// visualRange returns the first and last row of the range being selected
+func (t TreePanel) visualRange() (lo, hi int, ok bool) {
+	if t.visualFrom == nil || t.cursor < 0 || t.cursor >= len(t.visible) {
+		return 0, 0, false
+	}
+	from := slices.Index(t.visible, t.visualFrom)
+	if from < 0 {
+		// The start row was collapsed away; the range starts at the cursor
+		from = t.cursor
+	}
+	return min(from, t.cursor), max(from, t.cursor), true
+}
+
 // RefreshVisible refreshes the visible nodes list
 func (t *TreePanel) RefreshVisible() {
 	logging.Debug.Printf("[TreePanel] RefreshVisible: before=%d visible, cursor=%d", len(t.visible), t.cursor)
@@ -69,14 +189,66 @@ func (t *TreePanel) RefreshVisible() {
 	logging.Debug.Printf("[TreePanel] RefreshVisible: after=%d visible, cursor=%d", len(t.visible), t.cursor)
 }
 
-// Selected returns the currently selected node
+// SetFilter shows only folders and files holding bytes in set; zero shows everything
+func (t *TreePanel) SetFilter(set model.CategorySet) {
+	t.filter = set
+	t.RefreshVisible()
+}
+
+// SetHideHidden leaves hidden items out of the tree, or shows them again
+func (t *TreePanel) SetHideHidden(hide bool) {
+	t.noHidden = hide
+	t.RefreshVisible()
+}
+
+// SetOrder lists each folder's children in order
+func (t *TreePanel) SetOrder(order model.SortOrder) {
+	t.order = order
+	t.RefreshVisible()
+}
+
+// shows reports whether child passes the category filter and hidden setting
+func (t TreePanel) shows(child *model.Node) bool {
+	if t.noHidden && child.Attrs.Has(model.AttrHidden) {
+		return false
+	}
+	return t.filter == 0 || child.HasCategory(t.filter)
+}
+
+// Selected returns the currently selected node, or nil on an "N more" row
 func (t TreePanel) Selected() *model.Node {
 	if t.cursor >= 0 && t.cursor < len(t.visible) {
-		return t.visible[t.cursor]
+		if node := t.visible[t.cursor]; t.more[node] == nil {
+			return node
+		}
 	}
 	return nil
 }
 
+// ShowMore lists the next page of a large folder when the cursor is on its
+// "N more" row, and reports whether it was
+func (t *TreePanel) ShowMore() bool {
+	if t.cursor < 0 || t.cursor >= len(t.visible) {
+		return false
+	}
+	dir := t.more[t.visible[t.cursor]]
+	if dir == nil {
+		return false
+	}
+	t.shown[dir.Path()] = t.pageLimit(dir) + treePageSize
+	t.updateVisible()
+	t.ensureVisible()
+	return true
+}
+
+// pageLimit returns how many children of a large folder are listed
+func (t TreePanel) pageLimit(dir *model.Node) int {
+	if n, ok := t.shown[dir.Path()]; ok {
+		return n
+	}
+	return treePageSize
+}
+
 // Update handles messages
 func (t TreePanel) Update(msg tea.Msg) (TreePanel, tea.Cmd) {
 	return t, nil
@@ -130,26 +302,32 @@ func (t *TreePanel) PageDown() {
 // Collapse collapses current folder
 func (t *TreePanel) Collapse() {
 	if node := t.Selected(); node != nil && node.IsDir {
-		delete(t.expanded, node.Path)
+		delete(t.expanded, node.Path())
 		t.updateVisible()
 	}
 }
 
 // Expand expands current folder
 func (t *TreePanel) Expand() {
+	if t.ShowMore() {
+		return
+	}
 	if node := t.Selected(); node != nil && node.IsDir {
-		t.expanded[node.Path] = true
+		t.expanded[node.Path()] = true
 		t.updateVisible()
 	}
 }
 
 // Toggle toggles expand/collapse of current folder
 func (t *TreePanel) Toggle() {
+	if t.ShowMore() {
+		return
+	}
 	if node := t.Selected(); node != nil && node.IsDir {
-		if t.expanded[node.Path] {
-			delete(t.expanded, node.Path)
+		if t.expanded[node.Path()] {
+			delete(t.expanded, node.Path())
 		} else {
-			t.expanded[node.Path] = true
+			t.expanded[node.Path()] = true
 		}
 		t.updateVisible()
 	}
@@ -179,10 +357,22 @@ func (t *TreePanel) ExpandTo(node *model.Node) {
 		path = append([]*model.Node{n}, path...)
 	}
 
-	// Expand each ancestor
-	for _, n := range path {
-		if n.IsDir {
-			t.expanded[n.Path] = true
+	// Expand each ancestor, listing enough of a large folder to include the next step
+	for i, n := range path {
+		if !n.IsDir {
+			continue
+		}
+		t.expanded[n.Path()] = true
+		if i+1 < len(path) && len(n.Children) > treePageSize {
+			rank := 0
+			for _, child := range n.Children {
+				if t.order.Before(child, path[i+1]) {
+					rank++
+				}
+			}
+			if rank >= t.pageLimit(n) {
+				t.shown[n.Path()] = (rank/treePageSize + 1) * treePageSize
+			}
 		}
 	}
 
@@ -203,7 +393,7 @@ func (t *TreePanel) ensureVisible() {
 	if t.cursor < t.offset {
 		t.offset = t.cursor
 	}
-	maxVisible := t.height - 2 // account for borders
+	maxVisible := t.height - 3 // rows View draws: border (2) + 1 for lipgloss height calculation
 	if maxVisible < 1 {
 		maxVisible = 1
 	}
@@ -214,6 +404,7 @@ func (t *TreePanel) ensureVisible() {
 
 func (t *TreePanel) updateVisible() {
 	t.visible = nil
+	t.more = make(map[*model.Node]*model.Node)
 	if t.root == nil {
 		return
 	}
@@ -223,24 +414,40 @@ func (t *TreePanel) updateVisible() {
 func (t *TreePanel) collectVisible(node *model.Node) {
 	t.visible = append(t.visible, node)
 
-	if node.IsDir && t.expanded[node.Path] {
-		// Sort children by size, then by name
-		children := make([]*model.Node, len(node.Children))
-		copy(children, node.Children)
-		model.SortBySize(children)
+	if node.IsDir && t.expanded[node.Path()] {
+		children := node.Children
+		if t.filter != 0 || t.noHidden {
+			children = nil
+			for _, child := range node.Children {
+				if t.shows(child) {
+					children = append(children, child)
+				}
+			}
+		}
 
-		for _, child := range children {
+		// Sort children in the chosen order; very large folders are listed a page at a time
+		limit := len(children)
+		if limit > treePageSize {
+			limit = min(t.pageLimit(node), limit)
+		}
+		for _, child := range t.order.Page(children, 0, limit) {
 			t.collectVisible(child)
 		}
+		if hidden := len(children) - limit; hidden > 0 {
+			row := &model.Node{
+				Name:   fmt.Sprintf("… %s more", FormatCount(int64(hidden))),
+				Parent: node,
+				Attrs:  model.AttrVirtual,
+			}
+			t.more[row] = node
+			t.visible = append(t.visible, row)
+		}
 	}
 }
 
 // ForceRefresh forces a complete refresh of the visible list
 func (t *TreePanel) ForceRefresh() {
-	t.visible = nil
-	if t.root != nil {
-		t.collectVisible(t.root)
-	}
+	t.updateVisible()
 }
 
 func (t TreePanel) getDepth(node *model.Node) int {
@@ -286,8 +493,11 @@ func (t TreePanel) buildLineContent(node *model.Node) lineContent {
 	depth := t.getDepth(node)
 
 	prefix := strings.Repeat("  ", depth)
+	if t.more[node] != nil {
+		return lineContent{prefix: prefix + "  ", name: node.Name}
+	}
 	if node.IsDir {
-		if t.expanded[node.Path] {
+		if t.expanded[node.Path()] {
 			prefix += "\u25bc " // down triangle
 		} else {
 			prefix += "\u25b6 " // right triangle
@@ -297,13 +507,24 @@ func (t TreePanel) buildLineContent(node *model.Node) lineContent {
 	}
 
 	name := node.Name
+	if t.marked(node) {
+		name = "◆ " + name
+	}
+	if marker := attrMarker(node.Attrs); marker != "" {
+		name += " " + marker
+	}
 	size := FormatSize(node.TotalSize())
+	if node.Parent != nil {
+		size += " " + FormatShare(node.ShareOfParent())
+	}
 
 	// For deleted items, skip size (will show as delta)
 	var deletedBadge string
 	if node.IsDeleted {
 		deletedBadge = " DEL"
 		size = ""
+	} else if node.IsStale {
+		deletedBadge = " SYNC"
 	}
 
 	// Size bar for directories
@@ -331,6 +552,14 @@ func (t TreePanel) buildLineContent(node *model.Node) lineContent {
 	if node.IsDeleted {
 		// Deleted item - show its full size as freed
 		changeStr = fmt.Sprintf("-%s", FormatSize(node.TotalSize()))
+	} else if t.comparing {
+		// Net change since the earlier scan, with deleted items counted out
+		switch delta := node.TotalSize() - node.DeletedSize - node.PrevSize; {
+		case delta > 0:
+			changeStr = "+" + FormatSize(delta)
+		case delta < 0:
+			changeStr = "-" + FormatSize(-delta)
+		}
 	} else if node.DeletedSize > 0 {
 		// Contains deleted children - show accumulated freed size
 		changeStr = fmt.Sprintf("-%s", FormatSize(node.DeletedSize))
@@ -339,6 +568,42 @@ func (t TreePanel) buildLineContent(node *model.Node) lineContent {
 	return lineContent{prefix, name, deletedBadge, sizeBar, size, changeStr}
 }
 
+// attrMarker returns a short symbol for links, cloud placeholders and synthetic nodes
+func attrMarker(attrs model.NodeAttr) string {
+	switch {
+	case attrs.IsLink():
+		return "↪"
+	case attrs.Has(model.AttrCloud):
+		return "☁"
+	case attrs.Has(model.AttrVirtual):
+		return "≈"
+	case attrs.Has(model.AttrGrouped):
+		return "…"
+	}
+	return ""
+}
+
+// attrLabel describes a node's special filesystem attributes
+func attrLabel(attrs model.NodeAttr) string {
+	switch {
+	case attrs.Has(model.AttrJunction):
+		return "junction"
+	case attrs.Has(model.AttrSymlink):
+		return "symlink"
+	case attrs.Has(model.AttrCloud):
+		return "cloud placeholder"
+	case attrs.Has(model.AttrReparse):
+		return "reparse point"
+	case attrs.Has(model.AttrVirtual):
+		return "estimated, not on disk"
+	case attrs.Has(model.AttrGrouped):
+		return "small files, grouped"
+	case attrs.Has(model.AttrHidden):
+		return "hidden"
+	}
+	return ""
+}
+
 // buildLine creates the text content for a node (for width calculation)
 // Must match the styling applied in View() for accurate width measurement
 func (t TreePanel) buildLine(node *model.Node) string {
@@ -348,6 +613,8 @@ func (t TreePanel) buildLine(node *model.Node) string {
 	deletedBadge := c.deletedBadge
 	if node.IsDeleted && deletedBadge != "" {
 		deletedBadge = " " + DeletedBadge.Render("DEL")
+	} else if node.IsStale {
+		deletedBadge = " " + DeletedBadge.Render("SYNC")
 	}
 
 	return fmt.Sprintf("%s%s%s %s %s %s", c.prefix, c.name, deletedBadge, c.sizeBar, c.size, c.changeStr)
@@ -371,18 +638,27 @@ func (t TreePanel) View() string {
 		maxVisible = 1
 	}
 
+	visLo, visHi, visual := t.visualRange()
 	for i := t.offset; i < len(t.visible) && len(lines) < maxVisible; i++ {
 		node := t.visible[i]
 		c := t.buildLineContent(node)
+		inRange := visual && i >= visLo && i <= visHi && t.more[node] == nil
+		if inRange && !t.marked(node) {
+			c.name = "◆ " + c.name
+		}
 
 		// Apply styles to components
 		deletedBadge := c.deletedBadge
 		if node.IsDeleted && deletedBadge != "" {
 			deletedBadge = " " + DeletedBadge.Render("DEL")
+		} else if node.IsStale {
+			deletedBadge = " " + DeletedBadge.Render("SYNC")
 		}
 
 		changeStr := c.changeStr
-		if changeStr != "" {
+		if strings.HasPrefix(changeStr, "+") {
+			changeStr = GrewStyle.Render(changeStr)
+		} else if changeStr != "" {
 			changeStr = ShrunkStyle.Render(changeStr)
 		}
 
@@ -392,14 +668,27 @@ func (t TreePanel) View() string {
 		// Determine color based on node type and deletion state
 		var itemStyle lipgloss.Style
 		maxW := t.width - 2
+		flashColor, flashing := t.flash(node)
 		if i == t.cursor && t.focused {
 			itemStyle = TreeItemSelected.Width(maxW).MaxWidth(maxW)
 		} else if i == t.cursor && !t.focused {
 			// Show dimmer selection when unfocused
 			itemStyle = TreeItemSelectedUnfocused.Width(maxW).MaxWidth(maxW)
+		} else if t.more[node] != nil {
+			// Placeholder for the rest of a large folder
+			itemStyle = lipgloss.NewStyle().Foreground(ColorMuted).Italic(true).MaxWidth(maxW)
+		} else if flashing {
+			// Size just changed on disk - fading highlight
+			itemStyle = lipgloss.NewStyle().Foreground(flashColor).Bold(true).MaxWidth(maxW)
+		} else if t.marked(node) || inRange {
+			// Marked for a batch operation, or in the range being selected - amber
+			itemStyle = MarkedStyle.MaxWidth(maxW)
 		} else if node.IsDeleted {
 			// Deleted item - red
 			itemStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#EF4444")).MaxWidth(maxW)
+		} else if t.comparing && node.IsNew {
+			// Not in the earlier scan - orange
+			itemStyle = GrewStyle.MaxWidth(maxW)
 		} else if node.DeletedSize > 0 {
 			// Contains deleted children - purple
 			itemStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#A855F7")).MaxWidth(maxW)
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lumipallolabs/diskdive/internal/model"
+)
+
+func TestTreeRenameKeepsExpanded(t *testing.T) {
+	root := &model.Node{Name: "root", IsDir: true}
+	root.SetPath(filepath.Join("/", "root"))
+	from := &model.Node{Name: "from", IsDir: true}
+	root.AddChild(from)
+	inner := &model.Node{Name: "inner", IsDir: true}
+	from.AddChild(inner)
+	inner.AddChild(&model.Node{Name: "file", Size: 10})
+	root.AddChild(&model.Node{Name: "fromage", IsDir: true})
+
+	tree := NewTreePanel()
+	tree.SetRoot(root)
+	for _, node := range []*model.Node{from, inner, root.Children[1]} {
+		tree.expanded[node.Path()] = true
+	}
+
+	oldPath := from.Path()
+	from.Move(root, "to")
+	tree.Rename(oldPath, from.Path())
+
+	for _, path := range []string{from.Path(), inner.Path(), filepath.Join("/", "root", "fromage")} {
+		if !tree.expanded[path] {
+			t.Errorf("expected %s to stay expanded", path)
+		}
+	}
+	if tree.expanded[oldPath] {
+		t.Errorf("expected nothing expanded at the old path %s", oldPath)
+	}
+	if len(tree.visible) != 5 {
+		t.Errorf("expected 5 visible rows, got %d", len(tree.visible))
+	}
+}
+
+func TestTreeVisualRange(t *testing.T) {
+	root := &model.Node{Name: "root", IsDir: true}
+	root.SetPath(filepath.Join("/", "root"))
+	for _, size := range []int64{40, 30, 20, 10} {
+		root.AddChild(&model.Node{Name: fmt.Sprintf("f%d", size), Size: size})
+	}
+
+	tree := NewTreePanel()
+	tree.SetRoot(root)
+	tree.SetSize(40, 20)
+	tree.MoveDown()
+	tree.MoveDown() // f30
+	tree.StartVisual()
+	tree.MoveUp()
+	tree.MoveUp() // Onto the root row: above where the range started
+	if !tree.Visual() {
+		t.Fatal("expected a range being selected")
+	}
+
+	var names []string
+	for _, node := range tree.EndVisual() {
+		names = append(names, node.Name)
+	}
+	if got := strings.Join(names, ","); got != "root,f40,f30" {
+		t.Errorf("expected the rows from the root to f30, got %s", got)
+	}
+	if tree.Visual() {
+		t.Error("expected the range selection to have ended")
+	}
+}
@@ -32,6 +32,12 @@ type TreemapPanel struct {
 	height   int
 	focused  bool
 
+	flashColor func(*model.Node) (lipgloss.Color, bool) // reports live-update highlights
+	project    func() model.Projection                  // planned deletion to draw, nil for the tree as it is
+	projection model.Projection                         // taken from project by the last layout
+	emptyHints []string                                 // why a scan found no bytes, shown in place of blocks
+	noHidden   bool                                     // leaves out hidden items
+
 	// Render cache
 	cachedView     string
 	cacheValid     bool
@@ -53,6 +59,18 @@ func (t *TreemapPanel) SetRoot(root *model.Node) {
 	t.layout()
 }
 
+// SetEmptyHints sets the suggestions shown instead of blocks while the
+// whole tree holds no bytes
+func (t *TreemapPanel) SetEmptyHints(hints []string) {
+	t.emptyHints = hints
+}
+
+// SetHideHidden leaves hidden items out of the map, or shows them again
+func (t *TreemapPanel) SetHideHidden(hide bool) {
+	t.noHidden = hide
+	t.layout()
+}
+
 // SetSize sets the panel dimensions
 func (t *TreemapPanel) SetSize(w, h int) {
 	if t.width != w || t.height != h {
@@ -68,11 +86,35 @@ func (t *TreemapPanel) SetFocused(focused bool) {
 	t.focused = focused
 }
 
+// SetFlashChecker sets the function used to highlight recently changed blocks
+func (t *TreemapPanel) SetFlashChecker(flashColor func(*model.Node) (lipgloss.Color, bool)) {
+	t.flashColor = flashColor
+}
+
+// SetProjector sets the function giving the planned deletion to draw the
+// tree after, or nil to draw it as it is, and lays the blocks out again
+func (t *TreemapPanel) SetProjector(project func() model.Projection) {
+	t.project = project
+	t.layout()
+}
+
 // InvalidateCache marks the render cache as invalid
 func (t *TreemapPanel) InvalidateCache() {
 	t.cacheValid = false
 }
 
+// Relayout recomputes block positions after the tree structure changed,
+// falling back to the root if the focused or selected node left the tree
+func (t *TreemapPanel) Relayout() {
+	if !t.isDescendant(t.focus, t.root) {
+		t.focus = t.root
+	}
+	if !t.isDescendant(t.selected, t.root) {
+		t.selected = t.focus
+	}
+	t.layout()
+}
+
 // SetFocus sets the focus node (what to display in treemap)
 // If a file is selected, shows its parent directory contents instead
 func (t *TreemapPanel) SetFocus(node *model.Node) {
@@ -108,6 +150,11 @@ func (t TreemapPanel) Selected() *model.Node {
 	return t.selected
 }
 
+// Focus returns the node whose contents the treemap shows
+func (t TreemapPanel) Focus() *model.Node {
+	return t.focus
+}
+
 // SelectFirst selects the first non-grouped block
 func (t *TreemapPanel) SelectFirst() {
 	for i := range t.blocks {
@@ -239,6 +286,11 @@ const (
 	minBlockHeight  = 3  // minimum height for any block (border + 1 line text)
 	maxVisibleItems = 15 // max items before grouping remainder into "N more"
 
+	// cellAspect is how many times taller than wide a terminal cell is.
+	// Layout runs in units where a cell is 1 wide and cellAspect tall, so
+	// blocks squarify prefers look square on screen rather than tall.
+	cellAspect = 2.0
+
 	// Layout constants for treemap panel (no outer border - blocks have their own)
 	treemapBorderH = 2 // margin for rightmost block borders
 	treemapPadding = 0 // no padding
@@ -256,11 +308,15 @@ func (t *TreemapPanel) layout() {
 
 	// Get children to display
 	var nodes []*model.Node
-	if t.focus.IsDir && len(t.focus.Children) > 0 {
-		nodes = make([]*model.Node, len(t.focus.Children))
-		copy(nodes, t.focus.Children)
+	if t.focus.IsDir {
+		for _, child := range t.focus.Children {
+			if !t.noHidden || !child.Attrs.Has(model.AttrHidden) {
+				nodes = append(nodes, child)
+			}
+		}
 		model.SortBySize(nodes)
-	} else {
+	}
+	if len(nodes) == 0 {
 		// Single file or empty dir - show as single block
 		nodes = []*model.Node{t.focus}
 	}
@@ -276,10 +332,20 @@ func (t *TreemapPanel) layout() {
 		contentH = 1
 	}
 
+	// Sizes after the planned deletion, if any
+	t.projection = model.Projection{}
+	if t.project != nil {
+		t.projection = t.project()
+	}
+
 	// Prepare items with their REAL sizes - no modifications
 	items := make([]*treemapItem, 0, len(nodes))
 	for _, n := range nodes {
-		size := float64(n.TotalSize())
+		projected := t.projection.Size(n)
+		if projected == 0 && n.TotalSize() > 0 {
+			continue // Would be deleted
+		}
+		size := float64(projected)
 		if size < 1 {
 			size = 1 // Prevent division by zero, but keep proportions
 		}
@@ -295,7 +361,7 @@ func (t *TreemapPanel) layout() {
 		X: 0,
 		Y: 0,
 		W: float64(contentW),
-		H: float64(contentH),
+		H: float64(contentH) * cellAspect,
 	}
 
 	var blocks []squarify.Block
@@ -326,7 +392,7 @@ func (t *TreemapPanel) layout() {
 		hasGroupedItems := remainingItems >= 2
 		if hasGroupedItems {
 			// Reserve bottom strip for "N more" block
-			mainRect.H = float64(contentH - minBlockHeight)
+			mainRect.H = float64(contentH-minBlockHeight) * cellAspect
 			// We'll show maxVisible-1 items + grouped block
 			numVisible = maxVisible - 1
 			if numVisible > len(items) {
@@ -353,6 +419,7 @@ func (t *TreemapPanel) layout() {
 			MaxDepth: 1,
 			Sort:     true,
 		})
+		toCells(blocks)
 
 		// Check if all main blocks meet minimum dimensions
 		allFit := true
@@ -406,13 +473,14 @@ func (t *TreemapPanel) layout() {
 		// Only reserve space for grouped block if 2+ items to group
 		needsGrouped := len(items) > 2 // 1 shown + 2+ grouped
 		if needsGrouped {
-			mainRect.H = float64(contentH - minBlockHeight)
+			mainRect.H = float64(contentH-minBlockHeight) * cellAspect
 		}
 
 		blocks, metas = squarify.Squarify(root, mainRect, squarify.Options{
 			MaxDepth: 1,
 			Sort:     true,
 		})
+		toCells(blocks)
 
 		// Add grouped block only if 2+ items
 		if needsGrouped {
@@ -508,12 +576,25 @@ func (t *TreemapPanel) layout() {
 	}
 }
 
+// toCells converts blocks laid out in square units back to terminal cells
+func toCells(blocks []squarify.Block) {
+	for i := range blocks {
+		blocks[i].Y /= cellAspect
+		blocks[i].H /= cellAspect
+	}
+}
+
 // View renders the treemap
 func (t *TreemapPanel) View() string {
 	if t.focus == nil {
 		return TreemapPanelStyle.Render("No data")
 	}
 
+	// A scan that found nothing gets an explanation rather than one empty block
+	if t.focus == t.root && t.root.TotalSize() == 0 {
+		return renderEmptyState(t.root, t.emptyHints, t.width-treemapBorderH, t.height-treemapBorderV)
+	}
+
 	// Check if cache is valid
 	if t.cacheValid &&
 		t.cachedFocus == t.focus &&
@@ -608,22 +689,38 @@ func (t TreemapPanel) renderBlock(block Block) string {
 	// Determine colors - border color indicates type, no background fill
 	var fgColor, borderColor lipgloss.Color
 
-	if block.IsGrouped {
+	if block.IsGrouped || (block.Node != nil && block.Node.Attrs.Has(model.AttrGrouped)) {
 		fgColor = lipgloss.Color("#6B7280")
 		borderColor = lipgloss.Color("#4B5563")
 	} else if block.Node != nil && block.Node.IsDeleted {
 		// Deleted items shown in muted gray
 		fgColor = lipgloss.Color("#6B7280")
 		borderColor = lipgloss.Color("#374151")
+	} else if block.Node != nil && block.Node.Attrs.Has(model.AttrVirtual) {
+		// Estimated system space the scan could not see
+		fgColor = ColorVirtual
+		borderColor = ColorVirtual
 	} else {
 		if block.Node != nil && block.Node.IsDir {
 			// Directories: cyan border and text
 			fgColor = ColorDir
 			borderColor = ColorDir
 		} else {
-			// Files: muted gray border and text
+			// Files: tinted by category, muted gray when uncategorized
 			fgColor = ColorFile
 			borderColor = lipgloss.Color("#6B7280")
+			if block.Node != nil && block.Node.Category != model.CategoryOther {
+				fgColor = categoryColor(block.Node.Category)
+				borderColor = fgColor
+			}
+		}
+	}
+
+	if block.Node != nil && t.flashColor != nil {
+		if color, ok := t.flashColor(block.Node); ok {
+			// Size just changed on disk - fading highlight
+			fgColor = color
+			borderColor = color
 		}
 	}
 
@@ -639,13 +736,14 @@ func (t TreemapPanel) renderBlock(block Block) string {
 	}
 
 	// Build label
-	var label, sizeStr string
+	var label, sizeStr, shareStr string
 	if block.IsGrouped {
 		label = fmt.Sprintf("%d more", block.GroupCount)
 		sizeStr = FormatSize(block.GroupSize)
 	} else if block.Node != nil {
 		label = block.Node.Name
-		sizeStr = FormatSize(block.Node.TotalSize())
+		sizeStr = FormatSize(t.projection.Size(block.Node))
+		shareStr = FormatShare(block.Node.ShareOfRoot()) + " of total"
 	}
 
 	// Inner dimensions (excluding border)
@@ -662,6 +760,9 @@ func (t TreemapPanel) renderBlock(block Block) string {
 	text := label
 	if innerH > 1 && sizeStr != "" {
 		text = label + "\n" + sizeStr
+		if innerH > 2 && shareStr != "" {
+			text += "\n" + shareStr
+		}
 	}
 
 	// Render the block with border using lipgloss
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/jeffwilliams/squarify"
@@ -319,3 +320,65 @@ func TestTreemapBlocksTile(t *testing.T) {
 		t.Errorf("Blocks only cover %.1f%% of area, expected at least 90%%", coverage*100)
 	}
 }
+
+func TestTreemapCellAspect(t *testing.T) {
+	// Four equal children in an area that looks square on screen: 80 cells
+	// wide and 40 tall, with cells twice as tall as wide
+	root := &model.Node{Name: "root", IsDir: true}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		root.Children = append(root.Children, &model.Node{Name: name, Size: 100, IsDir: true, Parent: root})
+	}
+
+	panel := NewTreemapPanel()
+	panel.SetSize(82, 40)
+	panel.SetRoot(root)
+
+	if len(panel.blocks) != 4 {
+		t.Fatalf("Expected 4 blocks, got %d", len(panel.blocks))
+	}
+	for _, block := range panel.blocks {
+		t.Logf("Block %s: x=%d y=%d w=%d h=%d", block.Node.Name, block.X, block.Y, block.Width, block.Height)
+		// A block that looks square is twice as wide in cells as it is tall
+		if block.Width != 40 || block.Height != 20 {
+			t.Errorf("Block %s: expected a 40x20 quarter, got %dx%d", block.Node.Name, block.Width, block.Height)
+		}
+	}
+}
+
+func TestHideHidden(t *testing.T) {
+	root := &model.Node{Name: "home", IsDir: true}
+	root.AddChild(&model.Node{Name: ".cache", Size: 500, IsDir: true, Attrs: model.AttrHidden})
+	root.AddChild(&model.Node{Name: "docs", Size: 300, IsDir: true})
+	root.AddChild(&model.Node{Name: "music", Size: 200, IsDir: true})
+	root.ComputeSizes()
+
+	tree := NewTreePanel()
+	tree.SetSize(40, 20)
+	tree.SetRoot(root)
+	panel := NewTreemapPanel()
+	panel.SetSize(80, 24)
+	panel.SetRoot(root)
+
+	names := func() (inTree, inMap []string) {
+		for _, n := range tree.visible[1:] {
+			inTree = append(inTree, n.Name)
+		}
+		for _, b := range panel.blocks {
+			inMap = append(inMap, b.Node.Name)
+		}
+		return inTree, inMap
+	}
+
+	tree.SetHideHidden(true)
+	panel.SetHideHidden(true)
+	inTree, inMap := names()
+	if len(inTree) != 2 || len(inMap) != 2 || slices.Contains(inTree, ".cache") || slices.Contains(inMap, ".cache") {
+		t.Errorf("expected .cache left out, got tree %v and treemap %v", inTree, inMap)
+	}
+
+	tree.SetHideHidden(false)
+	panel.SetHideHidden(false)
+	if inTree, inMap := names(); len(inTree) != 3 || len(inMap) != 3 {
+		t.Errorf("expected .cache back, got tree %v and treemap %v", inTree, inMap)
+	}
+}
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+// WatchStatsOverlay shows what the filesystem watcher has done since it
+// started, for when changes stop showing up
+type WatchStatsOverlay struct {
+	stats   core.WatchStats
+	visible bool
+	width   int
+	height  int
+}
+
+// NewWatchStatsOverlay creates a new watcher activity overlay component
+func NewWatchStatsOverlay() WatchStatsOverlay {
+	return WatchStatsOverlay{}
+}
+
+// Show displays the overlay with the given activity
+func (o *WatchStatsOverlay) Show(stats core.WatchStats) {
+	o.stats = stats
+	o.visible = true
+}
+
+// SetVisible sets the visibility of the overlay
+func (o *WatchStatsOverlay) SetVisible(visible bool) {
+	o.visible = visible
+}
+
+// IsVisible returns whether the overlay is visible
+func (o WatchStatsOverlay) IsVisible() bool {
+	return o.visible
+}
+
+// SetSize sets the dimensions for centering
+func (o *WatchStatsOverlay) SetSize(w, h int) {
+	o.width = w
+	o.height = h
+}
+
+// View renders the watcher activity overlay
+func (o WatchStatsOverlay) View() string {
+	if !o.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true).
+		MarginBottom(1)
+
+	labelStyle := lipgloss.NewStyle().Foreground(ColorMuted).Width(14)
+	valueStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render("Watcher activity"))
+	content.WriteString("\n")
+
+	s := o.stats
+	if s.Path != "" {
+		state := "running"
+		if !s.Running {
+			state = "stopped"
+		}
+		mode := s.Mode
+		if s.Scoped && s.Mode != "polling" {
+			mode += ", open folders only"
+		}
+		if s.Reason != "" && s.Mode != "" {
+			mode += " (" + s.Reason + ")"
+		}
+		lastEvent := "none yet"
+		if !s.LastEvent.IsZero() {
+			lastEvent = formatSince(s.Quiet)
+		}
+		rows := [][2]string{
+			{"Watching", truncateLeft(s.Path, 50)},
+			{"Mode", mode},
+			{"State", state},
+			{"Started", formatSince(s.Uptime)},
+			{"Changes", FormatCount(int64(s.Events))},
+			{"Last change", lastEvent},
+			{"Lost", fmt.Sprintf("%s times", FormatCount(int64(s.Dropped)))},
+			{"Rescans", FormatCount(int64(s.Rescans))},
+		}
+		if s.Queue > 0 {
+			rows = append(rows, [2]string{"Queue", fmt.Sprintf("%s of %s", FormatCount(int64(s.Queued)), FormatCount(int64(s.Queue)))})
+		}
+		if s.Started.IsZero() {
+			rows = rows[:1]
+		}
+		for _, row := range rows {
+			content.WriteString(labelStyle.Render(row[0]))
+			content.WriteString(valueStyle.Render(row[1]))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(dimStyle.Render(watchHint(s)))
+	content.WriteString("\n\n")
+	content.WriteString(dimStyle.Render("Press any key to close"))
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(o.width, o.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// watchHint explains the most likely reason changes are or are not showing up
+func watchHint(s core.WatchStats) string {
+	switch {
+	case s.Path == "":
+		return "Not watching - changes show up once a scan finishes."
+	case s.Started.IsZero():
+		return "Watching failed to start: " + s.Reason + "\nPress r to rescan."
+	case !s.Running:
+		return "The watcher has stopped, so changes no longer show up.\nPress r to rescan and start it again."
+	case s.Queue > 0 && s.Queued >= s.Queue:
+		return "Changes arrive faster than they can be followed;\nsome will be lost and their folders rescanned."
+	case s.Mode == "polling":
+		return "Only the scanned folder and folders open in the tree\nare checked for changes."
+	case s.Scoped:
+		return "Only the scanned folder and folders open in the tree\nor zoomed into are watched (watch.scope)."
+	case s.Dropped > 0:
+		return "Some changes were lost; the folders they were in\nwere rescanned."
+	}
+	return "Changes below the scanned folder are being followed."
+}
+
+// formatSince renders how long ago something happened, in seconds when recent
+func formatSince(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	}
+	return FormatAge(d) + " ago"
+}
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/core"
+)
+
+func TestWatchHint(t *testing.T) {
+	started := time.Now()
+	tests := []struct {
+		name  string
+		stats core.WatchStats
+		want  string
+	}{
+		{"not watching", core.WatchStats{}, "Not watching"},
+		{"failed", core.WatchStats{Path: "/data", Reason: "permission denied"}, "permission denied"},
+		{"stopped", core.WatchStats{Path: "/data", Mode: "native", Started: started}, "has stopped"},
+		{"queue full", core.WatchStats{Path: "/data", Mode: "native", Started: started, Running: true, Queued: 100, Queue: 100}, "faster than"},
+		{"lost", core.WatchStats{Path: "/data", Mode: "native", Started: started, Running: true, Dropped: 2, Queue: 100}, "were lost"},
+		{"fine", core.WatchStats{Path: "/data", Mode: "native", Started: started, Running: true, Queue: 100}, "being followed"},
+	}
+	for _, tt := range tests {
+		if got := watchHint(tt.stats); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: expected hint containing %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWatchStatsAges(t *testing.T) {
+	o := NewWatchStatsOverlay()
+	o.SetSize(120, 40)
+	o.Show(core.WatchStats{Path: "/data", Mode: "native", Running: true, Started: time.Unix(1, 0), Uptime: 42 * time.Second, LastEvent: time.Unix(1, 0), Quiet: 7 * time.Second})
+	view := o.View()
+	for _, want := range []string{"42s ago", "7s ago"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the view, got:\n%s", want, view)
+		}
+	}
+}
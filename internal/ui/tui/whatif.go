@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/stats"
+)
+
+// whatIfBar summarizes what deleting the marked items would buy, in place of the help bar
+func whatIfBar(w core.WhatIfState, width int) string {
+	labelStyle := lipgloss.NewStyle().Foreground(ColorMarked).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#9CA3AF"))
+	gainStyle := lipgloss.NewStyle().Foreground(ColorShrunk)
+	sep := dimStyle.Render(" │ ")
+
+	parts := []string{labelStyle.Render("WHAT-IF")}
+	if w.Projection.Count() == 0 {
+		parts = append(parts, dimStyle.Render("mark items with m to see what deleting them frees"))
+	} else {
+		parts = append(parts, fmt.Sprintf("%s marked free %s",
+			FormatCount(int64(w.Projection.Count())), gainStyle.Render(FormatSize(w.Projection.Freed()))))
+	}
+	if w.Total > 0 {
+		parts = append(parts, dimStyle.Render("free ")+FormatSize(w.Free)+dimStyle.Render(" → ")+gainStyle.Render(FormatSize(w.ProjectedFree())))
+	}
+
+	switch days, ok := w.DaysLeft(); {
+	case !w.GrowthKnown:
+		parts = append(parts, dimStyle.Render("scan again later to forecast when the drive fills"))
+	case !ok:
+		parts = append(parts, dimStyle.Render("not filling up"))
+	default:
+		projected, _ := w.ProjectedDaysLeft()
+		parts = append(parts, dimStyle.Render("full in ")+formatDays(days)+dimStyle.Render(" → ")+gainStyle.Render(formatDays(projected)))
+	}
+	parts = append(parts, dimStyle.Render("W to leave"))
+
+	return HelpStyle.Width(width).MaxHeight(1).Render(strings.Join(parts, sep))
+}
+
+// formatDays renders a forecast number of days, rounded to what a forecast can tell
+func formatDays(days float64) string {
+	switch {
+	case days < 1:
+		return "under a day"
+	case days < 2:
+		return "1 day"
+	case days < 365:
+		return fmt.Sprintf("%d days", int(days))
+	default:
+		return fmt.Sprintf("%.1f years", days/365)
+	}
+}
+
+// formatTrend renders how fast a drive with free bytes left fills up or
+// empties over its recent scans, or "" before there are enough scans
+func formatTrend(s stats.DriveStats, free int64) string {
+	perDay, ok := s.Trend()
+	switch {
+	case !ok:
+		return ""
+	case perDay < 0:
+		return "shrinking ~" + FormatSize(int64(-perDay)) + "/day"
+	case int64(perDay) == 0:
+		return "steady"
+	}
+	days, _ := model.DaysUntilFull(free, perDay)
+	return "~" + FormatSize(int64(perDay)) + "/day, full in " + formatDays(days)
+}
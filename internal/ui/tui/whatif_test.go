@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/stats"
+)
+
+func TestFormatDays(t *testing.T) {
+	tests := []struct {
+		days float64
+		want string
+	}{
+		{0.4, "under a day"},
+		{1.7, "1 day"},
+		{42.9, "42 days"},
+		{730, "2.0 years"},
+	}
+	for _, tt := range tests {
+		if got := formatDays(tt.days); got != tt.want {
+			t.Errorf("formatDays(%v) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTrend(t *testing.T) {
+	at := time.Unix(1000, 0)
+	usage := func(before, after int64) stats.DriveStats {
+		return stats.DriveStats{Usage: []stats.UsageSample{
+			{At: at, Used: before},
+			{At: at.Add(48 * time.Hour), Used: after},
+		}}
+	}
+	tests := []struct {
+		name  string
+		stats stats.DriveStats
+		want  string
+	}{
+		{"one scan", stats.DriveStats{Usage: []stats.UsageSample{{At: at, Used: 10}}}, ""},
+		{"growing", usage(1<<30, 3<<30), "~1.0GB/day, full in 41 days"},
+		{"shrinking", usage(3<<30, 1<<30), "shrinking ~1.0GB/day"},
+		{"steady", usage(1<<30, 1<<30), "steady"},
+	}
+	for _, tt := range tests {
+		if got := formatTrend(tt.stats, 41<<30+1); got != tt.want {
+			t.Errorf("%s: formatTrend = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lumipallolabs/diskdive/internal/cleanup"
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+)
+
+const (
+	wizardItemLimit = 8  // Items listed per step
+	wizardTextWidth = 64 // Width descriptions are wrapped to
+)
+
+// wizardState is where the cleanup wizard is in its walk through the steps
+type wizardState int
+
+const (
+	wizardSearching wizardState = iota
+	wizardStep
+	wizardConfirming // Asking before a step is deleted for good
+	wizardRemoving
+	wizardDone
+)
+
+// Messages for the cleanup wizard
+type (
+	wizardPlannedMsg struct {
+		steps    []cleanup.Step
+		hidden   int // Items left out because they were dismissed before
+		err      error
+		ruleErrs []error // Rules files left out
+	}
+	wizardRemovedMsg struct {
+		freed    int64
+		diskFree int64
+		err      error
+	}
+)
+
+// wizardKeys are the few keys the wizard answers to
+var wizardKeys = struct {
+	Clean, Skip, Keep, Unhide, Quit key.Binding
+}{
+	Clean:  key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "clean up")),
+	Skip:   key.NewBinding(key.WithKeys("s", "n", "right"), key.WithHelp("s", "skip")),
+	Keep:   key.NewBinding(key.WithKeys("k"), key.WithHelp("k", "keep, don't suggest again")),
+	Unhide: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "show kept items again")),
+	Quit:   key.NewBinding(key.WithKeys("q", "esc", "ctrl+c"), key.WithHelp("q", "finish")),
+}
+
+// Wizard is the guided cleanup mode: one suggestion category per screen,
+// with what it would free and a yes/skip answer, for people who would
+// rather not find their way around the treemap
+type Wizard struct {
+	path    string // Folder scanned for suggestions
+	home    string // Home folder trash, caches and downloads are found in
+	state   wizardState
+	steps   []cleanup.Step
+	current int
+	hidden  int              // Items not suggested because they were kept before
+	ctrl    *core.Controller // Removes steps and remembers what was kept across sessions
+	free    int64            // Free space on the drive, updated as steps are cleaned
+	freed   int64            // Bytes freed so far
+	status  string           // Outcome of the last step
+	ctx     context.Context  // Cancelled when the user gives up while searching
+	cancel  context.CancelFunc
+	width   int
+	height  int
+}
+
+// NewWizard creates a cleanup wizard for the tree at path, looking for
+// trash, caches and downloads under home
+func NewWizard(path, home string) Wizard {
+	total, free := model.GetDiskSpace(path)
+	if total == 0 {
+		free = -1 // Unknown, so no projection is shown
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctrl := core.NewController(path)
+	return Wizard{path: path, home: home, free: free, ctrl: ctrl, ctx: ctx, cancel: cancel}
+}
+
+// Init starts looking for suggestions
+func (w Wizard) Init() tea.Cmd {
+	return tea.Batch(w.plan(w.ctx), tea.Tick(spinnerTickInterval, func(t time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	}))
+}
+
+// plan scans the path and works out the steps
+func (w Wizard) plan(ctx context.Context) tea.Cmd {
+	path, home, ctrl := w.path, w.home, w.ctrl
+	return func() tea.Msg {
+		root, err := scanner.NewWalker(8, ctrl.ScanPolicy()).Scan(ctx, path)
+		if err != nil {
+			return wizardPlannedMsg{err: err}
+		}
+		root.ComputeSizes()
+		rules, ruleErrs := cleanup.LoadRules(cleanup.RuleDirs(home), home)
+		steps, err := cleanup.Plan(ctx, root, home, time.Now(), rules)
+		steps, hidden := cleanup.Hide(steps, ctrl.Dismissed)
+		return wizardPlannedMsg{steps: steps, hidden: hidden, err: err, ruleErrs: ruleErrs}
+	}
+}
+
+// Update handles messages for the wizard
+func (w Wizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		w.width = msg.Width
+		w.height = msg.Height
+		return w, nil
+
+	case spinnerTickMsg:
+		if w.state == wizardSearching || w.state == wizardRemoving {
+			return w, tea.Tick(spinnerTickInterval, func(t time.Time) tea.Msg {
+				return spinnerTickMsg{}
+			})
+		}
+		return w, nil
+
+	case wizardPlannedMsg:
+		w.steps = msg.steps
+		w.hidden = msg.hidden
+		switch {
+		case msg.err != nil:
+			w.status = "Stopped looking early: " + msg.err.Error()
+		case len(msg.ruleErrs) == 1:
+			w.status = "Skipped a rules file: " + msg.ruleErrs[0].Error()
+		case len(msg.ruleErrs) > 1:
+			w.status = fmt.Sprintf("Skipped %d rules files, first %v", len(msg.ruleErrs), msg.ruleErrs[0])
+		}
+		w.state = wizardStep
+		if len(w.steps) == 0 {
+			w.state = wizardDone
+		}
+		return w, nil
+
+	case wizardRemovedMsg:
+		w.freed += msg.freed
+		if w.free >= 0 && msg.diskFree > 0 {
+			w.free = msg.diskFree
+		}
+		w.status = "Freed " + FormatSize(msg.freed)
+		if msg.err != nil {
+			w.status += ", but some items could not be removed: " + msg.err.Error()
+		}
+		return w.next(), nil
+
+	case tea.KeyMsg:
+		return w.handleKey(msg)
+	}
+	return w, nil
+}
+
+// handleKey answers the current screen
+func (w Wizard) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch w.state {
+	case wizardDone:
+		if w.hidden > 0 && key.Matches(msg, wizardKeys.Unhide) {
+			return w.unhide()
+		}
+		w.ctrl.Stop()
+		return w, tea.Quit
+	case wizardRemoving:
+		return w, nil // Let the removal finish so the tally stays right
+	case wizardSearching:
+		if key.Matches(msg, wizardKeys.Quit) {
+			w.cancel()
+			w.ctrl.Stop()
+			return w, tea.Quit
+		}
+		return w, nil
+	case wizardConfirming:
+		if key.Matches(msg, wizardKeys.Clean) {
+			return w.remove()
+		}
+		w.state = wizardStep
+		w.status = "Nothing deleted"
+		return w, nil
+	}
+
+	switch {
+	case key.Matches(msg, wizardKeys.Clean):
+		// Deleting for good asks first as confirm.delete says
+		step := w.steps[w.current]
+		hasDir := slices.ContainsFunc(step.Items, func(n *model.Node) bool { return n.IsDir })
+		if step.Permanent && w.ctrl.Config().Confirm.Delete.Needs(step.Size(), hasDir) {
+			w.state = wizardConfirming
+			return w, nil
+		}
+		return w.remove()
+	case key.Matches(msg, wizardKeys.Skip):
+		w.status = "Skipped: " + w.steps[w.current].Title
+		return w.next(), nil
+	case key.Matches(msg, wizardKeys.Keep):
+		step := w.steps[w.current]
+		paths := make([]string, len(step.Items))
+		for i, n := range step.Items {
+			paths[i] = n.Path()
+		}
+		w.ctrl.Dismiss(step.ID, paths)
+		w.hidden += len(paths)
+		w.status = "Kept, and not suggested again: " + step.Title
+		return w.next(), nil
+	case w.hidden > 0 && key.Matches(msg, wizardKeys.Unhide):
+		return w.unhide()
+	case key.Matches(msg, wizardKeys.Quit):
+		w.state = wizardDone
+	}
+	return w, nil
+}
+
+// remove cleans up the current step through the controller, which counts
+// what goes as freed
+func (w Wizard) remove() (tea.Model, tea.Cmd) {
+	eventCh, err := w.ctrl.CleanUp(w.steps[w.current])
+	if err != nil {
+		w.state = wizardStep
+		w.status = "Could not clean up: " + err.Error()
+		return w, nil
+	}
+	w.state = wizardRemoving
+	return w, tea.Batch(
+		func() tea.Msg {
+			var msg wizardRemovedMsg
+			for event := range eventCh {
+				if e, ok := event.(core.CleanupCompletedEvent); ok {
+					msg = wizardRemovedMsg{freed: e.Freed, diskFree: e.DiskFree, err: e.Err}
+				}
+			}
+			return msg
+		},
+		tea.Tick(spinnerTickInterval, func(t time.Time) tea.Msg {
+			return spinnerTickMsg{}
+		}),
+	)
+}
+
+// unhide forgets what was kept and looks for suggestions again
+func (w Wizard) unhide() (tea.Model, tea.Cmd) {
+	w.ctrl.ResetDismissed()
+	w.state = wizardSearching
+	w.steps = nil
+	w.current = 0
+	w.hidden = 0
+	w.status = ""
+	return w, tea.Batch(w.plan(w.ctx), tea.Tick(spinnerTickInterval, func(t time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	}))
+}
+
+// next moves on to the following step, or to the summary after the last
+func (w Wizard) next() Wizard {
+	w.current++
+	w.state = wizardStep
+	if w.current >= len(w.steps) {
+		w.state = wizardDone
+	}
+	return w
+}
+
+// View renders the current screen of the wizard
+func (w Wizard) View() string {
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 3)
+
+	titleStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	headingStyle := lipgloss.NewStyle().Foreground(ColorText).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(ColorText).Width(wizardTextWidth)
+	savingStyle := lipgloss.NewStyle().Foreground(ColorSuccess).Bold(true)
+	sizeStyle := lipgloss.NewStyle().Foreground(ColorDir).Width(10).Align(lipgloss.Right)
+	pathStyle := lipgloss.NewStyle().Foreground(ColorText)
+	dimStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	spinnerStyle := lipgloss.NewStyle().Foreground(ColorCyan).Bold(true)
+
+	spinner := spinnerFrames[int(time.Now().UnixMilli()/int64(spinnerTickInterval.Milliseconds()))%len(spinnerFrames)]
+
+	var content strings.Builder
+	switch w.state {
+	case wizardSearching:
+		content.WriteString(titleStyle.Render("Cleanup"))
+		content.WriteString("\n\n")
+		content.WriteString(spinnerStyle.Render(spinner) + " Looking for things to clean up in " + w.path + "…")
+		content.WriteString("\n\n")
+		content.WriteString(dimStyle.Render("q cancel"))
+
+	case wizardStep, wizardConfirming, wizardRemoving:
+		step := w.steps[w.current]
+		content.WriteString(titleStyle.Render(fmt.Sprintf("Cleanup · step %d of %d", w.current+1, len(w.steps))))
+		content.WriteString("\n")
+		if w.status != "" {
+			content.WriteString(dimStyle.Render(w.status))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+		content.WriteString(headingStyle.Render(step.Title))
+		content.WriteString("  ")
+		content.WriteString(severityLabel(step.Severity))
+		content.WriteString("\n")
+		content.WriteString(textStyle.Render(step.Description))
+		content.WriteString("\n\n")
+
+		size := step.Size()
+		content.WriteString(savingStyle.Render("Frees " + FormatSize(size)))
+		if w.free >= 0 {
+			content.WriteString(dimStyle.Render(fmt.Sprintf("  (free space %s → %s)", FormatSize(w.free), FormatSize(w.free+size))))
+		}
+		content.WriteString("\n\n")
+
+		for i, n := range step.Items {
+			if i == wizardItemLimit {
+				content.WriteString(dimStyle.Render(fmt.Sprintf("%10s  …and %d more", "", len(step.Items)-i)))
+				content.WriteString("\n")
+				break
+			}
+			content.WriteString(sizeStyle.Render(FormatSize(n.TotalSize())))
+			content.WriteString("  ")
+			content.WriteString(pathStyle.Render(truncateLeft(w.displayPath(n), topFilesPathWidth)))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+
+		if step.Command != "" {
+			content.WriteString(dimStyle.Render("Cleans up with: " + step.Command))
+			content.WriteString("\n\n")
+		}
+		switch {
+		case w.state == wizardRemoving:
+			content.WriteString(spinnerStyle.Render(spinner) + " Removing…")
+		case w.state == wizardConfirming:
+			items := fmt.Sprintf("these %d items", len(step.Items))
+			if len(step.Items) == 1 {
+				items = "this item"
+			}
+			content.WriteString(lipgloss.NewStyle().Foreground(ColorDanger).Bold(true).Render(
+				"Delete " + items + " for good? There is no undo."))
+			content.WriteString("\n")
+			content.WriteString(dimStyle.Render("y delete  any other key cancel"))
+		case step.Command == "" && !step.Permanent:
+			content.WriteString(dimStyle.Render("y move to trash  s skip  k keep  q finish"))
+		default:
+			content.WriteString(dimStyle.Render("y clean up  s skip  k keep  q finish"))
+		}
+		if w.hidden > 0 {
+			content.WriteString("\n")
+			content.WriteString(dimStyle.Render(w.hiddenNote()))
+		}
+
+	case wizardDone:
+		content.WriteString(titleStyle.Render("Cleanup"))
+		content.WriteString("\n\n")
+		if w.status != "" {
+			content.WriteString(dimStyle.Render(w.status))
+			content.WriteString("\n\n")
+		}
+		switch {
+		case len(w.steps) == 0:
+			content.WriteString(textStyle.Render("Nothing to clean up here. It already looks tidy."))
+		case w.freed > 0:
+			content.WriteString(savingStyle.Render("All done. You freed " + FormatSize(w.freed) + "."))
+		default:
+			content.WriteString(textStyle.Render("All done. Nothing was removed."))
+		}
+		content.WriteString("\n\n")
+		if w.hidden > 0 {
+			content.WriteString(dimStyle.Render(w.hiddenNote()))
+			content.WriteString("\n")
+		}
+		content.WriteString(dimStyle.Render("Press any key to exit"))
+	}
+
+	box := boxStyle.Render(content.String())
+	return lipgloss.Place(w.width, w.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// hiddenNote says how many items are left out because they were kept
+func (w Wizard) hiddenNote() string {
+	items := "items"
+	if w.hidden == 1 {
+		items = "item"
+	}
+	return fmt.Sprintf("%d %s you chose to keep not shown · r show them again", w.hidden, items)
+}
+
+// severityLabel tells how much care a step's items need, in the colour of
+// the risk
+func severityLabel(s cleanup.Severity) string {
+	switch s {
+	case cleanup.SeveritySafe:
+		return lipgloss.NewStyle().Foreground(ColorSuccess).Render("safe")
+	case cleanup.SeverityCaution:
+		return lipgloss.NewStyle().Foreground(ColorDanger).Render("check each item")
+	}
+	return lipgloss.NewStyle().Foreground(ColorMarked).Render("review first")
+}
+
+// displayPath shows n relative to the home folder, as "~/Downloads/x.dmg"
+func (w Wizard) displayPath(n *model.Node) string {
+	path := n.Path()
+	if rel, err := filepath.Rel(w.home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		path = filepath.Join("~", rel)
+	}
+	if n.IsDir {
+		path += string(filepath.Separator)
+	}
+	return path
+}
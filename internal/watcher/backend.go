@@ -0,0 +1,29 @@
+package watcher
+
+import "errors"
+
+// ErrUnsupported is returned by New on platforms without a native watcher;
+// Notify or a Poller can take its place
+var ErrUnsupported = errors.New("no native filesystem watcher on this platform")
+
+// Backend watches a folder tree and reports changes below it. Watcher and
+// Poller are both backends.
+type Backend interface {
+	Events() <-chan Event
+	AddRecursive(root string) error
+	Start()
+	Stop() error
+}
+
+// Scoped is a backend that follows the root and only the folders below it
+// passed to Follow, for trees too large to watch whole
+type Scoped interface {
+	Backend
+	Follow(dirs []string)
+}
+
+var (
+	_ Scoped = (*Watcher)(nil)
+	_ Scoped = (*Notify)(nil)
+	_ Scoped = (*Poller)(nil)
+)
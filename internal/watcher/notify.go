@@ -0,0 +1,227 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Notify watches for changes through fsnotify. It runs wherever fsnotify
+// does, BSDs included, but knows less than the native watchers: every
+// folder needs a watch of its own (kqueue even opens every file), and a
+// rename arrives as the old name going away and the new one appearing. It
+// backs platforms without a native watcher and serves as a baseline when a
+// native one misbehaves.
+type Notify struct {
+	w       *fsnotify.Watcher
+	root    string
+	scoped  bool            // Only the root and followed folders are watched
+	watched map[string]bool // Folders with a watch
+	eventCh chan Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+}
+
+// NewNotify creates an fsnotify watcher that holds up to queue events for
+// the reader
+func NewNotify(queue int) (*Notify, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("fsnotify: %w", err)
+	}
+	return &Notify{
+		w:       w,
+		watched: make(map[string]bool),
+		eventCh: make(chan Event, queue),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+func (n *Notify) Events() <-chan Event {
+	return n.eventCh
+}
+
+// AddRecursive watches root and every folder below it
+func (n *Notify) AddRecursive(root string) error {
+	n.mu.Lock()
+	n.root = root
+	n.mu.Unlock()
+	return n.addTree(root)
+}
+
+// AddScoped watches root alone; folders below it are watched once passed
+// to Follow
+func (n *Notify) AddScoped(root string) error {
+	n.mu.Lock()
+	n.root = root
+	n.scoped = true
+	n.mu.Unlock()
+	return n.addWatch(root)
+}
+
+// Follow sets the folders watched besides the root when scoped. Recursive
+// watchers already cover every folder and ignore it.
+func (n *Notify) Follow(dirs []string) {
+	n.mu.Lock()
+	if !n.scoped || n.closed {
+		n.mu.Unlock()
+		return
+	}
+	want := map[string]bool{n.root: true}
+	for _, dir := range dirs {
+		want[dir] = true
+	}
+	for dir := range n.watched {
+		if !want[dir] {
+			_ = n.w.Remove(dir)
+			delete(n.watched, dir)
+		}
+	}
+	var added []string
+	for dir := range want {
+		if !n.watched[dir] {
+			added = append(added, dir)
+		}
+	}
+	n.mu.Unlock()
+
+	for _, dir := range added {
+		_ = n.addWatch(dir) // Gone or unreadable; it goes unwatched
+	}
+}
+
+// addTree adds watches for dir and the folders below it. Folders that
+// cannot be read are skipped; any other failure, such as running out of
+// watches, stops the walk.
+func (n *Notify) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Unreadable or vanished; keep watching the rest
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := n.addWatch(path); err != nil {
+			if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// addWatch watches one folder
+func (n *Notify) addWatch(path string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return nil
+	}
+	if err := n.w.Add(path); err != nil {
+		return err
+	}
+	n.watched[path] = true
+	return nil
+}
+
+// removeTree forgets the watches of dir and the folders below it once dir
+// is gone
+func (n *Notify) removeTree(dir string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	prefix := dir + string(filepath.Separator)
+	for path := range n.watched {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			_ = n.w.Remove(path)
+			delete(n.watched, path)
+		}
+	}
+}
+
+func (n *Notify) Start() {
+	n.wg.Add(1)
+	go n.run()
+}
+
+func (n *Notify) run() {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.done:
+			return
+		case event, ok := <-n.w.Events:
+			if !ok {
+				return
+			}
+			n.handleEvent(event)
+		case err, ok := <-n.w.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				n.mu.Lock()
+				root := n.root
+				n.mu.Unlock()
+				n.send(Event{Type: EventOverflow, Path: root})
+			}
+		}
+	}
+}
+
+func (n *Notify) handleEvent(event fsnotify.Event) {
+	switch {
+	case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+		// A renamed item's new name arrives as a creation
+		n.removeTree(event.Name)
+		n.send(Event{Type: EventDeleted, Path: event.Name})
+	case event.Has(fsnotify.Create):
+		n.mu.Lock()
+		scoped := n.scoped
+		n.mu.Unlock()
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() && !scoped {
+			_ = n.addTree(event.Name)
+		}
+		n.send(Event{Type: EventCreated, Path: event.Name})
+	case event.Has(fsnotify.Write):
+		n.send(Event{Type: EventModified, Path: event.Name})
+	}
+}
+
+// send delivers event unless the watcher is stopping
+func (n *Notify) send(event Event) {
+	select {
+	case n.eventCh <- event:
+	case <-n.done:
+	}
+}
+
+func (n *Notify) Stop() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.mu.Unlock()
+
+	close(n.done)
+	err := n.w.Close()
+	n.wg.Wait()
+	close(n.eventCh)
+	return err
+}
@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitFor reads events from ch until one of type kind arrives for path,
+// and returns it
+func waitFor(t *testing.T, ch <-chan Event, kind EventType, path string) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-ch:
+			if e.Type == kind && e.Path == path {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no event %d for %s", kind, path)
+		}
+	}
+}
+
+func TestNotifyReportsChanges(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewNotify(100)
+	if err != nil {
+		t.Skipf("fsnotify unavailable: %v", err)
+	}
+	if err := n.AddRecursive(root); err != nil {
+		t.Fatal(err)
+	}
+	n.Start()
+	defer n.Stop()
+
+	file := filepath.Join(sub, "log.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n.Events(), EventCreated, file)
+
+	// Folders created later are watched too
+	nested := filepath.Join(root, "new")
+	if err := os.Mkdir(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n.Events(), EventCreated, nested)
+	inner := filepath.Join(nested, "inner.txt")
+	if err := os.WriteFile(inner, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n.Events(), EventCreated, inner)
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n.Events(), EventDeleted, file)
+}
+
+func TestNotifyScoped(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := NewNotify(100)
+	if err != nil {
+		t.Skipf("fsnotify unavailable: %v", err)
+	}
+	if err := n.AddScoped(root); err != nil {
+		t.Fatal(err)
+	}
+	n.Start()
+	defer n.Stop()
+
+	// Not followed yet: only the root's own entries are reported
+	if err := os.WriteFile(filepath.Join(sub, "quiet.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(root, "marker.txt")
+	if err := os.WriteFile(marker, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case e := <-n.Events():
+			if filepath.Dir(e.Path) == sub {
+				t.Fatalf("unexpected event from a folder not followed: %+v", e)
+			}
+			done = e.Path == marker
+		case <-timeout:
+			t.Fatal("no event for the root's own file")
+		}
+	}
+
+	n.Follow([]string{sub})
+	file := filepath.Join(sub, "log.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n.Events(), EventCreated, file)
+}
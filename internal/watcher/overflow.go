@@ -0,0 +1,34 @@
+package watcher
+
+import "path/filepath"
+
+// dropTracker remembers the folders of events dropped because the reader
+// was behind, so the loss can be reported as EventOverflow once it catches
+// up instead of the tree silently drifting from the disk
+type dropTracker map[string]bool
+
+// add records the folders event changed
+func (d dropTracker) add(event Event) {
+	switch event.Type {
+	case EventOverflow:
+		d[event.Path] = true
+	case EventRenamed:
+		d[filepath.Dir(event.OldPath)] = true
+		d[filepath.Dir(event.Path)] = true
+	default:
+		d[filepath.Dir(event.Path)] = true
+	}
+}
+
+// report sends an EventOverflow per recorded folder, waiting for the reader,
+// and forgets the folders sent. It gives up when done is closed.
+func (d dropTracker) report(ch chan<- Event, done <-chan struct{}) {
+	for dir := range d {
+		select {
+		case ch <- Event{Type: EventOverflow, Path: dir}:
+			delete(d, dir)
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package watcher
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestDropTrackerReportsFolders(t *testing.T) {
+	root := filepath.Join("/", "root")
+	d := dropTracker{}
+	d.add(Event{Type: EventCreated, Path: filepath.Join(root, "a", "new.txt")})
+	d.add(Event{Type: EventDeleted, Path: filepath.Join(root, "a", "old.txt")})
+	d.add(Event{Type: EventRenamed, Path: filepath.Join(root, "b", "x"), OldPath: filepath.Join(root, "c", "x")})
+	d.add(Event{Type: EventOverflow, Path: filepath.Join(root, "d")})
+
+	ch := make(chan Event, 10)
+	d.report(ch, nil)
+	close(ch)
+
+	var got []string
+	for e := range ch {
+		if e.Type != EventOverflow {
+			t.Errorf("expected overflow events, got type %d", e.Type)
+		}
+		got = append(got, filepath.Base(e.Path))
+	}
+	sort.Strings(got)
+	if got, want := strings.Join(got, " "), "a b c d"; got != want {
+		t.Errorf("expected folders %s, got %s", want, got)
+	}
+	if len(d) != 0 {
+		t.Errorf("expected reported folders to be forgotten, %d left", len(d))
+	}
+}
@@ -0,0 +1,211 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// pollEntry is what the poller remembers of a listed item
+type pollEntry struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// Poller finds changes by listing folders again every interval and comparing
+// the listings, for network filesystems and platforms where native watching
+// fails. Listing a whole tree that often would be too slow, so it compares
+// only the root and the folders passed to Follow, such as those open in the
+// tree.
+type Poller struct {
+	interval time.Duration
+	root     string
+	listings map[string]map[string]pollEntry // Folder -> name -> entry
+	eventCh  chan Event
+	done     chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	closed   bool
+}
+
+// NewPoller creates a poller that compares its folders every interval and
+// holds up to queue events for the reader
+func NewPoller(interval time.Duration, queue int) *Poller {
+	return &Poller{
+		interval: interval,
+		listings: make(map[string]map[string]pollEntry),
+		eventCh:  make(chan Event, queue),
+		done:     make(chan struct{}),
+	}
+}
+
+func (p *Poller) Events() <-chan Event {
+	return p.eventCh
+}
+
+// AddRecursive sets the root, which is always compared. Folders below it are
+// compared once passed to Follow.
+func (p *Poller) AddRecursive(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a folder", root)
+	}
+	p.mu.Lock()
+	p.root = root
+	p.mu.Unlock()
+	p.Follow(nil)
+	return nil
+}
+
+// Follow sets the folders compared besides the root. Folders followed
+// before keep their listings; new ones are listed now, so only changes from
+// now on are reported.
+func (p *Poller) Follow(dirs []string) {
+	p.mu.Lock()
+	want := map[string]bool{p.root: true}
+	for _, dir := range dirs {
+		want[dir] = true
+	}
+	for dir := range p.listings {
+		if !want[dir] {
+			delete(p.listings, dir)
+		}
+	}
+	var added []string
+	for dir := range want {
+		if _, ok := p.listings[dir]; !ok {
+			added = append(added, dir)
+		}
+	}
+	p.mu.Unlock()
+
+	// Listing a network folder can take a while; do it unlocked
+	for _, dir := range added {
+		listing, err := listFolder(dir)
+		if err != nil {
+			continue
+		}
+		p.mu.Lock()
+		if _, ok := p.listings[dir]; !ok {
+			p.listings[dir] = listing
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *Poller) Start() {
+	p.wg.Add(1)
+	go p.run()
+}
+
+func (p *Poller) run() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			p.pollOnce()
+		}
+	}
+}
+
+// pollOnce lists every polled folder again and reports what changed
+func (p *Poller) pollOnce() {
+	p.mu.Lock()
+	dirs := make([]string, 0, len(p.listings))
+	for dir := range p.listings {
+		dirs = append(dirs, dir)
+	}
+	p.mu.Unlock()
+
+	for _, dir := range dirs {
+		listing, err := listFolder(dir)
+		p.mu.Lock()
+		old, ok := p.listings[dir]
+		switch {
+		case !ok:
+			// No longer polled
+		case err != nil:
+			// Gone or unreachable; its parent's listing reports a deletion
+			delete(p.listings, dir)
+		default:
+			p.listings[dir] = listing
+		}
+		p.mu.Unlock()
+		if ok && err == nil {
+			p.compare(dir, old, listing)
+		}
+	}
+}
+
+// compare reports the differences between two listings of dir
+func (p *Poller) compare(dir string, old, listing map[string]pollEntry) {
+	for name, before := range old {
+		after, ok := listing[name]
+		switch {
+		case !ok:
+			p.send(Event{Type: EventDeleted, Path: filepath.Join(dir, name)})
+		case after.isDir != before.isDir:
+			// Replaced by an item of the other kind
+			p.send(Event{Type: EventDeleted, Path: filepath.Join(dir, name)})
+			p.send(Event{Type: EventCreated, Path: filepath.Join(dir, name)})
+		case !after.isDir && (after.size != before.size || !after.modTime.Equal(before.modTime)):
+			p.send(Event{Type: EventModified, Path: filepath.Join(dir, name)})
+		}
+	}
+	for name := range listing {
+		if _, ok := old[name]; !ok {
+			p.send(Event{Type: EventCreated, Path: filepath.Join(dir, name)})
+		}
+	}
+}
+
+// listFolder lists the items in dir with their sizes and modification times
+func listFolder(dir string) (map[string]pollEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	listing := make(map[string]pollEntry, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed while listing
+		}
+		listing[entry.Name()] = pollEntry{size: info.Size(), modTime: info.ModTime(), isDir: entry.IsDir()}
+	}
+	return listing, nil
+}
+
+// send delivers event unless the poller is stopping
+func (p *Poller) send(event Event) {
+	select {
+	case p.eventCh <- event:
+	case <-p.done:
+	}
+}
+
+func (p *Poller) Stop() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.done)
+	p.wg.Wait()
+	close(p.eventCh)
+	return nil
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPollerReportsChanges(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	write := func(path, data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(root, "gone.txt"), "x")
+	write(filepath.Join(sub, "log.txt"), "x")
+	write(filepath.Join(sub, "quiet.txt"), "x")
+
+	p := NewPoller(time.Hour, 100)
+	if err := p.AddRecursive(root); err != nil {
+		t.Fatal(err)
+	}
+	p.Follow([]string{sub})
+
+	if err := os.Remove(filepath.Join(root, "gone.txt")); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(root, "new.txt"), "x")
+	write(filepath.Join(sub, "log.txt"), "xxxx")
+	p.pollOnce()
+
+	var got []string
+	for len(p.eventCh) > 0 {
+		e := <-p.eventCh
+		rel, _ := filepath.Rel(root, e.Path)
+		got = append(got, map[EventType]string{EventDeleted: "-", EventCreated: "+", EventModified: "~"}[e.Type]+filepath.ToSlash(rel))
+	}
+	sort.Strings(got)
+	want := []string{"+new.txt", "-gone.txt", "~sub/log.txt"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	// A folder no longer followed is not compared
+	p.Follow(nil)
+	write(filepath.Join(sub, "quiet.txt"), "xxxx")
+	p.pollOnce()
+	if len(p.eventCh) != 0 {
+		t.Errorf("expected no events from a folder no longer followed, got %d", len(p.eventCh))
+	}
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
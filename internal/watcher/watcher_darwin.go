@@ -17,27 +17,33 @@ const (
 	EventDeleted EventType = iota
 	EventCreated
 	EventModified
+	EventOverflow // Events were lost; whatever is below Path may have changed
+	EventRenamed  // Moved from OldPath to Path, both within the watched tree
 )
 
 // Event represents a filesystem change event
 type Event struct {
-	Type EventType
-	Path string
+	Type    EventType
+	Path    string
+	OldPath string // For EventRenamed, where the item was before
 }
 
 // Watcher watches for filesystem changes using macOS FSEvents
 type Watcher struct {
 	stream  *fsevents.EventStream
 	eventCh chan Event
+	dropped dropTracker // Folders of events dropped since the last batch
 	done    chan struct{}
 	wg      sync.WaitGroup
 	mu      sync.Mutex
 	closed  bool
 }
 
-func New() (*Watcher, error) {
+// New creates a watcher that holds up to queue events for the reader
+func New(queue int) (*Watcher, error) {
 	return &Watcher{
-		eventCh: make(chan Event, 100),
+		eventCh: make(chan Event, queue),
+		dropped: dropTracker{},
 		done:    make(chan struct{}),
 	}, nil
 }
@@ -61,6 +67,15 @@ func (w *Watcher) AddRecursive(root string) error {
 	return nil
 }
 
+// AddScoped watches root like AddRecursive: one FSEvents stream covers the whole tree
+// at the cost of a single watch, so there is nothing to save by scoping.
+func (w *Watcher) AddScoped(root string) error {
+	return w.AddRecursive(root)
+}
+
+// Follow does nothing; the whole tree is already watched
+func (w *Watcher) Follow(dirs []string) {}
+
 func (w *Watcher) Start() {
 	if w.stream == nil {
 		return
@@ -81,21 +96,51 @@ func (w *Watcher) run() {
 			if !ok {
 				return
 			}
-			for _, event := range events {
-				w.handleEvent(event)
+			for i := 0; i < len(events); i++ {
+				if i+1 < len(events) && w.handleRename(events[i], events[i+1]) {
+					i++
+					continue
+				}
+				w.handleEvent(events[i])
 			}
+			w.dropped.report(w.eventCh, w.done)
 		}
 	}
 }
 
-func (w *Watcher) handleEvent(event fsevents.Event) {
-	path := event.Path
-	if len(path) > 0 && path[0] != '/' {
-		path = "/" + path
+// eventPath returns the absolute path of event
+func eventPath(event fsevents.Event) string {
+	if len(event.Path) > 0 && event.Path[0] != '/' {
+		return "/" + event.Path
 	}
+	return event.Path
+}
+
+// handleRename reports from and to as EventRenamed if they are the two
+// halves of one rename: FSEvents gives them consecutive IDs, the old path
+// first and gone, the new one after and present. Returns false otherwise.
+func (w *Watcher) handleRename(from, to fsevents.Event) bool {
+	if from.Flags&to.Flags&fsevents.ItemRenamed == 0 || to.ID != from.ID+1 {
+		return false
+	}
+	oldPath, path := eventPath(from), eventPath(to)
+	if _, err := os.Lstat(oldPath); err == nil {
+		return false
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return false
+	}
+	w.send(Event{Type: EventRenamed, Path: path, OldPath: oldPath})
+	return true
+}
+
+func (w *Watcher) handleEvent(event fsevents.Event) {
+	path := eventPath(event)
 
 	var eventType EventType
-	if event.Flags&fsevents.ItemRemoved != 0 {
+	if event.Flags&fsevents.MustScanSubDirs != 0 {
+		eventType = EventOverflow // Events below path were coalesced or dropped
+	} else if event.Flags&fsevents.ItemRemoved != 0 {
 		eventType = EventDeleted
 	} else if event.Flags&fsevents.ItemRenamed != 0 {
 		// Rename could be move-in or move-out - check if path exists
@@ -111,10 +156,16 @@ func (w *Watcher) handleEvent(event fsevents.Event) {
 	} else {
 		return
 	}
+	w.send(Event{Type: eventType, Path: path})
+}
 
+// send delivers event. If the reader is behind, the event is dropped and
+// its folder reported as EventOverflow after the current batch.
+func (w *Watcher) send(event Event) {
 	select {
-	case w.eventCh <- Event{Type: eventType, Path: path}:
+	case w.eventCh <- event:
 	default:
+		w.dropped.add(event)
 	}
 }
 
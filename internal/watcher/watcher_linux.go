@@ -0,0 +1,410 @@
+//go:build linux
+
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// EventType represents the type of filesystem event
+type EventType int
+
+const (
+	EventDeleted EventType = iota
+	EventCreated
+	EventModified
+	EventOverflow // Events were lost; whatever is below Path may have changed
+	EventRenamed  // Moved from OldPath to Path, both within the watched tree
+)
+
+// Event represents a filesystem change event
+type Event struct {
+	Type    EventType
+	Path    string
+	OldPath string // For EventRenamed, where the item was before
+}
+
+// watchMask selects the inotify events the watcher asks for on each folder
+const watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO |
+	unix.IN_MODIFY | unix.IN_DELETE_SELF | unix.IN_ONLYDIR | unix.IN_DONT_FOLLOW
+
+// Watcher watches for filesystem changes using Linux inotify. inotify is
+// not recursive, so every folder below the root gets a watch of its own,
+// added as folders appear and dropped as they go. Added with AddScoped, it
+// watches only the root and the folders passed to Follow instead.
+type Watcher struct {
+	fd      int
+	file    *os.File // fd, read through the runtime poller so Stop can interrupt it
+	root    string
+	dev     uint64 // Device of root; folders on other filesystems are not watched
+	scoped  bool   // Only the root and followed folders are watched
+	paths   map[int]string
+	wds     map[string]int
+	moved   *move // Moved out of a folder, until its arrival pairs it up
+	writes  []string        // Files written to in the current read, in order
+	written map[string]bool // Of writes, those still to be reported
+	eventCh chan Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+}
+
+// move is an IN_MOVED_FROM waiting for the IN_MOVED_TO with its cookie
+type move struct {
+	cookie uint32
+	path   string
+	isDir  bool
+}
+
+// New creates a watcher that holds up to queue events for the reader
+func New(queue int) (*Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	return &Watcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		paths:   make(map[int]string),
+		wds:     make(map[string]int),
+		written: make(map[string]bool),
+		eventCh: make(chan Event, queue),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+func (w *Watcher) Events() <-chan Event {
+	return w.eventCh
+}
+
+// AddRecursive watches root and every folder below it on the same
+// filesystem. When the kernel's limit on watches is reached, the folders
+// watched so far stay watched and the error says how to raise the limit.
+func (w *Watcher) AddRecursive(root string) error {
+	var st unix.Stat_t
+	if err := unix.Stat(root, &st); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.root = root
+	w.dev = st.Dev
+	w.mu.Unlock()
+	return w.addTree(root)
+}
+
+// AddScoped watches root alone. Folders below it are watched once passed to
+// Follow, which keeps the number of watches small on huge trees.
+func (w *Watcher) AddScoped(root string) error {
+	var st unix.Stat_t
+	if err := unix.Stat(root, &st); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.root = root
+	w.dev = st.Dev
+	w.scoped = true
+	w.mu.Unlock()
+	return w.addWatch(root)
+}
+
+// Follow sets the folders watched besides the root when scoped: watches of
+// folders no longer passed are removed and new ones added. Recursive
+// watchers already cover every folder and ignore it.
+func (w *Watcher) Follow(dirs []string) {
+	w.mu.Lock()
+	if !w.scoped || w.closed {
+		w.mu.Unlock()
+		return
+	}
+	want := map[string]bool{w.root: true}
+	for _, dir := range dirs {
+		want[dir] = true
+	}
+	for path, wd := range w.wds {
+		if !want[path] {
+			_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.wds, path)
+			delete(w.paths, wd)
+		}
+	}
+	var added []string
+	for dir := range want {
+		if _, ok := w.wds[dir]; !ok {
+			added = append(added, dir)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, dir := range added {
+		if w.sameDevice(dir) {
+			_ = w.addWatch(dir) // Gone or unreadable; it goes unwatched
+		}
+	}
+}
+
+// addTree adds watches for dir and the folders below it
+func (w *Watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil // Unreadable or vanished; keep watching the rest
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && !w.sameDevice(path) {
+			return filepath.SkipDir
+		}
+		if err := w.addWatch(path); err != nil {
+			if errors.Is(err, unix.ENOSPC) {
+				return fmt.Errorf("inotify watch limit reached at %s; raise fs.inotify.max_user_watches to watch all of %s", path, dir)
+			}
+			return nil // Permission denied or gone; its contents go unwatched
+		}
+		return nil
+	})
+}
+
+// isScoped reports whether only followed folders are watched
+func (w *Watcher) isScoped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.scoped
+}
+
+// sameDevice reports whether path is on the filesystem being watched
+func (w *Watcher) sameDevice(path string) bool {
+	var st unix.Stat_t
+	if err := unix.Lstat(path, &st); err != nil {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return st.Dev == w.dev
+}
+
+// addWatch watches one folder. A folder moved within the tree keeps its
+// watch descriptor, which then maps to the new path.
+func (w *Watcher) addWatch(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	wd, err := unix.InotifyAddWatch(w.fd, path, watchMask)
+	if err != nil {
+		return err
+	}
+	if old, ok := w.paths[wd]; ok {
+		delete(w.wds, old)
+	}
+	w.paths[wd] = path
+	w.wds[path] = wd
+	return nil
+}
+
+// renameTree points the watches of dir and the folders below it at their
+// paths under newDir. The watch descriptors stay valid across a rename.
+func (w *Watcher) renameTree(dir, newDir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	prefix := dir + string(filepath.Separator)
+	renamed := make(map[string]int)
+	for path, wd := range w.wds {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			renamed[newDir+path[len(dir):]] = wd
+			delete(w.wds, path)
+		}
+	}
+	for path, wd := range renamed {
+		w.wds[path] = wd
+		w.paths[wd] = path
+	}
+}
+
+// removeTree drops the watches of dir and the folders below it, once dir
+// has moved out of the tree
+func (w *Watcher) removeTree(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	prefix := dir + string(filepath.Separator)
+	for path, wd := range w.wds {
+		if path == dir || strings.HasPrefix(path, prefix) {
+			_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.wds, path)
+			delete(w.paths, wd)
+		}
+	}
+}
+
+func (w *Watcher) Start() {
+	w.wg.Add(1)
+	go w.run()
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+	buf := make([]byte, 64*1024)
+
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return // Closed by Stop
+		}
+		w.processEvents(buf[:n])
+	}
+}
+
+func (w *Watcher) processEvents(buf []byte) {
+	for len(buf) >= unix.SizeofInotifyEvent {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[0]))
+		end := unix.SizeofInotifyEvent + int(raw.Len)
+		if end > len(buf) {
+			return
+		}
+		name := strings.TrimRight(string(buf[unix.SizeofInotifyEvent:end]), "\x00")
+		w.handleEvent(int(raw.Wd), raw.Mask, raw.Cookie, name)
+		buf = buf[end:]
+	}
+	// The kernel queues both halves of a rename together, so a move still
+	// unpaired at the end of a read left the tree
+	w.flushMove()
+	w.flushWrites()
+}
+
+// flushWrites reports each file written to in the current read once, as a
+// file being written fills a read with IN_MODIFY events for it
+func (w *Watcher) flushWrites() {
+	for _, path := range w.writes {
+		if w.written[path] {
+			delete(w.written, path)
+			w.send(Event{Type: EventModified, Path: path})
+		}
+	}
+	w.writes = w.writes[:0]
+	clear(w.written)
+}
+
+// flushMove reports a move that found no arrival as a deletion
+func (w *Watcher) flushMove() {
+	if w.moved == nil {
+		return
+	}
+	from := w.moved
+	w.moved = nil
+	if from.isDir {
+		w.removeTree(from.path)
+	}
+	delete(w.written, from.path)
+	w.send(Event{Type: EventDeleted, Path: from.path})
+}
+
+func (w *Watcher) handleEvent(wd int, mask uint32, cookie uint32, name string) {
+	if w.moved != nil && (mask&unix.IN_MOVED_TO == 0 || cookie != w.moved.cookie) {
+		w.flushMove()
+	}
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		w.mu.Lock()
+		root, scoped := w.root, w.scoped
+		w.mu.Unlock()
+		// Folders created while events were lost need watches too
+		if !scoped {
+			_ = w.addTree(root)
+		}
+		w.send(Event{Type: EventOverflow, Path: root})
+		return
+	}
+
+	w.mu.Lock()
+	dir, ok := w.paths[wd]
+	if ok && mask&unix.IN_IGNORED != 0 {
+		// The folder is gone or its watch was removed
+		delete(w.paths, wd)
+		if w.wds[dir] == wd {
+			delete(w.wds, dir)
+		}
+	}
+	w.mu.Unlock()
+	if !ok || name == "" {
+		return // Events on the folder itself arrive at its parent as well
+	}
+	path := filepath.Join(dir, name)
+	isDir := mask&unix.IN_ISDIR != 0
+
+	var eventType EventType
+	switch {
+	case mask&unix.IN_MOVED_FROM != 0:
+		w.moved = &move{cookie: cookie, path: path, isDir: isDir}
+		return
+	case mask&unix.IN_MOVED_TO != 0 && w.moved != nil:
+		from := w.moved
+		w.moved = nil
+		if isDir {
+			w.renameTree(from.path, path)
+		}
+		delete(w.written, from.path)
+		w.send(Event{Type: EventRenamed, Path: path, OldPath: from.path})
+		return
+	case mask&unix.IN_DELETE != 0:
+		delete(w.written, path)
+		eventType = EventDeleted
+	case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		if isDir && !w.isScoped() && w.sameDevice(path) {
+			_ = w.addTree(path)
+		}
+		eventType = EventCreated
+	case mask&unix.IN_MODIFY != 0:
+		if !isDir && !w.written[path] {
+			w.writes = append(w.writes, path)
+			w.written[path] = true
+		}
+		return
+	default:
+		return
+	}
+	w.send(Event{Type: eventType, Path: path})
+}
+
+// send delivers event unless the watcher is stopping. It blocks rather than
+// drop the event; if the reader falls behind, the kernel queue overflows
+// instead and the loss is reported as EventOverflow.
+func (w *Watcher) send(event Event) {
+	select {
+	case w.eventCh <- event:
+	case <-w.done:
+	}
+}
+
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	err := w.file.Close()
+	w.wg.Wait()
+	close(w.eventCh)
+	return err
+}
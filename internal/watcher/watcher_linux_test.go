@@ -0,0 +1,229 @@
+//go:build linux
+
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// startInotify watches root recursively, or only root when scoped, and
+// stops the watcher when the test ends
+func startInotify(t *testing.T, root string, scoped bool) *Watcher {
+	t.Helper()
+	w, err := New(100)
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	t.Cleanup(func() { w.Stop() })
+	add := w.AddRecursive
+	if scoped {
+		add = w.AddScoped
+	}
+	if err := add(root); err != nil {
+		t.Fatal(err)
+	}
+	w.Start()
+	return w
+}
+
+// watched reports whether w holds a watch for path
+func watched(w *Watcher, path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wd, ok := w.wds[path]
+	return ok && w.paths[wd] == path
+}
+
+func TestInotifyReportsNestedChanges(t *testing.T) {
+	root := t.TempDir()
+	deep := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Fatal(err)
+	}
+	w := startInotify(t, root, false)
+
+	file := filepath.Join(deep, "log.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventCreated, file)
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("more")
+	f.Close()
+	waitFor(t, w.Events(), EventModified, file)
+
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventDeleted, file)
+
+	// A folder created later is watched as well
+	fresh := filepath.Join(deep, "c")
+	if err := os.Mkdir(fresh, 0755); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventCreated, fresh)
+	inner := filepath.Join(fresh, "inner.txt")
+	if err := os.WriteFile(inner, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventCreated, inner)
+}
+
+func TestInotifyRenamesFolderWithinTree(t *testing.T) {
+	root := t.TempDir()
+	old := filepath.Join(root, "old")
+	if err := os.MkdirAll(filepath.Join(old, "inner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w := startInotify(t, root, false)
+
+	renamed := filepath.Join(root, "new")
+	if err := os.Rename(old, renamed); err != nil {
+		t.Fatal(err)
+	}
+	e := waitFor(t, w.Events(), EventRenamed, renamed)
+	if e.OldPath != old {
+		t.Errorf("expected the rename to come from %s, got %s", old, e.OldPath)
+	}
+	for _, path := range []string{renamed, filepath.Join(renamed, "inner")} {
+		if !watched(w, path) {
+			t.Errorf("expected the watch to follow the folder to %s", path)
+		}
+	}
+	for _, path := range []string{old, filepath.Join(old, "inner")} {
+		if watched(w, path) {
+			t.Errorf("expected no watch left at %s", path)
+		}
+	}
+
+	// Events below the folder come with its new path
+	file := filepath.Join(renamed, "inner", "a.txt")
+	if err := os.WriteFile(file, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventCreated, file)
+}
+
+func TestInotifyReportsMoveOutAsDelete(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	dir := filepath.Join(root, "leaving")
+	if err := os.MkdirAll(filepath.Join(dir, "inner"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w := startInotify(t, root, false)
+
+	if err := os.Rename(dir, filepath.Join(outside, "leaving")); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventDeleted, dir)
+	for _, path := range []string{dir, filepath.Join(dir, "inner")} {
+		if watched(w, path) {
+			t.Errorf("expected the watch of %s to be dropped", path)
+		}
+	}
+}
+
+func TestInotifyFollow(t *testing.T) {
+	root := t.TempDir()
+	a := filepath.Join(root, "a")
+	b := filepath.Join(root, "b")
+	for _, dir := range []string{a, b} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := startInotify(t, root, true)
+	if watched(w, a) || watched(w, b) {
+		t.Fatal("expected a scoped watcher to watch only the root")
+	}
+
+	w.Follow([]string{a})
+	if !watched(w, root) || !watched(w, a) || watched(w, b) {
+		t.Fatalf("expected the root and a watched after following a")
+	}
+	inA := filepath.Join(a, "one.txt")
+	if err := os.WriteFile(inA, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, w.Events(), EventCreated, inA)
+
+	w.Follow([]string{b})
+	if !watched(w, root) || watched(w, a) || !watched(w, b) {
+		t.Fatalf("expected the watch to move from a to b")
+	}
+
+	// Events come in order, so one from a would arrive before b's
+	quiet := filepath.Join(a, "two.txt")
+	if err := os.WriteFile(quiet, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inB := filepath.Join(b, "three.txt")
+	if err := os.WriteFile(inB, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-w.Events():
+			if e.Path == quiet {
+				t.Fatalf("expected no events from a folder no longer followed, got %+v", e)
+			}
+			if e.Type == EventCreated && e.Path == inB {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", inB)
+		}
+	}
+}
+
+func TestInotifyCoalescesWrites(t *testing.T) {
+	w, err := New(100)
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	t.Cleanup(func() { w.Stop() })
+	w.paths[1] = "/data"
+
+	// One read as a file being written fills it, with another file written
+	// and deleted in between
+	var buf []byte
+	add := func(mask uint32, name string) {
+		padded := make([]byte, 16)
+		copy(padded, name)
+		raw := unix.InotifyEvent{Wd: 1, Mask: mask, Len: uint32(len(padded))}
+		buf = append(buf, unsafe.Slice((*byte)(unsafe.Pointer(&raw)), unix.SizeofInotifyEvent)...)
+		buf = append(buf, padded...)
+	}
+	for range 50 {
+		add(unix.IN_MODIFY, "download.iso")
+	}
+	add(unix.IN_MODIFY, "log.txt")
+	add(unix.IN_DELETE, "log.txt")
+	add(unix.IN_MODIFY, "download.iso")
+	w.processEvents(buf)
+
+	var got []Event
+	for len(w.eventCh) > 0 {
+		got = append(got, <-w.eventCh)
+	}
+	want := []Event{
+		{Type: EventDeleted, Path: "/data/log.txt"},
+		{Type: EventModified, Path: "/data/download.iso"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
@@ -1,4 +1,4 @@
-//go:build !darwin && !windows
+//go:build !darwin && !windows && !linux
 
 package watcher
 
@@ -9,25 +9,26 @@ const (
 	EventDeleted EventType = iota
 	EventCreated
 	EventModified
+	EventOverflow // Events were lost; whatever is below Path may have changed
+	EventRenamed  // Moved from OldPath to Path, both within the watched tree
 )
 
 // Event represents a filesystem change event
 type Event struct {
-	Type EventType
-	Path string
+	Type    EventType
+	Path    string
+	OldPath string // For EventRenamed, where the item was before
 }
 
-// Watcher is a stub for non-macOS platforms
-// TODO: Implement using inotify on Linux, ReadDirectoryChangesW on Windows
+// Watcher is a stub for platforms without a watcher backend
 type Watcher struct {
 	eventCh chan Event
 }
 
-// New creates a new filesystem watcher (stub)
-func New() (*Watcher, error) {
-	return &Watcher{
-		eventCh: make(chan Event, 100),
-	}, nil
+// New fails on platforms without a watcher backend, so callers fall back
+// to Notify or a Poller
+func New(queue int) (*Watcher, error) {
+	return nil, ErrUnsupported
 }
 
 // Events returns the channel for receiving filesystem events
@@ -40,6 +41,14 @@ func (w *Watcher) AddRecursive(root string) error {
 	return nil
 }
 
+// AddScoped adds a path to watch without its subfolders (stub - does nothing)
+func (w *Watcher) AddScoped(root string) error {
+	return nil
+}
+
+// Follow sets the subfolders watched (stub - does nothing)
+func (w *Watcher) Follow(dirs []string) {}
+
 // Start begins watching for events (stub - does nothing)
 func (w *Watcher) Start() {
 }
@@ -17,12 +17,15 @@ const (
 	EventDeleted EventType = iota
 	EventCreated
 	EventModified
+	EventOverflow // Events were lost; whatever is below Path may have changed
+	EventRenamed  // Moved from OldPath to Path, both within the watched tree
 )
 
 // Event represents a filesystem change event
 type Event struct {
-	Type EventType
-	Path string
+	Type    EventType
+	Path    string
+	OldPath string // For EventRenamed, where the item was before
 }
 
 // Watcher watches for filesystem changes using Windows ReadDirectoryChangesW
@@ -30,15 +33,18 @@ type Watcher struct {
 	handle  windows.Handle
 	root    string
 	eventCh chan Event
+	dropped dropTracker // Folders of events dropped since the last batch
 	done    chan struct{}
 	wg      sync.WaitGroup
 	mu      sync.Mutex
 	closed  bool
 }
 
-func New() (*Watcher, error) {
+// New creates a watcher that holds up to queue events for the reader
+func New(queue int) (*Watcher, error) {
 	return &Watcher{
-		eventCh: make(chan Event, 100),
+		eventCh: make(chan Event, queue),
+		dropped: dropTracker{},
 		done:    make(chan struct{}),
 	}, nil
 }
@@ -72,12 +78,21 @@ func (w *Watcher) AddRecursive(root string) error {
 	return nil
 }
 
+// AddScoped watches root like AddRecursive: one directory handle covers the whole tree
+// at the cost of a single watch, so there is nothing to save by scoping.
+func (w *Watcher) AddScoped(root string) error {
+	return w.AddRecursive(root)
+}
+
+// Follow does nothing; the whole tree is already watched
+func (w *Watcher) Follow(dirs []string) {}
+
 func (w *Watcher) Start() {
 	w.wg.Add(1)
 	go w.run()
 }
 
-const notifyFilter = windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_DIR_NAME
+const notifyFilter = windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_DIR_NAME | windows.FILE_NOTIFY_CHANGE_SIZE
 
 func (w *Watcher) run() {
 	defer w.wg.Done()
@@ -107,26 +122,60 @@ func (w *Watcher) run() {
 
 		if bytesReturned > 0 {
 			w.processEvents(buf[:bytesReturned])
+		} else {
+			// The system's buffer overflowed and the changes are lost
+			w.dropped.add(Event{Type: EventOverflow, Path: w.root})
 		}
+		w.dropped.report(w.eventCh, w.done)
 	}
 }
 
 const (
+	fileActionAdded          = 1
 	fileActionRemoved        = 2
+	fileActionModified       = 3
 	fileActionRenamedOldName = 4
+	fileActionRenamedNewName = 5
 )
 
+// eventTypes maps the actions of FILE_NOTIFY_INFORMATION to event types;
+// other actions are ignored. The halves of a rename within the tree arrive
+// one after the other and are paired into EventRenamed; an old name without
+// a new one left the tree, and a new name without an old one came in.
+var eventTypes = map[uint32]EventType{
+	fileActionAdded:          EventCreated,
+	fileActionRemoved:        EventDeleted,
+	fileActionModified:       EventModified,
+	fileActionRenamedOldName: EventDeleted,
+	fileActionRenamedNewName: EventCreated,
+}
+
 func (w *Watcher) processEvents(buf []byte) {
+	var oldPath string // Of a rename, until its new name follows
 	for len(buf) >= 12 {
 		nextOffset := *(*uint32)(unsafe.Pointer(&buf[0]))
 		action := *(*uint32)(unsafe.Pointer(&buf[4]))
 		nameLen := *(*uint32)(unsafe.Pointer(&buf[8]))
 
-		if len(buf) >= 12+int(nameLen) && (action == fileActionRemoved || action == fileActionRenamedOldName) {
-			name := windows.UTF16ToString((*[1 << 15]uint16)(unsafe.Pointer(&buf[12]))[:nameLen/2])
-			select {
-			case w.eventCh <- Event{Type: EventDeleted, Path: filepath.Join(w.root, name)}:
+		if eventType, ok := eventTypes[action]; ok && len(buf) >= 12+int(nameLen) {
+			// The name is relative to the watched root and not terminated
+			name := windows.UTF16ToString(unsafe.Slice((*uint16)(unsafe.Pointer(&buf[12])), nameLen/2))
+			path := filepath.Join(w.root, name)
+			switch {
+			case action == fileActionRenamedNewName && oldPath != "":
+				w.send(Event{Type: EventRenamed, Path: path, OldPath: oldPath})
+				oldPath = ""
+			case action == fileActionRenamedOldName:
+				if oldPath != "" {
+					w.send(Event{Type: EventDeleted, Path: oldPath})
+				}
+				oldPath = path
 			default:
+				if oldPath != "" {
+					w.send(Event{Type: EventDeleted, Path: oldPath})
+					oldPath = ""
+				}
+				w.send(Event{Type: eventType, Path: path})
 			}
 		}
 
@@ -135,6 +184,19 @@ func (w *Watcher) processEvents(buf []byte) {
 		}
 		buf = buf[nextOffset:]
 	}
+	if oldPath != "" {
+		w.send(Event{Type: EventDeleted, Path: oldPath})
+	}
+}
+
+// send delivers event. If the reader is behind, the event is dropped and
+// its folder reported as EventOverflow after the current batch.
+func (w *Watcher) send(event Event) {
+	select {
+	case w.eventCh <- event:
+	default:
+		w.dropped.add(event)
+	}
 }
 
 func (w *Watcher) Stop() error {
@@ -1,17 +1,51 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/config"
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/scanner"
+	"github.com/lumipallolabs/diskdive/internal/stats"
 	"github.com/lumipallolabs/diskdive/internal/ui/tui"
 )
 
 func main() {
+	top := flag.Int("top", 0, "print the `N` largest files under the path and exit")
+	minFreed := flag.String("min-freed", "", "count deletions of at least `SIZE` as space freed, overriding freed.minSize")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: diskdive [--top N] [--min-freed SIZE] [path]\n")
+		fmt.Fprintf(os.Stderr, "       diskdive clean [path]   guided cleanup, of the home folder by default\n")
+		fmt.Fprintf(os.Stderr, "       diskdive status [path]  show a scan in progress and the latest snapshot\n")
+		fmt.Fprintf(os.Stderr, "       diskdive snapshot [--format csv|json] [--since T] [path]\n")
+		fmt.Fprintf(os.Stderr, "                               write the latest snapshot to stdout, one row per path\n")
+		fmt.Fprintf(os.Stderr, "       diskdive snapshot --save [--label L] [path]\n")
+		fmt.Fprintf(os.Stderr, "                               scan and save a snapshot without the interface, e.g. from cron\n")
+		fmt.Fprintf(os.Stderr, "       diskdive import [--dir D] [--label L] [file]\n")
+		fmt.Fprintf(os.Stderr, "                               save du -ab output from file or stdin as a snapshot\n")
+		fmt.Fprintf(os.Stderr, "       diskdive export [path]  write the scan to stdout as ncdu JSON\n")
+		fmt.Fprintf(os.Stderr, "       diskdive export --changes [--since T] [--min SIZE] [path]\n")
+		fmt.Fprintf(os.Stderr, "                               write only what changed since a snapshot, as JSON lines\n")
+		fmt.Fprintf(os.Stderr, "       diskdive stats [--format csv|json]\n")
+		fmt.Fprintf(os.Stderr, "                               write space freed and scans per drive to stdout\n")
+		fmt.Fprintf(os.Stderr, "       diskdive selftest       check this build on a generated folder, without a terminal\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
 	// Enable CPU profiling if CPUPROFILE env var is set
 	if cpuProfile := os.Getenv("CPUPROFILE"); cpuProfile != "" {
 		f, err := os.Create(cpuProfile)
@@ -26,10 +60,66 @@ func main() {
 		log.Printf("CPU profiling enabled, writing to %s", cpuProfile)
 	}
 
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "selftest" {
+		if err := selfTest(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var command string
+	if len(args) > 0 && (args[0] == "clean" || args[0] == "export" || args[0] == "status" || args[0] == "snapshot" || args[0] == "import" || args[0] == "stats") {
+		command, args = args[0], args[1:]
+	}
+
+	// Export takes its own flags after the command
+	exportFlags := flag.NewFlagSet("export", flag.ExitOnError)
+	exportFlags.Usage = flag.Usage
+	changes := exportFlags.Bool("changes", false, "export only paths whose size changed since a snapshot")
+	since := exportFlags.String("since", "", "compare with the newest snapshot from before `T`, a date or an age like 7d (default: the latest)")
+	minChange := exportFlags.String("min", "1MB", "leave out changes smaller than `SIZE`")
+	if command == "export" {
+		exportFlags.Parse(args)
+		args = exportFlags.Args()
+	}
+
+	// So does snapshot, which writes a saved scan or, with --save, takes one
+	snapshotFlags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	snapshotFlags.Usage = flag.Usage
+	format := snapshotFlags.String("format", cache.ExportCSV, "write `csv` or json")
+	snapshotSince := snapshotFlags.String("since", "", "write the newest snapshot from before `T`, a date or an age like 7d (default: the latest)")
+	save := snapshotFlags.Bool("save", false, "scan and save a snapshot instead of writing one")
+	label := snapshotFlags.String("label", "", "with --save, label the snapshot, e.g. \"before Xcode install\"")
+	var exportSet bool // --format or --since given, which --save does not take
+	if command == "snapshot" {
+		snapshotFlags.Parse(args)
+		args = snapshotFlags.Args()
+		snapshotFlags.Visit(func(f *flag.Flag) {
+			exportSet = exportSet || f.Name == "format" || f.Name == "since"
+		})
+	}
+	importFlags := flag.NewFlagSet("import", flag.ExitOnError)
+	importFlags.Usage = flag.Usage
+	duDir := importFlags.String("dir", "", "resolve relative paths in the du output against `D` (default: the working directory)")
+	importLabel := importFlags.String("label", "", "label the snapshot, e.g. \"before Xcode install\"")
+	if command == "import" {
+		importFlags.Parse(args)
+		args = importFlags.Args()
+	}
+	statsFlags := flag.NewFlagSet("stats", flag.ExitOnError)
+	statsFlags.Usage = flag.Usage
+	statsFormat := statsFlags.String("format", stats.ExportJSON, "write the stats as `csv` or json")
+	if command == "stats" {
+		statsFlags.Parse(args)
+		args = statsFlags.Args()
+	}
+
 	// Check for path argument
 	var scanPath string
-	if len(os.Args) > 1 {
-		path := os.Args[1]
+	if len(args) > 0 {
+		path := args[0]
 		absPath, err := filepath.Abs(path)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Invalid path: %v\n", err)
@@ -38,13 +128,439 @@ func main() {
 		scanPath = absPath
 	}
 
-	p := tea.NewProgram(
-		tui.NewApp(Version, scanPath),
-		tea.WithAltScreen(),
-	)
+	// Snapshots encrypted with a passphrase need it before anything opens them
+	if *top == 0 && command != "clean" && command != "stats" && (command != "export" || *changes) {
+		if err := askPassphrase(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	if _, err := p.Run(); err != nil {
+	if *top > 0 {
+		if err := printTop(scanPath, *top); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if command != "" {
+		run := runClean
+		switch {
+		case command == "export" && *changes:
+			run = func(path string) error { return exportChanges(path, *since, *minChange) }
+		case command == "export":
+			run = exportNcdu
+		case command == "status":
+			run = printStatus
+		case command == "stats":
+			run = func(string) error { return exportStats(*statsFormat) }
+		case command == "import":
+			run = func(file string) error { return importDu(file, *duDir, *importLabel) }
+		case command == "snapshot" && *save && exportSet:
+			run = func(string) error { return fmt.Errorf("--save cannot be used with --format or --since") }
+		case command == "snapshot" && *save:
+			run = func(path string) error { return captureSnapshot(path, *label) }
+		case command == "snapshot" && *label != "":
+			run = func(string) error { return fmt.Errorf("--label needs --save") }
+		case command == "snapshot":
+			run = func(path string) error { return exportSnapshot(path, *snapshotSince, *format) }
+		}
+		if err := run(scanPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	app := tui.NewApp(Version, scanPath)
+	if *minFreed != "" {
+		size, err := config.ParseSize(*minFreed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --min-freed: %v\n", err)
+			os.Exit(1)
+		}
+		app.SetMinFreed(int64(size))
+	}
+	p := tea.NewProgram(app, tea.WithAltScreen())
+
+	m, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+
+	// What the session did, once the alt screen is gone
+	if app, ok := m.(tui.App); ok {
+		fmt.Print(tui.FormatSummary(app.Summary()))
+	}
+}
+
+// runClean walks through cleanup suggestions for path, the home folder if empty
+func runClean(path string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		path = home
+	}
+	_, err = tea.NewProgram(tui.NewWizard(path, home), tea.WithAltScreen()).Run()
+	return err
+}
+
+// askPassphrase asks for the passphrase on the terminal when the config
+// encrypts snapshots with one and it is not in the environment. The first
+// passphrase for a cache is asked twice; later ones are checked.
+func askPassphrase() error {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil || cfg.Snapshots.Encrypt != cache.EncryptPassphrase || os.Getenv(cache.PassphraseEnv) != "" {
+		return nil
+	}
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		// Opening the cache reports the missing passphrase
+		return nil
+	}
+	read := func(prompt string) ([]byte, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		p, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return p, err
+	}
+
+	c := cache.New(cache.DefaultDir())
+	if !c.Keyed() {
+		p, err := read("New snapshot passphrase: ")
+		if err != nil {
+			return err
+		}
+		again, err := read("Repeat the passphrase: ")
+		if err != nil {
+			return err
+		}
+		if string(p) != string(again) {
+			return fmt.Errorf("the passphrases differ")
+		}
+		cache.SetPassphrase(p)
+		return nil
+	}
+	for range 3 {
+		p, err := read("Snapshot passphrase: ")
+		if err != nil {
+			return err
+		}
+		if err = c.Unlock(p); err == nil {
+			cache.SetPassphrase(p)
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+	return fmt.Errorf("no valid passphrase")
+}
+
+// openCache opens the snapshot cache, encrypted as the config says
+func openCache() (*cache.Cache, error) {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return nil, err
+	}
+	c := cache.New(cache.DefaultDir())
+	if err := c.Encrypt(cfg.Snapshots.Encrypt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// printTop scans path (the working directory if empty) and prints its n largest files
+func printTop(path string, n int) error {
+	root, err := scanTree(path)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range root.LargestFiles(n) {
+		fmt.Printf("%10s  %s\n", tui.FormatSize(f.Size), f.Path())
+	}
+	return nil
+}
+
+// exportNcdu scans path (the working directory if empty) and writes it to
+// stdout in ncdu's JSON format
+func exportNcdu(path string) error {
+	root, err := scanTree(path)
+	if err != nil {
+		return err
+	}
+	return model.WriteNcdu(os.Stdout, root, model.NewNcduHeader(Version, time.Now()))
+}
+
+// exportChanges scans path (the working directory if empty) and writes the
+// paths whose size moved by at least minChange since a snapshot of it, the
+// newest one taken before since or the latest if since is empty
+func exportChanges(path, since, minChange string) error {
+	min, err := config.ParseSize(minChange)
+	if err != nil {
+		return err
+	}
+	var before time.Time
+	if since != "" {
+		if before, err = parseSince(since, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	root, err := scanTree(path)
+	if err != nil {
+		return err
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	prev, header, err := c.LoadBefore(root.Path(), before)
+	if err != nil {
+		return err
+	}
+	prev.ComputeSizes()
+
+	changes := model.Changes(prev, root, int64(min))
+	return model.WriteChanges(os.Stdout, model.NewChangesHeader(Version, root.Path(), header.ScannedAt, time.Now(), int64(min)), changes)
+}
+
+// printStatus reports on path, the working directory if empty, without
+// scanning it: whether any diskdive is scanning it now, and the summary of
+// its latest snapshot
+func printStatus(path string) error {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		path = wd
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	fmt.Println(path)
+	if s, ok := c.Scanning(path); ok {
+		fmt.Printf("  scanning   since %s by pid %d on %s: %s files, %s so far, in %s\n",
+			s.Started.Format("15:04:05"), s.PID, s.Host,
+			tui.FormatCount(s.Files), tui.FormatSize(s.Bytes), s.Current)
+	} else {
+		fmt.Println("  scanning   no")
+	}
+
+	h, err := c.LoadHeader(path)
+	if err != nil {
+		fmt.Println("  last scan  none")
+		return nil
+	}
+	fmt.Printf("  last scan  %s (%s ago): %s in %s files and %s folders",
+		tui.FormatTime(h.ScannedAt), tui.FormatAge(now.Sub(h.ScannedAt)),
+		tui.FormatSize(h.Bytes), tui.FormatCount(h.Files), tui.FormatCount(h.Dirs))
+	if h.Duration >= time.Second {
+		fmt.Printf(", took %s", h.Duration.Truncate(time.Second))
+	}
+	fmt.Println()
+	if h.Label != "" {
+		fmt.Printf("  label      %s\n", h.Label)
+	}
+	fmt.Printf("  snapshot   %s\n", c.File(path, h.ScannedAt))
+	return nil
+}
+
+// captureSnapshot scans path, the working directory if empty, and saves a
+// snapshot of it the way the interface does, labeled unless label is empty,
+// so the next session has a fresh scan to compare with. It prints one line
+// saying where it went.
+func captureSnapshot(path, label string) error {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		path = wd
+	}
+	// Fail before scanning if the snapshot could not be saved
+	if _, err := openCache(); err != nil {
+		return err
+	}
+	ctrl := core.NewController(path)
+	defer ctrl.Stop() // Saves the scan in the stats
+	ctrl.SetVersion(Version)
+	ctrl.SetSnapshotLabel(label)
+	events, err := ctrl.StartScan(context.Background())
+	if err != nil {
+		return err
+	}
+
+	// The channel closes once the scan has given up its claim on the path
+	var root *model.Node
+	var saved *core.SnapshotSavedEvent
+	var scanErr error
+	for event := range events {
+		switch e := event.(type) {
+		case core.ScanCompletedEvent:
+			root, scanErr = e.Root, e.Err
+		case core.SnapshotSavedEvent:
+			saved = &e
+		}
+	}
+	if scanErr != nil {
+		return scanErr
+	}
+	if root == nil {
+		return fmt.Errorf("scan of %s ended without a result", path)
+	}
+	if saved != nil {
+		if saved.Err != nil {
+			return fmt.Errorf("save snapshot: %w", saved.Err)
+		}
+		fmt.Printf("Saved %s (%s in %s files) to %s\n", path, tui.FormatSize(root.TotalSize()),
+			tui.FormatCount(root.FileCount()), saved.File)
+		return nil
+	}
+
+	// Another diskdive was already scanning the path and saved the snapshot
+	if label != "" {
+		if err := labelLatest(path, label); err != nil {
+			return fmt.Errorf("label snapshot: %w", err)
+		}
+	}
+	fmt.Printf("Saved %s (%s in %s files), scanned by diskdive pid %d\n", path, tui.FormatSize(root.TotalSize()),
+		tui.FormatCount(root.FileCount()), ctrl.ScanState().Following)
+	return nil
+}
+
+// labelLatest labels the latest snapshot of path
+func labelLatest(path, label string) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	h, err := c.LoadHeader(path)
+	if err != nil {
+		return err
+	}
+	return c.Label(path, h.ScannedAt, label)
+}
+
+// exportSnapshot writes a saved snapshot of path, the working directory if
+// empty, to stdout: the newest one taken before since, or the latest if
+// since is empty
+func exportSnapshot(path, since, format string) error {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		path = wd
+	}
+	var before time.Time
+	if since != "" {
+		var err error
+		if before, err = parseSince(since, time.Now()); err != nil {
+			return err
+		}
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	return c.Export(os.Stdout, path, before, format)
+}
+
+// exportStats writes the stats store to stdout in format
+func exportStats(format string) error {
+	m := stats.NewManager()
+	defer m.Close()
+	if err := m.Load(); err != nil {
+		return err
+	}
+	return m.Export(os.Stdout, format)
+}
+
+// importDu saves the du -ab output in file, or on stdin if file is empty, as
+// a snapshot of the folder it lists, labeled unless label is empty, so later
+// scans can be compared with it
+func importDu(file, dir, label string) error {
+	in := os.Stdin
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		dir = wd
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	root, err := model.ReadDu(in, abs)
+	if err != nil {
+		return fmt.Errorf("read du output: %w", err)
+	}
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+	snap := cache.NewSnapshot(root, 0)
+	snap.Header.Env = cache.CaptureEnvironment(Version, []string{"imported from du"})
+	snap.Header.Label = label
+	if err := c.Write(root.Path(), snap); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %s (%s in %s files) to %s\n", root.Path(), tui.FormatSize(root.TotalSize()),
+		tui.FormatCount(root.FileCount()), c.File(root.Path(), snap.Header.ScannedAt))
+	return nil
+}
+
+// parseSince reads a --since value: a date such as 2024-05-01, a date and
+// time, or an age such as 7d or 36h counted back from now
+func parseSince(s string, now time.Time) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01-02 15:04", time.DateTime} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (want a date like 2024-05-01 or an age like 7d)", s)
+}
+
+// scanTree scans path, the working directory if empty, and computes sizes
+func scanTree(path string) (*model.Node, error) {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		path = wd
+	}
+
+	root, err := scanner.NewWalker(8, scanner.ScanPolicy{}).Scan(context.Background(), path)
+	if err != nil {
+		return nil, err
+	}
+	root.ComputeSizes()
+	return root, nil
 }
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/lumipallolabs/diskdive/internal/cache"
+	"github.com/lumipallolabs/diskdive/internal/core"
+	"github.com/lumipallolabs/diskdive/internal/model"
+	"github.com/lumipallolabs/diskdive/internal/ui/tui"
+	"github.com/lumipallolabs/diskdive/internal/watcher"
+)
+
+// selfTestWait bounds how long a step waits for the controller
+const selfTestWait = 30 * time.Second
+
+// selfTestTree is the generated folder the self test scans, as file paths
+// and sizes. The watch step deletes staleFile, creates addedFile, moves
+// renamedFrom to renamedTo and creates missedFile without telling.
+var selfTestTree = map[string]int{
+	"docs/notes.txt":       10 << 10,
+	"docs/report.pdf":      20 << 10,
+	"media/clip.mov":       300 << 10,
+	"media/raw/frame.dng":  200 << 10,
+	"cache/stale.tmp":      50 << 10,
+	"empty/.keep":          0,
+	"deep/a/b/c/d/leaf.md": 1 << 10,
+}
+
+const (
+	staleFile   = "cache/stale.tmp"
+	addedFile   = "docs/added.txt"
+	renamedFrom = "docs/notes.txt"
+	renamedTo   = "media/notes.txt"
+	missedFile  = "docs/missed.txt"
+)
+
+// selfTest checks a build without a terminal, for packagers and CI: it
+// generates a small tree in a temporary folder, scans it, saves and reloads
+// the snapshot, watches a file come and go, exports the scan and compares it
+// with the snapshot, printing a line per step. Config, stats and snapshots
+// go to a temporary home folder, so the user's own are left alone.
+func selfTest() error {
+	tmp, err := os.MkdirTemp("", "diskdive-selftest-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if err := os.Setenv(homeEnv(), filepath.Join(tmp, "home")); err != nil {
+		return err
+	}
+	t := &selfTestRun{tree: filepath.Join(tmp, "tree")}
+	for rel, size := range selfTestTree {
+		if err := t.writeFile(rel, size); err != nil {
+			return err
+		}
+		t.files++
+		t.bytes += int64(size)
+	}
+	defer func() {
+		if t.ctrl != nil {
+			t.ctrl.Stop()
+		}
+	}()
+
+	fmt.Printf("diskdive %s self test on %s/%s\n", Version, runtime.GOOS, runtime.GOARCH)
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"scan", t.scan},
+		{"snapshot", t.snapshot},
+		{"watch", t.watch},
+		{"export", t.export},
+		{"compare", t.compare},
+	}
+	for _, step := range steps {
+		detail, err := step.run()
+		if err != nil {
+			fmt.Printf("  FAIL  %-8s  %v\n", step.name, err)
+			return fmt.Errorf("self test failed at %s", step.name)
+		}
+		fmt.Printf("  ok    %-8s  %s\n", step.name, detail)
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// homeEnv names the environment variable os.UserHomeDir reads
+func homeEnv() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "USERPROFILE"
+	case "plan9":
+		return "home"
+	}
+	return "HOME"
+}
+
+// selfTestRun is the state the self test steps share
+type selfTestRun struct {
+	tree   string
+	files  int
+	bytes  int64 // Logical size of the generated files
+	ctrl   *core.Controller
+	events <-chan core.Event // Of the scan, until the snapshot is saved
+	root   *model.Node
+	saved  time.Time // When the snapshot was taken
+}
+
+// writeFile creates the file at rel below the tree with size bytes
+func (t *selfTestRun) writeFile(rel string, size int) error {
+	path := filepath.Join(t.tree, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, bytes.Repeat([]byte{'d'}, size), 0644)
+}
+
+// next waits for the next event of type E on ch, failing on error events
+func next[E core.Event](ch <-chan core.Event, what string) (E, error) {
+	var zero E
+	timeout := time.After(selfTestWait)
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return zero, fmt.Errorf("no %s: events ended", what)
+			}
+			if e, ok := event.(core.ErrorEvent); ok {
+				return zero, e.Err
+			}
+			if e, ok := event.(E); ok {
+				return e, nil
+			}
+		case <-timeout:
+			return zero, fmt.Errorf("no %s within %s", what, selfTestWait)
+		}
+	}
+}
+
+// scan scans the tree through the controller and checks what it found
+func (t *selfTestRun) scan() (string, error) {
+	t.ctrl = core.NewController(t.tree)
+	t.ctrl.SetVersion(Version)
+	events, err := t.ctrl.StartScan(context.Background())
+	if err != nil {
+		return "", err
+	}
+	t.events = events
+	done, err := next[core.ScanCompletedEvent](events, "completed scan")
+	if err != nil {
+		return "", err
+	}
+	if done.Err != nil {
+		return "", done.Err
+	}
+	t.root = done.Root
+	if got := t.root.FileCount(); got != int64(t.files) {
+		return "", fmt.Errorf("found %d files, want %d", got, t.files)
+	}
+	if t.root.Logical != t.bytes {
+		return "", fmt.Errorf("found %d bytes, want %d", t.root.Logical, t.bytes)
+	}
+	if t.root.Find(filepath.Join(t.tree, "deep", "a", "b", "c", "d")) == nil {
+		return "", fmt.Errorf("nested folder missing from the tree")
+	}
+	return fmt.Sprintf("%d files, %s", t.files, tui.FormatSize(t.bytes)), nil
+}
+
+// snapshot waits for the scan's snapshot and reads it back
+func (t *selfTestRun) snapshot() (string, error) {
+	saved, err := next[core.SnapshotSavedEvent](t.events, "saved snapshot")
+	if err != nil {
+		return "", err
+	}
+	if saved.Err != nil {
+		return "", saved.Err
+	}
+	header, err := cache.New(cache.DefaultDir()).LoadHeader(t.tree)
+	if err != nil {
+		return "", err
+	}
+	if header.Files != int64(t.files) || header.Bytes != t.root.TotalSize() {
+		return "", fmt.Errorf("snapshot holds %d files and %d bytes, want %d and %d", header.Files, header.Bytes, t.files, t.root.TotalSize())
+	}
+	t.saved = header.ScannedAt
+	return filepath.Base(saved.File), nil
+}
+
+// watch deletes one file, creates another and moves a third, tells the
+// controller as the watcher would, and waits for the tree to follow. It
+// then creates a file and reports the event as lost, which should have the
+// folder rescanned. The changes are
+// simulated so the step runs the same on platforms without a watcher.
+func (t *selfTestRun) watch() (string, error) {
+	t.ctrl.FinalizeScan()
+	changes := make(chan watcher.Event, 1)
+	defer close(changes)
+	events, err := t.ctrl.SimulateWatching(changes)
+	if err != nil {
+		return "", err
+	}
+
+	stale := filepath.Join(t.tree, filepath.FromSlash(staleFile))
+	if err := os.Remove(stale); err != nil {
+		return "", err
+	}
+	changes <- watcher.Event{Type: watcher.EventDeleted, Path: stale}
+	deleted, err := next[core.DeletionDetectedEvent](events, "deletion")
+	if err != nil {
+		return "", err
+	}
+	if deleted.Node == nil || !deleted.Node.IsDeleted {
+		return "", fmt.Errorf("deleted %s not marked in the tree", deleted.Path)
+	}
+
+	if err := t.writeFile(addedFile, 4<<10); err != nil {
+		return "", err
+	}
+	changes <- watcher.Event{Type: watcher.EventCreated, Path: filepath.Join(t.tree, filepath.FromSlash(addedFile))}
+	created, err := next[core.CreationDetectedEvent](events, "creation")
+	if err != nil {
+		return "", err
+	}
+	if t.root.Find(filepath.Join(t.tree, filepath.FromSlash(addedFile))) == nil {
+		return "", fmt.Errorf("created file missing from the tree after %s", created.Path)
+	}
+
+	from := filepath.Join(t.tree, filepath.FromSlash(renamedFrom))
+	to := filepath.Join(t.tree, filepath.FromSlash(renamedTo))
+	node := t.root.Find(from)
+	if err := os.Rename(from, to); err != nil {
+		return "", err
+	}
+	changes <- watcher.Event{Type: watcher.EventRenamed, Path: to, OldPath: from}
+	if _, err := next[core.MoveDetectedEvent](events, "move"); err != nil {
+		return "", err
+	}
+	if node == nil || t.root.Find(to) != node || t.root.Find(from) != nil {
+		return "", fmt.Errorf("moved file not moved in the tree")
+	}
+
+	if err := t.writeFile(missedFile, 2<<10); err != nil {
+		return "", err
+	}
+	missed := filepath.Join(t.tree, filepath.FromSlash(missedFile))
+	changes <- watcher.Event{Type: watcher.EventOverflow, Path: filepath.Dir(missed)}
+	if _, err := next[core.ResyncedEvent](events, "rescan after lost events"); err != nil {
+		return "", err
+	}
+	if t.root.Find(missed) == nil {
+		return "", fmt.Errorf("file created while events were lost missing after the rescan")
+	}
+	return "deletion, creation, move and lost events handled", nil
+}
+
+// export writes the live tree as ncdu JSON and the snapshot as CSV
+func (t *selfTestRun) export() (string, error) {
+	var ncdu bytes.Buffer
+	if err := model.WriteNcdu(&ncdu, t.ctrl.Root(), model.NewNcduHeader(Version, time.Now())); err != nil {
+		return "", err
+	}
+	if !json.Valid(ncdu.Bytes()) {
+		return "", fmt.Errorf("ncdu export is not valid JSON")
+	}
+	if !strings.Contains(ncdu.String(), `"added.txt"`) {
+		return "", fmt.Errorf("ncdu export lacks the created file")
+	}
+
+	var csv bytes.Buffer
+	if err := cache.New(cache.DefaultDir()).Export(&csv, t.tree, time.Time{}, "csv"); err != nil {
+		return "", err
+	}
+	rows := strings.Count(csv.String(), "\n") - 1 // Less the column names
+	if rows < t.files {
+		return "", fmt.Errorf("csv export has %d rows, want at least %d", rows, t.files)
+	}
+	return fmt.Sprintf("ncdu %s, csv %d rows", tui.FormatSize(int64(ncdu.Len())), rows), nil
+}
+
+// compare compares the live tree with the snapshot taken before the watch
+func (t *selfTestRun) compare() (string, error) {
+	diff, err := t.ctrl.Compare(t.saved, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	added := diff.Find(filepath.Join(t.tree, filepath.FromSlash(addedFile)))
+	if added == nil || !added.IsNew {
+		return "", fmt.Errorf("created file not flagged new")
+	}
+	stale := diff.Find(filepath.Join(t.tree, filepath.FromSlash(staleFile)))
+	if stale == nil || !stale.IsDeleted {
+		return "", fmt.Errorf("deleted file not shown as deleted")
+	}
+	return "new and deleted items found", nil
+}